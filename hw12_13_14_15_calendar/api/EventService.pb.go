@@ -7,6 +7,7 @@
 package api
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	durationpb "google.golang.org/protobuf/types/known/durationpb"
@@ -23,14 +24,70 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Режим пакетного запроса: по умолчанию ошибка любой операции отменяет весь пакет
+type BatchMode int32
+
+const (
+	BatchMode_ALL_OR_NOTHING BatchMode = 0
+	BatchMode_BEST_EFFORT    BatchMode = 1
+)
+
+// Enum value maps for BatchMode.
+var (
+	BatchMode_name = map[int32]string{
+		0: "ALL_OR_NOTHING",
+		1: "BEST_EFFORT",
+	}
+	BatchMode_value = map[string]int32{
+		"ALL_OR_NOTHING": 0,
+		"BEST_EFFORT":    1,
+	}
+)
+
+func (x BatchMode) Enum() *BatchMode {
+	p := new(BatchMode)
+	*p = x
+	return p
+}
+
+func (x BatchMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BatchMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_EventService_proto_enumTypes[0].Descriptor()
+}
+
+func (BatchMode) Type() protoreflect.EnumType {
+	return &file_api_EventService_proto_enumTypes[0]
+}
+
+func (x BatchMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BatchMode.Descriptor instead.
+func (BatchMode) EnumDescriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{0}
+}
+
 type CreateEventRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
-	StartTime     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=startTime,proto3" json:"startTime,omitempty"`
-	Duration      *durationpb.Duration   `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
-	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
-	UserId        string                 `protobuf:"bytes,5,opt,name=userId,proto3" json:"userId,omitempty"`
-	NotifyBefore  *durationpb.Duration   `protobuf:"bytes,6,opt,name=notifyBefore,proto3" json:"notifyBefore,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Title        string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	StartTime    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	Duration     *durationpb.Duration   `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
+	Description  string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	UserId       string                 `protobuf:"bytes,5,opt,name=userId,proto3" json:"userId,omitempty"`
+	NotifyBefore *durationpb.Duration   `protobuf:"bytes,6,opt,name=notifyBefore,proto3" json:"notifyBefore,omitempty"`
+	// Календарь события; пусто - личный календарь пользователя
+	CalendarId string `protobuf:"bytes,7,opt,name=calendarId,proto3" json:"calendarId,omitempty"`
+	// Ключ идемпотентности: повтор запроса с тем же ключом вернёт уже созданное событие.
+	// Вместо поля можно передать метаданные idempotency-key (в REST - заголовок Idempotency-Key).
+	RequestId string `protobuf:"bytes,8,opt,name=requestId,proto3" json:"requestId,omitempty"`
+	// Категория и цвет (#rrggbb) для отображения, теги для фильтрации
+	Category      string   `protobuf:"bytes,9,opt,name=category,proto3" json:"category,omitempty"`
+	Color         string   `protobuf:"bytes,10,opt,name=color,proto3" json:"color,omitempty"`
+	Tags          []string `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -107,15 +164,56 @@ func (x *CreateEventRequest) GetNotifyBefore() *durationpb.Duration {
 	return nil
 }
 
+func (x *CreateEventRequest) GetCalendarId() string {
+	if x != nil {
+		return x.CalendarId
+	}
+	return ""
+}
+
+func (x *CreateEventRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *CreateEventRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *CreateEventRequest) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *CreateEventRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
 type UpdateEventRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	StartTime     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=startTime,proto3" json:"startTime,omitempty"`
-	Duration      *durationpb.Duration   `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
-	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
-	UserId        string                 `protobuf:"bytes,6,opt,name=userId,proto3" json:"userId,omitempty"`
-	NotifyBefore  *durationpb.Duration   `protobuf:"bytes,7,opt,name=notifyBefore,proto3" json:"notifyBefore,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Id           string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title        string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	StartTime    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	Duration     *durationpb.Duration   `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	Description  string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	UserId       string                 `protobuf:"bytes,6,opt,name=userId,proto3" json:"userId,omitempty"`
+	NotifyBefore *durationpb.Duration   `protobuf:"bytes,7,opt,name=notifyBefore,proto3" json:"notifyBefore,omitempty"`
+	// Ожидаемая текущая версия события; 0 - обновить без проверки
+	ExpectedVersion int64 `protobuf:"varint,8,opt,name=expectedVersion,proto3" json:"expectedVersion,omitempty"`
+	// Разметка заменяется целиком, как и остальные поля
+	Category      string   `protobuf:"bytes,9,opt,name=category,proto3" json:"category,omitempty"`
+	Color         string   `protobuf:"bytes,10,opt,name=color,proto3" json:"color,omitempty"`
+	Tags          []string `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -199,11 +297,41 @@ func (x *UpdateEventRequest) GetNotifyBefore() *durationpb.Duration {
 	return nil
 }
 
+func (x *UpdateEventRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *UpdateEventRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *UpdateEventRequest) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *UpdateEventRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
 type DeleteEventRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Ожидаемая текущая версия события; 0 - удалить без проверки
+	ExpectedVersion int64 `protobuf:"varint,2,opt,name=expectedVersion,proto3" json:"expectedVersion,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *DeleteEventRequest) Reset() {
@@ -243,6 +371,13 @@ func (x *DeleteEventRequest) GetId() string {
 	return ""
 }
 
+func (x *DeleteEventRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
 type DeleteEventResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -331,9 +466,11 @@ func (x *GetEventRequest) GetId() string {
 	return ""
 }
 
+// tags - вернуть только события, отмеченные всеми перечисленными тегами
 type ListEventsForDayRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Date          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -375,9 +512,17 @@ func (x *ListEventsForDayRequest) GetDate() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *ListEventsForDayRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
 type ListEventsForWeekRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Date          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -419,9 +564,17 @@ func (x *ListEventsForWeekRequest) GetDate() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *ListEventsForWeekRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
 type ListEventsForMonthRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Date          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -463,27 +616,33 @@ func (x *ListEventsForMonthRequest) GetDate() *timestamppb.Timestamp {
 	return nil
 }
 
-type ListEventsResponse struct {
+func (x *ListEventsForMonthRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type ListDeletedEventsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Events        []*EventResponse       `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListEventsResponse) Reset() {
-	*x = ListEventsResponse{}
+func (x *ListDeletedEventsRequest) Reset() {
+	*x = ListDeletedEventsRequest{}
 	mi := &file_api_EventService_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListEventsResponse) String() string {
+func (x *ListDeletedEventsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListEventsResponse) ProtoMessage() {}
+func (*ListDeletedEventsRequest) ProtoMessage() {}
 
-func (x *ListEventsResponse) ProtoReflect() protoreflect.Message {
+func (x *ListDeletedEventsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_EventService_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -495,45 +654,32 @@ func (x *ListEventsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListEventsResponse.ProtoReflect.Descriptor instead.
-func (*ListEventsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListDeletedEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListDeletedEventsRequest) Descriptor() ([]byte, []int) {
 	return file_api_EventService_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ListEventsResponse) GetEvents() []*EventResponse {
-	if x != nil {
-		return x.Events
-	}
-	return nil
-}
-
-type EventResponse struct {
+type RestoreEventRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	StartTime     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=startTime,proto3" json:"startTime,omitempty"`
-	Duration      *durationpb.Duration   `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
-	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
-	UserId        string                 `protobuf:"bytes,6,opt,name=userId,proto3" json:"userId,omitempty"`
-	NotifyBefore  *durationpb.Duration   `protobuf:"bytes,7,opt,name=notifyBefore,proto3" json:"notifyBefore,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EventResponse) Reset() {
-	*x = EventResponse{}
+func (x *RestoreEventRequest) Reset() {
+	*x = RestoreEventRequest{}
 	mi := &file_api_EventService_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EventResponse) String() string {
+func (x *RestoreEventRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EventResponse) ProtoMessage() {}
+func (*RestoreEventRequest) ProtoMessage() {}
 
-func (x *EventResponse) ProtoReflect() protoreflect.Message {
+func (x *RestoreEventRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_EventService_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -545,190 +691,1882 @@ func (x *EventResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EventResponse.ProtoReflect.Descriptor instead.
-func (*EventResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use RestoreEventRequest.ProtoReflect.Descriptor instead.
+func (*RestoreEventRequest) Descriptor() ([]byte, []int) {
 	return file_api_EventService_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *EventResponse) GetId() string {
+func (x *RestoreEventRequest) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-func (x *EventResponse) GetTitle() string {
+type GetEventHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEventHistoryRequest) Reset() {
+	*x = GetEventHistoryRequest{}
+	mi := &file_api_EventService_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEventHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEventHistoryRequest) ProtoMessage() {}
+
+func (x *GetEventHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[10]
 	if x != nil {
-		return x.Title
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEventHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetEventHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetEventHistoryRequest) GetId() string {
+	if x != nil {
+		return x.Id
 	}
 	return ""
 }
 
-func (x *EventResponse) GetStartTime() *timestamppb.Timestamp {
+// Запись журнала изменений; before и after не заданы, если события не было видно
+type AuditRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	EventId       string                 `protobuf:"bytes,2,opt,name=eventId,proto3" json:"eventId,omitempty"`
+	Action        string                 `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Actor         string                 `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	RequestId     string                 `protobuf:"bytes,5,opt,name=requestId,proto3" json:"requestId,omitempty"`
+	Before        *EventResponse         `protobuf:"bytes,6,opt,name=before,proto3" json:"before,omitempty"`
+	After         *EventResponse         `protobuf:"bytes,7,opt,name=after,proto3" json:"after,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditRecord) Reset() {
+	*x = AuditRecord{}
+	mi := &file_api_EventService_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditRecord) ProtoMessage() {}
+
+func (x *AuditRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[11]
 	if x != nil {
-		return x.StartTime
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *EventResponse) GetDuration() *durationpb.Duration {
+// Deprecated: Use AuditRecord.ProtoReflect.Descriptor instead.
+func (*AuditRecord) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AuditRecord) GetId() int64 {
 	if x != nil {
-		return x.Duration
+		return x.Id
 	}
-	return nil
+	return 0
 }
 
-func (x *EventResponse) GetDescription() string {
+func (x *AuditRecord) GetEventId() string {
 	if x != nil {
-		return x.Description
+		return x.EventId
 	}
 	return ""
 }
 
-func (x *EventResponse) GetUserId() string {
+func (x *AuditRecord) GetAction() string {
 	if x != nil {
-		return x.UserId
+		return x.Action
 	}
 	return ""
 }
 
-func (x *EventResponse) GetNotifyBefore() *durationpb.Duration {
+func (x *AuditRecord) GetActor() string {
 	if x != nil {
-		return x.NotifyBefore
+		return x.Actor
 	}
-	return nil
+	return ""
 }
 
-var File_api_EventService_proto protoreflect.FileDescriptor
+func (x *AuditRecord) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
 
-const file_api_EventService_proto_rawDesc = "" +
-	"\n" +
-	"\x16api/EventService.proto\x12\x05event\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\"\x94\x02\n" +
-	"\x12CreateEventRequest\x12\x14\n" +
-	"\x05title\x18\x01 \x01(\tR\x05title\x128\n" +
-	"\tstartTime\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
-	"\bduration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12 \n" +
-	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x16\n" +
-	"\x06userId\x18\x05 \x01(\tR\x06userId\x12=\n" +
-	"\fnotifyBefore\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\fnotifyBefore\"\xa4\x02\n" +
-	"\x12UpdateEventRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
-	"\x05title\x18\x02 \x01(\tR\x05title\x128\n" +
-	"\tstartTime\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
-	"\bduration\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12 \n" +
-	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x16\n" +
-	"\x06userId\x18\x06 \x01(\tR\x06userId\x12=\n" +
-	"\fnotifyBefore\x18\a \x01(\v2\x19.google.protobuf.DurationR\fnotifyBefore\"$\n" +
-	"\x12DeleteEventRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"/\n" +
-	"\x13DeleteEventResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"!\n" +
-	"\x0fGetEventRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"I\n" +
-	"\x17ListEventsForDayRequest\x12.\n" +
-	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\"J\n" +
-	"\x18ListEventsForWeekRequest\x12.\n" +
-	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\"K\n" +
-	"\x19ListEventsForMonthRequest\x12.\n" +
-	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\"B\n" +
-	"\x12ListEventsResponse\x12,\n" +
-	"\x06events\x18\x01 \x03(\v2\x14.event.EventResponseR\x06events\"\x9f\x02\n" +
-	"\rEventResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
-	"\x05title\x18\x02 \x01(\tR\x05title\x128\n" +
-	"\tstartTime\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
-	"\bduration\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12 \n" +
-	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x16\n" +
-	"\x06userId\x18\x06 \x01(\tR\x06userId\x12=\n" +
-	"\fnotifyBefore\x18\a \x01(\v2\x19.google.protobuf.DurationR\fnotifyBefore2\x84\x04\n" +
-	"\x0fCalendarService\x12>\n" +
-	"\vCreateEvent\x12\x19.event.CreateEventRequest\x1a\x14.event.EventResponse\x12>\n" +
-	"\vUpdateEvent\x12\x19.event.UpdateEventRequest\x1a\x14.event.EventResponse\x12D\n" +
-	"\vDeleteEvent\x12\x19.event.DeleteEventRequest\x1a\x1a.event.DeleteEventResponse\x128\n" +
-	"\bGetEvent\x12\x16.event.GetEventRequest\x1a\x14.event.EventResponse\x12M\n" +
-	"\x10ListEventsForDay\x12\x1e.event.ListEventsForDayRequest\x1a\x19.event.ListEventsResponse\x12O\n" +
-	"\x11ListEventsForWeek\x12\x1f.event.ListEventsForWeekRequest\x1a\x19.event.ListEventsResponse\x12Q\n" +
-	"\x12ListEventsForMonth\x12 .event.ListEventsForMonthRequest\x1a\x19.event.ListEventsResponseB\vZ\t./api;apib\x06proto3"
+func (x *AuditRecord) GetBefore() *EventResponse {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
 
-var (
-	file_api_EventService_proto_rawDescOnce sync.Once
-	file_api_EventService_proto_rawDescData []byte
-)
+func (x *AuditRecord) GetAfter() *EventResponse {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
 
-func file_api_EventService_proto_rawDescGZIP() []byte {
-	file_api_EventService_proto_rawDescOnce.Do(func() {
-		file_api_EventService_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_EventService_proto_rawDesc), len(file_api_EventService_proto_rawDesc)))
-	})
-	return file_api_EventService_proto_rawDescData
+func (x *AuditRecord) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
 }
 
-var file_api_EventService_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
-var file_api_EventService_proto_goTypes = []any{
-	(*CreateEventRequest)(nil),        // 0: event.CreateEventRequest
-	(*UpdateEventRequest)(nil),        // 1: event.UpdateEventRequest
-	(*DeleteEventRequest)(nil),        // 2: event.DeleteEventRequest
-	(*DeleteEventResponse)(nil),       // 3: event.DeleteEventResponse
-	(*GetEventRequest)(nil),           // 4: event.GetEventRequest
-	(*ListEventsForDayRequest)(nil),   // 5: event.ListEventsForDayRequest
-	(*ListEventsForWeekRequest)(nil),  // 6: event.ListEventsForWeekRequest
-	(*ListEventsForMonthRequest)(nil), // 7: event.ListEventsForMonthRequest
-	(*ListEventsResponse)(nil),        // 8: event.ListEventsResponse
-	(*EventResponse)(nil),             // 9: event.EventResponse
-	(*timestamppb.Timestamp)(nil),     // 10: google.protobuf.Timestamp
-	(*durationpb.Duration)(nil),       // 11: google.protobuf.Duration
+type EventHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*AuditRecord         `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
-var file_api_EventService_proto_depIdxs = []int32{
-	10, // 0: event.CreateEventRequest.startTime:type_name -> google.protobuf.Timestamp
-	11, // 1: event.CreateEventRequest.duration:type_name -> google.protobuf.Duration
-	11, // 2: event.CreateEventRequest.notifyBefore:type_name -> google.protobuf.Duration
-	10, // 3: event.UpdateEventRequest.startTime:type_name -> google.protobuf.Timestamp
-	11, // 4: event.UpdateEventRequest.duration:type_name -> google.protobuf.Duration
-	11, // 5: event.UpdateEventRequest.notifyBefore:type_name -> google.protobuf.Duration
-	10, // 6: event.ListEventsForDayRequest.date:type_name -> google.protobuf.Timestamp
-	10, // 7: event.ListEventsForWeekRequest.date:type_name -> google.protobuf.Timestamp
-	10, // 8: event.ListEventsForMonthRequest.date:type_name -> google.protobuf.Timestamp
-	9,  // 9: event.ListEventsResponse.events:type_name -> event.EventResponse
-	10, // 10: event.EventResponse.startTime:type_name -> google.protobuf.Timestamp
-	11, // 11: event.EventResponse.duration:type_name -> google.protobuf.Duration
-	11, // 12: event.EventResponse.notifyBefore:type_name -> google.protobuf.Duration
-	0,  // 13: event.CalendarService.CreateEvent:input_type -> event.CreateEventRequest
-	1,  // 14: event.CalendarService.UpdateEvent:input_type -> event.UpdateEventRequest
-	2,  // 15: event.CalendarService.DeleteEvent:input_type -> event.DeleteEventRequest
-	4,  // 16: event.CalendarService.GetEvent:input_type -> event.GetEventRequest
-	5,  // 17: event.CalendarService.ListEventsForDay:input_type -> event.ListEventsForDayRequest
-	6,  // 18: event.CalendarService.ListEventsForWeek:input_type -> event.ListEventsForWeekRequest
-	7,  // 19: event.CalendarService.ListEventsForMonth:input_type -> event.ListEventsForMonthRequest
-	9,  // 20: event.CalendarService.CreateEvent:output_type -> event.EventResponse
-	9,  // 21: event.CalendarService.UpdateEvent:output_type -> event.EventResponse
-	3,  // 22: event.CalendarService.DeleteEvent:output_type -> event.DeleteEventResponse
-	9,  // 23: event.CalendarService.GetEvent:output_type -> event.EventResponse
-	8,  // 24: event.CalendarService.ListEventsForDay:output_type -> event.ListEventsResponse
-	8,  // 25: event.CalendarService.ListEventsForWeek:output_type -> event.ListEventsResponse
-	8,  // 26: event.CalendarService.ListEventsForMonth:output_type -> event.ListEventsResponse
-	20, // [20:27] is the sub-list for method output_type
-	13, // [13:20] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+
+func (x *EventHistoryResponse) Reset() {
+	*x = EventHistoryResponse{}
+	mi := &file_api_EventService_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func init() { file_api_EventService_proto_init() }
-func file_api_EventService_proto_init() {
-	if File_api_EventService_proto != nil {
-		return
+func (x *EventHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventHistoryResponse) ProtoMessage() {}
+
+func (x *EventHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventHistoryResponse.ProtoReflect.Descriptor instead.
+func (*EventHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *EventHistoryResponse) GetRecords() []*AuditRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+// Поиск по словам из названия и описания; from включительно, to - не включая
+type SearchEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=userId,proto3" json:"userId,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchEventsRequest) Reset() {
+	*x = SearchEventsRequest{}
+	mi := &file_api_EventService_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchEventsRequest) ProtoMessage() {}
+
+func (x *SearchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchEventsRequest.ProtoReflect.Descriptor instead.
+func (*SearchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SearchEventsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchEventsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SearchEventsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *SearchEventsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *SearchEventsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *EventResponse         `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Rank          float64                `protobuf:"fixed64,2,opt,name=rank,proto3" json:"rank,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_api_EventService_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SearchResult) GetEvent() *EventResponse {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *SearchResult) GetRank() float64 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+type SearchEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchEventsResponse) Reset() {
+	*x = SearchEventsResponse{}
+	mi := &file_api_EventService_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchEventsResponse) ProtoMessage() {}
+
+func (x *SearchEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchEventsResponse.ProtoReflect.Descriptor instead.
+func (*SearchEventsResponse) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SearchEventsResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchOperation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Operation:
+	//
+	//	*BatchOperation_Create
+	//	*BatchOperation_Update
+	//	*BatchOperation_Delete
+	Operation     isBatchOperation_Operation `protobuf_oneof:"operation"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchOperation) Reset() {
+	*x = BatchOperation{}
+	mi := &file_api_EventService_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchOperation) ProtoMessage() {}
+
+func (x *BatchOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchOperation.ProtoReflect.Descriptor instead.
+func (*BatchOperation) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BatchOperation) GetOperation() isBatchOperation_Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+func (x *BatchOperation) GetCreate() *CreateEventRequest {
+	if x != nil {
+		if x, ok := x.Operation.(*BatchOperation_Create); ok {
+			return x.Create
+		}
+	}
+	return nil
+}
+
+func (x *BatchOperation) GetUpdate() *UpdateEventRequest {
+	if x != nil {
+		if x, ok := x.Operation.(*BatchOperation_Update); ok {
+			return x.Update
+		}
+	}
+	return nil
+}
+
+func (x *BatchOperation) GetDelete() *DeleteEventRequest {
+	if x != nil {
+		if x, ok := x.Operation.(*BatchOperation_Delete); ok {
+			return x.Delete
+		}
+	}
+	return nil
+}
+
+type isBatchOperation_Operation interface {
+	isBatchOperation_Operation()
+}
+
+type BatchOperation_Create struct {
+	Create *CreateEventRequest `protobuf:"bytes,1,opt,name=create,proto3,oneof"`
+}
+
+type BatchOperation_Update struct {
+	Update *UpdateEventRequest `protobuf:"bytes,2,opt,name=update,proto3,oneof"`
+}
+
+type BatchOperation_Delete struct {
+	Delete *DeleteEventRequest `protobuf:"bytes,3,opt,name=delete,proto3,oneof"`
+}
+
+func (*BatchOperation_Create) isBatchOperation_Operation() {}
+
+func (*BatchOperation_Update) isBatchOperation_Operation() {}
+
+func (*BatchOperation_Delete) isBatchOperation_Operation() {}
+
+type BatchMutateEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mode          BatchMode              `protobuf:"varint,1,opt,name=mode,proto3,enum=event.BatchMode" json:"mode,omitempty"`
+	Operations    []*BatchOperation      `protobuf:"bytes,2,rep,name=operations,proto3" json:"operations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchMutateEventsRequest) Reset() {
+	*x = BatchMutateEventsRequest{}
+	mi := &file_api_EventService_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchMutateEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchMutateEventsRequest) ProtoMessage() {}
+
+func (x *BatchMutateEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchMutateEventsRequest.ProtoReflect.Descriptor instead.
+func (*BatchMutateEventsRequest) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BatchMutateEventsRequest) GetMode() BatchMode {
+	if x != nil {
+		return x.Mode
+	}
+	return BatchMode_ALL_OR_NOTHING
+}
+
+func (x *BatchMutateEventsRequest) GetOperations() []*BatchOperation {
+	if x != nil {
+		return x.Operations
+	}
+	return nil
+}
+
+// Результат одной операции; code - gRPC-код, который вернул бы одиночный вызов
+type BatchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Code          int32                  `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Version       int64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchResult) Reset() {
+	*x = BatchResult{}
+	mi := &file_api_EventService_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResult) ProtoMessage() {}
+
+func (x *BatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResult.ProtoReflect.Descriptor instead.
+func (*BatchResult) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BatchResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BatchResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *BatchResult) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type BatchMutateEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Applied       int32                  `protobuf:"varint,1,opt,name=applied,proto3" json:"applied,omitempty"`
+	Results       []*BatchResult         `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchMutateEventsResponse) Reset() {
+	*x = BatchMutateEventsResponse{}
+	mi := &file_api_EventService_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchMutateEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchMutateEventsResponse) ProtoMessage() {}
+
+func (x *BatchMutateEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchMutateEventsResponse.ProtoReflect.Descriptor instead.
+func (*BatchMutateEventsResponse) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BatchMutateEventsResponse) GetApplied() int32 {
+	if x != nil {
+		return x.Applied
+	}
+	return 0
+}
+
+func (x *BatchMutateEventsResponse) GetResults() []*BatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ListEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*EventResponse       `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEventsResponse) Reset() {
+	*x = ListEventsResponse{}
+	mi := &file_api_EventService_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEventsResponse) ProtoMessage() {}
+
+func (x *ListEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListEventsResponse) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListEventsResponse) GetEvents() []*EventResponse {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type EventResponse struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Id           string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title        string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	StartTime    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	Duration     *durationpb.Duration   `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	Description  string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	UserId       string                 `protobuf:"bytes,6,opt,name=userId,proto3" json:"userId,omitempty"`
+	NotifyBefore *durationpb.Duration   `protobuf:"bytes,7,opt,name=notifyBefore,proto3" json:"notifyBefore,omitempty"`
+	Version      int64                  `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
+	// Когда событие перенесено в корзину; не задано для активных событий
+	DeletedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=deletedAt,proto3" json:"deletedAt,omitempty"`
+	CalendarId    string                 `protobuf:"bytes,10,opt,name=calendarId,proto3" json:"calendarId,omitempty"`
+	Category      string                 `protobuf:"bytes,11,opt,name=category,proto3" json:"category,omitempty"`
+	Color         string                 `protobuf:"bytes,12,opt,name=color,proto3" json:"color,omitempty"`
+	Tags          []string               `protobuf:"bytes,13,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventResponse) Reset() {
+	*x = EventResponse{}
+	mi := &file_api_EventService_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventResponse) ProtoMessage() {}
+
+func (x *EventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventResponse.ProtoReflect.Descriptor instead.
+func (*EventResponse) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *EventResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EventResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *EventResponse) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *EventResponse) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *EventResponse) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *EventResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *EventResponse) GetNotifyBefore() *durationpb.Duration {
+	if x != nil {
+		return x.NotifyBefore
+	}
+	return nil
+}
+
+func (x *EventResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *EventResponse) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *EventResponse) GetCalendarId() string {
+	if x != nil {
+		return x.CalendarId
+	}
+	return ""
+}
+
+func (x *EventResponse) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *EventResponse) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *EventResponse) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// Статистика тегов по событиям с началом в [from, to); незаданные границы не ограничивают выборку
+type GetTagStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTagStatsRequest) Reset() {
+	*x = GetTagStatsRequest{}
+	mi := &file_api_EventService_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTagStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTagStatsRequest) ProtoMessage() {}
+
+func (x *GetTagStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTagStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetTagStatsRequest) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetTagStatsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetTagStatsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *GetTagStatsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type TagStat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagStat) Reset() {
+	*x = TagStat{}
+	mi := &file_api_EventService_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagStat) ProtoMessage() {}
+
+func (x *TagStat) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagStat.ProtoReflect.Descriptor instead.
+func (*TagStat) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *TagStat) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *TagStat) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type TagStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []*TagStat             `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagStatsResponse) Reset() {
+	*x = TagStatsResponse{}
+	mi := &file_api_EventService_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagStatsResponse) ProtoMessage() {}
+
+func (x *TagStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagStatsResponse.ProtoReflect.Descriptor instead.
+func (*TagStatsResponse) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TagStatsResponse) GetTags() []*TagStat {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type Calendar struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// personal, team или shared
+	Kind    string `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	OwnerId string `protobuf:"bytes,4,opt,name=ownerId,proto3" json:"ownerId,omitempty"`
+	// Роль текущего пользователя: free-busy, read, write или owner
+	Role          string `protobuf:"bytes,5,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Calendar) Reset() {
+	*x = Calendar{}
+	mi := &file_api_EventService_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Calendar) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Calendar) ProtoMessage() {}
+
+func (x *Calendar) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Calendar.ProtoReflect.Descriptor instead.
+func (*Calendar) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Calendar) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Calendar) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Calendar) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Calendar) GetOwnerId() string {
+	if x != nil {
+		return x.OwnerId
+	}
+	return ""
+}
+
+func (x *Calendar) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type CreateCalendarRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCalendarRequest) Reset() {
+	*x = CreateCalendarRequest{}
+	mi := &file_api_EventService_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCalendarRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCalendarRequest) ProtoMessage() {}
+
+func (x *CreateCalendarRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCalendarRequest.ProtoReflect.Descriptor instead.
+func (*CreateCalendarRequest) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *CreateCalendarRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateCalendarRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+type ListCalendarsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCalendarsRequest) Reset() {
+	*x = ListCalendarsRequest{}
+	mi := &file_api_EventService_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCalendarsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCalendarsRequest) ProtoMessage() {}
+
+func (x *ListCalendarsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCalendarsRequest.ProtoReflect.Descriptor instead.
+func (*ListCalendarsRequest) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{27}
+}
+
+type ListCalendarsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Calendars     []*Calendar            `protobuf:"bytes,1,rep,name=calendars,proto3" json:"calendars,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCalendarsResponse) Reset() {
+	*x = ListCalendarsResponse{}
+	mi := &file_api_EventService_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCalendarsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCalendarsResponse) ProtoMessage() {}
+
+func (x *ListCalendarsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCalendarsResponse.ProtoReflect.Descriptor instead.
+func (*ListCalendarsResponse) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListCalendarsResponse) GetCalendars() []*Calendar {
+	if x != nil {
+		return x.Calendars
+	}
+	return nil
+}
+
+// Доступ к календарю; granteeType - user или group
+type ACLEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GranteeType   string                 `protobuf:"bytes,1,opt,name=granteeType,proto3" json:"granteeType,omitempty"`
+	GranteeId     string                 `protobuf:"bytes,2,opt,name=granteeId,proto3" json:"granteeId,omitempty"`
+	Role          string                 `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ACLEntry) Reset() {
+	*x = ACLEntry{}
+	mi := &file_api_EventService_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ACLEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ACLEntry) ProtoMessage() {}
+
+func (x *ACLEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ACLEntry.ProtoReflect.Descriptor instead.
+func (*ACLEntry) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ACLEntry) GetGranteeType() string {
+	if x != nil {
+		return x.GranteeType
+	}
+	return ""
+}
+
+func (x *ACLEntry) GetGranteeId() string {
+	if x != nil {
+		return x.GranteeId
+	}
+	return ""
+}
+
+func (x *ACLEntry) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type ShareCalendarRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CalendarId    string                 `protobuf:"bytes,1,opt,name=calendarId,proto3" json:"calendarId,omitempty"`
+	Entry         *ACLEntry              `protobuf:"bytes,2,opt,name=entry,proto3" json:"entry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareCalendarRequest) Reset() {
+	*x = ShareCalendarRequest{}
+	mi := &file_api_EventService_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareCalendarRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareCalendarRequest) ProtoMessage() {}
+
+func (x *ShareCalendarRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareCalendarRequest.ProtoReflect.Descriptor instead.
+func (*ShareCalendarRequest) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ShareCalendarRequest) GetCalendarId() string {
+	if x != nil {
+		return x.CalendarId
+	}
+	return ""
+}
+
+func (x *ShareCalendarRequest) GetEntry() *ACLEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+type ShareCalendarResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareCalendarResponse) Reset() {
+	*x = ShareCalendarResponse{}
+	mi := &file_api_EventService_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareCalendarResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareCalendarResponse) ProtoMessage() {}
+
+func (x *ShareCalendarResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareCalendarResponse.ProtoReflect.Descriptor instead.
+func (*ShareCalendarResponse) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{31}
+}
+
+type RevokeCalendarAccessRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CalendarId    string                 `protobuf:"bytes,1,opt,name=calendarId,proto3" json:"calendarId,omitempty"`
+	GranteeType   string                 `protobuf:"bytes,2,opt,name=granteeType,proto3" json:"granteeType,omitempty"`
+	GranteeId     string                 `protobuf:"bytes,3,opt,name=granteeId,proto3" json:"granteeId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeCalendarAccessRequest) Reset() {
+	*x = RevokeCalendarAccessRequest{}
+	mi := &file_api_EventService_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeCalendarAccessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeCalendarAccessRequest) ProtoMessage() {}
+
+func (x *RevokeCalendarAccessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeCalendarAccessRequest.ProtoReflect.Descriptor instead.
+func (*RevokeCalendarAccessRequest) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *RevokeCalendarAccessRequest) GetCalendarId() string {
+	if x != nil {
+		return x.CalendarId
+	}
+	return ""
+}
+
+func (x *RevokeCalendarAccessRequest) GetGranteeType() string {
+	if x != nil {
+		return x.GranteeType
+	}
+	return ""
+}
+
+func (x *RevokeCalendarAccessRequest) GetGranteeId() string {
+	if x != nil {
+		return x.GranteeId
+	}
+	return ""
+}
+
+type RevokeCalendarAccessResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeCalendarAccessResponse) Reset() {
+	*x = RevokeCalendarAccessResponse{}
+	mi := &file_api_EventService_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeCalendarAccessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeCalendarAccessResponse) ProtoMessage() {}
+
+func (x *RevokeCalendarAccessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeCalendarAccessResponse.ProtoReflect.Descriptor instead.
+func (*RevokeCalendarAccessResponse) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{33}
+}
+
+type ListCalendarAccessRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CalendarId    string                 `protobuf:"bytes,1,opt,name=calendarId,proto3" json:"calendarId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCalendarAccessRequest) Reset() {
+	*x = ListCalendarAccessRequest{}
+	mi := &file_api_EventService_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCalendarAccessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCalendarAccessRequest) ProtoMessage() {}
+
+func (x *ListCalendarAccessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCalendarAccessRequest.ProtoReflect.Descriptor instead.
+func (*ListCalendarAccessRequest) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListCalendarAccessRequest) GetCalendarId() string {
+	if x != nil {
+		return x.CalendarId
+	}
+	return ""
+}
+
+type ListCalendarAccessResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*ACLEntry            `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCalendarAccessResponse) Reset() {
+	*x = ListCalendarAccessResponse{}
+	mi := &file_api_EventService_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCalendarAccessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCalendarAccessResponse) ProtoMessage() {}
+
+func (x *ListCalendarAccessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_EventService_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCalendarAccessResponse.ProtoReflect.Descriptor instead.
+func (*ListCalendarAccessResponse) Descriptor() ([]byte, []int) {
+	return file_api_EventService_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ListCalendarAccessResponse) GetEntries() []*ACLEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+var File_api_EventService_proto protoreflect.FileDescriptor
+
+const file_api_EventService_proto_rawDesc = "" +
+	"\n" +
+	"\x16api/EventService.proto\x12\x05event\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1cgoogle/api/annotations.proto\"\x98\x03\n" +
+	"\x12CreateEventRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x128\n" +
+	"\tstartTime\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bduration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06userId\x18\x05 \x01(\tR\x06userId\x12=\n" +
+	"\fnotifyBefore\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\fnotifyBefore\x12\x1e\n" +
+	"\n" +
+	"calendarId\x18\a \x01(\tR\n" +
+	"calendarId\x12\x1c\n" +
+	"\trequestId\x18\b \x01(\tR\trequestId\x12\x1a\n" +
+	"\bcategory\x18\t \x01(\tR\bcategory\x12\x14\n" +
+	"\x05color\x18\n" +
+	" \x01(\tR\x05color\x12\x12\n" +
+	"\x04tags\x18\v \x03(\tR\x04tags\"\x94\x03\n" +
+	"\x12UpdateEventRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x128\n" +
+	"\tstartTime\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bduration\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06userId\x18\x06 \x01(\tR\x06userId\x12=\n" +
+	"\fnotifyBefore\x18\a \x01(\v2\x19.google.protobuf.DurationR\fnotifyBefore\x12(\n" +
+	"\x0fexpectedVersion\x18\b \x01(\x03R\x0fexpectedVersion\x12\x1a\n" +
+	"\bcategory\x18\t \x01(\tR\bcategory\x12\x14\n" +
+	"\x05color\x18\n" +
+	" \x01(\tR\x05color\x12\x12\n" +
+	"\x04tags\x18\v \x03(\tR\x04tags\"N\n" +
+	"\x12DeleteEventRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12(\n" +
+	"\x0fexpectedVersion\x18\x02 \x01(\x03R\x0fexpectedVersion\"/\n" +
+	"\x13DeleteEventResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"!\n" +
+	"\x0fGetEventRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"]\n" +
+	"\x17ListEventsForDayRequest\x12.\n" +
+	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\"^\n" +
+	"\x18ListEventsForWeekRequest\x12.\n" +
+	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\"_\n" +
+	"\x19ListEventsForMonthRequest\x12.\n" +
+	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\"\x1a\n" +
+	"\x18ListDeletedEventsRequest\"%\n" +
+	"\x13RestoreEventRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"(\n" +
+	"\x16GetEventHistoryRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x97\x02\n" +
+	"\vAuditRecord\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\aeventId\x18\x02 \x01(\tR\aeventId\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x12\x1c\n" +
+	"\trequestId\x18\x05 \x01(\tR\trequestId\x12,\n" +
+	"\x06before\x18\x06 \x01(\v2\x14.event.EventResponseR\x06before\x12*\n" +
+	"\x05after\x18\a \x01(\v2\x14.event.EventResponseR\x05after\x128\n" +
+	"\tcreatedAt\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"D\n" +
+	"\x14EventHistoryResponse\x12,\n" +
+	"\arecords\x18\x01 \x03(\v2\x12.event.AuditRecordR\arecords\"\xb5\x01\n" +
+	"\x13SearchEventsRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x16\n" +
+	"\x06userId\x18\x02 \x01(\tR\x06userId\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"N\n" +
+	"\fSearchResult\x12*\n" +
+	"\x05event\x18\x01 \x01(\v2\x14.event.EventResponseR\x05event\x12\x12\n" +
+	"\x04rank\x18\x02 \x01(\x01R\x04rank\"E\n" +
+	"\x14SearchEventsResponse\x12-\n" +
+	"\aresults\x18\x01 \x03(\v2\x13.event.SearchResultR\aresults\"\xbc\x01\n" +
+	"\x0eBatchOperation\x123\n" +
+	"\x06create\x18\x01 \x01(\v2\x19.event.CreateEventRequestH\x00R\x06create\x123\n" +
+	"\x06update\x18\x02 \x01(\v2\x19.event.UpdateEventRequestH\x00R\x06update\x123\n" +
+	"\x06delete\x18\x03 \x01(\v2\x19.event.DeleteEventRequestH\x00R\x06deleteB\v\n" +
+	"\toperation\"w\n" +
+	"\x18BatchMutateEventsRequest\x12$\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x10.event.BatchModeR\x04mode\x125\n" +
+	"\n" +
+	"operations\x18\x02 \x03(\v2\x15.event.BatchOperationR\n" +
+	"operations\"w\n" +
+	"\vBatchResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\x05R\x04code\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversion\"c\n" +
+	"\x19BatchMutateEventsResponse\x12\x18\n" +
+	"\aapplied\x18\x01 \x01(\x05R\aapplied\x12,\n" +
+	"\aresults\x18\x02 \x03(\v2\x12.event.BatchResultR\aresults\"B\n" +
+	"\x12ListEventsResponse\x12,\n" +
+	"\x06events\x18\x01 \x03(\v2\x14.event.EventResponseR\x06events\"\xd9\x03\n" +
+	"\rEventResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x128\n" +
+	"\tstartTime\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bduration\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06userId\x18\x06 \x01(\tR\x06userId\x12=\n" +
+	"\fnotifyBefore\x18\a \x01(\v2\x19.google.protobuf.DurationR\fnotifyBefore\x12\x18\n" +
+	"\aversion\x18\b \x01(\x03R\aversion\x128\n" +
+	"\tdeletedAt\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12\x1e\n" +
+	"\n" +
+	"calendarId\x18\n" +
+	" \x01(\tR\n" +
+	"calendarId\x12\x1a\n" +
+	"\bcategory\x18\v \x01(\tR\bcategory\x12\x14\n" +
+	"\x05color\x18\f \x01(\tR\x05color\x12\x12\n" +
+	"\x04tags\x18\r \x03(\tR\x04tags\"\x86\x01\n" +
+	"\x12GetTagStatsRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"1\n" +
+	"\aTagStat\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"6\n" +
+	"\x10TagStatsResponse\x12\"\n" +
+	"\x04tags\x18\x01 \x03(\v2\x0e.event.TagStatR\x04tags\"p\n" +
+	"\bCalendar\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x18\n" +
+	"\aownerId\x18\x04 \x01(\tR\aownerId\x12\x12\n" +
+	"\x04role\x18\x05 \x01(\tR\x04role\"?\n" +
+	"\x15CreateCalendarRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\"\x16\n" +
+	"\x14ListCalendarsRequest\"F\n" +
+	"\x15ListCalendarsResponse\x12-\n" +
+	"\tcalendars\x18\x01 \x03(\v2\x0f.event.CalendarR\tcalendars\"^\n" +
+	"\bACLEntry\x12 \n" +
+	"\vgranteeType\x18\x01 \x01(\tR\vgranteeType\x12\x1c\n" +
+	"\tgranteeId\x18\x02 \x01(\tR\tgranteeId\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\"]\n" +
+	"\x14ShareCalendarRequest\x12\x1e\n" +
+	"\n" +
+	"calendarId\x18\x01 \x01(\tR\n" +
+	"calendarId\x12%\n" +
+	"\x05entry\x18\x02 \x01(\v2\x0f.event.ACLEntryR\x05entry\"\x17\n" +
+	"\x15ShareCalendarResponse\"}\n" +
+	"\x1bRevokeCalendarAccessRequest\x12\x1e\n" +
+	"\n" +
+	"calendarId\x18\x01 \x01(\tR\n" +
+	"calendarId\x12 \n" +
+	"\vgranteeType\x18\x02 \x01(\tR\vgranteeType\x12\x1c\n" +
+	"\tgranteeId\x18\x03 \x01(\tR\tgranteeId\"\x1e\n" +
+	"\x1cRevokeCalendarAccessResponse\";\n" +
+	"\x19ListCalendarAccessRequest\x12\x1e\n" +
+	"\n" +
+	"calendarId\x18\x01 \x01(\tR\n" +
+	"calendarId\"G\n" +
+	"\x1aListCalendarAccessResponse\x12)\n" +
+	"\aentries\x18\x01 \x03(\v2\x0f.event.ACLEntryR\aentries*0\n" +
+	"\tBatchMode\x12\x12\n" +
+	"\x0eALL_OR_NOTHING\x10\x00\x12\x0f\n" +
+	"\vBEST_EFFORT\x10\x012\x87\x0f\n" +
+	"\x0fCalendarService\x12U\n" +
+	"\vCreateEvent\x12\x19.event.CreateEventRequest\x1a\x14.event.EventResponse\"\x15\x82\xd3\xe4\x93\x02\x0f:\x01*\"\n" +
+	"/v1/events\x12Z\n" +
+	"\vUpdateEvent\x12\x19.event.UpdateEventRequest\x1a\x14.event.EventResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\x1a\x0f/v1/events/{id}\x12]\n" +
+	"\vDeleteEvent\x12\x19.event.DeleteEventRequest\x1a\x1a.event.DeleteEventResponse\"\x17\x82\xd3\xe4\x93\x02\x11*\x0f/v1/events/{id}\x12i\n" +
+	"\x11ListDeletedEvents\x12\x1f.event.ListDeletedEventsRequest\x1a\x19.event.ListEventsResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/events:trash\x12a\n" +
+	"\fRestoreEvent\x12\x1a.event.RestoreEventRequest\x1a\x14.event.EventResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\"\x17/v1/events/{id}:restore\x12b\n" +
+	"\fSearchEvents\x12\x1a.event.SearchEventsRequest\x1a\x1b.event.SearchEventsResponse\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/events:search\x12s\n" +
+	"\x11BatchMutateEvents\x12\x1f.event.BatchMutateEventsRequest\x1a .event.BatchMutateEventsResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/events:batch\x12n\n" +
+	"\x0fGetEventHistory\x12\x1d.event.GetEventHistoryRequest\x1a\x1b.event.EventHistoryResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/v1/events/{id}/history\x12Q\n" +
+	"\bGetEvent\x12\x16.event.GetEventRequest\x1a\x14.event.EventResponse\"\x17\x82\xd3\xe4\x93\x02\x11\x12\x0f/v1/events/{id}\x12e\n" +
+	"\x10ListEventsForDay\x12\x1e.event.ListEventsForDayRequest\x1a\x19.event.ListEventsResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/events:day\x12h\n" +
+	"\x11ListEventsForWeek\x12\x1f.event.ListEventsForWeekRequest\x1a\x19.event.ListEventsResponse\"\x17\x82\xd3\xe4\x93\x02\x11\x12\x0f/v1/events:week\x12k\n" +
+	"\x12ListEventsForMonth\x12 .event.ListEventsForMonthRequest\x1a\x19.event.ListEventsResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/events:month\x12Y\n" +
+	"\vGetTagStats\x12\x19.event.GetTagStatsRequest\x1a\x17.event.TagStatsResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/tags:stats\x12Y\n" +
+	"\x0eCreateCalendar\x12\x1c.event.CreateCalendarRequest\x1a\x0f.event.Calendar\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/calendars\x12a\n" +
+	"\rListCalendars\x12\x1b.event.ListCalendarsRequest\x1a\x1c.event.ListCalendarsResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/calendars\x12y\n" +
+	"\rShareCalendar\x12\x1b.event.ShareCalendarRequest\x1a\x1c.event.ShareCalendarResponse\"-\x82\xd3\xe4\x93\x02':\x05entry\x1a\x1e/v1/calendars/{calendarId}/acl\x12\xa1\x01\n" +
+	"\x14RevokeCalendarAccess\x12\".event.RevokeCalendarAccessRequest\x1a#.event.RevokeCalendarAccessResponse\"@\x82\xd3\xe4\x93\x02:*8/v1/calendars/{calendarId}/acl/{granteeType}/{granteeId}\x12\x81\x01\n" +
+	"\x12ListCalendarAccess\x12 .event.ListCalendarAccessRequest\x1a!.event.ListCalendarAccessResponse\"&\x82\xd3\xe4\x93\x02 \x12\x1e/v1/calendars/{calendarId}/aclB\vZ\t./api;apib\x06proto3"
+
+var (
+	file_api_EventService_proto_rawDescOnce sync.Once
+	file_api_EventService_proto_rawDescData []byte
+)
+
+func file_api_EventService_proto_rawDescGZIP() []byte {
+	file_api_EventService_proto_rawDescOnce.Do(func() {
+		file_api_EventService_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_EventService_proto_rawDesc), len(file_api_EventService_proto_rawDesc)))
+	})
+	return file_api_EventService_proto_rawDescData
+}
+
+var file_api_EventService_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_EventService_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
+var file_api_EventService_proto_goTypes = []any{
+	(BatchMode)(0),                       // 0: event.BatchMode
+	(*CreateEventRequest)(nil),           // 1: event.CreateEventRequest
+	(*UpdateEventRequest)(nil),           // 2: event.UpdateEventRequest
+	(*DeleteEventRequest)(nil),           // 3: event.DeleteEventRequest
+	(*DeleteEventResponse)(nil),          // 4: event.DeleteEventResponse
+	(*GetEventRequest)(nil),              // 5: event.GetEventRequest
+	(*ListEventsForDayRequest)(nil),      // 6: event.ListEventsForDayRequest
+	(*ListEventsForWeekRequest)(nil),     // 7: event.ListEventsForWeekRequest
+	(*ListEventsForMonthRequest)(nil),    // 8: event.ListEventsForMonthRequest
+	(*ListDeletedEventsRequest)(nil),     // 9: event.ListDeletedEventsRequest
+	(*RestoreEventRequest)(nil),          // 10: event.RestoreEventRequest
+	(*GetEventHistoryRequest)(nil),       // 11: event.GetEventHistoryRequest
+	(*AuditRecord)(nil),                  // 12: event.AuditRecord
+	(*EventHistoryResponse)(nil),         // 13: event.EventHistoryResponse
+	(*SearchEventsRequest)(nil),          // 14: event.SearchEventsRequest
+	(*SearchResult)(nil),                 // 15: event.SearchResult
+	(*SearchEventsResponse)(nil),         // 16: event.SearchEventsResponse
+	(*BatchOperation)(nil),               // 17: event.BatchOperation
+	(*BatchMutateEventsRequest)(nil),     // 18: event.BatchMutateEventsRequest
+	(*BatchResult)(nil),                  // 19: event.BatchResult
+	(*BatchMutateEventsResponse)(nil),    // 20: event.BatchMutateEventsResponse
+	(*ListEventsResponse)(nil),           // 21: event.ListEventsResponse
+	(*EventResponse)(nil),                // 22: event.EventResponse
+	(*GetTagStatsRequest)(nil),           // 23: event.GetTagStatsRequest
+	(*TagStat)(nil),                      // 24: event.TagStat
+	(*TagStatsResponse)(nil),             // 25: event.TagStatsResponse
+	(*Calendar)(nil),                     // 26: event.Calendar
+	(*CreateCalendarRequest)(nil),        // 27: event.CreateCalendarRequest
+	(*ListCalendarsRequest)(nil),         // 28: event.ListCalendarsRequest
+	(*ListCalendarsResponse)(nil),        // 29: event.ListCalendarsResponse
+	(*ACLEntry)(nil),                     // 30: event.ACLEntry
+	(*ShareCalendarRequest)(nil),         // 31: event.ShareCalendarRequest
+	(*ShareCalendarResponse)(nil),        // 32: event.ShareCalendarResponse
+	(*RevokeCalendarAccessRequest)(nil),  // 33: event.RevokeCalendarAccessRequest
+	(*RevokeCalendarAccessResponse)(nil), // 34: event.RevokeCalendarAccessResponse
+	(*ListCalendarAccessRequest)(nil),    // 35: event.ListCalendarAccessRequest
+	(*ListCalendarAccessResponse)(nil),   // 36: event.ListCalendarAccessResponse
+	(*timestamppb.Timestamp)(nil),        // 37: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),          // 38: google.protobuf.Duration
+}
+var file_api_EventService_proto_depIdxs = []int32{
+	37, // 0: event.CreateEventRequest.startTime:type_name -> google.protobuf.Timestamp
+	38, // 1: event.CreateEventRequest.duration:type_name -> google.protobuf.Duration
+	38, // 2: event.CreateEventRequest.notifyBefore:type_name -> google.protobuf.Duration
+	37, // 3: event.UpdateEventRequest.startTime:type_name -> google.protobuf.Timestamp
+	38, // 4: event.UpdateEventRequest.duration:type_name -> google.protobuf.Duration
+	38, // 5: event.UpdateEventRequest.notifyBefore:type_name -> google.protobuf.Duration
+	37, // 6: event.ListEventsForDayRequest.date:type_name -> google.protobuf.Timestamp
+	37, // 7: event.ListEventsForWeekRequest.date:type_name -> google.protobuf.Timestamp
+	37, // 8: event.ListEventsForMonthRequest.date:type_name -> google.protobuf.Timestamp
+	22, // 9: event.AuditRecord.before:type_name -> event.EventResponse
+	22, // 10: event.AuditRecord.after:type_name -> event.EventResponse
+	37, // 11: event.AuditRecord.createdAt:type_name -> google.protobuf.Timestamp
+	12, // 12: event.EventHistoryResponse.records:type_name -> event.AuditRecord
+	37, // 13: event.SearchEventsRequest.from:type_name -> google.protobuf.Timestamp
+	37, // 14: event.SearchEventsRequest.to:type_name -> google.protobuf.Timestamp
+	22, // 15: event.SearchResult.event:type_name -> event.EventResponse
+	15, // 16: event.SearchEventsResponse.results:type_name -> event.SearchResult
+	1,  // 17: event.BatchOperation.create:type_name -> event.CreateEventRequest
+	2,  // 18: event.BatchOperation.update:type_name -> event.UpdateEventRequest
+	3,  // 19: event.BatchOperation.delete:type_name -> event.DeleteEventRequest
+	0,  // 20: event.BatchMutateEventsRequest.mode:type_name -> event.BatchMode
+	17, // 21: event.BatchMutateEventsRequest.operations:type_name -> event.BatchOperation
+	19, // 22: event.BatchMutateEventsResponse.results:type_name -> event.BatchResult
+	22, // 23: event.ListEventsResponse.events:type_name -> event.EventResponse
+	37, // 24: event.EventResponse.startTime:type_name -> google.protobuf.Timestamp
+	38, // 25: event.EventResponse.duration:type_name -> google.protobuf.Duration
+	38, // 26: event.EventResponse.notifyBefore:type_name -> google.protobuf.Duration
+	37, // 27: event.EventResponse.deletedAt:type_name -> google.protobuf.Timestamp
+	37, // 28: event.GetTagStatsRequest.from:type_name -> google.protobuf.Timestamp
+	37, // 29: event.GetTagStatsRequest.to:type_name -> google.protobuf.Timestamp
+	24, // 30: event.TagStatsResponse.tags:type_name -> event.TagStat
+	26, // 31: event.ListCalendarsResponse.calendars:type_name -> event.Calendar
+	30, // 32: event.ShareCalendarRequest.entry:type_name -> event.ACLEntry
+	30, // 33: event.ListCalendarAccessResponse.entries:type_name -> event.ACLEntry
+	1,  // 34: event.CalendarService.CreateEvent:input_type -> event.CreateEventRequest
+	2,  // 35: event.CalendarService.UpdateEvent:input_type -> event.UpdateEventRequest
+	3,  // 36: event.CalendarService.DeleteEvent:input_type -> event.DeleteEventRequest
+	9,  // 37: event.CalendarService.ListDeletedEvents:input_type -> event.ListDeletedEventsRequest
+	10, // 38: event.CalendarService.RestoreEvent:input_type -> event.RestoreEventRequest
+	14, // 39: event.CalendarService.SearchEvents:input_type -> event.SearchEventsRequest
+	18, // 40: event.CalendarService.BatchMutateEvents:input_type -> event.BatchMutateEventsRequest
+	11, // 41: event.CalendarService.GetEventHistory:input_type -> event.GetEventHistoryRequest
+	5,  // 42: event.CalendarService.GetEvent:input_type -> event.GetEventRequest
+	6,  // 43: event.CalendarService.ListEventsForDay:input_type -> event.ListEventsForDayRequest
+	7,  // 44: event.CalendarService.ListEventsForWeek:input_type -> event.ListEventsForWeekRequest
+	8,  // 45: event.CalendarService.ListEventsForMonth:input_type -> event.ListEventsForMonthRequest
+	23, // 46: event.CalendarService.GetTagStats:input_type -> event.GetTagStatsRequest
+	27, // 47: event.CalendarService.CreateCalendar:input_type -> event.CreateCalendarRequest
+	28, // 48: event.CalendarService.ListCalendars:input_type -> event.ListCalendarsRequest
+	31, // 49: event.CalendarService.ShareCalendar:input_type -> event.ShareCalendarRequest
+	33, // 50: event.CalendarService.RevokeCalendarAccess:input_type -> event.RevokeCalendarAccessRequest
+	35, // 51: event.CalendarService.ListCalendarAccess:input_type -> event.ListCalendarAccessRequest
+	22, // 52: event.CalendarService.CreateEvent:output_type -> event.EventResponse
+	22, // 53: event.CalendarService.UpdateEvent:output_type -> event.EventResponse
+	4,  // 54: event.CalendarService.DeleteEvent:output_type -> event.DeleteEventResponse
+	21, // 55: event.CalendarService.ListDeletedEvents:output_type -> event.ListEventsResponse
+	22, // 56: event.CalendarService.RestoreEvent:output_type -> event.EventResponse
+	16, // 57: event.CalendarService.SearchEvents:output_type -> event.SearchEventsResponse
+	20, // 58: event.CalendarService.BatchMutateEvents:output_type -> event.BatchMutateEventsResponse
+	13, // 59: event.CalendarService.GetEventHistory:output_type -> event.EventHistoryResponse
+	22, // 60: event.CalendarService.GetEvent:output_type -> event.EventResponse
+	21, // 61: event.CalendarService.ListEventsForDay:output_type -> event.ListEventsResponse
+	21, // 62: event.CalendarService.ListEventsForWeek:output_type -> event.ListEventsResponse
+	21, // 63: event.CalendarService.ListEventsForMonth:output_type -> event.ListEventsResponse
+	25, // 64: event.CalendarService.GetTagStats:output_type -> event.TagStatsResponse
+	26, // 65: event.CalendarService.CreateCalendar:output_type -> event.Calendar
+	29, // 66: event.CalendarService.ListCalendars:output_type -> event.ListCalendarsResponse
+	32, // 67: event.CalendarService.ShareCalendar:output_type -> event.ShareCalendarResponse
+	34, // 68: event.CalendarService.RevokeCalendarAccess:output_type -> event.RevokeCalendarAccessResponse
+	36, // 69: event.CalendarService.ListCalendarAccess:output_type -> event.ListCalendarAccessResponse
+	52, // [52:70] is the sub-list for method output_type
+	34, // [34:52] is the sub-list for method input_type
+	34, // [34:34] is the sub-list for extension type_name
+	34, // [34:34] is the sub-list for extension extendee
+	0,  // [0:34] is the sub-list for field type_name
+}
+
+func init() { file_api_EventService_proto_init() }
+func file_api_EventService_proto_init() {
+	if File_api_EventService_proto != nil {
+		return
+	}
+	file_api_EventService_proto_msgTypes[16].OneofWrappers = []any{
+		(*BatchOperation_Create)(nil),
+		(*BatchOperation_Update)(nil),
+		(*BatchOperation_Delete)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_EventService_proto_rawDesc), len(file_api_EventService_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   10,
+			NumEnums:      1,
+			NumMessages:   36,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_EventService_proto_goTypes,
 		DependencyIndexes: file_api_EventService_proto_depIdxs,
+		EnumInfos:         file_api_EventService_proto_enumTypes,
 		MessageInfos:      file_api_EventService_proto_msgTypes,
 	}.Build()
 	File_api_EventService_proto = out.File
@@ -19,26 +19,51 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CalendarService_CreateEvent_FullMethodName        = "/event.CalendarService/CreateEvent"
-	CalendarService_UpdateEvent_FullMethodName        = "/event.CalendarService/UpdateEvent"
-	CalendarService_DeleteEvent_FullMethodName        = "/event.CalendarService/DeleteEvent"
-	CalendarService_GetEvent_FullMethodName           = "/event.CalendarService/GetEvent"
-	CalendarService_ListEventsForDay_FullMethodName   = "/event.CalendarService/ListEventsForDay"
-	CalendarService_ListEventsForWeek_FullMethodName  = "/event.CalendarService/ListEventsForWeek"
-	CalendarService_ListEventsForMonth_FullMethodName = "/event.CalendarService/ListEventsForMonth"
+	CalendarService_CreateEvent_FullMethodName          = "/event.CalendarService/CreateEvent"
+	CalendarService_UpdateEvent_FullMethodName          = "/event.CalendarService/UpdateEvent"
+	CalendarService_DeleteEvent_FullMethodName          = "/event.CalendarService/DeleteEvent"
+	CalendarService_ListDeletedEvents_FullMethodName    = "/event.CalendarService/ListDeletedEvents"
+	CalendarService_RestoreEvent_FullMethodName         = "/event.CalendarService/RestoreEvent"
+	CalendarService_SearchEvents_FullMethodName         = "/event.CalendarService/SearchEvents"
+	CalendarService_BatchMutateEvents_FullMethodName    = "/event.CalendarService/BatchMutateEvents"
+	CalendarService_GetEventHistory_FullMethodName      = "/event.CalendarService/GetEventHistory"
+	CalendarService_GetEvent_FullMethodName             = "/event.CalendarService/GetEvent"
+	CalendarService_ListEventsForDay_FullMethodName     = "/event.CalendarService/ListEventsForDay"
+	CalendarService_ListEventsForWeek_FullMethodName    = "/event.CalendarService/ListEventsForWeek"
+	CalendarService_ListEventsForMonth_FullMethodName   = "/event.CalendarService/ListEventsForMonth"
+	CalendarService_GetTagStats_FullMethodName          = "/event.CalendarService/GetTagStats"
+	CalendarService_CreateCalendar_FullMethodName       = "/event.CalendarService/CreateCalendar"
+	CalendarService_ListCalendars_FullMethodName        = "/event.CalendarService/ListCalendars"
+	CalendarService_ShareCalendar_FullMethodName        = "/event.CalendarService/ShareCalendar"
+	CalendarService_RevokeCalendarAccess_FullMethodName = "/event.CalendarService/RevokeCalendarAccess"
+	CalendarService_ListCalendarAccess_FullMethodName   = "/event.CalendarService/ListCalendarAccess"
 )
 
 // CalendarServiceClient is the client API for CalendarService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// REST-маршруты генерируются из аннотаций google.api.http (grpc-gateway),
+// поэтому HTTP /v1 и gRPC обслуживаются одной реализацией.
 type CalendarServiceClient interface {
 	CreateEvent(ctx context.Context, in *CreateEventRequest, opts ...grpc.CallOption) (*EventResponse, error)
 	UpdateEvent(ctx context.Context, in *UpdateEventRequest, opts ...grpc.CallOption) (*EventResponse, error)
 	DeleteEvent(ctx context.Context, in *DeleteEventRequest, opts ...grpc.CallOption) (*DeleteEventResponse, error)
+	ListDeletedEvents(ctx context.Context, in *ListDeletedEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
+	RestoreEvent(ctx context.Context, in *RestoreEventRequest, opts ...grpc.CallOption) (*EventResponse, error)
+	SearchEvents(ctx context.Context, in *SearchEventsRequest, opts ...grpc.CallOption) (*SearchEventsResponse, error)
+	BatchMutateEvents(ctx context.Context, in *BatchMutateEventsRequest, opts ...grpc.CallOption) (*BatchMutateEventsResponse, error)
+	GetEventHistory(ctx context.Context, in *GetEventHistoryRequest, opts ...grpc.CallOption) (*EventHistoryResponse, error)
 	GetEvent(ctx context.Context, in *GetEventRequest, opts ...grpc.CallOption) (*EventResponse, error)
 	ListEventsForDay(ctx context.Context, in *ListEventsForDayRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
 	ListEventsForWeek(ctx context.Context, in *ListEventsForWeekRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
 	ListEventsForMonth(ctx context.Context, in *ListEventsForMonthRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
+	GetTagStats(ctx context.Context, in *GetTagStatsRequest, opts ...grpc.CallOption) (*TagStatsResponse, error)
+	CreateCalendar(ctx context.Context, in *CreateCalendarRequest, opts ...grpc.CallOption) (*Calendar, error)
+	ListCalendars(ctx context.Context, in *ListCalendarsRequest, opts ...grpc.CallOption) (*ListCalendarsResponse, error)
+	ShareCalendar(ctx context.Context, in *ShareCalendarRequest, opts ...grpc.CallOption) (*ShareCalendarResponse, error)
+	RevokeCalendarAccess(ctx context.Context, in *RevokeCalendarAccessRequest, opts ...grpc.CallOption) (*RevokeCalendarAccessResponse, error)
+	ListCalendarAccess(ctx context.Context, in *ListCalendarAccessRequest, opts ...grpc.CallOption) (*ListCalendarAccessResponse, error)
 }
 
 type calendarServiceClient struct {
@@ -79,6 +104,56 @@ func (c *calendarServiceClient) DeleteEvent(ctx context.Context, in *DeleteEvent
 	return out, nil
 }
 
+func (c *calendarServiceClient) ListDeletedEvents(ctx context.Context, in *ListDeletedEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListEventsResponse)
+	err := c.cc.Invoke(ctx, CalendarService_ListDeletedEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) RestoreEvent(ctx context.Context, in *RestoreEventRequest, opts ...grpc.CallOption) (*EventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EventResponse)
+	err := c.cc.Invoke(ctx, CalendarService_RestoreEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) SearchEvents(ctx context.Context, in *SearchEventsRequest, opts ...grpc.CallOption) (*SearchEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchEventsResponse)
+	err := c.cc.Invoke(ctx, CalendarService_SearchEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) BatchMutateEvents(ctx context.Context, in *BatchMutateEventsRequest, opts ...grpc.CallOption) (*BatchMutateEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchMutateEventsResponse)
+	err := c.cc.Invoke(ctx, CalendarService_BatchMutateEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) GetEventHistory(ctx context.Context, in *GetEventHistoryRequest, opts ...grpc.CallOption) (*EventHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EventHistoryResponse)
+	err := c.cc.Invoke(ctx, CalendarService_GetEventHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *calendarServiceClient) GetEvent(ctx context.Context, in *GetEventRequest, opts ...grpc.CallOption) (*EventResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(EventResponse)
@@ -119,17 +194,91 @@ func (c *calendarServiceClient) ListEventsForMonth(ctx context.Context, in *List
 	return out, nil
 }
 
+func (c *calendarServiceClient) GetTagStats(ctx context.Context, in *GetTagStatsRequest, opts ...grpc.CallOption) (*TagStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TagStatsResponse)
+	err := c.cc.Invoke(ctx, CalendarService_GetTagStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) CreateCalendar(ctx context.Context, in *CreateCalendarRequest, opts ...grpc.CallOption) (*Calendar, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Calendar)
+	err := c.cc.Invoke(ctx, CalendarService_CreateCalendar_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) ListCalendars(ctx context.Context, in *ListCalendarsRequest, opts ...grpc.CallOption) (*ListCalendarsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCalendarsResponse)
+	err := c.cc.Invoke(ctx, CalendarService_ListCalendars_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) ShareCalendar(ctx context.Context, in *ShareCalendarRequest, opts ...grpc.CallOption) (*ShareCalendarResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShareCalendarResponse)
+	err := c.cc.Invoke(ctx, CalendarService_ShareCalendar_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) RevokeCalendarAccess(ctx context.Context, in *RevokeCalendarAccessRequest, opts ...grpc.CallOption) (*RevokeCalendarAccessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeCalendarAccessResponse)
+	err := c.cc.Invoke(ctx, CalendarService_RevokeCalendarAccess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calendarServiceClient) ListCalendarAccess(ctx context.Context, in *ListCalendarAccessRequest, opts ...grpc.CallOption) (*ListCalendarAccessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCalendarAccessResponse)
+	err := c.cc.Invoke(ctx, CalendarService_ListCalendarAccess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CalendarServiceServer is the server API for CalendarService service.
 // All implementations must embed UnimplementedCalendarServiceServer
 // for forward compatibility.
+//
+// REST-маршруты генерируются из аннотаций google.api.http (grpc-gateway),
+// поэтому HTTP /v1 и gRPC обслуживаются одной реализацией.
 type CalendarServiceServer interface {
 	CreateEvent(context.Context, *CreateEventRequest) (*EventResponse, error)
 	UpdateEvent(context.Context, *UpdateEventRequest) (*EventResponse, error)
 	DeleteEvent(context.Context, *DeleteEventRequest) (*DeleteEventResponse, error)
+	ListDeletedEvents(context.Context, *ListDeletedEventsRequest) (*ListEventsResponse, error)
+	RestoreEvent(context.Context, *RestoreEventRequest) (*EventResponse, error)
+	SearchEvents(context.Context, *SearchEventsRequest) (*SearchEventsResponse, error)
+	BatchMutateEvents(context.Context, *BatchMutateEventsRequest) (*BatchMutateEventsResponse, error)
+	GetEventHistory(context.Context, *GetEventHistoryRequest) (*EventHistoryResponse, error)
 	GetEvent(context.Context, *GetEventRequest) (*EventResponse, error)
 	ListEventsForDay(context.Context, *ListEventsForDayRequest) (*ListEventsResponse, error)
 	ListEventsForWeek(context.Context, *ListEventsForWeekRequest) (*ListEventsResponse, error)
 	ListEventsForMonth(context.Context, *ListEventsForMonthRequest) (*ListEventsResponse, error)
+	GetTagStats(context.Context, *GetTagStatsRequest) (*TagStatsResponse, error)
+	CreateCalendar(context.Context, *CreateCalendarRequest) (*Calendar, error)
+	ListCalendars(context.Context, *ListCalendarsRequest) (*ListCalendarsResponse, error)
+	ShareCalendar(context.Context, *ShareCalendarRequest) (*ShareCalendarResponse, error)
+	RevokeCalendarAccess(context.Context, *RevokeCalendarAccessRequest) (*RevokeCalendarAccessResponse, error)
+	ListCalendarAccess(context.Context, *ListCalendarAccessRequest) (*ListCalendarAccessResponse, error)
 	mustEmbedUnimplementedCalendarServiceServer()
 }
 
@@ -149,6 +298,21 @@ func (UnimplementedCalendarServiceServer) UpdateEvent(context.Context, *UpdateEv
 func (UnimplementedCalendarServiceServer) DeleteEvent(context.Context, *DeleteEventRequest) (*DeleteEventResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteEvent not implemented")
 }
+func (UnimplementedCalendarServiceServer) ListDeletedEvents(context.Context, *ListDeletedEventsRequest) (*ListEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeletedEvents not implemented")
+}
+func (UnimplementedCalendarServiceServer) RestoreEvent(context.Context, *RestoreEventRequest) (*EventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreEvent not implemented")
+}
+func (UnimplementedCalendarServiceServer) SearchEvents(context.Context, *SearchEventsRequest) (*SearchEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchEvents not implemented")
+}
+func (UnimplementedCalendarServiceServer) BatchMutateEvents(context.Context, *BatchMutateEventsRequest) (*BatchMutateEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchMutateEvents not implemented")
+}
+func (UnimplementedCalendarServiceServer) GetEventHistory(context.Context, *GetEventHistoryRequest) (*EventHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEventHistory not implemented")
+}
 func (UnimplementedCalendarServiceServer) GetEvent(context.Context, *GetEventRequest) (*EventResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetEvent not implemented")
 }
@@ -161,6 +325,24 @@ func (UnimplementedCalendarServiceServer) ListEventsForWeek(context.Context, *Li
 func (UnimplementedCalendarServiceServer) ListEventsForMonth(context.Context, *ListEventsForMonthRequest) (*ListEventsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListEventsForMonth not implemented")
 }
+func (UnimplementedCalendarServiceServer) GetTagStats(context.Context, *GetTagStatsRequest) (*TagStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTagStats not implemented")
+}
+func (UnimplementedCalendarServiceServer) CreateCalendar(context.Context, *CreateCalendarRequest) (*Calendar, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCalendar not implemented")
+}
+func (UnimplementedCalendarServiceServer) ListCalendars(context.Context, *ListCalendarsRequest) (*ListCalendarsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCalendars not implemented")
+}
+func (UnimplementedCalendarServiceServer) ShareCalendar(context.Context, *ShareCalendarRequest) (*ShareCalendarResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareCalendar not implemented")
+}
+func (UnimplementedCalendarServiceServer) RevokeCalendarAccess(context.Context, *RevokeCalendarAccessRequest) (*RevokeCalendarAccessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeCalendarAccess not implemented")
+}
+func (UnimplementedCalendarServiceServer) ListCalendarAccess(context.Context, *ListCalendarAccessRequest) (*ListCalendarAccessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCalendarAccess not implemented")
+}
 func (UnimplementedCalendarServiceServer) mustEmbedUnimplementedCalendarServiceServer() {}
 func (UnimplementedCalendarServiceServer) testEmbeddedByValue()                         {}
 
@@ -236,6 +418,96 @@ func _CalendarService_DeleteEvent_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CalendarService_ListDeletedEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeletedEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).ListDeletedEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_ListDeletedEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).ListDeletedEvents(ctx, req.(*ListDeletedEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_RestoreEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).RestoreEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_RestoreEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).RestoreEvent(ctx, req.(*RestoreEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_SearchEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).SearchEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_SearchEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).SearchEvents(ctx, req.(*SearchEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_BatchMutateEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchMutateEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).BatchMutateEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_BatchMutateEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).BatchMutateEvents(ctx, req.(*BatchMutateEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_GetEventHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).GetEventHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_GetEventHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).GetEventHistory(ctx, req.(*GetEventHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CalendarService_GetEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetEventRequest)
 	if err := dec(in); err != nil {
@@ -308,6 +580,114 @@ func _CalendarService_ListEventsForMonth_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CalendarService_GetTagStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTagStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).GetTagStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_GetTagStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).GetTagStats(ctx, req.(*GetTagStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_CreateCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).CreateCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_CreateCalendar_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).CreateCalendar(ctx, req.(*CreateCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_ListCalendars_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCalendarsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).ListCalendars(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_ListCalendars_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).ListCalendars(ctx, req.(*ListCalendarsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_ShareCalendar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareCalendarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).ShareCalendar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_ShareCalendar_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).ShareCalendar(ctx, req.(*ShareCalendarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_RevokeCalendarAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeCalendarAccessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).RevokeCalendarAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_RevokeCalendarAccess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).RevokeCalendarAccess(ctx, req.(*RevokeCalendarAccessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalendarService_ListCalendarAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCalendarAccessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalendarServiceServer).ListCalendarAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalendarService_ListCalendarAccess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalendarServiceServer).ListCalendarAccess(ctx, req.(*ListCalendarAccessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CalendarService_ServiceDesc is the grpc.ServiceDesc for CalendarService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -327,6 +707,26 @@ var CalendarService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteEvent",
 			Handler:    _CalendarService_DeleteEvent_Handler,
 		},
+		{
+			MethodName: "ListDeletedEvents",
+			Handler:    _CalendarService_ListDeletedEvents_Handler,
+		},
+		{
+			MethodName: "RestoreEvent",
+			Handler:    _CalendarService_RestoreEvent_Handler,
+		},
+		{
+			MethodName: "SearchEvents",
+			Handler:    _CalendarService_SearchEvents_Handler,
+		},
+		{
+			MethodName: "BatchMutateEvents",
+			Handler:    _CalendarService_BatchMutateEvents_Handler,
+		},
+		{
+			MethodName: "GetEventHistory",
+			Handler:    _CalendarService_GetEventHistory_Handler,
+		},
 		{
 			MethodName: "GetEvent",
 			Handler:    _CalendarService_GetEvent_Handler,
@@ -343,6 +743,30 @@ var CalendarService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListEventsForMonth",
 			Handler:    _CalendarService_ListEventsForMonth_Handler,
 		},
+		{
+			MethodName: "GetTagStats",
+			Handler:    _CalendarService_GetTagStats_Handler,
+		},
+		{
+			MethodName: "CreateCalendar",
+			Handler:    _CalendarService_CreateCalendar_Handler,
+		},
+		{
+			MethodName: "ListCalendars",
+			Handler:    _CalendarService_ListCalendars_Handler,
+		},
+		{
+			MethodName: "ShareCalendar",
+			Handler:    _CalendarService_ShareCalendar_Handler,
+		},
+		{
+			MethodName: "RevokeCalendarAccess",
+			Handler:    _CalendarService_RevokeCalendarAccess_Handler,
+		},
+		{
+			MethodName: "ListCalendarAccess",
+			Handler:    _CalendarService_ListCalendarAccess_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/EventService.proto",
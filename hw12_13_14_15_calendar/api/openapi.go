@@ -0,0 +1,8 @@
+package api
+
+import _ "embed"
+
+// OpenAPISpec - спецификация REST API, сгенерированная protoc-gen-openapiv2 из EventService.proto
+//
+//go:embed EventService.swagger.json
+var OpenAPISpec []byte
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/validate"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MaxIdempotencyKeyLength - предельная длина ключа идемпотентности (колонка key в хранилищах)
+const MaxIdempotencyKeyLength = 255
+
+// Сгенерированные типы нельзя разметить тегами, поэтому поля событий копируются в eventFields.
+// Имена полей - как в JSON-представлении proto, чтобы ошибки указывали на поля запроса REST-шлюза.
+type eventFields struct {
+	Title        string        `json:"title" validate:"required,max=255"`
+	StartTime    time.Time     `json:"startTime" validate:"required,min=1970-01-01,max=9999-12-31"`
+	Duration     time.Duration `json:"duration" validate:"required,min=1m,max=720h"`
+	Description  string        `json:"description" validate:"max=10000"`
+	UserID       string        `json:"userId" validate:"required,id,max=36"`
+	CalendarID   string        `json:"calendarId" validate:"max=128"`
+	NotifyBefore time.Duration `json:"notifyBefore" validate:"min=0s,max=720h"`
+	Category     string        `json:"category" validate:"max=64"`
+	Color        string        `json:"color" validate:"color"`
+	Tags         []string      `json:"tags" validate:"max=20,tags"`
+}
+
+// Validate вызывается перехватчиком валидации и обработчиками
+func (r *CreateEventRequest) Validate() error {
+	var errs validate.Errors
+	if len(r.GetRequestId()) > MaxIdempotencyKeyLength {
+		errs = append(errs, validate.FieldError{Field: "requestId", Message: "must be at most 255 characters long"})
+	}
+	err := validate.Struct(eventFields{
+		Title:        r.GetTitle(),
+		StartTime:    asTime(r),
+		Duration:     r.GetDuration().AsDuration(),
+		Description:  r.GetDescription(),
+		UserID:       r.GetUserId(),
+		CalendarID:   r.GetCalendarId(),
+		NotifyBefore: r.GetNotifyBefore().AsDuration(),
+		Category:     r.GetCategory(),
+		Color:        r.GetColor(),
+		Tags:         r.GetTags(),
+	})
+	var fieldErrs validate.Errors
+	if errors.As(err, &fieldErrs) {
+		errs = append(errs, fieldErrs...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (r *UpdateEventRequest) Validate() error {
+	var errs validate.Errors
+	if r.GetId() == "" {
+		errs = append(errs, validate.FieldError{Field: "id", Message: "is required"})
+	}
+	err := validate.Struct(eventFields{
+		Title:        r.GetTitle(),
+		StartTime:    asTime(r),
+		Duration:     r.GetDuration().AsDuration(),
+		Description:  r.GetDescription(),
+		UserID:       r.GetUserId(),
+		NotifyBefore: r.GetNotifyBefore().AsDuration(),
+		Category:     r.GetCategory(),
+		Color:        r.GetColor(),
+		Tags:         r.GetTags(),
+	})
+	var fieldErrs validate.Errors
+	if errors.As(err, &fieldErrs) {
+		errs = append(errs, fieldErrs...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// asTime возвращает нулевое время для незаданного startTime: AsTime дал бы 1970-01-01,
+// и пропущенное поле прошло бы проверку required
+func asTime(r interface{ GetStartTime() *timestamppb.Timestamp }) time.Time {
+	if r.GetStartTime() == nil {
+		return time.Time{}
+	}
+	return r.GetStartTime().AsTime()
+}
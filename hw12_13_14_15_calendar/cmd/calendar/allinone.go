@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/config"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	memoryqueue "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/queue/memory"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/scheduler"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/sender"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server"
+	internalhttp "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server/http"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+const defaultQueueCapacity = 1000
+
+// runAllInOne запускает календарь, планировщик и рассыльщик в одном процессе.
+// Уведомления передаются через очередь в памяти, поэтому RabbitMQ не нужен.
+func runAllInOne() {
+	cfg, err := LoadAllInOneConfig(configFile)
+	if err != nil {
+		log.Fatalf("Error: loading config from file %v", err)
+	}
+	calendarConfig := cfg.Calendar()
+
+	logg := logger.New(cfg.Logger.Level)
+
+	// Планировщик читает события из того же хранилища, что и календарь
+	strg, err := initStorage(calendarConfig, logg)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer strg.Close()
+
+	blobs, err := initBlobStore(calendarConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize attachment store: %v", err)
+	}
+	calendar := app.New(logg, strg, app.WithAttachments(blobs, calendarConfig.Attachments.MaxSize))
+
+	ctx, cancel := createShutdownContext()
+	defer cancel()
+
+	rateLimit, err := initRateLimit(calendarConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize rate limiter: %v", err)
+	}
+	defer rateLimit.Close()
+
+	grpcServer, err := initGRPCServer(calendarConfig, logg, calendar, rateLimit)
+	if err != nil {
+		log.Fatalf("Failed to initialize grpc server: %v", err)
+	}
+	gateway, err := grpcServer.GatewayHandler(ctx)
+	if err != nil {
+		log.Fatalf("Failed to initialize grpc gateway: %v", err)
+	}
+	httpServer, err := initHTTPServer(calendarConfig, logg, calendar, rateLimit, internalhttp.WithHandler("/v1/*", gateway))
+	if err != nil {
+		log.Fatalf("Failed to initialize http server: %v", err)
+	}
+
+	capacity := cfg.EventQueue.Capacity
+	if capacity == 0 {
+		capacity = defaultQueueCapacity
+	}
+	queue := memoryqueue.NewMemoryQueue[storage.Notification](capacity)
+	defer queue.Close()
+
+	eventRetention, trashRetention := cfg.Scheduler.Retention()
+	schedulerOpts := []scheduler.Option{
+		scheduler.WithArchiver(scheduler.NewFileArchiver(cfg.Scheduler.ArchiveDir)),
+		scheduler.WithRetention(eventRetention, trashRetention),
+	}
+	// Планировщик удаляет файлы вложений из того же хранилища, в которое их кладёт календарь
+	if blobs != nil {
+		schedulerOpts = append(schedulerOpts, scheduler.WithBlobStore(blobs))
+	}
+	sched := scheduler.NewScheduler(
+		logg, strg, queue,
+		cfg.EventQueue.Name, cfg.EventQueue.Exchange, cfg.Scheduler.CheckInterval,
+		schedulerOpts...,
+	)
+	go sched.Start(ctx)
+
+	sender.NewConsumer(queue, logg).Start(ctx, cfg.EventQueue.Name)
+
+	// Перечитываем конфигурацию по SIGHUP
+	go watchAllInOneConfig(ctx, cfg, logg, sched, rateLimit)
+
+	logg.Info("calendar is running in all-in-one mode...")
+
+	failed := runServers(ctx, cancel, logg, map[string]server.CalculatorServer{
+		"http": httpServer,
+		"grpc": grpcServer,
+	})
+
+	logg.Info("calendar is stopped")
+	if failed {
+		strg.Close()
+		os.Exit(1)
+	}
+}
+
+// watchAllInOneConfig перечитывает конфигурацию по SIGHUP, пока не отменён контекст
+func watchAllInOneConfig(
+	ctx context.Context, current *AllInOneConfig, logg *logger.Logger, sched *scheduler.Scheduler, rateLimit *rateLimiter,
+) {
+	config.OnReload(ctx, func() {
+		reloadAllInOneConfig(current, logg, sched, rateLimit)
+	})
+}
+
+// reloadAllInOneConfig применяет уровень логирования, ограничение частоты запросов и настройки
+// планировщика; остальное, включая очередь, требует рестарта
+func reloadAllInOneConfig(current *AllInOneConfig, logg *logger.Logger, sched *scheduler.Scheduler, rateLimit *rateLimiter) {
+	updated, err := LoadAllInOneConfig(configFile)
+	if err != nil {
+		logg.Error("failed to reload config, keeping current one: " + err.Error())
+		return
+	}
+
+	hot, restart := config.Split(config.Diff(current, updated))
+	rateLimitChanged := false
+	for _, field := range hot {
+		if strings.HasPrefix(field, rateLimitFields) {
+			rateLimitChanged = true
+			continue
+		}
+		switch field {
+		case "logger.level":
+			logg.SetLevel(updated.Logger.Level)
+		case "scheduler.check-interval":
+			sched.SetCheckInterval(updated.Scheduler.CheckInterval)
+		case "scheduler.event-retention", "scheduler.trash-retention":
+			sched.SetRetention(updated.Scheduler.Retention())
+		case "scheduler.archive-dir":
+			sched.SetArchiver(scheduler.NewFileArchiver(updated.Scheduler.ArchiveDir))
+		}
+	}
+	if rateLimitChanged && reloadRateLimit(updated.Calendar(), rateLimit, logg) {
+		current.Server.RateLimit = updated.Server.RateLimit
+	}
+	current.Logger = updated.Logger
+	current.Scheduler = updated.Scheduler
+
+	if len(restart) > 0 {
+		logg.Warn("config changes require restart: " + strings.Join(restart, ", "))
+	}
+	if len(hot) > 0 {
+		logg.Info("config reloaded, applied: " + strings.Join(hot, ", "))
+	} else {
+		logg.Info("config reloaded, nothing to apply")
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/config"
+)
+
+// AllInOneConfig - конфигурация режима all-in-one: календарь, планировщик и рассыльщик в одном процессе.
+// Раздел rabbit не нужен: уведомления передаются через очередь в памяти.
+type AllInOneConfig struct {
+	Logger      Logger `reload:"hot"`
+	Storage     Storage
+	Server      Server
+	Attachments Attachments
+	EventQueue  EventQueue        `yaml:"event-queue"`
+	Scheduler   SchedulerSettings `reload:"hot"`
+}
+
+type EventQueue struct {
+	Name     string `env:"EVENT_QUEUE_NAME"`
+	Exchange string `env:"EVENT_QUEUE_EXCHANGE"`
+	// Сколько уведомлений помещается в очередь; 0 - значение по умолчанию
+	Capacity int `env:"EVENT_QUEUE_CAPACITY"`
+}
+
+type SchedulerSettings struct {
+	CheckInterval  string `yaml:"check-interval" env:"SCHEDULER_CHECK_INTERVAL"`
+	EventRetention string `yaml:"event-retention" env:"SCHEDULER_EVENT_RETENTION"` // Сколько хранить закончившиеся события
+	TrashRetention string `yaml:"trash-retention" env:"SCHEDULER_TRASH_RETENTION"` // Сколько хранить события в корзине
+	ArchiveDir     string `yaml:"archive-dir" env:"SCHEDULER_ARCHIVE_DIR"`         // Куда архивировать события перед удалением
+}
+
+// Retention возвращает сроки хранения; ошибки разбора отсекает Validate
+func (s SchedulerSettings) Retention() (events, trash time.Duration) {
+	events, _ = time.ParseDuration(s.EventRetention)
+	trash, _ = time.ParseDuration(s.TrashRetention)
+	return events, trash
+}
+
+// Calendar возвращает часть конфигурации, общую с обычным запуском календаря
+func (cfg *AllInOneConfig) Calendar() *Config {
+	return &Config{Logger: cfg.Logger, Storage: cfg.Storage, Server: cfg.Server, Attachments: cfg.Attachments}
+}
+
+func (cfg *AllInOneConfig) Validate() error {
+	var check config.Checker
+
+	cfg.Calendar().validate(&check)
+
+	check.Required("event-queue.name", cfg.EventQueue.Name)
+	if cfg.EventQueue.Capacity < 0 {
+		check.Fail("event-queue.capacity", "must not be negative, got %d", cfg.EventQueue.Capacity)
+	}
+	check.Duration("scheduler.check-interval", cfg.Scheduler.CheckInterval)
+	check.Duration("scheduler.event-retention", cfg.Scheduler.EventRetention)
+	check.Duration("scheduler.trash-retention", cfg.Scheduler.TrashRetention)
+	check.Required("scheduler.archive-dir", cfg.Scheduler.ArchiveDir)
+
+	return check.Err()
+}
+
+func LoadAllInOneConfig(path string) (*AllInOneConfig, error) {
+	var cfg AllInOneConfig
+	if err := config.Load(path, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -2,38 +2,146 @@ package main
 
 import (
 	"fmt"
-	"os"
+	"sort"
+	"time"
 
-	yml "gopkg.in/yaml.v3"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/blob"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/config"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/ratelimit"
+	internalgrpc "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server/grpc"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/backend"
+	filestorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/file"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/tlsconfig"
 )
 
 // При желании конфигурацию можно вынести в internal/config.
 // Организация конфига в main принуждает нас сужать API компонентов, использовать
 // при их конструировании только необходимые параметры, а также уменьшает вероятность циклической зависимости.
+// Поэтому в internal/config живут только загрузка, подстановка окружения и проверки.
+// Поля с тегом reload:"hot" применяются по SIGHUP без перезапуска, остальные требуют рестарта.
 type Config struct {
-	Logger  Logger
-	Storage Storage
-	Server  Server
+	Logger      Logger `reload:"hot"`
+	Storage     Storage
+	Server      Server
+	Attachments Attachments
+}
+
+// Attachments - где хранить содержимое файлов, прикреплённых к событиям
+type Attachments struct {
+	// fs или memory; пусто - к событиям можно прикреплять только ссылки
+	Store   string `env:"ATTACHMENTS_STORE"`
+	Dir     string `env:"ATTACHMENTS_DIR"`                      // Каталог для fs
+	MaxSize int64  `yaml:"max-size" env:"ATTACHMENTS_MAX_SIZE"` // Предельный размер файла в байтах; 0 - 10 МБ
 }
 
 type Server struct {
-	Host     string
-	Port     int
-	GRPCPort string `yaml:"grpc_port"`
+	Host     string `env:"SERVER_HOST"`
+	Port     int    `env:"SERVER_PORT"`
+	GRPCPort string `yaml:"grpc_port" env:"GRPC_PORT"`
+	GRPC     GRPC   `yaml:"grpc"`
+	// HTTPS для REST API; без cert-file сервер работает по HTTP
+	TLS ServerTLS `yaml:"tls"`
+	// Ограничение частоты запросов; действует и на HTTP, и на gRPC
+	RateLimit RateLimit `yaml:"rate-limit" reload:"hot"`
+}
+
+// Хранилища счётчиков ограничителя
+const (
+	rateLimitMemory   = "memory"
+	rateLimitDatabase = "database"
+)
+
+type RateLimit struct {
+	Enabled bool `env:"RATE_LIMIT_ENABLED"`
+	// memory - у каждого экземпляра свои счётчики, database - общие в Postgres из storage
+	Backend string `env:"RATE_LIMIT_BACKEND"`
+	// Правила по классам запросов: read, write, search, batch; класс без правила не ограничивается
+	Rules map[string]RateRule
+}
+
+type RateRule struct {
+	Limit int    // Сколько запросов разрешено за Per
+	Per   string // Длительность, например 1m
+	Burst int    // Сколько запросов можно сделать подряд; 0 - столько же, сколько Limit
+}
+
+// Policy собирает правила ограничителя; ошибки разбора отсекает Validate
+func (rateLimit *RateLimit) Policy(limiter ratelimit.Limiter) ratelimit.Policy {
+	rules := make(map[string]ratelimit.Rule, len(rateLimit.Rules))
+	for class, rule := range rateLimit.Rules {
+		per, _ := time.ParseDuration(rule.Per)
+		rules[class] = ratelimit.Rule{Limit: rule.Limit, Per: per, Burst: rule.Burst}
+	}
+	return ratelimit.Policy{Limiter: limiter, Rules: rules}
+}
+
+type GRPC struct {
+	Reflection     bool   `env:"GRPC_REFLECTION"`
+	RequestTimeout string `yaml:"request-timeout" env:"GRPC_REQUEST_TIMEOUT"`
+	// Порядок перехватчиков в цепочке; пустой список означает цепочку по умолчанию
+	Interceptors []string
+	// TLS для gRPC; client-ca-file включает проверку клиентских сертификатов
+	TLS ServerTLS `yaml:"tls"`
+}
+
+// ServerTLS - файлы сертификатов сервера. Файлы перечитываются при изменении, без перезапуска.
+// Переменные окружения для путей задаются в шаблоне конфигурации: структура общая для HTTP и gRPC.
+type ServerTLS struct {
+	CertFile     string `yaml:"cert-file"`
+	KeyFile      string `yaml:"key-file"`
+	ClientCAFile string `yaml:"client-ca-file"`
+}
+
+func (t ServerTLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+func (t ServerTLS) Files() tlsconfig.ServerFiles {
+	return tlsconfig.ServerFiles{CertFile: t.CertFile, KeyFile: t.KeyFile, ClientCAFile: t.ClientCAFile}
+}
+
+func (t ServerTLS) validate(check *config.Checker, field string) {
+	if t.Enabled() || t.KeyFile != "" || t.ClientCAFile != "" {
+		check.Required(field+".cert-file", t.CertFile)
+		check.Required(field+".key-file", t.KeyFile)
+	}
 }
 
 type Storage struct {
-	Type     string
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Database string
-	SSLMode  string `yaml:"sslmode"`
+	Type     string `env:"STORAGE_TYPE"`
+	Host     string `env:"POSTGRES_HOST"`
+	Port     int    `env:"POSTGRES_PORT"`
+	User     string `env:"POSTGRES_USER"`
+	Password string `env:"POSTGRES_PASSWORD"`
+	Database string `env:"POSTGRES_DB"`
+	SSLMode  string `yaml:"sslmode" env:"POSTGRES_SSLMODE"`
+	// CA сервера Postgres и клиентский сертификат; нужны при sslmode verify-ca, verify-full
+	// и при аутентификации по сертификату
+	SSLRootCert string `yaml:"sslrootcert" env:"POSTGRES_SSLROOTCERT"`
+	SSLCert     string `yaml:"sslcert" env:"POSTGRES_SSLCERT"`
+	SSLKey      string `yaml:"sslkey" env:"POSTGRES_SSLKEY"`
+	// Применять встроенные миграции Postgres при запуске
+	AutoMigrate bool        `yaml:"auto-migrate" env:"POSTGRES_AUTO_MIGRATE"`
+	File        FileStorage `yaml:"file"`
+	SQLite      SQLite      `yaml:"sqlite"`
+}
+
+// SQLite - настройки хранилища в файле SQLite (storage.type: sqlite)
+type SQLite struct {
+	Path string `env:"SQLITE_PATH"`
+}
+
+// FileStorage - настройки хранилища в файлах (storage.type: file)
+type FileStorage struct {
+	Path          string `env:"STORAGE_PATH"`
+	Fsync         string `env:"STORAGE_FSYNC"` // always, interval или never
+	FsyncInterval string `yaml:"fsync-interval" env:"STORAGE_FSYNC_INTERVAL"`
+	// После скольких записей журнала делать снимок; 0 - значение по умолчанию
+	SnapshotEvery int `yaml:"snapshot-every" env:"STORAGE_SNAPSHOT_EVERY"`
 }
 
 func (storage *Storage) GetPostgresDSN() string {
-	return fmt.Sprintf(
+	dsn := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		storage.Host,
 		storage.Port,
@@ -42,27 +150,131 @@ func (storage *Storage) GetPostgresDSN() string {
 		storage.Database,
 		storage.SSLMode,
 	)
+	// Драйвер читает файлы при каждом новом соединении, поэтому обновлённые сертификаты подхватываются сами
+	for _, param := range [][2]string{
+		{"sslrootcert", storage.SSLRootCert}, {"sslcert", storage.SSLCert}, {"sslkey", storage.SSLKey},
+	} {
+		if param[1] != "" {
+			dsn += " " + param[0] + "=" + param[1]
+		}
+	}
+	return dsn
+}
+
+// Settings собирает параметры открытия хранилища; ошибки разбора отсекает Validate
+func (storage *Storage) Settings() backend.Settings {
+	interval, _ := time.ParseDuration(storage.File.FsyncInterval)
+	return backend.Settings{
+		Type:        storage.Type,
+		PostgresDSN: storage.GetPostgresDSN(),
+		SQLitePath:  storage.SQLite.Path,
+		FilePath:    storage.File.Path,
+		FileOptions: []filestorage.Option{
+			filestorage.WithFsync(filestorage.FsyncPolicy(storage.File.Fsync), interval),
+			filestorage.WithSnapshotEvery(storage.File.SnapshotEvery),
+		},
+	}
 }
 
 type Logger struct {
-	Level string
+	Level string `env:"LOG_LEVEL"`
 }
 
 func NewConfig() Config {
 	return Config{}
 }
 
-func LoadConfig(path string) (*Config, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open config file: %w", err)
+func (cfg *Config) Validate() error {
+	var check config.Checker
+	cfg.validate(&check)
+	return check.Err()
+}
+
+// validate проверяет поля календаря; её же использует конфигурация режима all-in-one
+func (cfg *Config) validate(check *config.Checker) {
+	check.OneOf("logger.level", cfg.Logger.Level, "debug", "info", "warn", "error")
+
+	check.Required("server.host", cfg.Server.Host)
+	check.Range("server.port", cfg.Server.Port, 1, 65535)
+	check.Required("server.grpc_port", cfg.Server.GRPCPort)
+	if cfg.Server.GRPC.RequestTimeout != "" {
+		check.Duration("server.grpc.request-timeout", cfg.Server.GRPC.RequestTimeout)
+	}
+	cfg.Server.TLS.validate(check, "server.tls")
+	cfg.Server.GRPC.TLS.validate(check, "server.grpc.tls")
+	for i, name := range cfg.Server.GRPC.Interceptors {
+		check.OneOf(fmt.Sprintf("server.grpc.interceptors[%d]", i), name, internalgrpc.DefaultInterceptors...)
+	}
+
+	if cfg.Server.RateLimit.Enabled {
+		cfg.validateRateLimit(check)
+	}
+
+	if cfg.Attachments.Store != "" {
+		check.OneOf("attachments.store", cfg.Attachments.Store, blob.Types...)
 	}
-	defer file.Close()
+	if cfg.Attachments.Store == blob.TypeFS {
+		check.Required("attachments.dir", cfg.Attachments.Dir)
+	}
+	if cfg.Attachments.MaxSize < 0 {
+		check.Fail("attachments.max-size", "must not be negative, got %d", cfg.Attachments.MaxSize)
+	}
+
+	check.OneOf("storage.type", cfg.Storage.Type, backend.Types...)
+	if cfg.Storage.Type == backend.TypeDatabase {
+		check.Required("storage.host", cfg.Storage.Host)
+		check.Range("storage.port", cfg.Storage.Port, 1, 65535)
+		check.Required("storage.user", cfg.Storage.User)
+		check.Required("storage.database", cfg.Storage.Database)
+		check.OneOf("storage.sslmode", cfg.Storage.SSLMode,
+			"disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	}
+	if cfg.Storage.Type == backend.TypeSQLite {
+		check.Required("storage.sqlite.path", cfg.Storage.SQLite.Path)
+	}
+	if cfg.Storage.Type == backend.TypeFile {
+		check.Required("storage.file.path", cfg.Storage.File.Path)
+		check.OneOf("storage.file.fsync", cfg.Storage.File.Fsync,
+			string(filestorage.FsyncAlways), string(filestorage.FsyncInterval), string(filestorage.FsyncNever))
+		if cfg.Storage.File.Fsync == string(filestorage.FsyncInterval) {
+			check.Duration("storage.file.fsync-interval", cfg.Storage.File.FsyncInterval)
+		}
+		if cfg.Storage.File.SnapshotEvery < 0 {
+			check.Fail("storage.file.snapshot-every", "must not be negative, got %d", cfg.Storage.File.SnapshotEvery)
+		}
+	}
+}
 
-	decoder := yml.NewDecoder(file)
-	var cfg Config
-	if err := decoder.Decode(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to decode config file: %w", err)
+func (cfg *Config) validateRateLimit(check *config.Checker) {
+	rateLimit := cfg.Server.RateLimit
+	check.OneOf("server.rate-limit.backend", rateLimit.Backend, rateLimitMemory, rateLimitDatabase)
+	if rateLimit.Backend == rateLimitDatabase && cfg.Storage.Type != backend.TypeDatabase {
+		check.Fail("server.rate-limit.backend", "database requires storage.type %s, got %s", backend.TypeDatabase, cfg.Storage.Type)
+	}
+	// Классы сортируются, чтобы ошибки выводились в одном и том же порядке
+	classes := make([]string, 0, len(rateLimit.Rules))
+	for class := range rateLimit.Rules {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		rule := rateLimit.Rules[class]
+		field := "server.rate-limit.rules." + class
+		check.OneOf(field, class, ratelimit.Classes...)
+		if rule.Limit <= 0 {
+			check.Fail(field+".limit", "must be positive, got %d", rule.Limit)
+		}
+		if rule.Burst < 0 {
+			check.Fail(field+".burst", "must not be negative, got %d", rule.Burst)
+		}
+		check.Duration(field+".per", rule.Per)
+	}
+}
+
+func LoadConfig(path string) (*Config, error) {
+	cfg := NewConfig()
+	if err := config.Load(path, &cfg); err != nil {
+		return nil, err
 	}
 	return &cfg, nil
 }
@@ -7,17 +7,22 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	internalgrpc "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server/grpc"
-	sqlstorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sql"
 
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/blob"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/ratelimit"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server"
 	internalhttp "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server/http"
-	memorystorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/memory"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/backend"
+	sqlstorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sql"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/tlsconfig"
 )
 
 var configFile string
@@ -33,11 +38,21 @@ func main() {
 		printVersion()
 		return
 	}
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(flag.Args()[1:]); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+	if flag.Arg(0) == "all-in-one" {
+		runAllInOne()
+		return
+	}
 
 	// Загружаем конфигурацию
 	config, err := LoadConfig(configFile)
 	if err != nil {
-		log.Fatalf("Error: loading config from file %d", err)
+		log.Fatalf("Error: loading config from file %v", err)
 	}
 
 	// Инициализируем логгер
@@ -51,78 +66,224 @@ func main() {
 	defer storage.Close()
 
 	// Создаем приложение
-	calendar := app.New(logg, storage)
+	blobs, err := initBlobStore(config)
+	if err != nil {
+		log.Fatalf("Failed to initialize attachment store: %v", err)
+	}
+	calendar := app.New(logg, storage, app.WithAttachments(blobs, config.Attachments.MaxSize))
 
 	// Создаем контекст для graceful shutdown
 	ctx, cancel := createShutdownContext()
 	defer cancel()
 
-	// Запускаем HTTP сервер
-	httpServer := initHTTPServer(config, logg, calendar)
-	go gracefulShutdown(ctx, httpServer, logg)
+	rateLimit, err := initRateLimit(config)
+	if err != nil {
+		log.Fatalf("Failed to initialize rate limiter: %v", err)
+	}
+	defer rateLimit.Close()
 
-	// Запускаем gRPC сервер
-	go startGRPCServer(config, logg, calendar, ctx)
+	grpcServer, err := initGRPCServer(config, logg, calendar, rateLimit)
+	if err != nil {
+		log.Fatalf("Failed to initialize grpc server: %v", err)
+	}
+
+	// REST API /v1 транскодируется в вызовы gRPC сервера внутри процесса
+	gateway, err := grpcServer.GatewayHandler(ctx)
+	if err != nil {
+		log.Fatalf("Failed to initialize grpc gateway: %v", err)
+	}
+	httpServer, err := initHTTPServer(config, logg, calendar, rateLimit, internalhttp.WithHandler("/v1/*", gateway))
+	if err != nil {
+		log.Fatalf("Failed to initialize http server: %v", err)
+	}
+
+	// Перечитываем конфигурацию по SIGHUP
+	go watchConfig(ctx, config, logg, rateLimit)
 
 	logg.Info("calendar is running...")
 
-	// Запускаем HTTP сервер и ждем завершения
-	if err := httpServer.Start(ctx); err != nil {
-		logg.Error("failed to start http server: " + err.Error())
-		cancel()
-		os.Exit(1)
-	}
+	// Запускаем оба сервера и ждем завершения; падение одного останавливает другой
+	failed := runServers(ctx, cancel, logg, map[string]server.CalculatorServer{
+		"http": httpServer,
+		"grpc": grpcServer,
+	})
 
 	logg.Info("calendar is stopped")
+	if failed {
+		storage.Close()
+		os.Exit(1)
+	}
 }
 
 // initStorage инициализирует хранилище в зависимости от конфигурации
-func initStorage(config *Config, logg app.Logger) (app.Storage, error) {
-	switch config.Storage.Type {
-	case "memory":
-		return memorystorage.New(logg), nil
-	case "database":
-		return initDatabaseStorage(config, logg)
-	default:
-		return nil, fmt.Errorf("unknown storage type: %s", config.Storage.Type)
+func initStorage(config *Config, logg app.Logger) (backend.Storage, error) {
+	// Без auto-migrate схему готовит calendar migrate up
+	if config.Storage.Type == backend.TypeDatabase && config.Storage.AutoMigrate {
+		if err := sqlstorage.RunMigrations(config.Storage.GetPostgresDSN()); err != nil {
+			return nil, fmt.Errorf("migrations failed: %w", err)
+		}
 	}
+	return backend.Open(config.Storage.Settings(), logg)
 }
 
-// initDatabaseStorage инициализирует базу данных
-func initDatabaseStorage(config *Config, logg app.Logger) (app.Storage, error) {
-	// Создаем хранилище (миграции выполняются отдельным контейнером)
-	storage, err := sqlstorage.New(config.Storage.GetPostgresDSN(), logg)
-	if err != nil {
-		return nil, fmt.Errorf("sql storage failed: %w", err)
+// initBlobStore открывает хранилище содержимого вложений; nil, если оно не настроено
+func initBlobStore(config *Config) (blob.Store, error) {
+	if config.Attachments.Store == "" {
+		return nil, nil
 	}
+	return blob.Open(config.Attachments.Store, config.Attachments.Dir)
+}
 
-	return storage, nil
+// rateLimiter - ограничитель частоты запросов из раздела server.rate-limit. Серверы читают
+// политику из policy, поэтому при перечитывании конфигурации новые правила действуют сразу.
+// Выключенный ограничитель хранит пустую политику и ничего не ограничивает.
+type rateLimiter struct {
+	policy  *ratelimit.Switch
+	backend string
+	limiter ratelimit.Limiter
+	close   func()
+}
+
+// initRateLimit создаёт ограничитель по конфигурации; Close освобождает соединение с базой общего ограничителя
+func initRateLimit(config *Config) (*rateLimiter, error) {
+	r := &rateLimiter{policy: ratelimit.NewSwitch(ratelimit.Policy{}), close: func() {}}
+	if err := r.apply(config); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// apply включает правила из конфигурации. Хранилище счётчиков открывается заново,
+// только если сменился backend, иначе счётчики клиентов сохраняются.
+func (r *rateLimiter) apply(config *Config) error {
+	rateLimit := config.Server.RateLimit
+	if !rateLimit.Enabled {
+		r.policy.Store(ratelimit.Policy{})
+		return nil
+	}
+	if r.limiter != nil && rateLimit.Backend == r.backend {
+		r.policy.Store(rateLimit.Policy(r.limiter))
+		return nil
+	}
+
+	var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter()
+	closeLimiter := func() {}
+	if rateLimit.Backend == rateLimitDatabase {
+		dbLimiter, err := sqlstorage.NewRateLimiter(config.Storage.GetPostgresDSN())
+		if err != nil {
+			return err
+		}
+		limiter, closeLimiter = dbLimiter, func() { _ = dbLimiter.Close() }
+	}
+	// Прежнее хранилище закрывается после замены политики, чтобы новые запросы его уже не видели
+	previous := r.close
+	r.limiter, r.backend, r.close = limiter, rateLimit.Backend, closeLimiter
+	r.policy.Store(rateLimit.Policy(limiter))
+	previous()
+	return nil
+}
+
+func (r *rateLimiter) Close() {
+	r.close()
 }
 
 // initHTTPServer создает и настраивает HTTP сервер
-func initHTTPServer(config *Config, logg app.Logger, calendar *app.App) server.CalculatorServer {
-	return internalhttp.NewServer(logg, config.Server.Host, config.Server.Port, calendar)
+func initHTTPServer(
+	config *Config, logg app.Logger, calendar *app.App, rateLimit *rateLimiter, opts ...internalhttp.Option,
+) (server.CalculatorServer, error) {
+	opts = append(opts, internalhttp.WithRateLimit(rateLimit.policy))
+	if config.Server.TLS.Enabled() {
+		tlsConfig, err := tlsconfig.NewServerConfig(config.Server.TLS.Files(), logg)
+		if err != nil {
+			return nil, fmt.Errorf("http tls: %w", err)
+		}
+		opts = append(opts, internalhttp.WithTLS(tlsConfig))
+	}
+	return internalhttp.NewServer(logg, config.Server.Host, config.Server.Port, calendar, opts...), nil
+}
+
+// initGRPCServer создает gRPC сервер с цепочкой перехватчиков из конфигурации
+func initGRPCServer(config *Config, logg app.Logger, calendar *app.App, rateLimit *rateLimiter) (*internalgrpc.CalendarGRPCServer, error) {
+	var timeout time.Duration
+	if config.Server.GRPC.RequestTimeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(config.Server.GRPC.RequestTimeout); err != nil {
+			return nil, fmt.Errorf("invalid grpc request timeout: %w", err)
+		}
+	}
+
+	names := config.Server.GRPC.Interceptors
+	if len(names) == 0 {
+		names = internalgrpc.DefaultInterceptors
+	}
+	interceptors, err := internalgrpc.BuildInterceptors(logg, names, timeout)
+	if err != nil {
+		return nil, err
+	}
+	// Ограничитель ставится в конец цепочки: ему нужен пользователь из ActorInterceptor.
+	// Он есть в цепочке и выключенным, чтобы его можно было включить без перезапуска.
+	interceptors = append(interceptors, internalgrpc.RateLimitInterceptor(rateLimit.policy, logg))
+
+	opts := []internalgrpc.Option{
+		internalgrpc.WithInterceptors(interceptors...),
+		internalgrpc.WithReflection(config.Server.GRPC.Reflection),
+	}
+	if config.Server.GRPC.TLS.Enabled() {
+		tlsConfig, err := tlsconfig.NewServerConfig(config.Server.GRPC.TLS.Files(), logg)
+		if err != nil {
+			return nil, fmt.Errorf("grpc tls: %w", err)
+		}
+		opts = append(opts, internalgrpc.WithTLS(tlsConfig))
+	}
+	return internalgrpc.NewCalendarGRPCServer(logg, config.Server.GRPCPort, calendar, opts...), nil
 }
 
 // createShutdownContext создает контекст для graceful shutdown
 func createShutdownContext() (context.Context, context.CancelFunc) {
-	return signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	return signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 }
 
-// gracefulShutdown обрабатывает graceful shutdown HTTP сервера
-func gracefulShutdown(ctx context.Context, server server.CalculatorServer, logg app.Logger) {
+// runServers запускает серверы и останавливает их все, когда отменён контекст или один из них упал.
+// Возвращает true, если хотя бы один сервер завершился с ошибкой.
+func runServers(ctx context.Context, cancel context.CancelFunc, logg app.Logger, servers map[string]server.CalculatorServer) bool {
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Bool
+	)
+
+	for name, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Start(ctx); err != nil {
+				logg.Error(fmt.Sprintf("failed to start %s server: %s", name, err))
+				failed.Store(true)
+				cancel()
+			}
+		}()
+	}
+
 	<-ctx.Done()
+	gracefulShutdown(servers, logg)
+	wg.Wait()
+
+	return failed.Load()
+}
 
+// gracefulShutdown останавливает серверы, давая им время завершить текущие запросы
+func gracefulShutdown(servers map[string]server.CalculatorServer, logg app.Logger) {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*3)
 	defer cancel()
 
-	if err := server.Stop(shutdownCtx); err != nil {
-		logg.Error("failed to stop http server: " + err.Error())
+	var wg sync.WaitGroup
+	for name, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Stop(shutdownCtx); err != nil {
+				logg.Error(fmt.Sprintf("failed to stop %s server: %s", name, err))
+			}
+		}()
 	}
-}
-
-// startGRPCServer запускает gRPC сервер
-func startGRPCServer(config *Config, logg app.Logger, calendar *app.App, ctx context.Context) {
-	grpcServer := internalgrpc.NewCalendarGRPCServer(logg, config.Server.GRPCPort, calendar)
-	grpcServer.Start(ctx)
+	wg.Wait()
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/backend"
+	sqlstorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sql"
+)
+
+var migrationsDir string
+
+func init() {
+	flag.StringVar(&migrationsDir, "migrations-dir", "migrations", "Where 'migrate create' puts new migration files")
+}
+
+const migrateUsage = "usage: calendar [-config file] migrate up|down [N]|status|force V|create name"
+
+// runMigrate выполняет подкоманду migrate; args - аргументы после слова migrate.
+// Миграции встроены в бинарник, поэтому каталог с ними нужен только для create.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		up, down, err := sqlstorage.CreateMigration(migrationsDir, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println("created", up)
+		fmt.Println("created", down)
+		return nil
+	}
+
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if config.Storage.Type != backend.TypeDatabase {
+		return fmt.Errorf("migrations are for storage type %q, configured %q", backend.TypeDatabase, config.Storage.Type)
+	}
+
+	migrator, err := sqlstorage.NewMigrator(config.Storage.GetPostgresDSN())
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		if err := migrator.Up(); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 2 {
+			return errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		if err := migrator.Down(steps); err != nil {
+			return err
+		}
+	case "force":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := migrator.Force(version); err != nil {
+			return err
+		}
+	case "status":
+		return printMigrationStatus(migrator)
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("schema version: %d, dirty: %t\n", version, dirty)
+	return nil
+}
+
+func printMigrationStatus(migrator *sqlstorage.Migrator) error {
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		mark := "pending"
+		if status.Applied {
+			mark = "applied"
+		}
+		fmt.Fprintf(os.Stdout, "%03d %-40s %s\n", status.Version, status.Name, mark)
+	}
+	fmt.Printf("schema version: %d, dirty: %t\n", version, dirty)
+	return nil
+}
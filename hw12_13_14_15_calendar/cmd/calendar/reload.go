@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/config"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+)
+
+// watchConfig перечитывает конфигурацию по SIGHUP, пока не отменён контекст
+func watchConfig(ctx context.Context, current *Config, logg *logger.Logger, rateLimit *rateLimiter) {
+	config.OnReload(ctx, func() {
+		reloadConfig(current, logg, rateLimit)
+	})
+}
+
+// reloadConfig применяет изменения, безопасные на лету, и сообщает о тех, что требуют рестарта.
+// В current попадают только применённые поля, поэтому неприменённые изменения
+// будут видны и при следующем перечитывании.
+func reloadConfig(current *Config, logg *logger.Logger, rateLimit *rateLimiter) {
+	updated, err := LoadConfig(configFile)
+	if err != nil {
+		logg.Error("failed to reload config, keeping current one: " + err.Error())
+		return
+	}
+
+	hot, restart := config.Split(config.Diff(current, updated))
+	rateLimitChanged := false
+	for _, field := range hot {
+		if strings.HasPrefix(field, rateLimitFields) {
+			rateLimitChanged = true
+			continue
+		}
+		switch field {
+		case "logger.level":
+			logg.SetLevel(updated.Logger.Level)
+		}
+	}
+	if rateLimitChanged && reloadRateLimit(updated, rateLimit, logg) {
+		current.Server.RateLimit = updated.Server.RateLimit
+	}
+	current.Logger = updated.Logger
+
+	if len(restart) > 0 {
+		logg.Warn("config changes require restart: " + strings.Join(restart, ", "))
+	}
+	if len(hot) > 0 {
+		logg.Info("config reloaded, applied: " + strings.Join(hot, ", "))
+	} else {
+		logg.Info("config reloaded, nothing to apply")
+	}
+}
+
+// rateLimitFields - префикс полей раздела server.rate-limit; раздел применяется целиком
+const rateLimitFields = "server.rate-limit."
+
+// reloadRateLimit заменяет политику ограничителя; false, если новую применить не удалось
+func reloadRateLimit(updated *Config, rateLimit *rateLimiter, logg *logger.Logger) bool {
+	if err := rateLimit.apply(updated); err != nil {
+		logg.Error("failed to apply rate limit, keeping current one: " + err.Error())
+		return false
+	}
+	return true
+}
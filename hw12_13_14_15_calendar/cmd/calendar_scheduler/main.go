@@ -7,14 +7,15 @@ import (
 	"log"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"syscall"
 
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/blob"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/queue/rabbit"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/scheduler"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/backend"
 	sqlstorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sql"
 )
 
@@ -42,18 +43,39 @@ func main() {
 	}
 	defer notificationStorage.Close()
 
+	rabbitOpts, err := config.Rabbit.TLS.options(logg)
+	if err != nil {
+		log.Fatalf("Error: rabbit tls %v", err)
+	}
 	queue, err := rabbit.NewRabbitQueue[storage.Notification](
 		config.Rabbit.Url,
 		config.Rabbit.Username,
 		config.Rabbit.Password,
 		logg,
+		rabbitOpts...,
 	)
 	if err != nil {
 		log.Fatalf("Error: creating rabbit queue %v", err)
 	}
 	defer queue.Close()
 
-	scheduler := scheduler.NewScheduler(logg, notificationStorage, queue, config.EventQueue.Name, config.EventQueue.Exchange, config.Scheduler.CheckInterval)
+	eventRetention, trashRetention := config.Scheduler.Retention()
+	schedulerOpts := []scheduler.Option{
+		scheduler.WithArchiver(scheduler.NewFileArchiver(config.Scheduler.ArchiveDir)),
+		scheduler.WithRetention(eventRetention, trashRetention),
+	}
+	if config.Attachments.Store != "" {
+		blobs, err := blob.Open(config.Attachments.Store, config.Attachments.Dir)
+		if err != nil {
+			log.Fatalf("Error: opening attachment store %v", err)
+		}
+		schedulerOpts = append(schedulerOpts, scheduler.WithBlobStore(blobs))
+	}
+	scheduler := scheduler.NewScheduler(
+		logg, notificationStorage, queue,
+		config.EventQueue.Name, config.EventQueue.Exchange, config.Scheduler.CheckInterval,
+		schedulerOpts...,
+	)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -63,24 +85,21 @@ func main() {
 
 	go scheduler.Start(ctx)
 
+	// Перечитываем конфигурацию по SIGHUP
+	go watchConfig(ctx, config, logg, scheduler)
+
 	<-sigChan
 	fmt.Println("Scheduler stopped")
 }
 
 func initNotificationStorage(config *SchedulerConfig, logg app.Logger) (scheduler.NotificationStorage, error) {
-	migrationsPath, err := filepath.Abs("./migrations")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get migrations path: %w", err)
-	}
-
-	if err := sqlstorage.RunMigrations(config.Storage.GetPostgresDSN(), migrationsPath); err != nil {
-		return nil, fmt.Errorf("migrations failed: %w", err)
-	}
-
-	notificationStorage, err := scheduler.NewSQLNotificationStorage(config.Storage.GetPostgresDSN(), logg)
-	if err != nil {
-		return nil, fmt.Errorf("sql notification storage failed: %w", err)
+	// Миграции встроены в бинарник; SQLite применяет их при открытии.
+	// Без auto-migrate схему Postgres готовит calendar migrate up
+	if config.Storage.Type == backend.TypeDatabase && config.Storage.AutoMigrate {
+		if err := sqlstorage.RunMigrations(config.Storage.GetPostgresDSN()); err != nil {
+			return nil, fmt.Errorf("migrations failed: %w", err)
+		}
 	}
 
-	return notificationStorage, nil
+	return backend.Open(config.Storage.Settings(), logg)
 }
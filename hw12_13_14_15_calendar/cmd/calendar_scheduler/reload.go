@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/config"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/scheduler"
+)
+
+// watchConfig перечитывает конфигурацию по SIGHUP, пока не отменён контекст
+func watchConfig(ctx context.Context, current *SchedulerConfig, logg *logger.Logger, sched *scheduler.Scheduler) {
+	config.OnReload(ctx, func() {
+		reloadConfig(current, logg, sched)
+	})
+}
+
+// reloadConfig применяет изменения, безопасные на лету, и сообщает о тех, что требуют рестарта.
+// В current попадают только применённые поля, поэтому неприменённые изменения
+// будут видны и при следующем перечитывании.
+func reloadConfig(current *SchedulerConfig, logg *logger.Logger, sched *scheduler.Scheduler) {
+	updated, err := LoadConfig(configFile)
+	if err != nil {
+		logg.Error("failed to reload config, keeping current one: " + err.Error())
+		return
+	}
+
+	hot, restart := config.Split(config.Diff(current, updated))
+	queueChanged := false
+	for _, field := range hot {
+		switch field {
+		case "logger.level":
+			logg.SetLevel(updated.Logger.Level)
+		case "scheduler.check-interval":
+			sched.SetCheckInterval(updated.Scheduler.CheckInterval)
+		case "scheduler.event-retention", "scheduler.trash-retention":
+			sched.SetRetention(updated.Scheduler.Retention())
+		case "scheduler.archive-dir":
+			sched.SetArchiver(scheduler.NewFileArchiver(updated.Scheduler.ArchiveDir))
+		case "event-queue.name", "event-queue.exchange":
+			queueChanged = true
+		}
+	}
+	if queueChanged {
+		sched.SetQueue(updated.EventQueue.Name, updated.EventQueue.Exchange)
+	}
+	current.Logger = updated.Logger
+	current.Scheduler = updated.Scheduler
+	current.EventQueue = updated.EventQueue
+
+	if len(restart) > 0 {
+		logg.Warn("config changes require restart: " + strings.Join(restart, ", "))
+	}
+	if len(hot) > 0 {
+		logg.Info("config reloaded, applied: " + strings.Join(hot, ", "))
+	} else {
+		logg.Info("config reloaded, nothing to apply")
+	}
+}
@@ -2,64 +2,174 @@ package main
 
 import (
 	"fmt"
-	"os"
+	"time"
 
-	yml "gopkg.in/yaml.v3"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/blob"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/config"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/queue/rabbit"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/backend"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/tlsconfig"
 )
 
+// Поля с тегом reload:"hot" применяются по SIGHUP без перезапуска, остальные требуют рестарта.
 type SchedulerConfig struct {
-	Logger     Logger
-	Storage    Storage
-	Rabbit     Rabbit
-	EventQueue EventQueue `yaml:"event-queue"`
-	Scheduler  SchedulerSettings
+	Logger      Logger `reload:"hot"`
+	Storage     Storage
+	Rabbit      Rabbit
+	EventQueue  EventQueue        `yaml:"event-queue" reload:"hot"`
+	Scheduler   SchedulerSettings `reload:"hot"`
+	Attachments Attachments
+}
+
+// Attachments - хранилище файлов-вложений календаря; планировщик удаляет из него файлы
+// окончательно удалённых событий. Пусто - файлы не удаляются.
+type Attachments struct {
+	Store string `env:"ATTACHMENTS_STORE"`
+	Dir   string `env:"ATTACHMENTS_DIR"` // Тот же каталог, что у календаря
 }
 
 type EventQueue struct {
-	Name     string
-	Exchange string
+	Name     string `env:"EVENT_QUEUE_NAME"`
+	Exchange string `env:"EVENT_QUEUE_EXCHANGE"`
 }
 
 type SchedulerSettings struct {
-	CheckInterval string `yaml:"check-interval"`
+	CheckInterval  string `yaml:"check-interval" env:"SCHEDULER_CHECK_INTERVAL"`
+	EventRetention string `yaml:"event-retention" env:"SCHEDULER_EVENT_RETENTION"` // Сколько хранить закончившиеся события
+	TrashRetention string `yaml:"trash-retention" env:"SCHEDULER_TRASH_RETENTION"` // Сколько хранить события в корзине
+	ArchiveDir     string `yaml:"archive-dir" env:"SCHEDULER_ARCHIVE_DIR"`         // Куда архивировать события перед удалением
+}
+
+// Retention возвращает сроки хранения; ошибки разбора отсекает Validate
+func (s SchedulerSettings) Retention() (events, trash time.Duration) {
+	events, _ = time.ParseDuration(s.EventRetention)
+	trash, _ = time.ParseDuration(s.TrashRetention)
+	return events, trash
 }
 
 type Rabbit struct {
-	Url      string
-	Username string
-	Password string
+	Url      string    `env:"RABBITMQ_URL"`
+	Username string    `env:"RABBITMQ_USER"`
+	Password string    `env:"RABBITMQ_PASS"`
+	TLS      RabbitTLS `yaml:"tls"`
+}
+
+// RabbitTLS - подключение по amqps://. Без ca-file брокер проверяется по системным CA,
+// cert-file и key-file нужны, если брокер требует клиентский сертификат.
+type RabbitTLS struct {
+	Enabled    bool   `env:"RABBITMQ_TLS"`
+	CAFile     string `yaml:"ca-file" env:"RABBITMQ_CA_FILE"`
+	CertFile   string `yaml:"cert-file" env:"RABBITMQ_CERT_FILE"`
+	KeyFile    string `yaml:"key-file" env:"RABBITMQ_KEY_FILE"`
+	ServerName string `yaml:"server-name" env:"RABBITMQ_SERVER_NAME"`
+}
+
+func (t RabbitTLS) validate(check *config.Checker) {
+	if t.Enabled && (t.CertFile == "") != (t.KeyFile == "") {
+		check.Fail("rabbit.tls", "cert-file and key-file must be set together")
+	}
+}
+
+// options - параметры подключения к RabbitMQ; сертификаты перечитываются при изменении файлов
+func (t RabbitTLS) options(logg app.Logger) ([]rabbit.Option, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+	tlsConfig, err := tlsconfig.NewClientConfig(tlsconfig.ClientFiles{
+		CAFile: t.CAFile, CertFile: t.CertFile, KeyFile: t.KeyFile, ServerName: t.ServerName,
+	}, logg)
+	if err != nil {
+		return nil, err
+	}
+	return []rabbit.Option{rabbit.WithTLS(tlsConfig)}, nil
 }
 
 type Storage struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Database string
-	SSLMode  string `yaml:"sslmode"`
+	Type     string `env:"STORAGE_TYPE"`
+	Host     string `env:"POSTGRES_HOST"`
+	Port     int    `env:"POSTGRES_PORT"`
+	User     string `env:"POSTGRES_USER"`
+	Password string `env:"POSTGRES_PASSWORD"`
+	Database string `env:"POSTGRES_DB"`
+	SSLMode  string `yaml:"sslmode" env:"POSTGRES_SSLMODE"`
+	// CA сервера Postgres и клиентский сертификат
+	SSLRootCert string `yaml:"sslrootcert" env:"POSTGRES_SSLROOTCERT"`
+	SSLCert     string `yaml:"sslcert" env:"POSTGRES_SSLCERT"`
+	SSLKey      string `yaml:"sslkey" env:"POSTGRES_SSLKEY"`
+	// Применять встроенные миграции Postgres при запуске
+	AutoMigrate bool   `yaml:"auto-migrate" env:"POSTGRES_AUTO_MIGRATE"`
+	SQLite      SQLite `yaml:"sqlite"`
+}
+
+type SQLite struct {
+	Path string `env:"SQLITE_PATH"`
 }
 
 type Logger struct {
-	Level string
+	Level string `env:"LOG_LEVEL"`
 }
 
-func LoadConfig(path string) (*SchedulerConfig, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open config file: %w", err)
+func (cfg *SchedulerConfig) Validate() error {
+	var check config.Checker
+
+	check.OneOf("logger.level", cfg.Logger.Level, "debug", "info", "warn", "error")
+
+	check.OneOf("storage.type", cfg.Storage.Type, backend.Types...)
+	switch cfg.Storage.Type {
+	case backend.TypeMemory, backend.TypeFile:
+		// Эти хранилища живут внутри процесса календаря, отдельный планировщик их не увидит
+		check.Fail("storage.type", "%q keeps events inside the calendar process, use database or sqlite", cfg.Storage.Type)
+	case backend.TypeDatabase:
+		check.Required("storage.host", cfg.Storage.Host)
+		check.Range("storage.port", cfg.Storage.Port, 1, 65535)
+		check.Required("storage.user", cfg.Storage.User)
+		check.Required("storage.database", cfg.Storage.Database)
+	case backend.TypeSQLite:
+		check.Required("storage.sqlite.path", cfg.Storage.SQLite.Path)
+	}
+
+	check.Required("rabbit.url", cfg.Rabbit.Url)
+	check.Required("rabbit.username", cfg.Rabbit.Username)
+	cfg.Rabbit.TLS.validate(&check)
+
+	check.Required("event-queue.name", cfg.EventQueue.Name)
+	check.Duration("scheduler.check-interval", cfg.Scheduler.CheckInterval)
+	check.Duration("scheduler.event-retention", cfg.Scheduler.EventRetention)
+	check.Duration("scheduler.trash-retention", cfg.Scheduler.TrashRetention)
+	check.Required("scheduler.archive-dir", cfg.Scheduler.ArchiveDir)
+
+	switch cfg.Attachments.Store {
+	case "":
+	case blob.TypeFS:
+		check.Required("attachments.dir", cfg.Attachments.Dir)
+	default:
+		// Остальные хранилища живут внутри процесса календаря
+		check.Fail("attachments.store", "%q is not shared with the calendar process, use fs", cfg.Attachments.Store)
 	}
-	defer file.Close()
 
-	decoder := yml.NewDecoder(file)
+	return check.Err()
+}
+
+func LoadConfig(path string) (*SchedulerConfig, error) {
 	var cfg SchedulerConfig
-	if err := decoder.Decode(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	if err := config.Load(path, &cfg); err != nil {
+		return nil, err
 	}
 	return &cfg, nil
 }
 
+// Settings собирает параметры открытия хранилища
+func (storage *Storage) Settings() backend.Settings {
+	return backend.Settings{
+		Type:        storage.Type,
+		PostgresDSN: storage.GetPostgresDSN(),
+		SQLitePath:  storage.SQLite.Path,
+	}
+}
+
 func (storage *Storage) GetPostgresDSN() string {
-	return fmt.Sprintf(
+	dsn := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		storage.Host,
 		storage.Port,
@@ -68,4 +178,12 @@ func (storage *Storage) GetPostgresDSN() string {
 		storage.Database,
 		storage.SSLMode,
 	)
+	for _, param := range [][2]string{
+		{"sslrootcert", storage.SSLRootCert}, {"sslcert", storage.SSLCert}, {"sslkey", storage.SSLKey},
+	} {
+		if param[1] != "" {
+			dsn += " " + param[0] + "=" + param[1]
+		}
+	}
+	return dsn
 }
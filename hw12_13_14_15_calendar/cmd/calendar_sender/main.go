@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -11,6 +10,7 @@ import (
 
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/queue/rabbit"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/sender"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
 )
 
@@ -25,11 +25,17 @@ func main() {
 
 	logg := logger.New(config.Logger.Level)
 
+	rabbitOpts, err := config.Rabbit.TLS.options(logg)
+	if err != nil {
+		logg.Error("Failed to configure RabbitMQ TLS: " + err.Error())
+		os.Exit(1)
+	}
 	queue, err := rabbit.NewRabbitQueue[storage.Notification](
 		config.Rabbit.URL,
 		config.Rabbit.Username,
 		config.Rabbit.Password,
 		logg,
+		rabbitOpts...,
 	)
 	if err != nil {
 		logg.Error("Failed to connect to RabbitMQ: " + err.Error())
@@ -45,32 +51,11 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				msgChan, errChan := queue.Get(ctx, config.EventQueue.Name)
+	cons := sender.NewConsumer(queue, logg)
+	cons.Start(ctx, config.EventQueue.Name)
 
-				for {
-					select {
-					case <-ctx.Done():
-						return
-					case msg := <-msgChan:
-						notification := msg.Body
-						logg.Info(fmt.Sprintf("Sending notification: EventID=%s, Title=%s, UserID=%s, EventTime=%s",
-							notification.EventID, notification.Title, notification.UserID, notification.EventTime.Format("2006-01-02 15:04:05")))
-					case err := <-errChan:
-						if err != nil {
-							logg.Error("Error receiving message: " + err.Error())
-							return
-						}
-					}
-				}
-			}
-		}
-	}()
+	// Перечитываем конфигурацию по SIGHUP
+	go watchConfig(ctx, *configPath, config, logg, cons)
 
 	<-sigChan
 	logg.Info("Sender stopped")
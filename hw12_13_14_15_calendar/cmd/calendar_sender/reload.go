@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/config"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/sender"
+)
+
+// watchConfig перечитывает конфигурацию по SIGHUP, пока не отменён контекст
+func watchConfig(ctx context.Context, configPath string, current *Config, logg *logger.Logger, cons *sender.Consumer) {
+	config.OnReload(ctx, func() {
+		reloadConfig(ctx, configPath, current, logg, cons)
+	})
+}
+
+// reloadConfig применяет изменения, безопасные на лету, и сообщает о тех, что требуют рестарта.
+// В current попадают только применённые поля, поэтому неприменённые изменения
+// будут видны и при следующем перечитывании.
+func reloadConfig(ctx context.Context, configPath string, current *Config, logg *logger.Logger, cons *sender.Consumer) {
+	updated, err := LoadConfig(configPath)
+	if err != nil {
+		logg.Error("failed to reload config, keeping current one: " + err.Error())
+		return
+	}
+
+	hot, restart := config.Split(config.Diff(current, updated))
+	for _, field := range hot {
+		switch field {
+		case "logger.level":
+			logg.SetLevel(updated.Logger.Level)
+		case "event-queue.name":
+			cons.Start(ctx, updated.EventQueue.Name)
+		}
+	}
+	current.Logger = updated.Logger
+	current.EventQueue = updated.EventQueue
+
+	if len(restart) > 0 {
+		logg.Warn("config changes require restart: " + strings.Join(restart, ", "))
+	}
+	if len(hot) > 0 {
+		logg.Info("config reloaded, applied: " + strings.Join(hot, ", "))
+	} else {
+		logg.Info("config reloaded, nothing to apply")
+	}
+}
@@ -1,36 +1,75 @@
 package main
 
 import (
-	"fmt"
-	"os"
-
-	yml "gopkg.in/yaml.v3"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/config"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/queue/rabbit"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/tlsconfig"
 )
 
+// Поля с тегом reload:"hot" применяются по SIGHUP без перезапуска, остальные требуют рестарта.
 type Config struct {
 	Logger struct {
-		Level string `yaml:"level"`
-	} `yaml:"logger"`
+		Level string `yaml:"level" env:"LOG_LEVEL"`
+	} `yaml:"logger" reload:"hot"`
 	Rabbit struct {
-		URL      string `yaml:"url"`
-		Username string `yaml:"username"`
-		Password string `yaml:"password"`
+		URL      string    `yaml:"url" env:"RABBITMQ_URL"`
+		Username string    `yaml:"username" env:"RABBITMQ_USER"`
+		Password string    `yaml:"password" env:"RABBITMQ_PASS"`
+		TLS      RabbitTLS `yaml:"tls"`
 	} `yaml:"rabbit"`
 	EventQueue struct {
-		Name     string `yaml:"name"`
-		Exchange string `yaml:"exchange"`
-	} `yaml:"event-queue"`
+		Name     string `yaml:"name" env:"EVENT_QUEUE_NAME"`
+		Exchange string `yaml:"exchange" env:"EVENT_QUEUE_EXCHANGE"`
+	} `yaml:"event-queue" reload:"hot"`
 }
 
-func LoadConfig(configPath string) (*Config, error) {
-	data, err := os.ReadFile(configPath)
+// RabbitTLS - подключение по amqps://. Без ca-file брокер проверяется по системным CA,
+// cert-file и key-file нужны, если брокер требует клиентский сертификат.
+type RabbitTLS struct {
+	Enabled    bool   `env:"RABBITMQ_TLS"`
+	CAFile     string `yaml:"ca-file" env:"RABBITMQ_CA_FILE"`
+	CertFile   string `yaml:"cert-file" env:"RABBITMQ_CERT_FILE"`
+	KeyFile    string `yaml:"key-file" env:"RABBITMQ_KEY_FILE"`
+	ServerName string `yaml:"server-name" env:"RABBITMQ_SERVER_NAME"`
+}
+
+func (t RabbitTLS) validate(check *config.Checker) {
+	if t.Enabled && (t.CertFile == "") != (t.KeyFile == "") {
+		check.Fail("rabbit.tls", "cert-file and key-file must be set together")
+	}
+}
+
+// options - параметры подключения к RabbitMQ; сертификаты перечитываются при изменении файлов
+func (t RabbitTLS) options(logg app.Logger) ([]rabbit.Option, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+	tlsConfig, err := tlsconfig.NewClientConfig(tlsconfig.ClientFiles{
+		CAFile: t.CAFile, CertFile: t.CertFile, KeyFile: t.KeyFile, ServerName: t.ServerName,
+	}, logg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
 	}
+	return []rabbit.Option{rabbit.WithTLS(tlsConfig)}, nil
+}
+
+func (cfg *Config) Validate() error {
+	var check config.Checker
+
+	check.OneOf("logger.level", cfg.Logger.Level, "debug", "info", "warn", "error")
+	check.Required("rabbit.url", cfg.Rabbit.URL)
+	check.Required("rabbit.username", cfg.Rabbit.Username)
+	cfg.Rabbit.TLS.validate(&check)
+	check.Required("event-queue.name", cfg.EventQueue.Name)
 
-	var config Config
-	if err := yml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	return check.Err()
+}
+
+func LoadConfig(configPath string) (*Config, error) {
+	var cfg Config
+	if err := config.Load(configPath, &cfg); err != nil {
+		return nil, err
 	}
-	return &config, nil
+	return &cfg, nil
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/config"
+)
+
+// Config - подключение к gRPC API календаря. Файл необязателен: всё можно задать
+// переменными окружения, а флаги командной строки переопределяют и то, и другое.
+type Config struct {
+	Address string    `yaml:"address" env:"CALENDARCTL_ADDRESS"`
+	User    string    `yaml:"user" env:"CALENDARCTL_USER"`
+	Groups  string    `yaml:"groups" env:"CALENDARCTL_GROUPS"` // Через запятую
+	Token   string    `yaml:"token" env:"CALENDARCTL_TOKEN"`
+	Timeout string    `yaml:"timeout" env:"CALENDARCTL_TIMEOUT"`
+	Retries int       `yaml:"retries" env:"CALENDARCTL_RETRIES"`
+	TLS     ClientTLS `yaml:"tls"`
+}
+
+// ClientTLS - подключение по TLS; cert-file и key-file нужны, если сервер требует mTLS
+type ClientTLS struct {
+	Enabled    bool   `yaml:"enabled" env:"CALENDARCTL_TLS"`
+	CAFile     string `yaml:"ca-file" env:"CALENDARCTL_CA_FILE"`
+	CertFile   string `yaml:"cert-file" env:"CALENDARCTL_CERT_FILE"`
+	KeyFile    string `yaml:"key-file" env:"CALENDARCTL_KEY_FILE"`
+	ServerName string `yaml:"server-name" env:"CALENDARCTL_SERVER_NAME"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Address: "localhost:6523",
+		Timeout: "10s",
+		Retries: 2,
+	}
+}
+
+// defaultConfigPath - $XDG_CONFIG_HOME/calendarctl/config.yml или его аналог на других ОС
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "calendarctl", "config.yml")
+}
+
+func (cfg *Config) Validate() error {
+	var check config.Checker
+
+	check.Required("address", cfg.Address)
+	check.Duration("timeout", cfg.Timeout)
+	check.Range("retries", cfg.Retries, 0, 10)
+	if cfg.TLS.Enabled && (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		check.Fail("tls", "cert-file and key-file must be set together")
+	}
+
+	return check.Err()
+}
+
+// LoadConfig читает файл конфигурации. Отсутствие файла по умолчанию - не ошибка:
+// тогда берутся значения по умолчанию и переменные окружения.
+func LoadConfig(path string, explicit bool) (*Config, error) {
+	cfg := defaultConfig()
+	err := config.Load(path, &cfg)
+	if err == nil {
+		return &cfg, nil
+	}
+	if explicit || !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := config.ApplyEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to apply env overrides: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// Форматы, в которых принимается время; без часового пояса время считается местным
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339, YYYY-MM-DD HH:MM or YYYY-MM-DD", value)
+}
+
+func newEventCommand(c *cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "event",
+		Aliases: []string{"events"},
+		Short:   "Manage calendar events",
+	}
+	cmd.AddCommand(
+		newEventCreateCommand(c),
+		newEventGetCommand(c),
+		newEventUpdateCommand(c),
+		newEventDeleteCommand(c),
+		newEventListCommand(c),
+	)
+	return cmd
+}
+
+// eventFlags - поля события, общие для create и update
+type eventFlags struct {
+	title        string
+	description  string
+	userID       string
+	start        string
+	duration     time.Duration
+	notifyBefore time.Duration
+	category     string
+	color        string
+	tags         []string
+}
+
+func (f *eventFlags) register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&f.title, "title", "", "event title")
+	flags.StringVar(&f.description, "description", "", "event description")
+	flags.StringVar(&f.userID, "user-id", "", "event owner (defaults to the configured user)")
+	flags.StringVar(&f.start, "start", "", "start time: RFC 3339, YYYY-MM-DD HH:MM or YYYY-MM-DD")
+	flags.DurationVar(&f.duration, "duration", time.Hour, "event duration")
+	flags.DurationVar(&f.notifyBefore, "notify-before", 0, "send a reminder this long before the start")
+	flags.StringVar(&f.category, "category", "", "event category")
+	flags.StringVar(&f.color, "color", "", "event color like #1e90ff")
+	flags.StringSliceVar(&f.tags, "tag", nil, "event tag; repeat or separate with commas for several tags")
+}
+
+func newEventCreateCommand(c *cli) *cobra.Command {
+	var (
+		fields     eventFlags
+		calendarID string
+		requestID  string
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an event",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			start, err := parseTime(fields.start)
+			if err != nil {
+				return err
+			}
+			userID := fields.userID
+			if userID == "" {
+				userID = c.config.User
+			}
+
+			event, err := c.client.CreateEvent(cmd.Context(), client.NewEvent{
+				CalendarID:   calendarID,
+				Title:        fields.title,
+				Description:  fields.description,
+				UserID:       userID,
+				StartTime:    start,
+				Duration:     fields.duration,
+				NotifyBefore: fields.notifyBefore,
+				Category:     fields.category,
+				Color:        fields.color,
+				Tags:         fields.tags,
+				RequestID:    requestID,
+			})
+			if err != nil {
+				return err
+			}
+			return printEvent(c.out, c.output, event)
+		},
+	}
+	fields.register(cmd)
+	cmd.Flags().StringVar(&calendarID, "calendar", "", "calendar ID (defaults to the owner's default calendar)")
+	cmd.Flags().StringVar(&requestID, "request-id", "", "idempotency key: repeating the command with it does not create a duplicate")
+	_ = cmd.MarkFlagRequired("title")
+	_ = cmd.MarkFlagRequired("start")
+	return cmd
+}
+
+func newEventGetCommand(c *cli) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get ID",
+		Short: "Show an event",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			event, err := c.client.GetEvent(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printEvent(c.out, c.output, event)
+		},
+	}
+}
+
+func newEventUpdateCommand(c *cli) *cobra.Command {
+	var fields eventFlags
+	cmd := &cobra.Command{
+		Use:   "update ID",
+		Short: "Change an event; fields without flags keep their values",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			current, err := c.client.GetEvent(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			// Версия прочитанного события защищает от затирания чужих изменений между чтением и записью
+			update := client.EventUpdate{
+				ID:              current.ID,
+				Title:           current.Title,
+				Description:     current.Description,
+				UserID:          current.UserID,
+				StartTime:       current.StartTime,
+				Duration:        current.Duration,
+				NotifyBefore:    current.NotifyBefore,
+				Category:        current.Category,
+				Color:           current.Color,
+				Tags:            current.Tags,
+				ExpectedVersion: current.Version,
+			}
+			flags := cmd.Flags()
+			if flags.Changed("title") {
+				update.Title = fields.title
+			}
+			if flags.Changed("description") {
+				update.Description = fields.description
+			}
+			if flags.Changed("user-id") {
+				update.UserID = fields.userID
+			}
+			if flags.Changed("start") {
+				if update.StartTime, err = parseTime(fields.start); err != nil {
+					return err
+				}
+			}
+			if flags.Changed("duration") {
+				update.Duration = fields.duration
+			}
+			if flags.Changed("notify-before") {
+				update.NotifyBefore = fields.notifyBefore
+			}
+			if flags.Changed("category") {
+				update.Category = fields.category
+			}
+			if flags.Changed("color") {
+				update.Color = fields.color
+			}
+			if flags.Changed("tag") {
+				update.Tags = fields.tags
+			}
+
+			event, err := c.client.UpdateEvent(cmd.Context(), update)
+			if err != nil {
+				return err
+			}
+			return printEvent(c.out, c.output, event)
+		},
+	}
+	fields.register(cmd)
+	return cmd
+}
+
+func newEventDeleteCommand(c *cli) *cobra.Command {
+	var version int64
+	cmd := &cobra.Command{
+		Use:   "delete ID",
+		Short: "Move an event to the trash",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.client.DeleteEvent(cmd.Context(), args[0], version); err != nil {
+				return err
+			}
+			if c.output == outputTable {
+				fmt.Fprintf(c.out, "event %s deleted\n", args[0])
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&version, "version", 0, "delete only if the event still has this version")
+	return cmd
+}
+
+func newEventListCommand(c *cli) *cobra.Command {
+	var (
+		date string
+		tags []string
+	)
+	cmd := &cobra.Command{
+		Use:       "list day|week|month",
+		Short:     "List events of the day, week or month containing --date",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"day", "week", "month"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			period, err := client.ParsePeriod(args[0])
+			if err != nil {
+				return err
+			}
+			// Границы дней сервер считает по UTC, поэтому и дата разбирается в UTC
+			day := time.Now()
+			if date != "" {
+				if day, err = time.Parse(time.DateOnly, date); err != nil {
+					return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+				}
+			}
+
+			events, err := c.client.ListEvents(cmd.Context(), period, day, tags...)
+			if err != nil {
+				return err
+			}
+			return printEvents(c.out, c.output, events)
+		},
+	}
+	cmd.Flags().StringVar(&date, "date", "", "any date within the period, YYYY-MM-DD in UTC (defaults to today)")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "show only events with all of these tags")
+	return cmd
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/ical"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+func newImportCommand(c *cli) *cobra.Command {
+	var calendarID string
+	cmd := &cobra.Command{
+		Use:   "import FILE.ics",
+		Short: "Create events from an iCalendar file (- reads stdin)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in := io.Reader(os.Stdin)
+			if args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				in = file
+			}
+			decoded, err := ical.Decode(in)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", args[0], err)
+			}
+
+			created := make([]client.Event, 0, len(decoded))
+			for _, event := range decoded {
+				result, err := c.client.CreateEvent(cmd.Context(), client.NewEvent{
+					CalendarID:   calendarID,
+					Title:        event.Title,
+					Description:  event.Description,
+					UserID:       c.config.User,
+					StartTime:    event.StartTime,
+					Duration:     time.Duration(event.Duration),
+					NotifyBefore: time.Duration(event.NotifyBefore),
+					Color:        event.Color,
+					Tags:         event.Tags,
+					RequestID:    importKey(calendarID, event),
+				})
+				if err != nil {
+					return fmt.Errorf("failed to import %q: %w", event.Title, err)
+				}
+				created = append(created, result)
+			}
+			return printEvents(c.out, c.output, created)
+		},
+	}
+	cmd.Flags().StringVar(&calendarID, "calendar", "", "calendar ID (defaults to the user's default calendar)")
+	return cmd
+}
+
+// importKey - ключ идемпотентности по UID события из файла: повторный импорт того же файла
+// не создаёт дубликатов, пока сервер помнит ключ. Событие без UID импортируется всегда.
+func importKey(calendarID string, event storage.Event) string {
+	if event.ID == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(calendarID + "\x00" + event.ID))
+	return "ics-" + hex.EncodeToString(sum[:])
+}
+
+func newExportCommand(c *cli) *cobra.Command {
+	var from, to, output string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write events of [--from, --to) as an iCalendar file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			start, err := parseTime(from)
+			if err != nil {
+				return err
+			}
+			end, err := parseTime(to)
+			if err != nil {
+				return err
+			}
+			if !end.After(start) {
+				return fmt.Errorf("--to must be after --from")
+			}
+
+			events, err := client.ListRange(cmd.Context(), c.client, start, end)
+			if err != nil {
+				return err
+			}
+			encoded := make([]storage.Event, 0, len(events))
+			for _, event := range events {
+				encoded = append(encoded, storage.Event{
+					ID:           event.ID,
+					CalendarID:   event.CalendarID,
+					Title:        event.Title,
+					Description:  event.Description,
+					UserID:       event.UserID,
+					StartTime:    event.StartTime,
+					Duration:     calendar_types.CalendarDuration(event.Duration),
+					NotifyBefore: calendar_types.CalendarDuration(event.NotifyBefore),
+					Labels:       storage.Labels{Category: event.Category, Color: event.Color, Tags: event.Tags},
+					Version:      event.Version,
+				})
+			}
+
+			out := c.out
+			if output != "" && output != "-" {
+				file, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				out = file
+			}
+			if err := ical.Encode(out, encoded); err != nil {
+				return fmt.Errorf("failed to write calendar: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "start of the interval")
+	cmd.Flags().StringVar(&to, "to", "", "end of the interval, exclusive")
+	cmd.Flags().StringVarP(&output, "file", "f", "", "write to this file instead of stdout")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
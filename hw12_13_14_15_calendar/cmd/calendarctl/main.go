@@ -0,0 +1,126 @@
+// calendarctl - консольный клиент gRPC API календаря
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/tlsconfig"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// cli - состояние, общее для всех команд: флаги корневой команды и клиент, созданный по ним
+type cli struct {
+	configFile string
+	address    string
+	user       string
+	token      string
+	output     string
+
+	config *Config
+	client client.Client
+	out    io.Writer
+}
+
+func main() {
+	if err := newRootCommand(os.Stdout).Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand(out io.Writer) *cobra.Command {
+	c := &cli{out: out}
+	root := &cobra.Command{
+		Use:          "calendarctl",
+		Short:        "Command-line client for the calendar gRPC API",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if !needsClient(cmd) {
+				return nil
+			}
+			return c.connect(cmd)
+		},
+		PersistentPostRunE: func(*cobra.Command, []string) error {
+			if c.client == nil {
+				return nil
+			}
+			return c.client.Close()
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&c.configFile, "config", defaultConfigPath(), "path to configuration file")
+	flags.StringVar(&c.address, "address", "", "gRPC address of the calendar server (overrides config)")
+	flags.StringVar(&c.user, "user", "", "user to act on behalf of (overrides config)")
+	flags.StringVar(&c.token, "token", "", "access token (overrides config)")
+	flags.StringVarP(&c.output, "output", "o", outputTable, "output format: table, json or yaml")
+
+	root.AddCommand(newEventCommand(c), newImportCommand(c), newExportCommand(c), newVersionCommand(out))
+	return root
+}
+
+// needsClient - служебным командам подключение к серверу не нужно
+func needsClient(cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		switch cmd.Name() {
+		case "version", "help", "completion":
+			return false
+		}
+	}
+	return true
+}
+
+// connect загружает конфигурацию, накладывает на неё флаги и создаёт клиент
+func (c *cli) connect(cmd *cobra.Command) error {
+	if err := checkOutput(c.output); err != nil {
+		return err
+	}
+
+	cfg, err := LoadConfig(c.configFile, cmd.Flags().Changed("config"))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if c.address != "" {
+		cfg.Address = c.address
+	}
+	if c.user != "" {
+		cfg.User = c.user
+	}
+	if c.token != "" {
+		cfg.Token = c.token
+	}
+
+	timeout, _ := time.ParseDuration(cfg.Timeout) // Проверено в Validate
+	opts := []client.Option{
+		client.WithTimeout(timeout),
+		client.WithRetries(cfg.Retries, client.DefaultBackoff),
+	}
+	if cfg.User != "" {
+		var groups []string
+		if cfg.Groups != "" {
+			groups = strings.Split(cfg.Groups, ",")
+		}
+		opts = append(opts, client.WithUser(cfg.User, groups...))
+	}
+	if cfg.Token != "" {
+		opts = append(opts, client.WithToken(cfg.Token))
+	}
+	if cfg.TLS.Enabled {
+		tlsConfig, err := tlsconfig.NewClientConfig(tlsconfig.ClientFiles{
+			CAFile: cfg.TLS.CAFile, CertFile: cfg.TLS.CertFile, KeyFile: cfg.TLS.KeyFile, ServerName: cfg.TLS.ServerName,
+		}, logger.New("error"))
+		if err != nil {
+			return fmt.Errorf("failed to configure tls: %w", err)
+		}
+		opts = append(opts, client.WithTLS(tlsConfig))
+	}
+
+	c.config = cfg
+	c.client, err = client.NewGRPC(cfg.Address, opts...)
+	return err
+}
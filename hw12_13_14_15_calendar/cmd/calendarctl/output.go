@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+// Форматы вывода
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+func checkOutput(format string) error {
+	switch format {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, expected table, json or yaml", format)
+	}
+}
+
+// eventView - событие в JSON и YAML: длительности строками вида 1h30m0s, время в RFC 3339
+type eventView struct {
+	ID           string     `json:"id" yaml:"id"`
+	CalendarID   string     `json:"calendar_id,omitempty" yaml:"calendar_id,omitempty"`
+	Title        string     `json:"title" yaml:"title"`
+	Description  string     `json:"description,omitempty" yaml:"description,omitempty"`
+	UserID       string     `json:"user_id" yaml:"user_id"`
+	StartTime    time.Time  `json:"start_time" yaml:"start_time"`
+	Duration     string     `json:"duration" yaml:"duration"`
+	NotifyBefore string     `json:"notify_before" yaml:"notify_before"`
+	Category     string     `json:"category,omitempty" yaml:"category,omitempty"`
+	Color        string     `json:"color,omitempty" yaml:"color,omitempty"`
+	Tags         []string   `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Version      int64      `json:"version" yaml:"version"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" yaml:"deleted_at,omitempty"`
+}
+
+func newEventView(event client.Event) eventView {
+	return eventView{
+		ID:           event.ID,
+		CalendarID:   event.CalendarID,
+		Title:        event.Title,
+		Description:  event.Description,
+		UserID:       event.UserID,
+		StartTime:    event.StartTime,
+		Duration:     event.Duration.String(),
+		NotifyBefore: event.NotifyBefore.String(),
+		Category:     event.Category,
+		Color:        event.Color,
+		Tags:         event.Tags,
+		Version:      event.Version,
+		DeletedAt:    event.DeletedAt,
+	}
+}
+
+// printEvent выводит одно событие: в JSON и YAML - объектом, а не списком
+func printEvent(out io.Writer, format string, event client.Event) error {
+	if format == outputTable {
+		return printTable(out, []client.Event{event})
+	}
+	return encode(out, format, newEventView(event))
+}
+
+func printEvents(out io.Writer, format string, events []client.Event) error {
+	if format == outputTable {
+		return printTable(out, events)
+	}
+	views := make([]eventView, 0, len(events))
+	for _, event := range events {
+		views = append(views, newEventView(event))
+	}
+	return encode(out, format, views)
+}
+
+func encode(out io.Writer, format string, value any) error {
+	if format == outputYAML {
+		encoder := yaml.NewEncoder(out)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func printTable(out io.Writer, events []client.Event) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTART\tDURATION\tTITLE\tUSER\tTAGS\tVERSION")
+	for _, event := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			event.ID, event.StartTime.Local().Format("2006-01-02 15:04"), event.Duration,
+			event.Title, event.UserID, strings.Join(event.Tags, ","), event.Version)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	release   = "UNKNOWN"
+	buildDate = "UNKNOWN"
+	gitHash   = "UNKNOWN"
+)
+
+func newVersionCommand(out io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print build information",
+		Args:  cobra.NoArgs,
+		RunE: func(*cobra.Command, []string) error {
+			return json.NewEncoder(out).Encode(struct {
+				Release   string
+				BuildDate string
+				GitHash   string
+			}{
+				Release:   release,
+				BuildDate: buildDate,
+				GitHash:   gitHash,
+			})
+		},
+	}
+}
@@ -1,7 +1,4 @@
 #!/bin/sh
 
-# Заполняем шаблон конфигурации из переменных окружения
-envsubst < config.template.yml > config.yml
-
-# Запускаем приложение
-exec ./calendar -config ./config.yml
+# Переменные окружения подставляются в шаблон самим приложением
+exec ./calendar -config ./config.template.yml
@@ -1,7 +1,4 @@
 #!/bin/sh
 
-# Заполняем шаблон конфигурации из переменных окружения
-envsubst < scheduler_config.template.yaml > scheduler_config.yaml
-
-# Запускаем приложение
-exec ./calendar_scheduler -config ./scheduler_config.yaml
+# Переменные окружения подставляются в шаблон самим приложением
+exec ./calendar_scheduler -config ./scheduler_config.template.yaml
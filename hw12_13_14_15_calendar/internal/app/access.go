@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Проверка доступа опирается на пользователя из requestctx. Аутентификацию выполняет
+// шлюз перед сервисом, он же передаёт пользователя и группы в заголовках. Вызов без
+// пользователя отклоняется с storage.ErrUnauthenticated, если он не помечен как внутренний
+// через requestctx.WithSystem (планировщик, миграции, тесты); внутренние вызовы не проверяются.
+
+// principal возвращает того, кто выполняет запрос, или storage.ErrUnauthenticated
+func principal(ctx context.Context) (storage.Principal, error) {
+	actor := requestctx.Actor(ctx)
+	if actor == "" {
+		return storage.Principal{}, storage.ErrUnauthenticated
+	}
+	return storage.Principal{UserID: actor, Groups: requestctx.Groups(ctx)}, nil
+}
+
+// accessChecker - роли пользователя во всех видимых ему календарях, загруженные один раз на запрос
+type accessChecker struct {
+	trusted bool
+	user    string
+	roles   map[string]storage.Role
+}
+
+func (a *App) accessChecker(ctx context.Context) (*accessChecker, error) {
+	p, err := principal(ctx)
+	if err != nil {
+		if requestctx.System(ctx) {
+			return &accessChecker{trusted: true}, nil
+		}
+		return nil, err
+	}
+	calendars, err := a.storage.ListAccessibleCalendars(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	roles := make(map[string]storage.Role, len(calendars))
+	for _, calendar := range calendars {
+		roles[calendar.Calendar.ID] = calendar.Role
+	}
+	return &accessChecker{user: p.UserID, roles: roles}, nil
+}
+
+func (c *accessChecker) role(calendarID string) storage.Role {
+	if c.trusted {
+		return storage.RoleOwner
+	}
+	return c.roles[calendarID]
+}
+
+// require возвращает storage.ErrForbidden, если роли в календаре недостаточно
+func (c *accessChecker) require(calendarID string, required storage.Role) error {
+	if !c.role(calendarID).Allows(required) {
+		return storage.ErrForbidden
+	}
+	return nil
+}
+
+// calendarsWith возвращает отсортированные ID календарей, где роли пользователя достаточно;
+// для внутренних вызовов - nil, что в запросах к хранилищу означает "все календари"
+func (c *accessChecker) calendarsWith(required storage.Role) []string {
+	if c.trusted {
+		return nil
+	}
+	ids := []string{}
+	for calendarID, role := range c.roles {
+		if role.Allows(required) {
+			ids = append(ids, calendarID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// visible оставляет события, которые пользователь может видеть хотя бы как занятое время;
+// для роли free-busy у событий скрываются название, описание, напоминание и разметка
+func (c *accessChecker) visible(events []storage.Event) []storage.Event {
+	if c.trusted {
+		return events
+	}
+	result := make([]storage.Event, 0, len(events))
+	for _, event := range events {
+		role := c.role(event.CalendarID)
+		switch {
+		case role.Allows(storage.RoleRead):
+			result = append(result, event)
+		case role.Allows(storage.RoleFreeBusy):
+			result = append(result, freeBusy(event))
+		}
+	}
+	return result
+}
+
+func freeBusy(event storage.Event) storage.Event {
+	event.Title = ""
+	event.Description = ""
+	event.NotifyBefore = 0
+	event.Labels = storage.Labels{}
+	return event
+}
+
+// requireEventRole проверяет роль в календаре события, в том числе события из корзины
+func (a *App) requireEventRole(ctx context.Context, id string, required storage.Role) error {
+	checker, err := a.accessChecker(ctx)
+	if err != nil || checker.trusted {
+		return err
+	}
+	calendarID, err := a.eventCalendar(ctx, id)
+	if err != nil {
+		return err
+	}
+	return checker.require(calendarID, required)
+}
+
+// eventCalendar ищет календарь события среди активных событий и в корзине
+func (a *App) eventCalendar(ctx context.Context, id string) (string, error) {
+	event, err := a.storage.GetEventByID(ctx, id)
+	if err == nil {
+		return event.CalendarID, nil
+	}
+	if !errors.Is(err, storage.ErrEventNotFound) {
+		return "", err
+	}
+
+	event, err = a.storage.GetDeletedEvent(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return event.CalendarID, nil
+}
+
+// targetCalendar выбирает календарь для нового события и проверяет роль write в нём: календарь
+// указан явно или это личный календарь владельца события. Свой личный календарь пользователь
+// может выбрать всегда; он создаётся при первом обращении, но только после проверки доступа.
+func (a *App) targetCalendar(ctx context.Context, checker *accessChecker, calendarID, userID string) (string, error) {
+	if calendarID != "" && calendarID != storage.PersonalCalendarID(userID) {
+		if _, err := a.storage.GetCalendar(ctx, calendarID); err != nil {
+			return "", err
+		}
+		return calendarID, checker.require(calendarID, storage.RoleWrite)
+	}
+
+	calendarID = storage.PersonalCalendarID(userID)
+	if !checker.trusted && checker.user != userID {
+		if err := checker.require(calendarID, storage.RoleWrite); err != nil {
+			return "", err
+		}
+	}
+	return calendarID, a.ensurePersonalCalendar(ctx, userID)
+}
+
+// ensurePersonalCalendar создаёт личный календарь пользователя, если его ещё нет
+func (a *App) ensurePersonalCalendar(ctx context.Context, userID string) error {
+	err := a.storage.CreateCalendar(ctx, storage.Calendar{
+		ID:      storage.PersonalCalendarID(userID),
+		Name:    "Personal",
+		Kind:    storage.CalendarPersonal,
+		OwnerID: userID,
+	})
+	if err != nil && !errors.Is(err, storage.ErrCalendarExists) {
+		return err
+	}
+	return nil
+}
@@ -4,14 +4,27 @@ import (
 	"context"
 	"time"
 
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/blob"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
 
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
 )
 
+// Ограничения выдачи поиска: сколько результатов по умолчанию и сколько максимум
+const (
+	DefaultSearchLimit = 50
+	MaxSearchLimit     = 200
+)
+
+// MaxBatchSize - сколько операций можно передать в одном пакетном вызове
+const MaxBatchSize = 1000
+
 type App struct {
 	logger  Logger
 	storage Storage
+
+	blobs             blob.Store // Содержимое файлов-вложений; nil - файлы прикреплять нельзя
+	maxAttachmentSize int64
 }
 
 type Logger interface {
@@ -22,29 +35,96 @@ type Logger interface {
 }
 
 type Storage interface {
+	// Изменения событий хранилище записывает в журнал изменений в той же транзакции,
+	// пользователя и ID запроса для журнала берёт из requestctx
 	AddEvent(ctx context.Context, e storage.Event) error
 	UpdateEvent(ctx context.Context, e storage.Event) error
-	DeleteEvent(ctx context.Context, id string) error
+	DeleteEvent(ctx context.Context, id string, expectedVersion int64) error
+	ListDeletedEvents(ctx context.Context) ([]storage.Event, error)
+	GetDeletedEvent(ctx context.Context, id string) (storage.Event, error)
+	RestoreEvent(ctx context.Context, id string) error
 	GetEventByID(ctx context.Context, id string) (storage.Event, error)
-	ListEventsForDay(ctx context.Context, date time.Time) ([]storage.Event, error)
-	ListEventsForWeek(ctx context.Context, date time.Time) ([]storage.Event, error)
-	ListEventsForMonth(ctx context.Context, date time.Time) ([]storage.Event, error)
+	ListEventsForDay(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error)
+	ListEventsForWeek(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error)
+	ListEventsForMonth(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error)
+	SearchEvents(ctx context.Context, query storage.SearchQuery) ([]storage.SearchResult, error)
+	ApplyBatch(ctx context.Context, ops []storage.BatchOperation, atomic bool) ([]storage.BatchResult, error)
+	ListAuditRecords(ctx context.Context, eventID string) ([]storage.AuditRecord, error)
+	CreateCalendar(ctx context.Context, calendar storage.Calendar) error
+	GetCalendar(ctx context.Context, id string) (storage.Calendar, error)
+	ListAccessibleCalendars(ctx context.Context, principal storage.Principal) ([]storage.CalendarAccess, error)
+	SetACLEntry(ctx context.Context, entry storage.ACLEntry) error
+	DeleteACLEntry(ctx context.Context, calendarID string, granteeType storage.GranteeType, granteeID string) error
+	ListACL(ctx context.Context, calendarID string) ([]storage.ACLEntry, error)
+	ListCalendarEvents(ctx context.Context, calendarID string, from, to time.Time) ([]storage.Event, error)
+	ListTagStats(ctx context.Context, query storage.TagStatsQuery) ([]storage.TagStat, error)
+	AddAttachment(ctx context.Context, attachment storage.Attachment) error
+	GetAttachment(ctx context.Context, id string) (storage.Attachment, error)
+	ListAttachments(ctx context.Context, eventID string) ([]storage.Attachment, error)
+	DeleteAttachment(ctx context.Context, id string) error
+	// ReserveIdempotencyKey сохраняет запись, если под ключом нет действующей; иначе возвращает
+	// действующую запись и false. Истёкшая запись заменяется новой.
+	ReserveIdempotencyKey(ctx context.Context, record storage.IdempotencyRecord) (storage.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, userID, key string, response storage.Event) error
+	ReleaseIdempotencyKey(ctx context.Context, userID, key string) error
 	Close() error
 }
 
-func New(logger Logger, storage Storage) *App {
-	return &App{
-		logger:  logger,
-		storage: storage,
+// Option настраивает приложение при создании
+type Option func(*App)
+
+// WithAttachments подключает хранилище содержимого вложений. Без него к событиям можно
+// прикреплять только ссылки. maxSize - предельный размер файла; 0 - DefaultMaxAttachmentSize.
+func WithAttachments(store blob.Store, maxSize int64) Option {
+	return func(a *App) {
+		a.blobs = store
+		if maxSize > 0 {
+			a.maxAttachmentSize = maxSize
+		}
+	}
+}
+
+func New(logger Logger, storage Storage, opts ...Option) *App {
+	a := &App{
+		logger:            logger,
+		storage:           storage,
+		maxAttachmentSize: DefaultMaxAttachmentSize,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
+// CreateEvent создаёт событие в личном календаре пользователя userID
 func (a *App) CreateEvent(
 	ctx context.Context,
 	id, title, description, userID string,
 	startTime time.Time,
 	duration, notifyBefore calendar_types.CalendarDuration,
 ) error {
+	return a.CreateEventInCalendar(ctx, "", id, title, description, userID, startTime, duration, notifyBefore, storage.Labels{})
+}
+
+// CreateEventInCalendar создаёт событие в календаре calendarID; пустой calendarID означает
+// личный календарь пользователя userID. Нужна роль write в этом календаре.
+// Теги сохраняются в нижнем регистре, без повторов и по алфавиту.
+func (a *App) CreateEventInCalendar(
+	ctx context.Context,
+	calendarID, id, title, description, userID string,
+	startTime time.Time,
+	duration, notifyBefore calendar_types.CalendarDuration,
+	labels storage.Labels,
+) error {
+	checker, err := a.accessChecker(ctx)
+	if err != nil {
+		return err
+	}
+	calendarID, err = a.targetCalendar(ctx, checker, calendarID, userID)
+	if err != nil {
+		return err
+	}
+
 	event := storage.Event{
 		ID:           id,
 		Title:        title,
@@ -52,16 +132,23 @@ func (a *App) CreateEvent(
 		StartTime:    startTime,
 		Duration:     duration,
 		UserID:       userID,
+		CalendarID:   calendarID,
 		NotifyBefore: notifyBefore,
+		Labels:       storage.NormalizeLabels(labels),
 	}
 	return a.storage.AddEvent(ctx, event)
 }
 
+// UpdateEvent обновляет событие; разметка, как и остальные поля, заменяется целиком. Если
+// expectedVersion не 0, обновление пройдет только при совпадении с текущей версией,
+// иначе вернется storage.ErrVersionConflict.
 func (a *App) UpdateEvent(
 	ctx context.Context,
 	id, title, description, userID string,
 	startTime time.Time,
 	duration, notifyBefore calendar_types.CalendarDuration,
+	labels storage.Labels,
+	expectedVersion int64,
 ) error {
 	event := storage.Event{
 		ID:           id,
@@ -71,26 +158,205 @@ func (a *App) UpdateEvent(
 		Duration:     duration,
 		UserID:       userID,
 		NotifyBefore: notifyBefore,
+		Labels:       storage.NormalizeLabels(labels),
+		Version:      expectedVersion,
+	}
+	if err := a.requireEventRole(ctx, id, storage.RoleWrite); err != nil {
+		return err
 	}
 	return a.storage.UpdateEvent(ctx, event)
 }
 
-func (a *App) DeleteEvent(ctx context.Context, id string) error {
-	return a.storage.DeleteEvent(ctx, id)
+// DeleteEvent переносит событие в корзину с той же проверкой версии, что и UpdateEvent.
+// Окончательно события удаляет планировщик по истечении срока хранения.
+func (a *App) DeleteEvent(ctx context.Context, id string, expectedVersion int64) error {
+	if err := a.requireEventRole(ctx, id, storage.RoleWrite); err != nil {
+		return err
+	}
+	return a.storage.DeleteEvent(ctx, id, expectedVersion)
+}
+
+// ListDeletedEvents возвращает события из корзины в календарях, где у пользователя есть роль write
+func (a *App) ListDeletedEvents(ctx context.Context) ([]storage.Event, error) {
+	checker, err := a.accessChecker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	events, err := a.storage.ListDeletedEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]storage.Event, 0, len(events))
+	for _, event := range events {
+		if checker.require(event.CalendarID, storage.RoleWrite) == nil {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// RestoreEvent возвращает событие из корзины
+func (a *App) RestoreEvent(ctx context.Context, id string) error {
+	if err := a.requireEventRole(ctx, id, storage.RoleWrite); err != nil {
+		return err
+	}
+	return a.storage.RestoreEvent(ctx, id)
+}
+
+// BatchMutateEvents применяет пакет операций в одной транзакции хранилища.
+// В режиме atomic ("всё или ничего") при ошибке любой операции не применяется ни одна,
+// иначе применяются все успешные. Результаты идут в порядке операций.
+// Операции в календарях без роли write получают storage.ErrForbidden и в хранилище не попадают.
+func (a *App) BatchMutateEvents(ctx context.Context, ops []storage.BatchOperation, atomic bool) ([]storage.BatchResult, error) {
+	checker, err := a.accessChecker(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	denied := make([]error, len(ops))
+	allowed := make([]storage.BatchOperation, 0, len(ops))
+	for i := range ops {
+		ops[i].Event.Labels = storage.NormalizeLabels(ops[i].Event.Labels)
+		denied[i] = a.authorizeBatchOp(ctx, checker, &ops[i])
+		if denied[i] != nil {
+			continue
+		}
+		allowed = append(allowed, ops[i])
+	}
+
+	results := make([]storage.BatchResult, len(ops))
+	if atomic && len(allowed) < len(ops) {
+		for i, op := range ops {
+			results[i] = storage.BatchResult{ID: op.Event.ID, Err: denied[i]}
+		}
+		storage.AbortBatch(results)
+		return results, nil
+	}
+
+	applied, err := a.storage.ApplyBatch(ctx, allowed, atomic)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, 0; i < len(ops); i++ {
+		if denied[i] != nil {
+			results[i] = storage.BatchResult{ID: ops[i].Event.ID, Err: denied[i]}
+			continue
+		}
+		results[i] = applied[j]
+		j++
+	}
+	return results, nil
+}
+
+// authorizeBatchOp проверяет право на операцию пакета и выбирает календарь для новых событий
+func (a *App) authorizeBatchOp(ctx context.Context, checker *accessChecker, op *storage.BatchOperation) error {
+	if op.Type == storage.BatchCreate {
+		calendarID, err := a.targetCalendar(ctx, checker, op.Event.CalendarID, op.Event.UserID)
+		if err != nil {
+			return err
+		}
+		op.Event.CalendarID = calendarID
+		return nil
+	}
+	if checker.trusted {
+		return nil
+	}
+	calendarID, err := a.eventCalendar(ctx, op.Event.ID)
+	if err != nil {
+		return err
+	}
+	return checker.require(calendarID, storage.RoleWrite)
 }
 
+// SearchEvents ищет события по словам из названия и описания; самые релевантные идут первыми.
+// Поиск идёт только по календарям, где у пользователя есть роль read.
+func (a *App) SearchEvents(ctx context.Context, query storage.SearchQuery) ([]storage.SearchResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultSearchLimit
+	}
+	if query.Limit > MaxSearchLimit {
+		query.Limit = MaxSearchLimit
+	}
+	checker, err := a.accessChecker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !checker.trusted {
+		query.CalendarIDs = checker.calendarsWith(storage.RoleRead)
+		// Пустой список в хранилище означает "все календари"
+		if len(query.CalendarIDs) == 0 {
+			return []storage.SearchResult{}, nil
+		}
+	}
+	return a.storage.SearchEvents(ctx, query)
+}
+
+// GetEventHistory возвращает журнал изменений события, от старых записей к новым
+func (a *App) GetEventHistory(ctx context.Context, id string) ([]storage.AuditRecord, error) {
+	if err := a.requireEventRole(ctx, id, storage.RoleRead); err != nil {
+		return nil, err
+	}
+	return a.storage.ListAuditRecords(ctx, id)
+}
+
+// GetEventByID возвращает событие; при роли free-busy у события остаётся только время
 func (a *App) GetEventByID(ctx context.Context, id string) (storage.Event, error) {
-	return a.storage.GetEventByID(ctx, id)
+	event, err := a.storage.GetEventByID(ctx, id)
+	if err != nil {
+		return storage.Event{}, err
+	}
+	checker, err := a.accessChecker(ctx)
+	if err != nil {
+		return storage.Event{}, err
+	}
+	visible := checker.visible([]storage.Event{event})
+	if len(visible) == 0 {
+		return storage.Event{}, storage.ErrForbidden
+	}
+	return visible[0], nil
 }
 
-func (a *App) ListEventsForDay(ctx context.Context, date time.Time) ([]storage.Event, error) {
-	return a.storage.ListEventsForDay(ctx, date)
+// Списки событий собираются по всем календарям, которые видит пользователь.
+// Если заданы теги, остаются события со всеми тегами из tags.
+
+func (a *App) ListEventsForDay(ctx context.Context, date time.Time, tags []string) ([]storage.Event, error) {
+	return a.visibleEvents(ctx, a.storage.ListEventsForDay, date, tags)
+}
+
+func (a *App) ListEventsForWeek(ctx context.Context, date time.Time, tags []string) ([]storage.Event, error) {
+	return a.visibleEvents(ctx, a.storage.ListEventsForWeek, date, tags)
 }
 
-func (a *App) ListEventsForWeek(ctx context.Context, date time.Time) ([]storage.Event, error) {
-	return a.storage.ListEventsForWeek(ctx, date)
+func (a *App) ListEventsForMonth(ctx context.Context, date time.Time, tags []string) ([]storage.Event, error) {
+	return a.visibleEvents(ctx, a.storage.ListEventsForMonth, date, tags)
 }
 
-func (a *App) ListEventsForMonth(ctx context.Context, date time.Time) ([]storage.Event, error) {
-	return a.storage.ListEventsForMonth(ctx, date)
+func (a *App) visibleEvents(
+	ctx context.Context,
+	list func(context.Context, time.Time, storage.ListQuery) ([]storage.Event, error),
+	date time.Time,
+	tags []string,
+) ([]storage.Event, error) {
+	checker, err := a.accessChecker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := storage.ListQuery{Tags: storage.NormalizeTags(tags)}
+	if !checker.trusted {
+		// При роли free-busy разметка событий скрыта, поэтому по тегам ищем только там, где есть роль read
+		required := storage.RoleFreeBusy
+		if len(query.Tags) > 0 {
+			required = storage.RoleRead
+		}
+		query.CalendarIDs = checker.calendarsWith(required)
+		// Пустой список в хранилище означает "все календари"
+		if len(query.CalendarIDs) == 0 {
+			return []storage.Event{}, nil
+		}
+	}
+	events, err := list(ctx, date, query)
+	if err != nil {
+		return nil, err
+	}
+	return checker.visible(events), nil
 }
@@ -0,0 +1,233 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/google/uuid"
+)
+
+// DefaultMaxAttachmentSize - предельный размер файла-вложения, если он не задан в WithAttachments
+const DefaultMaxAttachmentSize = 10 << 20
+
+// MaxAttachmentNameLength - длиннее имя файла обрезается
+const MaxAttachmentNameLength = 255
+
+// sniffLength - сколько первых байт файла нужно http.DetectContentType
+const sniffLength = 512
+
+// MaxAttachmentSize - предельный размер файла-вложения в байтах
+func (a *App) MaxAttachmentSize() int64 {
+	return a.maxAttachmentSize
+}
+
+// AddFileAttachment прикрепляет к событию файл, читая его из body потоком. Тип содержимого
+// определяется по первым байтам файла, а не со слов клиента; если по ним ничего не понятно,
+// берётся тип по расширению имени. Файл больше MaxAttachmentSize не сохраняется:
+// возвращается storage.ErrAttachmentTooLarge. Нужна роль write в календаре события.
+func (a *App) AddFileAttachment(ctx context.Context, eventID, name string, body io.Reader) (storage.Attachment, error) {
+	if a.blobs == nil {
+		return storage.Attachment{}, storage.ErrAttachmentsDisabled
+	}
+	if err := a.requireActiveEventRole(ctx, eventID, storage.RoleWrite); err != nil {
+		return storage.Attachment{}, err
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(body, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return storage.Attachment{}, err
+	}
+	head = head[:n]
+
+	attachment := storage.Attachment{
+		ID:          uuid.NewString(),
+		EventID:     eventID,
+		Kind:        storage.AttachmentFile,
+		Name:        cleanFileName(name),
+		ContentType: detectContentType(head, name),
+		CreatedBy:   requestctx.Actor(ctx),
+		CreatedAt:   time.Now().UTC(),
+	}
+	content := &sizeLimiter{r: io.MultiReader(bytes.NewReader(head), body), limit: a.maxAttachmentSize}
+	if err := a.blobs.Put(ctx, attachment.ID, content); err != nil {
+		return storage.Attachment{}, err
+	}
+	attachment.Size = content.n
+
+	if err := a.storage.AddAttachment(ctx, attachment); err != nil {
+		a.deleteBlob(ctx, attachment.ID)
+		return storage.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+// AddLinkAttachment прикрепляет к событию ссылку; без подписи подписью служит сам адрес.
+// Адрес проверяет вызывающий код. Нужна роль write в календаре события.
+func (a *App) AddLinkAttachment(ctx context.Context, eventID, name, url string) (storage.Attachment, error) {
+	if err := a.requireActiveEventRole(ctx, eventID, storage.RoleWrite); err != nil {
+		return storage.Attachment{}, err
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		name = url
+	}
+	attachment := storage.Attachment{
+		ID:        uuid.NewString(),
+		EventID:   eventID,
+		Kind:      storage.AttachmentLink,
+		Name:      name,
+		URL:       url,
+		CreatedBy: requestctx.Actor(ctx),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := a.storage.AddAttachment(ctx, attachment); err != nil {
+		return storage.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+// ListAttachments возвращает вложения события в порядке добавления; нужна роль read
+func (a *App) ListAttachments(ctx context.Context, eventID string) ([]storage.Attachment, error) {
+	if err := a.requireEventRole(ctx, eventID, storage.RoleRead); err != nil {
+		return nil, err
+	}
+	return a.storage.ListAttachments(ctx, eventID)
+}
+
+// OpenAttachment возвращает вложение события и, для файла, его содержимое, которое
+// вызывающий код должен закрыть. Для ссылки содержимое - nil. Нужна роль read.
+func (a *App) OpenAttachment(ctx context.Context, eventID, id string) (storage.Attachment, io.ReadCloser, error) {
+	attachment, err := a.eventAttachment(ctx, eventID, id, storage.RoleRead)
+	if err != nil || attachment.Kind != storage.AttachmentFile {
+		return attachment, nil, err
+	}
+	if a.blobs == nil {
+		return storage.Attachment{}, nil, storage.ErrAttachmentsDisabled
+	}
+	content, err := a.blobs.Open(ctx, attachment.ID)
+	if err != nil {
+		return storage.Attachment{}, nil, fmt.Errorf("failed to open attachment %s: %w", id, err)
+	}
+	return attachment, content, nil
+}
+
+// DeleteAttachment открепляет вложение от события и удаляет содержимое файла; нужна роль write
+func (a *App) DeleteAttachment(ctx context.Context, eventID, id string) error {
+	attachment, err := a.eventAttachment(ctx, eventID, id, storage.RoleWrite)
+	if err != nil {
+		return err
+	}
+	if err := a.storage.DeleteAttachment(ctx, id); err != nil {
+		return err
+	}
+	if attachment.Kind == storage.AttachmentFile {
+		a.deleteBlob(ctx, id)
+	}
+	return nil
+}
+
+// eventAttachment ищет вложение именно этого события, чтобы доступ к одному событию
+// не открывал вложения других
+func (a *App) eventAttachment(ctx context.Context, eventID, id string, required storage.Role) (storage.Attachment, error) {
+	if err := a.requireEventRole(ctx, eventID, required); err != nil {
+		return storage.Attachment{}, err
+	}
+	attachment, err := a.storage.GetAttachment(ctx, id)
+	if err != nil {
+		return storage.Attachment{}, err
+	}
+	if attachment.EventID != eventID {
+		return storage.Attachment{}, storage.ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// requireActiveEventRole - как requireEventRole, но событие из корзины считается ненайденным
+func (a *App) requireActiveEventRole(ctx context.Context, id string, required storage.Role) error {
+	event, err := a.storage.GetEventByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	checker, err := a.accessChecker(ctx)
+	if err != nil {
+		return err
+	}
+	return checker.require(event.CalendarID, required)
+}
+
+// deleteBlob удаляет содержимое, на которое больше нет ссылок. Метаданные уже изменены,
+// поэтому ошибка только логируется: останется лишний файл, а не битое вложение.
+func (a *App) deleteBlob(ctx context.Context, key string) {
+	if err := a.blobs.Delete(ctx, key); err != nil {
+		a.logger.Error(fmt.Sprintf("failed to delete attachment content %s: %s", key, err))
+	}
+}
+
+// sizeLimiter считает прочитанные байты и обрывает чтение, как только их больше limit
+type sizeLimiter struct {
+	r     io.Reader
+	n     int64
+	limit int64
+}
+
+func (l *sizeLimiter) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.limit {
+		return n, storage.ErrAttachmentTooLarge
+	}
+	return n, err
+}
+
+// extensionContentTypes - типы, которые берутся по расширению, если содержимое не распознано.
+// Здесь только форматы, которые браузер не показывает как страницу: двоичный файл с именем
+// x.html должен остаться application/octet-stream, а не отдаваться как text/html.
+var extensionContentTypes = map[string]string{
+	".doc":  "application/msword",
+	".xls":  "application/vnd.ms-excel",
+	".ppt":  "application/vnd.ms-powerpoint",
+	".7z":   "application/x-7z-compressed",
+	".heic": "image/heic",
+}
+
+// detectContentType определяет тип по содержимому; расширение имени учитывается только для
+// нераспознанного содержимого и только из extensionContentTypes
+func detectContentType(head []byte, name string) string {
+	contentType := http.DetectContentType(head)
+	if contentType == "application/octet-stream" {
+		if byExtension, ok := extensionContentTypes[strings.ToLower(path.Ext(name))]; ok {
+			return byExtension
+		}
+	}
+	return contentType
+}
+
+// cleanFileName оставляет от имени файла последний элемент пути без управляющих символов
+func cleanFileName(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if runes := []rune(name); len(runes) > MaxAttachmentNameLength {
+		name = string(runes[:MaxAttachmentNameLength])
+	}
+	if name == "" || name == "." || name == ".." {
+		return "attachment"
+	}
+	return name
+}
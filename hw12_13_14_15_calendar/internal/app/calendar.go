@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/google/uuid"
+)
+
+// CreateCalendar создаёт календарь, владельцем которого становится текущий пользователь
+func (a *App) CreateCalendar(ctx context.Context, name string, kind storage.CalendarKind) (storage.Calendar, error) {
+	p, err := principal(ctx)
+	if err != nil {
+		return storage.Calendar{}, err
+	}
+	calendar := storage.Calendar{
+		ID:      uuid.New().String(),
+		Name:    name,
+		Kind:    kind,
+		OwnerID: p.UserID,
+	}
+	if err := a.storage.CreateCalendar(ctx, calendar); err != nil {
+		return storage.Calendar{}, err
+	}
+	return calendar, nil
+}
+
+// ListCalendars возвращает календари, к которым у текущего пользователя есть доступ, с его ролью в них.
+// Личный календарь есть у каждого пользователя, даже если событий в нём ещё нет.
+func (a *App) ListCalendars(ctx context.Context) ([]storage.CalendarAccess, error) {
+	p, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.ensurePersonalCalendar(ctx, p.UserID); err != nil {
+		return nil, err
+	}
+	return a.storage.ListAccessibleCalendars(ctx, p)
+}
+
+// ShareCalendar выдаёт или меняет доступ к календарю; это может только владелец
+func (a *App) ShareCalendar(ctx context.Context, entry storage.ACLEntry) error {
+	if err := a.requireCalendarOwner(ctx, entry.CalendarID); err != nil {
+		return err
+	}
+	return a.storage.SetACLEntry(ctx, entry)
+}
+
+// RevokeCalendarAccess отзывает доступ к календарю; это может только владелец
+func (a *App) RevokeCalendarAccess(ctx context.Context, calendarID string, granteeType storage.GranteeType, granteeID string) error {
+	if err := a.requireCalendarOwner(ctx, calendarID); err != nil {
+		return err
+	}
+	return a.storage.DeleteACLEntry(ctx, calendarID, granteeType, granteeID)
+}
+
+// ListCalendarAccess возвращает выданные доступы к календарю; их видит только владелец
+func (a *App) ListCalendarAccess(ctx context.Context, calendarID string) ([]storage.ACLEntry, error) {
+	if err := a.requireCalendarOwner(ctx, calendarID); err != nil {
+		return nil, err
+	}
+	return a.storage.ListACL(ctx, calendarID)
+}
+
+func (a *App) requireCalendarOwner(ctx context.Context, calendarID string) error {
+	if _, err := a.storage.GetCalendar(ctx, calendarID); err != nil {
+		return err
+	}
+	checker, err := a.accessChecker(ctx)
+	if err != nil {
+		return err
+	}
+	return checker.require(calendarID, storage.RoleOwner)
+}
+
+// GetCalendar возвращает календарь с ролью текущего пользователя в нём
+func (a *App) GetCalendar(ctx context.Context, id string) (storage.CalendarAccess, error) {
+	calendar, err := a.storage.GetCalendar(ctx, id)
+	if err != nil {
+		return storage.CalendarAccess{}, err
+	}
+	checker, err := a.accessChecker(ctx)
+	if err != nil {
+		return storage.CalendarAccess{}, err
+	}
+	if err := checker.require(id, storage.RoleFreeBusy); err != nil {
+		return storage.CalendarAccess{}, err
+	}
+	return storage.CalendarAccess{Calendar: calendar, Role: checker.role(id)}, nil
+}
+
+// ListCalendarEvents возвращает события календаря, пересекающиеся с [from, to);
+// нулевая граница не ограничивает выборку. При роли free-busy у событий остаётся только время.
+func (a *App) ListCalendarEvents(ctx context.Context, calendarID string, from, to time.Time) ([]storage.Event, error) {
+	if _, err := a.GetCalendar(ctx, calendarID); err != nil {
+		return nil, err
+	}
+	events, err := a.storage.ListCalendarEvents(ctx, calendarID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	checker, err := a.accessChecker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return checker.visible(events), nil
+}
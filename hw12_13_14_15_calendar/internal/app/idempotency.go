@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// IdempotencyTTL - сколько хранится ответ на запрос с ключом идемпотентности.
+// Клиенты повторяют запросы в течение минут, сутки оставлены с запасом на офлайн-очереди мобильных клиентов.
+const IdempotencyTTL = 24 * time.Hour
+
+// CreateEventOnce создаёт событие как CreateEventInCalendar, но не больше одного раза на ключ key.
+// Повтор с тем же ключом и тем же содержимым возвращает событие, созданное первым запросом, а id
+// повтора не используется. С тем же ключом и другим содержимым - storage.ErrIdempotencyKeyReused.
+// Пустой key - обычное создание.
+//
+// ID события сохраняется при резервировании ключа. Если первый запрос не сохранил ответ (ещё
+// выполняется, упал после создания события или процесс завершился раньше), повтор создаёт
+// событие с этим ID сам; событие, которое уже есть, просто возвращается.
+func (a *App) CreateEventOnce(
+	ctx context.Context,
+	key, calendarID, id, title, description, userID string,
+	startTime time.Time,
+	duration, notifyBefore calendar_types.CalendarDuration,
+	labels storage.Labels,
+) (storage.Event, error) {
+	if key == "" {
+		if err := a.CreateEventInCalendar(ctx, calendarID, id, title, description, userID, startTime, duration, notifyBefore, labels); err != nil {
+			return storage.Event{}, err
+		}
+		return a.GetEventByID(ctx, id)
+	}
+
+	// Ключ принадлежит тому, кто выполняет запрос; внутренние вызовы - владельцу события
+	owner := requestctx.Actor(ctx)
+	if owner == "" {
+		owner = userID
+	}
+	fingerprint, err := requestFingerprint(calendarID, title, description, userID, startTime, duration, notifyBefore, labels)
+	if err != nil {
+		return storage.Event{}, err
+	}
+
+	record, reserved, err := a.storage.ReserveIdempotencyKey(ctx, storage.IdempotencyRecord{
+		UserID:      owner,
+		Key:         key,
+		Fingerprint: fingerprint,
+		EventID:     id,
+		ExpiresAt:   time.Now().Add(IdempotencyTTL),
+	})
+	if err != nil {
+		return storage.Event{}, err
+	}
+	if !reserved {
+		if record.Fingerprint != fingerprint {
+			return storage.Event{}, storage.ErrIdempotencyKeyReused
+		}
+		if record.Response != nil {
+			a.logger.Info(fmt.Sprintf("replayed create of event %s for idempotency key %s", record.EventID, key))
+			return *record.Response, nil
+		}
+		id = record.EventID
+	}
+
+	err = a.CreateEventInCalendar(ctx, calendarID, id, title, description, userID, startTime, duration, notifyBefore, labels)
+	switch {
+	case err == nil:
+	case !reserved && errors.Is(err, storage.ErrEventExists):
+		// Событие создал первый запрос, но ответ не сохранил
+		a.logger.Info(fmt.Sprintf("recovered create of event %s for idempotency key %s", id, key))
+	case reserved:
+		// Запрос не выполнился, поэтому повтор с тем же ключом должен выполниться заново
+		if releaseErr := a.storage.ReleaseIdempotencyKey(ctx, owner, key); releaseErr != nil {
+			a.logger.Error(fmt.Sprintf("failed to release idempotency key %s: %s", key, releaseErr))
+		}
+		return storage.Event{}, err
+	default:
+		return storage.Event{}, err
+	}
+
+	event, err := a.GetEventByID(ctx, id)
+	if err != nil {
+		return storage.Event{}, err
+	}
+	// Если ответ не сохранится, следующий повтор найдёт событие по ID из резерва
+	if err := a.storage.CompleteIdempotencyKey(ctx, owner, key, event); err != nil {
+		a.logger.Error(fmt.Sprintf("failed to save response for idempotency key %s: %s", key, err))
+	}
+	return event, nil
+}
+
+// requestFingerprint - хэш содержимого запроса на создание. Разметка добавляется, только если
+// она задана: так отпечатки запросов без неё совпадают с сохранёнными до появления разметки.
+func requestFingerprint(
+	calendarID, title, description, userID string,
+	startTime time.Time,
+	duration, notifyBefore calendar_types.CalendarDuration,
+	labels storage.Labels,
+) (string, error) {
+	fields := []any{
+		calendarID, title, description, userID, startTime.UTC(), time.Duration(duration), time.Duration(notifyBefore),
+	}
+	if labels = storage.NormalizeLabels(labels); labels.Category != "" || labels.Color != "" || len(labels.Tags) > 0 {
+		fields = append(fields, labels)
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Ограничения статистики тегов: сколько тегов по умолчанию и сколько максимум
+const (
+	DefaultTagStatsLimit = 50
+	MaxTagStatsLimit     = 500
+)
+
+// TagStats считает, сколькими событиями с началом в [from, to) отмечен каждый тег; самые частые
+// теги идут первыми. Учитываются календари, где у пользователя есть роль read: при роли
+// free-busy разметка событий скрыта. Нулевые границы не ограничивают выборку.
+func (a *App) TagStats(ctx context.Context, from, to time.Time, limit int) ([]storage.TagStat, error) {
+	if limit <= 0 {
+		limit = DefaultTagStatsLimit
+	}
+	if limit > MaxTagStatsLimit {
+		limit = MaxTagStatsLimit
+	}
+	query := storage.TagStatsQuery{From: from, To: to, Limit: limit}
+
+	checker, err := a.accessChecker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !checker.trusted {
+		query.CalendarIDs = checker.calendarsWith(storage.RoleRead)
+		// Пустой список в хранилище означает "все календари"
+		if len(query.CalendarIDs) == 0 {
+			return []storage.TagStat{}, nil
+		}
+	}
+	return a.storage.ListTagStats(ctx, query)
+}
@@ -0,0 +1,45 @@
+// Package blob хранит содержимое вложений событий. Метаданные вложений лежат в хранилище
+// событий, здесь - только байты под ключами, которые выбирает вызывающий код.
+// Реализации: FSStore - каталог на диске, S3Store - бакет S3-совместимого хранилища.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFound - под ключом ничего нет
+var ErrNotFound = errors.New("blob not found")
+
+// Store - хранилище содержимого вложений
+type Store interface {
+	// Put сохраняет содержимое r под ключом key. Если чтение r оборвалось ошибкой,
+	// под ключом ничего не остаётся и возвращается ошибка чтения.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open открывает содержимое для потокового чтения; ErrNotFound, если ключа нет
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет содержимое; отсутствие ключа ошибкой не считается
+	Delete(ctx context.Context, key string) error
+}
+
+// Значения attachments.store в конфигурации
+const (
+	TypeFS     = "fs"     // Каталог на диске
+	TypeMemory = "memory" // S3-совместимое хранилище в памяти; файлы пропадают при перезапуске
+)
+
+var Types = []string{TypeFS, TypeMemory}
+
+// Open создаёт хранилище по типу из конфигурации; dir нужен только для fs
+func Open(storeType, dir string) (Store, error) {
+	switch storeType {
+	case TypeFS:
+		return NewFSStore(dir)
+	case TypeMemory:
+		return NewS3Store(NewMemoryS3(), "attachments", ""), nil
+	default:
+		return nil, fmt.Errorf("unknown attachment store type: %s", storeType)
+	}
+}
@@ -0,0 +1,92 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingReader отдаёт часть данных и обрывается ошибкой, как прерванная загрузка
+type failingReader struct {
+	data io.Reader
+}
+
+var errBroken = errors.New("connection reset")
+
+func (r failingReader) Read(p []byte) (int, error) {
+	n, err := r.data.Read(p)
+	if err == io.EOF {
+		return n, errBroken
+	}
+	return n, err
+}
+
+// testStore проверяет поведение, общее для всех реализаций Store
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "a/1", strings.NewReader("agenda")))
+	body, err := store.Open(ctx, "a/1")
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+	assert.Equal(t, "agenda", string(data))
+
+	// Повторный Put заменяет содержимое
+	require.NoError(t, store.Put(ctx, "a/1", strings.NewReader("v2")))
+	body, err = store.Open(ctx, "a/1")
+	require.NoError(t, err)
+	data, _ = io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "v2", string(data))
+
+	// Оборванная загрузка ничего не оставляет
+	err = store.Put(ctx, "a/2", failingReader{data: strings.NewReader("partial")})
+	assert.ErrorIs(t, err, errBroken)
+	_, err = store.Open(ctx, "a/2")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Delete(ctx, "a/1"))
+	_, err = store.Open(ctx, "a/1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, "a/1"))
+}
+
+func TestFSStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFSStore(dir)
+	require.NoError(t, err)
+	testStore(t, store)
+
+	// Временные файлы оборванных загрузок удаляются
+	entries, err := os.ReadDir(dir + "/a")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	for _, key := range []string{"", "../escape", "/etc/passwd", "a/../../b"} {
+		assert.Error(t, store.Put(context.Background(), key, strings.NewReader("x")), key)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	s3 := NewMemoryS3()
+	testStore(t, NewS3Store(s3, "calendar", "attachments"))
+	assert.Zero(t, s3.Len())
+
+	// Префикс отделяет объекты хранилищ, делящих один бакет
+	ctx := context.Background()
+	first := NewS3Store(s3, "calendar", "first")
+	second := NewS3Store(s3, "calendar", "second/")
+	require.NoError(t, first.Put(ctx, "key", strings.NewReader("x")))
+	_, err := second.Open(ctx, "key")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s3.GetObject(ctx, "calendar", "first/key")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,91 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// S3API - часть API S3-совместимого хранилища, которой пользуется S3Store. Клиенты AWS SDK
+// или MinIO подключаются небольшим адаптером; MemoryS3 - реализация в памяти.
+type S3API interface {
+	// PutObject загружает объект; при ошибке чтения body объект не создаётся
+	PutObject(ctx context.Context, bucket, key string, body io.Reader) error
+	// GetObject возвращает ErrNotFound, если объекта нет
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	// DeleteObject, как и в S3, не считает отсутствие объекта ошибкой
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// S3Store хранит содержимое объектами в бакете; prefix отделяет вложения от других данных бакета
+type S3Store struct {
+	api    S3API
+	bucket string
+	prefix string
+}
+
+func NewS3Store(api S3API, bucket, prefix string) *S3Store {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &S3Store{api: api, bucket: bucket, prefix: prefix}
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader) error {
+	return s.api.PutObject(ctx, s.bucket, s.prefix+key, r)
+}
+
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.api.GetObject(ctx, s.bucket, s.prefix+key)
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	return s.api.DeleteObject(ctx, s.bucket, s.prefix+key)
+}
+
+// MemoryS3 - S3-совместимое хранилище в памяти для тестов и запуска без внешних сервисов
+type MemoryS3 struct {
+	mu      sync.RWMutex
+	objects map[string][]byte // бакет/ключ -> содержимое
+}
+
+func NewMemoryS3() *MemoryS3 {
+	return &MemoryS3{objects: map[string][]byte{}}
+}
+
+func (m *MemoryS3) PutObject(ctx context.Context, bucket, key string, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[bucket+"/"+key] = data
+	return nil
+}
+
+func (m *MemoryS3) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.objects[bucket+"/"+key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *MemoryS3) DeleteObject(ctx context.Context, bucket, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, bucket+"/"+key)
+	return nil
+}
+
+// Len - сколько объектов во всех бакетах
+func (m *MemoryS3) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.objects)
+}
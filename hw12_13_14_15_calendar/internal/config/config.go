@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	yml "gopkg.in/yaml.v3"
+)
+
+// Validator реализуется конфигурациями, которые умеют проверять себя после загрузки.
+type Validator interface {
+	Validate() error
+}
+
+// Load читает YAML-файл, подставляет в него переменные окружения (${VAR} и ${VAR:-default}),
+// применяет переопределения из окружения по тегу `env` и валидирует результат.
+func Load(path string, cfg any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	expanded, err := Expand(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to expand config file: %w", err)
+	}
+
+	if err := yml.Unmarshal([]byte(expanded), cfg); err != nil {
+		return fmt.Errorf("failed to decode config file: %w", err)
+	}
+
+	if err := ApplyEnv(cfg); err != nil {
+		return fmt.Errorf("failed to apply env overrides: %w", err)
+	}
+
+	if validator, ok := cfg.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Expand подставляет значения переменных окружения вида ${VAR} и ${VAR:-default}.
+// Для незаданной переменной без значения по умолчанию подставляется пустая строка,
+// как это делает shell. Последовательность $$ превращается в одиночный $.
+func Expand(s string) (string, error) {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference at offset %d", i)
+			}
+			value, err := lookup(s[i+2 : i+2+end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i += end + 2
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// lookup возвращает значение выражения внутри ${...}.
+func lookup(expr string) (string, error) {
+	name, def, hasDefault := strings.Cut(expr, ":-")
+	if name == "" {
+		return "", fmt.Errorf("empty variable name in ${%s}", expr)
+	}
+	if value, ok := os.LookupEnv(name); ok && (value != "" || !hasDefault) {
+		return value, nil
+	}
+	return def, nil
+}
+
+// ApplyEnv переопределяет поля структуры значениями переменных окружения,
+// имена которых заданы тегом `env`. Вложенные структуры обходятся рекурсивно,
+// поэтому переопределить можно и поле, которого нет в файле конфигурации.
+func ApplyEnv(cfg any) error {
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config must be a pointer to struct, got %T", cfg)
+	}
+	return applyEnv(value.Elem())
+}
+
+func applyEnv(value reflect.Value) error {
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := value.Field(i)
+
+		if name, ok := field.Tag.Lookup("env"); ok {
+			if raw, set := os.LookupEnv(name); set {
+				if err := setFromString(fieldValue, raw); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Struct {
+			if err := applyEnv(fieldValue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setFromString(value reflect.Value, raw string) error {
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(number)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(number)
+	case reflect.Float32, reflect.Float64:
+		number, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(number)
+	case reflect.Bool:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(flag)
+	default:
+		return fmt.Errorf("unsupported field type %s", value.Type())
+	}
+	return nil
+}
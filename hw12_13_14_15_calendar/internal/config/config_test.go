@@ -0,0 +1,227 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("CFG_SET", "value")
+	t.Setenv("CFG_EMPTY", "")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain text", "level: debug", "level: debug"},
+		{"set variable", "${CFG_SET}", "value"},
+		{"unset variable", "${CFG_UNSET}", ""},
+		{"default for unset", "${CFG_UNSET:-fallback}", "fallback"},
+		{"default for empty", "${CFG_EMPTY:-fallback}", "fallback"},
+		{"default ignored when set", "${CFG_SET:-fallback}", "value"},
+		{"default with colon", "${CFG_UNSET:-:6523}", ":6523"},
+		{"several in one line", "${CFG_SET}:${CFG_UNSET:-5672}", "value:5672"},
+		{"escaped dollar", "pa$$word", "pa$word"},
+		{"lonely dollar", "cost $5", "cost $5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Expand(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	_, err := Expand("${UNTERMINATED")
+	assert.Error(t, err)
+
+	_, err = Expand("${:-default}")
+	assert.Error(t, err)
+}
+
+type testConfig struct {
+	Logger struct {
+		Level string `env:"CFG_LOG_LEVEL"`
+	}
+	Server struct {
+		Port    int           `env:"CFG_PORT"`
+		Debug   bool          `env:"CFG_DEBUG"`
+		Timeout time.Duration `env:"CFG_TIMEOUT"`
+	}
+}
+
+func (cfg *testConfig) Validate() error {
+	var check Checker
+	check.OneOf("logger.level", cfg.Logger.Level, "debug", "info")
+	check.Range("server.port", cfg.Server.Port, 1, 65535)
+	return check.Err()
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("CFG_TEST_LEVEL", "info")
+
+	path := writeConfig(t, `
+logger:
+  level: ${CFG_TEST_LEVEL:-debug}
+server:
+  port: ${CFG_TEST_PORT:-8080}
+`)
+
+	var cfg testConfig
+	require.NoError(t, Load(path, &cfg))
+	assert.Equal(t, "info", cfg.Logger.Level)
+	assert.Equal(t, 8080, cfg.Server.Port)
+}
+
+func TestLoadEnvOverrides(t *testing.T) {
+	t.Setenv("CFG_PORT", "9090")
+	t.Setenv("CFG_DEBUG", "true")
+	t.Setenv("CFG_TIMEOUT", "5s")
+
+	path := writeConfig(t, `
+logger:
+  level: debug
+server:
+  port: 8080
+`)
+
+	var cfg testConfig
+	require.NoError(t, Load(path, &cfg))
+	assert.Equal(t, 9090, cfg.Server.Port)
+	assert.True(t, cfg.Server.Debug)
+	assert.Equal(t, 5*time.Second, cfg.Server.Timeout)
+}
+
+func TestLoadInvalidEnvOverride(t *testing.T) {
+	t.Setenv("CFG_PORT", "not-a-number")
+
+	path := writeConfig(t, "logger:\n  level: debug\n")
+
+	var cfg testConfig
+	err := Load(path, &cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CFG_PORT")
+}
+
+func TestLoadValidationAggregatesErrors(t *testing.T) {
+	path := writeConfig(t, `
+logger:
+  level: verbose
+server:
+  port: 70000
+`)
+
+	var cfg testConfig
+	err := Load(path, &cfg)
+	require.Error(t, err)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Errors, 2)
+	assert.Equal(t, "logger.level", validationErr.Errors[0].Field)
+	assert.Equal(t, "server.port", validationErr.Errors[1].Field)
+	assert.Contains(t, err.Error(), "logger.level")
+	assert.Contains(t, err.Error(), "server.port")
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	var cfg testConfig
+	err := Load(filepath.Join(t.TempDir(), "missing.yaml"), &cfg)
+	assert.Error(t, err)
+}
+
+func TestCheckerDuration(t *testing.T) {
+	var check Checker
+	check.Duration("ok", "10s")
+	check.Duration("bad", "ten seconds")
+	check.Duration("negative", "-1m")
+
+	err := check.Err()
+	require.Error(t, err)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Errors, 2)
+	assert.Equal(t, "bad", validationErr.Errors[0].Field)
+	assert.Equal(t, "negative", validationErr.Errors[1].Field)
+}
+
+type reloadConfig struct {
+	Logger struct {
+		Level string
+	} `reload:"hot"`
+	Queue struct {
+		Name string `yaml:"queue-name"`
+	} `yaml:"event-queue" reload:"hot"`
+	Server struct {
+		Port    int
+		Timeout time.Duration `reload:"hot"`
+	}
+}
+
+func TestDiff(t *testing.T) {
+	var old reloadConfig
+	old.Logger.Level = "info"
+	old.Queue.Name = "events"
+	old.Server.Port = 8080
+	old.Server.Timeout = time.Second
+
+	updated := old
+	updated.Logger.Level = "debug"
+	updated.Queue.Name = "events-v2"
+	updated.Server.Port = 9090
+	updated.Server.Timeout = 2 * time.Second
+
+	changes := Diff(&old, &updated)
+	assert.Equal(t, []Change{
+		{Field: "logger.level", Hot: true},
+		{Field: "event-queue.queue-name", Hot: true},
+		{Field: "server.port", Hot: false},
+		{Field: "server.timeout", Hot: true},
+	}, changes)
+
+	hot, restart := Split(changes)
+	assert.Equal(t, []string{"logger.level", "event-queue.queue-name", "server.timeout"}, hot)
+	assert.Equal(t, []string{"server.port"}, restart)
+
+	assert.Empty(t, Diff(&old, &old))
+}
+
+func TestDiffHotSection(t *testing.T) {
+	type section struct {
+		Enabled bool
+		Rules   map[string]int
+	}
+	var old struct {
+		Server struct {
+			Port      int
+			RateLimit section `yaml:"rate-limit" reload:"hot"`
+		}
+	}
+	old.Server.RateLimit.Rules = map[string]int{"read": 10}
+
+	updated := old
+	updated.Server.RateLimit = section{Enabled: true, Rules: map[string]int{"read": 20}}
+
+	// Раздел с тегом hot применяется на лету целиком, хотя соседние поля требуют рестарта
+	hot, restart := Split(Diff(&old, &updated))
+	assert.Equal(t, []string{"server.rate-limit.enabled", "server.rate-limit.rules"}, hot)
+	assert.Empty(t, restart)
+}
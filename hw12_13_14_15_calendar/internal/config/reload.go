@@ -0,0 +1,93 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+)
+
+// Change описывает изменившееся поле конфигурации.
+// Hot означает, что поле помечено тегом `reload:"hot"` (или вложено в такое поле)
+// и новое значение можно применить без перезапуска процесса.
+type Change struct {
+	Field string
+	Hot   bool
+}
+
+// Diff сравнивает две конфигурации одного типа и возвращает изменившиеся поля.
+// Имена полей строятся по yaml-тегам, как в файле конфигурации.
+func Diff(old, new any) []Change {
+	oldValue := reflect.Indirect(reflect.ValueOf(old))
+	newValue := reflect.Indirect(reflect.ValueOf(new))
+	if oldValue.Type() != newValue.Type() {
+		panic("config.Diff: configs of different types")
+	}
+
+	var changes []Change
+	diff(oldValue, newValue, "", false, &changes)
+	return changes
+}
+
+func diff(old, new reflect.Value, prefix string, hot bool, changes *[]Change) {
+	typ := old.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		path := fieldName(field)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		fieldHot := hot || field.Tag.Get("reload") == "hot"
+
+		oldField, newField := old.Field(i), new.Field(i)
+		if oldField.Kind() == reflect.Struct && field.Type != durationType {
+			diff(oldField, newField, path, fieldHot, changes)
+			continue
+		}
+		if !reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			*changes = append(*changes, Change{Field: path, Hot: fieldHot})
+		}
+	}
+}
+
+// fieldName повторяет правило yaml.v3: имя из тега, иначе имя поля в нижнем регистре.
+func fieldName(field reflect.StructField) string {
+	if tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ","); tag != "" && tag != "-" {
+		return tag
+	}
+	return strings.ToLower(field.Name)
+}
+
+// OnReload вызывает reload на каждый полученный SIGHUP, пока не отменён контекст.
+func OnReload(ctx context.Context, reload func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			reload()
+		}
+	}
+}
+
+// Split делит изменения на применимые на лету и требующие перезапуска.
+func Split(changes []Change) (hot []string, restart []string) {
+	for _, change := range changes {
+		if change.Hot {
+			hot = append(hot, change.Field)
+		} else {
+			restart = append(restart, change.Field)
+		}
+	}
+	return hot, restart
+}
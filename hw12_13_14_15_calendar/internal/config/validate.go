@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FieldError описывает ошибку в одном поле конфигурации.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError собирает все ошибки конфигурации, чтобы показать их разом, а не по одной.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fieldErr.Error())
+	}
+	return "invalid config: " + strings.Join(messages, "; ")
+}
+
+// Checker накапливает ошибки валидации. Нулевое значение готово к использованию.
+type Checker struct {
+	errors []FieldError
+}
+
+// Fail добавляет произвольную ошибку для поля.
+func (c *Checker) Fail(field, format string, args ...any) {
+	c.errors = append(c.errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Required проверяет, что строковое поле заполнено.
+func (c *Checker) Required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		c.Fail(field, "is required")
+	}
+}
+
+// Range проверяет, что число лежит в отрезке [min, max].
+func (c *Checker) Range(field string, value, min, max int) {
+	if value < min || value > max {
+		c.Fail(field, "must be between %d and %d, got %d", min, max, value)
+	}
+}
+
+// OneOf проверяет, что значение входит в список допустимых.
+func (c *Checker) OneOf(field, value string, allowed ...string) {
+	for _, candidate := range allowed {
+		if strings.EqualFold(value, candidate) {
+			return
+		}
+	}
+	c.Fail(field, "must be one of [%s], got %q", strings.Join(allowed, ", "), value)
+}
+
+// Duration проверяет, что строка разбирается как положительная длительность.
+func (c *Checker) Duration(field, value string) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		c.Fail(field, "invalid duration %q", value)
+		return
+	}
+	if duration <= 0 {
+		c.Fail(field, "must be positive, got %s", value)
+	}
+}
+
+// Err возвращает накопленные ошибки как *ValidationError или nil, если ошибок нет.
+func (c *Checker) Err() error {
+	if len(c.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: c.errors}
+}
@@ -0,0 +1,79 @@
+// Package etag переводит версию события в HTTP ETag и обратно. Им пользуются REST-шлюз
+// и CalDAV: у события один счётчик версий, поэтому и ETag у них один.
+package etag
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Format строит сильный ETag из версии события
+func Format(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// Parse достает версию из значения ETag; слабые W/"N" тоже принимаются
+func Parse(value string) (int64, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, errors.New("etag must be a quoted version")
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, errors.New("etag must be a quoted positive version")
+	}
+	return version, nil
+}
+
+// IfMatch возвращает версии из заголовка If-Match - списка ETag через запятую (RFC 9110, 13.1.1).
+// Пустой заголовок или * означает "без проверки" и возвращает nil.
+func IfMatch(header string) ([]int64, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil
+	}
+	var versions []int64
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimSpace(candidate) == "*" {
+			return nil, nil
+		}
+		version, err := Parse(candidate)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// Expected выбирает из версий If-Match ту, с которой сравнивать событие версии current:
+// саму current, если клиент её перечислил, иначе любую другую, чтобы изменение отклонилось
+// как конфликт. Без версий проверка не нужна, и возвращается 0.
+func Expected(versions []int64, current int64) int64 {
+	if len(versions) == 0 {
+		return 0
+	}
+	for _, version := range versions {
+		if version == current {
+			return current
+		}
+	}
+	return versions[0]
+}
+
+// NoneMatch проверяет If-None-Match: true, если копия клиента с версией version актуальна
+func NoneMatch(header string, version int64) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimSpace(candidate) == "*" {
+			return true
+		}
+		if parsed, err := Parse(candidate); err == nil && parsed == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package etag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	version, err := Parse(` W/"12" `)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), version)
+
+	for _, value := range []string{`12`, `"abc"`, `"0"`, `""`} {
+		_, err := Parse(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		header   string
+		versions []int64
+		wantErr  bool
+	}{
+		{header: "", versions: nil},
+		{header: "*", versions: nil},
+		{header: `"3"`, versions: []int64{3}},
+		{header: `"1", W/"2" ,"3"`, versions: []int64{1, 2, 3}},
+		{header: `"1", *`, versions: nil},
+		{header: `"1", 2`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			versions, err := IfMatch(tt.header)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.versions, versions)
+		})
+	}
+}
+
+func TestExpected(t *testing.T) {
+	assert.Equal(t, int64(0), Expected(nil, 4))
+	assert.Equal(t, int64(4), Expected([]int64{1, 4}, 4))
+	// Ни одна версия не совпала - возвращается чужая, и изменение отклоняется как конфликт
+	assert.Equal(t, int64(1), Expected([]int64{1, 2}, 4))
+}
+
+func TestNoneMatch(t *testing.T) {
+	assert.False(t, NoneMatch("", 1))
+	assert.True(t, NoneMatch("*", 1))
+	assert.True(t, NoneMatch(`"2", "1"`, 1))
+	assert.False(t, NoneMatch(`"2"`, 1))
+}
@@ -0,0 +1,445 @@
+// Package ical переводит события календаря в формат iCalendar (RFC 5545) и обратно.
+// Поддерживается подмножество, которое нужно клиентам CalDAV: VEVENT с названием,
+// описанием, началом, концом или длительностью, напоминанием VALARM, категориями (теги
+// события) и цветом (COLOR из RFC 7986, только в виде #rrggbb).
+// Повторяющиеся события (RRULE) хранятся как одно, первое, вхождение.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // часовые пояса из TZID нужны и в минимальном образе без zoneinfo
+	"unicode/utf8"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// ProdID - идентификатор программы, создавшей файл
+const ProdID = "-//Faoxis//Calendar//RU"
+
+const (
+	dateTimeFormat    = "20060102T150405Z"
+	localTimeFormat   = "20060102T150405"
+	dateFormat        = "20060102"
+	maxLineOctets     = 75
+	defaultAllDayTime = 24 * time.Hour
+	maxTagLength      = 32
+)
+
+// ErrNoEvents - в данных нет ни одного VEVENT
+var ErrNoEvents = errors.New("ical: no VEVENT found")
+
+// Encode пишет события одним VCALENDAR
+func Encode(w io.Writer, events []storage.Event) error {
+	out := &writer{w: bufio.NewWriter(w)}
+	out.line("BEGIN:VCALENDAR")
+	out.line("VERSION:2.0")
+	out.line("PRODID:" + ProdID)
+	out.line("CALSCALE:GREGORIAN")
+	stamp := time.Now().UTC().Format(dateTimeFormat)
+	for _, event := range events {
+		out.line("BEGIN:VEVENT")
+		out.line("UID:" + escapeText(event.ID))
+		out.line("DTSTAMP:" + stamp)
+		out.line("DTSTART:" + event.StartTime.UTC().Format(dateTimeFormat))
+		out.line("DTEND:" + event.StartTime.Add(time.Duration(event.Duration)).UTC().Format(dateTimeFormat))
+		out.line("SUMMARY:" + escapeText(event.Title))
+		if event.Description != "" {
+			out.line("DESCRIPTION:" + escapeText(event.Description))
+		}
+		if len(event.Tags) > 0 {
+			categories := make([]string, 0, len(event.Tags))
+			for _, tag := range event.Tags {
+				categories = append(categories, escapeText(tag))
+			}
+			out.line("CATEGORIES:" + strings.Join(categories, ","))
+		}
+		if event.Color != "" {
+			out.line("COLOR:" + event.Color)
+		}
+		if event.Version > 0 {
+			out.line("SEQUENCE:" + strconv.FormatInt(event.Version-1, 10))
+		}
+		if event.NotifyBefore > 0 {
+			out.line("BEGIN:VALARM")
+			out.line("ACTION:DISPLAY")
+			out.line("DESCRIPTION:" + escapeText(event.Title))
+			out.line("TRIGGER:-" + formatDuration(time.Duration(event.NotifyBefore)))
+			out.line("END:VALARM")
+		}
+		out.line("END:VEVENT")
+	}
+	out.line("END:VCALENDAR")
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+// Decode читает все VEVENT из VCALENDAR. Версия и пользователь у событий не заполняются.
+func Decode(r io.Reader) ([]storage.Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events  []storage.Event
+		current *eventBuilder
+		stack   []string
+	)
+	for _, raw := range lines {
+		prop, err := parseLine(raw)
+		if err != nil {
+			return nil, err
+		}
+		switch prop.name {
+		case "BEGIN":
+			component := strings.ToUpper(prop.value)
+			stack = append(stack, component)
+			if component == "VEVENT" && len(stack) == 2 {
+				current = &eventBuilder{}
+			}
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(prop.value) {
+				return nil, fmt.Errorf("ical: unexpected END:%s", prop.value)
+			}
+			stack = stack[:len(stack)-1]
+			if current != nil && len(stack) == 1 {
+				event, err := current.build()
+				if err != nil {
+					return nil, err
+				}
+				events = append(events, event)
+				current = nil
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch stack[len(stack)-1] {
+		case "VEVENT":
+			err = current.eventProperty(prop)
+		case "VALARM":
+			err = current.alarmProperty(prop)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("ical: unterminated %s", stack[len(stack)-1])
+	}
+	if len(events) == 0 {
+		return nil, ErrNoEvents
+	}
+	return events, nil
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseLine разбирает строку вида NAME;PARAM=VALUE:значение
+func parseLine(line string) (property, error) {
+	colon := -1
+	inQuotes := false
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, fmt.Errorf("ical: malformed line %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := property{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+type eventBuilder struct {
+	event       storage.Event
+	end         time.Time
+	duration    time.Duration
+	hasDuration bool
+	allDay      bool
+}
+
+func (b *eventBuilder) eventProperty(prop property) error {
+	var err error
+	switch prop.name {
+	case "UID":
+		b.event.ID = unescapeText(prop.value)
+	case "SUMMARY":
+		b.event.Title = unescapeText(prop.value)
+	case "DESCRIPTION":
+		b.event.Description = unescapeText(prop.value)
+	case "CATEGORIES":
+		b.event.Tags = storage.NormalizeTags(append(b.event.Tags, parseCategories(prop.value)...))
+	case "COLOR":
+		if colorPattern.MatchString(prop.value) {
+			b.event.Color = strings.ToLower(prop.value)
+		}
+	case "DTSTART":
+		b.event.StartTime, b.allDay, err = parseTime(prop)
+	case "DTEND":
+		b.end, _, err = parseTime(prop)
+	case "DURATION":
+		b.duration, err = parseDuration(prop.value)
+		b.hasDuration = true
+	}
+	if err != nil {
+		return fmt.Errorf("ical: invalid %s: %w", prop.name, err)
+	}
+	return nil
+}
+
+// alarmProperty учитывает только напоминания относительно начала события
+func (b *eventBuilder) alarmProperty(prop property) error {
+	if prop.name != "TRIGGER" || prop.params["VALUE"] == "DATE-TIME" || prop.params["RELATED"] == "END" {
+		return nil
+	}
+	trigger, err := parseDuration(prop.value)
+	if err != nil {
+		return fmt.Errorf("ical: invalid TRIGGER: %w", err)
+	}
+	if trigger < 0 && calendar_types.CalendarDuration(-trigger) > b.event.NotifyBefore {
+		b.event.NotifyBefore = calendar_types.CalendarDuration(-trigger)
+	}
+	return nil
+}
+
+func (b *eventBuilder) build() (storage.Event, error) {
+	if b.event.StartTime.IsZero() {
+		return storage.Event{}, errors.New("ical: VEVENT without DTSTART")
+	}
+	switch {
+	case b.hasDuration:
+		b.event.Duration = calendar_types.CalendarDuration(b.duration)
+	case !b.end.IsZero():
+		b.event.Duration = calendar_types.CalendarDuration(b.end.Sub(b.event.StartTime))
+	case b.allDay:
+		b.event.Duration = calendar_types.CalendarDuration(defaultAllDayTime)
+	}
+	if b.event.Duration < 0 {
+		return storage.Event{}, errors.New("ical: VEVENT ends before it starts")
+	}
+	return b.event, nil
+}
+
+// parseTime читает DATE-TIME в UTC, с TZID или "плавающее" (считается UTC), а также DATE
+func parseTime(prop property) (time.Time, bool, error) {
+	value := prop.value
+	if prop.params["VALUE"] == "DATE" || len(value) == len(dateFormat) {
+		t, err := time.Parse(dateFormat, value)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeFormat, value)
+		return t, false, err
+	}
+	location := time.UTC
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			location = loaded
+		}
+	}
+	t, err := time.ParseInLocation(localTimeFormat, value, location)
+	return t.UTC(), false, err
+}
+
+// parseDuration читает длительность ISO 8601 из RFC 5545: [+-]P[nW][nD][T[nH][nM][nS]]
+func parseDuration(value string) (time.Duration, error) {
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(value, "-"):
+		sign, value = -1, value[1:]
+	case strings.HasPrefix(value, "+"):
+		value = value[1:]
+	}
+	if !strings.HasPrefix(value, "P") || len(value) < 3 {
+		return 0, fmt.Errorf("malformed duration %q", value)
+	}
+
+	var total time.Duration
+	inTime := false
+	number := ""
+	units := map[bool]map[byte]time.Duration{
+		false: {'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour},
+		true:  {'H': time.Hour, 'M': time.Minute, 'S': time.Second},
+	}
+	for i := 1; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == 'T':
+			inTime = true
+		case c >= '0' && c <= '9':
+			number += string(c)
+		default:
+			unit, ok := units[inTime][c]
+			if !ok || number == "" {
+				return 0, fmt.Errorf("malformed duration %q", value)
+			}
+			n, _ := strconv.Atoi(number)
+			total += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("malformed duration %q", value)
+	}
+	return sign * total, nil
+}
+
+// formatDuration пишет длительность в виде P[nD]T[nH][nM][nS]
+func formatDuration(d time.Duration) string {
+	var sb strings.Builder
+	sb.WriteString("P")
+	if days := d / (24 * time.Hour); days > 0 {
+		sb.WriteString(strconv.FormatInt(int64(days), 10) + "D")
+		d -= days * 24 * time.Hour
+	}
+	if d == 0 {
+		if sb.Len() == 1 {
+			return "PT0S"
+		}
+		return sb.String()
+	}
+	sb.WriteString("T")
+	if h := d / time.Hour; h > 0 {
+		sb.WriteString(strconv.FormatInt(int64(h), 10) + "H")
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		sb.WriteString(strconv.FormatInt(int64(m), 10) + "M")
+		d -= m * time.Minute
+	}
+	if s := d / time.Second; s > 0 {
+		sb.WriteString(strconv.FormatInt(int64(s), 10) + "S")
+	}
+	return sb.String()
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// parseCategories делит CATEGORIES по неэкранированным запятым и превращает категории в теги:
+// пробелы заменяются дефисами, слишком длинные категории пропускаются
+func parseCategories(value string) []string {
+	var (
+		tags    []string
+		current strings.Builder
+	)
+	flush := func() {
+		tag := strings.Join(strings.Fields(unescapeText(current.String())), "-")
+		if tag != "" && utf8.RuneCountInString(tag) <= maxTagLength {
+			tags = append(tags, tag)
+		}
+		current.Reset()
+	}
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			current.WriteByte(value[i])
+			i++
+			current.WriteByte(value[i])
+		case value[i] == ',':
+			flush()
+		default:
+			current.WriteByte(value[i])
+		}
+	}
+	flush()
+	return tags
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func unescapeText(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// unfold склеивает перенесённые строки: продолжение начинается с пробела или табуляции
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// writer переносит строки длиннее 75 байт, не разрывая символы UTF-8
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (w *writer) line(s string) {
+	if w.err != nil {
+		return
+	}
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		if _, w.err = w.w.WriteString(s[:cut] + "\r\n "); w.err != nil {
+			return
+		}
+		s = s[cut:]
+		limit = maxLineOctets - 1
+	}
+	_, w.err = w.w.WriteString(s + "\r\n")
+}
@@ -0,0 +1,133 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	event := storage.Event{
+		ID:           "event-1",
+		Title:        "Планирование; спринт, 42",
+		Description:  "Повестка:\nобсудить " + strings.Repeat("очень длинную строку ", 10),
+		StartTime:    time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC),
+		Duration:     calendar_types.CalendarDuration(90 * time.Minute),
+		NotifyBefore: calendar_types.CalendarDuration(15 * time.Minute),
+		Version:      3,
+		Labels:       storage.Labels{Color: "#1e90ff", Tags: []string{"planning", "team-a"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, []storage.Event{event}))
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets)
+	}
+	assert.Contains(t, buf.String(), "TRIGGER:-PT15M\r\n")
+	assert.Contains(t, buf.String(), "SEQUENCE:2\r\n")
+	assert.Contains(t, buf.String(), "CATEGORIES:planning,team-a\r\n")
+
+	decoded, err := Decode(&buf)
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	assert.Equal(t, event.ID, decoded[0].ID)
+	assert.Equal(t, event.Title, decoded[0].Title)
+	assert.Equal(t, event.Description, decoded[0].Description)
+	assert.True(t, event.StartTime.Equal(decoded[0].StartTime))
+	assert.Equal(t, event.Duration, decoded[0].Duration)
+	assert.Equal(t, event.NotifyBefore, decoded[0].NotifyBefore)
+	assert.Equal(t, event.Labels, decoded[0].Labels)
+}
+
+func TestDecodeClientEvent(t *testing.T) {
+	// Событие в том виде, в каком его присылает Apple Calendar
+	data := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//Apple Inc.//macOS 14.5//EN\r\n" +
+		"BEGIN:VTIMEZONE\r\n" +
+		"TZID:Europe/Moscow\r\n" +
+		"BEGIN:STANDARD\r\n" +
+		"DTSTART:19700101T000000\r\n" +
+		"TZOFFSETFROM:+0300\r\n" +
+		"TZOFFSETTO:+0300\r\n" +
+		"END:STANDARD\r\n" +
+		"END:VTIMEZONE\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:6B29FC40-CA47-1067-B31D-00DD010662DA\r\n" +
+		"DTSTART;TZID=Europe/Moscow:20240603T130000\r\n" +
+		"DURATION:PT1H30M\r\n" +
+		"SUMMARY:Demo\\, retro\r\n" +
+		"CATEGORIES:Work,Client Meeting\r\n" +
+		"CATEGORIES:work\r\n" +
+		"COLOR:turquoise\r\n" +
+		"DESCRIPTION:Line one\\nline \r\n" +
+		" two\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"TRIGGER;VALUE=DURATION:-P1D\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := Decode(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	event := events[0]
+	assert.Equal(t, "6B29FC40-CA47-1067-B31D-00DD010662DA", event.ID)
+	assert.Equal(t, "Demo, retro", event.Title)
+	assert.Equal(t, "Line one\nline two", event.Description)
+	assert.True(t, time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC).Equal(event.StartTime))
+	assert.Equal(t, calendar_types.CalendarDuration(90*time.Minute), event.Duration)
+	assert.Equal(t, calendar_types.CalendarDuration(24*time.Hour), event.NotifyBefore)
+	// Категории становятся тегами, цвет по имени не поддерживается
+	assert.Equal(t, []string{"client-meeting", "work"}, event.Tags)
+	assert.Empty(t, event.Color)
+}
+
+func TestDecodeAllDayEvent(t *testing.T) {
+	data := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:day\nDTSTART;VALUE=DATE:20240603\nSUMMARY:Holiday\nEND:VEVENT\nEND:VCALENDAR\n"
+	events, err := Decode(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.True(t, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC).Equal(events[0].StartTime))
+	assert.Equal(t, calendar_types.CalendarDuration(24*time.Hour), events[0].Duration)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, err := Decode(strings.NewReader("BEGIN:VCALENDAR\nEND:VCALENDAR\n"))
+	assert.ErrorIs(t, err, ErrNoEvents)
+
+	_, err = Decode(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:x\nSUMMARY:No start\nEND:VEVENT\nEND:VCALENDAR\n"))
+	assert.Error(t, err)
+
+	_, err = Decode(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20240603T100000Z\n"))
+	assert.Error(t, err)
+
+	_, err = Decode(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20240603T100000Z\nDURATION:1h\nEND:VEVENT\nEND:VCALENDAR\n"))
+	assert.Error(t, err)
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"PT15M":    15 * time.Minute,
+		"-PT1H":    -time.Hour,
+		"P1W":      7 * 24 * time.Hour,
+		"P1DT2H3S": 26*time.Hour + 3*time.Second,
+		"+PT0S":    0,
+	}
+	for value, expected := range cases {
+		got, err := parseDuration(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, got, value)
+		if expected > 0 {
+			back, _ := parseDuration(formatDuration(expected))
+			assert.Equal(t, expected, back, value)
+		}
+	}
+}
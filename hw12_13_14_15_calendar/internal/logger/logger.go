@@ -2,10 +2,10 @@ package logger
 
 import (
 	"fmt"
-	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
 	"io"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,7 +19,7 @@ const (
 )
 
 type Logger struct {
-	level  Level
+	level  atomic.Int32
 	writer io.Writer
 }
 
@@ -38,11 +38,17 @@ func parseLevel(level string) Level {
 	}
 }
 
-func New(level string) app.Logger {
-	return &Logger{
-		level:  parseLevel(level),
+func New(level string) *Logger {
+	l := &Logger{
 		writer: os.Stdout,
 	}
+	l.SetLevel(level)
+	return l
+}
+
+// SetLevel меняет уровень логирования на лету, например при перечитывании конфигурации.
+func (l *Logger) SetLevel(level string) {
+	l.level.Store(int32(parseLevel(level)))
 }
 
 func (l *Logger) SetWriter(w io.Writer) {
@@ -50,7 +56,7 @@ func (l *Logger) SetWriter(w io.Writer) {
 }
 
 func (l *Logger) logf(lvl Level, tag, msg string) {
-	if lvl < l.level {
+	if lvl < Level(l.level.Load()) {
 		return
 	}
 	line := fmt.Sprintf("[%s] %s: %s\n", time.Now().Format(time.RFC3339), tag, msg)
@@ -42,7 +42,7 @@ func captureOutput(f func()) string {
 var outputWriter *bytes.Buffer
 
 func (l *Logger) logfTestable(lvl Level, tag, msg string) {
-	if lvl < l.level {
+	if lvl < Level(l.level.Load()) {
 		return
 	}
 	if outputWriter != nil {
@@ -51,7 +51,7 @@ func (l *Logger) logfTestable(lvl Level, tag, msg string) {
 }
 
 func TestLoggerFiltering(t *testing.T) {
-	l := &Logger{level: Warn}
+	l := New("warn")
 	var buf bytes.Buffer
 	l.SetWriter(&buf)
 
@@ -72,7 +72,7 @@ func TestLoggerFiltering(t *testing.T) {
 
 func TestLoggerMethods(t *testing.T) {
 	var buf bytes.Buffer
-	l := &Logger{level: Debug}
+	l := New("debug")
 	l.SetWriter(&buf)
 
 	l.Debug("debug line")
@@ -88,3 +88,21 @@ func TestLoggerMethods(t *testing.T) {
 		}
 	}
 }
+
+func TestLoggerSetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("error")
+	l.SetWriter(&buf)
+
+	l.Info("hidden")
+	l.SetLevel("info")
+	l.Info("visible")
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") {
+		t.Error("unexpected info entry before level change")
+	}
+	if !strings.Contains(out, "visible") {
+		t.Error("expected info entry after level change")
+	}
+}
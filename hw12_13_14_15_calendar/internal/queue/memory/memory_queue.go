@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/queue"
+)
+
+var (
+	ErrQueueFull = errors.New("queue is full")
+	ErrClosed    = errors.New("queue is closed")
+)
+
+// MemoryQueue - очередь внутри процесса для запуска всей системы одним бинарником.
+// Сообщения не переживают перезапуск. Точки обмена не нужны: сообщение сразу попадает в очередь по имени.
+type MemoryQueue[T any] struct {
+	capacity int
+
+	mu     sync.Mutex
+	queues map[string]chan queue.MessageQueue[T]
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewMemoryQueue создаёт очередь; в каждой именованной очереди помещается capacity сообщений
+func NewMemoryQueue[T any](capacity int) queue.Queue[T] {
+	return &MemoryQueue[T]{
+		capacity: capacity,
+		queues:   map[string]chan queue.MessageQueue[T]{},
+		closed:   make(chan struct{}),
+	}
+}
+
+func (q *MemoryQueue[T]) channel(name string) chan queue.MessageQueue[T] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ch, ok := q.queues[name]
+	if !ok {
+		ch = make(chan queue.MessageQueue[T], q.capacity)
+		q.queues[name] = ch
+	}
+	return ch
+}
+
+// Put не ждёт читателя: если очередь заполнена, возвращается ErrQueueFull
+func (q *MemoryQueue[T]) Put(queueName string, _ string, message queue.MessageQueue[T]) error {
+	select {
+	case <-q.closed:
+		return ErrClosed
+	default:
+	}
+	select {
+	case q.channel(queueName) <- message:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (q *MemoryQueue[T]) Get(ctx context.Context, queueName string) (<-chan queue.MessageQueue[T], <-chan error) {
+	source := q.channel(queueName)
+	ch := make(chan queue.MessageQueue[T])
+	errch := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.closed:
+				errch <- ErrClosed
+				return
+			case message := <-source:
+				select {
+				case ch <- message:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, errch
+}
+
+func (q *MemoryQueue[T]) Close() error {
+	q.once.Do(func() { close(q.closed) })
+	return nil
+}
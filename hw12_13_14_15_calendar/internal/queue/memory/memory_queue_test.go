@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryQueue(t *testing.T) {
+	q := NewMemoryQueue[string](2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, q.Put("events", "", queue.MessageQueue[string]{ID: "1", Body: "first"}))
+	require.NoError(t, q.Put("events", "", queue.MessageQueue[string]{ID: "2", Body: "second"}))
+	assert.ErrorIs(t, q.Put("events", "", queue.MessageQueue[string]{ID: "3"}), ErrQueueFull)
+	// Очереди с разными именами независимы
+	require.NoError(t, q.Put("other", "", queue.MessageQueue[string]{ID: "4"}))
+
+	messages, errs := q.Get(ctx, "events")
+	for _, expected := range []string{"first", "second"} {
+		select {
+		case message := <-messages:
+			assert.Equal(t, expected, message.Body)
+		case <-time.After(time.Second):
+			t.Fatal("message was not delivered")
+		}
+	}
+
+	require.NoError(t, q.Close())
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrClosed)
+	case <-time.After(time.Second):
+		t.Fatal("close was not reported")
+	}
+	assert.ErrorIs(t, q.Put("events", "", queue.MessageQueue[string]{}), ErrClosed)
+}
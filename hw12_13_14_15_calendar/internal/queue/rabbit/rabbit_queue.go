@@ -2,6 +2,7 @@ package rabbit
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"time"
 
@@ -17,8 +18,27 @@ type RabbitQueue[T any] struct {
 	codec      Codec
 }
 
-func NewRabbitQueue[T any](url, username, password string, logger app.Logger) (queue.Queue[T], error) {
-	connection, channel, err := connectToRabbitMQ(url, username, password)
+type options struct {
+	tls *tls.Config
+}
+
+// Option настраивает подключение к RabbitMQ
+type Option func(*options)
+
+// WithTLS подключается по amqps:// с проверкой сертификата брокера; клиентский сертификат
+// предъявляется, если он есть в конфигурации
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+	}
+}
+
+func NewRabbitQueue[T any](url, username, password string, logger app.Logger, opts ...Option) (queue.Queue[T], error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	connection, channel, err := connectToRabbitMQ(url, username, password, o.tls)
 	if err != nil {
 		return nil, err
 	}
@@ -30,9 +50,16 @@ func NewRabbitQueue[T any](url, username, password string, logger app.Logger) (q
 	}, nil
 }
 
-func connectToRabbitMQ(url, username, password string) (*amqp.Connection, *amqp.Channel, error) {
-	amqpURL := fmt.Sprintf("amqp://%s:%s@%s/", username, password, url)
-	conn, err := amqp.Dial(amqpURL)
+func connectToRabbitMQ(url, username, password string, tlsConfig *tls.Config) (*amqp.Connection, *amqp.Channel, error) {
+	var (
+		conn *amqp.Connection
+		err  error
+	)
+	if tlsConfig != nil {
+		conn, err = amqp.DialTLS(fmt.Sprintf("amqps://%s:%s@%s/", username, password, url), tlsConfig)
+	} else {
+		conn, err = amqp.Dial(fmt.Sprintf("amqp://%s:%s@%s/", username, password, url))
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// idleTimeout - через сколько без запросов корзина удаляется. Новая корзина создаётся полной,
+// так что для правил с периодом до часа клиент ничего не выигрывает.
+const idleTimeout = time.Hour
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryLimiter хранит корзины в памяти процесса. Подходит для одного экземпляра календаря;
+// при нескольких экземплярах каждый считает запросы сам, и общий лимит умножается на их число.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, rule Rule) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: rule.Capacity(), updated: now}
+		l.buckets[key] = b
+	}
+	tokens, retryAfter := rule.Take(b.tokens, now.Sub(b.updated))
+	b.tokens, b.updated = tokens, now
+	return retryAfter, nil
+}
+
+// sweep удаляет давно не использованные корзины, чтобы память не росла с числом клиентов
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleTimeout {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) > idleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
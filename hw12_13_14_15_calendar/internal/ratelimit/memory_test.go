@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	limiter := NewMemoryLimiter()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	rule := Rule{Limit: 2, Per: time.Minute, Burst: 3}
+	ctx := context.Background()
+
+	// Полная корзина пропускает Burst запросов подряд
+	for i := 0; i < 3; i++ {
+		retryAfter, err := limiter.Allow(ctx, "alice", rule)
+		require.NoError(t, err)
+		assert.Zero(t, retryAfter)
+	}
+	retryAfter, err := limiter.Allow(ctx, "alice", rule)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, retryAfter)
+
+	// У другого клиента своя корзина
+	retryAfter, _ = limiter.Allow(ctx, "bob", rule)
+	assert.Zero(t, retryAfter)
+
+	// За 30 секунд корзина пополняется на один токен
+	now = now.Add(30 * time.Second)
+	retryAfter, _ = limiter.Allow(ctx, "alice", rule)
+	assert.Zero(t, retryAfter)
+	retryAfter, _ = limiter.Allow(ctx, "alice", rule)
+	assert.Equal(t, 30*time.Second, retryAfter)
+}
+
+func TestPolicy(t *testing.T) {
+	policy := Policy{
+		Limiter: NewMemoryLimiter(),
+		Rules:   map[string]Rule{ClassWrite: {Limit: 1, Per: time.Hour}},
+	}
+	ctx := context.Background()
+	client := Client("", "10.0.0.1")
+
+	retryAfter, err := policy.Allow(ctx, ClassWrite, client)
+	require.NoError(t, err)
+	assert.Zero(t, retryAfter)
+	retryAfter, _ = policy.Allow(ctx, ClassWrite, client)
+	assert.Positive(t, retryAfter)
+
+	// Класс без правила не ограничивается, а классы считаются раздельно
+	for i := 0; i < 5; i++ {
+		retryAfter, _ = policy.Allow(ctx, ClassRead, client)
+		assert.Zero(t, retryAfter)
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	limiter := NewMemoryLimiter()
+	policy := NewSwitch(Policy{Limiter: limiter, Rules: map[string]Rule{ClassWrite: {Limit: 1, Per: time.Hour}}})
+	ctx := context.Background()
+	client := Client("alice", "")
+
+	retryAfter, _ := policy.Allow(ctx, ClassWrite, client)
+	assert.Zero(t, retryAfter)
+	retryAfter, _ = policy.Allow(ctx, ClassWrite, client)
+	assert.Positive(t, retryAfter)
+
+	// Новые правила действуют со следующего запроса
+	policy.Store(Policy{Limiter: limiter, Rules: map[string]Rule{ClassRead: {Limit: 1, Per: time.Hour}}})
+	retryAfter, _ = policy.Allow(ctx, ClassWrite, client)
+	assert.Zero(t, retryAfter)
+	retryAfter, _ = policy.Allow(ctx, ClassRead, client)
+	assert.Zero(t, retryAfter)
+	retryAfter, _ = policy.Allow(ctx, ClassRead, client)
+	assert.Positive(t, retryAfter)
+
+	// Нулевая политика ничего не ограничивает
+	policy.Store(Policy{})
+	for i := 0; i < 5; i++ {
+		retryAfter, _ = policy.Allow(ctx, ClassWrite, client)
+		assert.Zero(t, retryAfter)
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, "1", RetryAfterSeconds(10*time.Millisecond))
+	assert.Equal(t, "3", RetryAfterSeconds(2100*time.Millisecond))
+}
@@ -0,0 +1,116 @@
+// Package ratelimit ограничивает частоту запросов алгоритмом token bucket.
+//
+// У каждого ключа (класс запроса + пользователь или IP) своя корзина ёмкостью Burst,
+// которая пополняется со скоростью Limit токенов за Per. Запрос забирает один токен;
+// если токенов нет, клиент получает время, через которое стоит повторить попытку.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Классы запросов: для каждого задаётся своё ограничение
+const (
+	ClassRead   = "read"   // чтение событий и календарей
+	ClassWrite  = "write"  // создание, изменение и удаление
+	ClassSearch = "search" // полнотекстовый поиск, самый дорогой запрос на чтение
+	ClassBatch  = "batch"  // пакетные изменения
+)
+
+var Classes = []string{ClassRead, ClassWrite, ClassSearch, ClassBatch}
+
+// Rule - ограничение для класса запросов
+type Rule struct {
+	Limit int           // Сколько запросов разрешено за Per
+	Per   time.Duration // Период, за который корзина пополняется на Limit токенов
+	Burst int           // Ёмкость корзины; 0 - равна Limit
+}
+
+// Capacity - ёмкость корзины; новая корзина создаётся полной
+func (r Rule) Capacity() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return float64(r.Limit)
+}
+
+// ratePerSecond - скорость пополнения корзины
+func (r Rule) ratePerSecond() float64 {
+	return float64(r.Limit) / r.Per.Seconds()
+}
+
+// Take списывает токен из корзины, в которой было tokens токенов elapsed назад.
+// Возвращает новое число токенов и, если токена не хватило, через сколько он появится.
+func (r Rule) Take(tokens float64, elapsed time.Duration) (left float64, retryAfter time.Duration) {
+	tokens = math.Min(r.Capacity(), tokens+elapsed.Seconds()*r.ratePerSecond())
+	if tokens >= 1 {
+		return tokens - 1, 0
+	}
+	wait := (1 - tokens) / r.ratePerSecond()
+	return tokens, time.Duration(math.Ceil(wait * float64(time.Second)))
+}
+
+// Limiter решает, можно ли выполнить ещё один запрос с ключом key.
+// Нулевой retryAfter означает, что запрос разрешён.
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (retryAfter time.Duration, err error)
+}
+
+// Policy - ограничения по классам запросов; класс без правила не ограничивается
+type Policy struct {
+	Limiter Limiter
+	Rules   map[string]Rule
+}
+
+// Allow проверяет запрос класса class от клиента client (user:... или ip:...)
+func (p Policy) Allow(ctx context.Context, class, client string) (time.Duration, error) {
+	rule, ok := p.Rules[class]
+	if !ok || p.Limiter == nil {
+		return 0, nil
+	}
+	return p.Limiter.Allow(ctx, class+":"+client, rule)
+}
+
+// Switch - политика, которую можно заменить на лету, например при перечитывании конфигурации.
+// Запросы читают текущую политику без блокировок; нулевая политика ничего не ограничивает.
+type Switch struct {
+	policy atomic.Pointer[Policy]
+}
+
+func NewSwitch(policy Policy) *Switch {
+	s := &Switch{}
+	s.Store(policy)
+	return s
+}
+
+// Store заменяет политику; счётчики в хранилище ограничителя при этом не сбрасываются
+func (s *Switch) Store(policy Policy) {
+	s.policy.Store(&policy)
+}
+
+// Load возвращает текущую политику
+func (s *Switch) Load() Policy {
+	return *s.policy.Load()
+}
+
+// Allow проверяет запрос по текущей политике
+func (s *Switch) Allow(ctx context.Context, class, client string) (time.Duration, error) {
+	return s.Load().Allow(ctx, class, client)
+}
+
+// Client - ключ клиента: пользователь, если он известен, иначе адрес
+func Client(user, ip string) string {
+	if user != "" {
+		return "user:" + user
+	}
+	return "ip:" + ip
+}
+
+// RetryAfterSeconds - значение заголовка Retry-After: целое число секунд, не меньше одной
+func RetryAfterSeconds(retryAfter time.Duration) string {
+	return fmt.Sprint(int64(math.Max(1, math.Ceil(retryAfter.Seconds()))))
+}
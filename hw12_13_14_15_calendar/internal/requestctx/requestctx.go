@@ -0,0 +1,70 @@
+package requestctx
+
+import (
+	"context"
+	"strings"
+)
+
+type requestIDKey struct{}
+
+type actorKey struct{}
+
+type groupsKey struct{}
+
+type systemKey struct{}
+
+// WithRequestID сохраняет идентификатор запроса в контексте
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID возвращает идентификатор запроса из контекста или пустую строку
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// WithActor сохраняет в контексте того, кто выполняет запрос
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor возвращает того, кто выполняет запрос, или пустую строку
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithGroups сохраняет в контексте группы пользователя
+func WithGroups(ctx context.Context, groups []string) context.Context {
+	return context.WithValue(ctx, groupsKey{}, groups)
+}
+
+// Groups возвращает группы пользователя из контекста
+func Groups(ctx context.Context) []string {
+	groups, _ := ctx.Value(groupsKey{}).([]string)
+	return groups
+}
+
+// WithSystem помечает внутренний вызов без пользователя (планировщик, миграции, тесты),
+// для которого доступ к календарям не проверяется. Клиентские запросы так не помечаются.
+func WithSystem(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemKey{}, true)
+}
+
+// System сообщает, помечен ли вызов как внутренний
+func System(ctx context.Context) bool {
+	system, _ := ctx.Value(systemKey{}).(bool)
+	return system
+}
+
+// ParseGroups разбирает список групп через запятую, как он приходит в заголовке
+func ParseGroups(value string) []string {
+	var groups []string
+	for _, group := range strings.Split(value, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Archiver сохраняет события перед их окончательным удалением
+type Archiver interface {
+	Archive(events []storage.Event) error
+}
+
+// FileArchiver пишет каждую партию событий в отдельный файл <dir>/events-<время>.jsonl.gz,
+// по одному JSON-объекту на строку.
+type FileArchiver struct {
+	dir string
+	now func() time.Time
+}
+
+func NewFileArchiver(dir string) *FileArchiver {
+	return &FileArchiver{dir: dir, now: time.Now}
+}
+
+// archivedEvent - формат строки архива; теги заданы явно, чтобы формат не зависел от storage.Event
+type archivedEvent struct {
+	ID           string     `json:"id"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description,omitempty"`
+	StartTime    time.Time  `json:"start_time"`
+	Duration     string     `json:"duration"`
+	UserID       string     `json:"user_id"`
+	CalendarID   string     `json:"calendar_id,omitempty"`
+	NotifyBefore string     `json:"notify_before"`
+	Category     string     `json:"category,omitempty"`
+	Color        string     `json:"color,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	Version      int64      `json:"version"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	ArchivedAt   time.Time  `json:"archived_at"`
+}
+
+func (a *FileArchiver) Archive(events []storage.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create archive dir: %w", err)
+	}
+
+	now := a.now().UTC()
+	path := filepath.Join(a.dir, "events-"+now.Format("20060102T150405.000000000Z")+".jsonl.gz")
+
+	// Пишем во временный файл и переименовываем, чтобы в архиве не оставалось недописанных файлов
+	tmp, err := os.CreateTemp(a.dir, ".events-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeArchive(tmp, events, now); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync archive file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close archive file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename archive file: %w", err)
+	}
+	return nil
+}
+
+func writeArchive(file *os.File, events []storage.Event, archivedAt time.Time) error {
+	gz := gzip.NewWriter(file)
+	encoder := json.NewEncoder(gz)
+	for _, event := range events {
+		record := archivedEvent{
+			ID:           event.ID,
+			Title:        event.Title,
+			Description:  event.Description,
+			StartTime:    event.StartTime,
+			Duration:     time.Duration(event.Duration).String(),
+			UserID:       event.UserID,
+			CalendarID:   event.CalendarID,
+			NotifyBefore: time.Duration(event.NotifyBefore).String(),
+			Category:     event.Category,
+			Color:        event.Color,
+			Tags:         event.Tags,
+			Version:      event.Version,
+			DeletedAt:    event.DeletedAt,
+			ArchivedAt:   archivedAt,
+		}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write archive record: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive file: %w", err)
+	}
+	return nil
+}
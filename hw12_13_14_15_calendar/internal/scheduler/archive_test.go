@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileArchiver(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	archiver := NewFileArchiver(dir)
+	archiver.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	deletedAt := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	events := []storage.Event{
+		{ID: "event-1", Title: "Old", StartTime: time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC), Duration: calendar_types.CalendarDuration(time.Hour), UserID: "user1", Version: 2,
+			Labels: storage.Labels{Category: "work", Color: "#ff0000", Tags: []string{"planning", "q1"}}},
+		{ID: "event-2", Title: "Trashed", StartTime: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), UserID: "user2", Version: 3, DeletedAt: &deletedAt},
+	}
+	require.NoError(t, archiver.Archive(events))
+	require.NoError(t, archiver.Archive(nil))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "events-20240501T120000.000000000Z.jsonl.gz", files[0].Name())
+
+	file, err := os.Open(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	var records []archivedEvent
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var record archivedEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, records, 2)
+	assert.Equal(t, "event-1", records[0].ID)
+	assert.Equal(t, "1h0m0s", records[0].Duration)
+	assert.Equal(t, "work", records[0].Category)
+	assert.Equal(t, "#ff0000", records[0].Color)
+	assert.Equal(t, []string{"planning", "q1"}, records[0].Tags)
+	assert.Nil(t, records[0].DeletedAt)
+	assert.Equal(t, "event-2", records[1].ID)
+	require.NotNil(t, records[1].DeletedAt)
+	assert.True(t, deletedAt.Equal(*records[1].DeletedAt))
+}
@@ -10,6 +10,12 @@ import (
 type NotificationStorage interface {
 	GetEventsForNotification(ctx context.Context, now time.Time) ([]storage.Event, error)
 	MarkEventNotified(ctx context.Context, eventID string) error
-	CleanOldEvents(ctx context.Context) error
+	// ListEventsToPurge возвращает события, закончившиеся до endedBefore,
+	// и события, попавшие в корзину до deletedBefore
+	ListEventsToPurge(ctx context.Context, endedBefore, deletedBefore time.Time) ([]storage.Event, error)
+	// PurgeEvents окончательно удаляет события вместе с записями об их вложениях
+	PurgeEvents(ctx context.Context, ids []string) error
+	// ListAttachments возвращает вложения события, чтобы удалить их содержимое после PurgeEvents
+	ListAttachments(ctx context.Context, eventID string) ([]storage.Attachment, error)
 	Close() error
 }
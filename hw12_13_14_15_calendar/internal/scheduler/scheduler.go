@@ -2,44 +2,142 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/blob"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/queue"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
 )
 
+// Сроки хранения по умолчанию: закончившиеся события - год, события в корзине - месяц
+const (
+	DefaultEventRetention = 365 * 24 * time.Hour
+	DefaultTrashRetention = 30 * 24 * time.Hour
+)
+
 type Scheduler struct {
-	storage       NotificationStorage
-	logger        app.Logger
-	queue         queue.Queue[storage.Notification]
-	queueName     string
-	exchangeName  string
-	checkInterval string
+	storage NotificationStorage
+	logger  app.Logger
+	queue   queue.Queue[storage.Notification]
+	blobs   blob.Store
+
+	// Настройки ниже можно менять на лету при перечитывании конфигурации
+	mu             sync.RWMutex
+	archiver       Archiver
+	eventRetention time.Duration
+	trashRetention time.Duration
+	queueName      string
+	exchangeName   string
+	checkInterval  string
+	reconfigured   chan struct{}
 }
 
-func NewScheduler(logger app.Logger, storage NotificationStorage, queue queue.Queue[storage.Notification], queueName, exchangeName, checkInterval string) *Scheduler {
-	return &Scheduler{
-		storage:       storage,
-		logger:        logger,
-		queue:         queue,
-		queueName:     queueName,
-		exchangeName:  exchangeName,
-		checkInterval: checkInterval,
+// Option настраивает необязательные параметры планировщика
+type Option func(*Scheduler)
+
+// WithArchiver задаёт архив для событий перед окончательным удалением.
+// Без архива старые события и корзина не очищаются.
+func WithArchiver(archiver Archiver) Option {
+	return func(s *Scheduler) {
+		s.archiver = archiver
 	}
 }
 
-func (s *Scheduler) Start(ctx context.Context) {
-	interval, err := time.ParseDuration(s.checkInterval)
-	if err != nil {
-		s.logger.Error("Failed to parse check interval, using default 1m: " + err.Error())
+// WithBlobStore задаёт хранилище содержимого вложений, из которого удаляются файлы
+// окончательно удалённых событий. Без него файлы остаются в хранилище.
+func WithBlobStore(store blob.Store) Option {
+	return func(s *Scheduler) {
+		s.blobs = store
+	}
+}
+
+// WithRetention задаёт, сколько хранить закончившиеся события и события в корзине
+func WithRetention(events, trash time.Duration) Option {
+	return func(s *Scheduler) {
+		if events > 0 {
+			s.eventRetention = events
+		}
+		if trash > 0 {
+			s.trashRetention = trash
+		}
+	}
+}
+
+func NewScheduler(logger app.Logger, storage NotificationStorage, queue queue.Queue[storage.Notification], queueName, exchangeName, checkInterval string, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		storage:        storage,
+		logger:         logger,
+		queue:          queue,
+		eventRetention: DefaultEventRetention,
+		trashRetention: DefaultTrashRetention,
+		queueName:      queueName,
+		exchangeName:   exchangeName,
+		checkInterval:  checkInterval,
+		reconfigured:   make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// SetCheckInterval меняет интервал проверки событий; запущенный планировщик подхватит его сразу.
+func (s *Scheduler) SetCheckInterval(checkInterval string) {
+	s.mu.Lock()
+	s.checkInterval = checkInterval
+	s.mu.Unlock()
+
+	select {
+	case s.reconfigured <- struct{}{}:
+	default:
+	}
+}
+
+// SetQueue меняет очередь и эксчейндж, в которые отправляются уведомления.
+func (s *Scheduler) SetQueue(queueName, exchangeName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queueName = queueName
+	s.exchangeName = exchangeName
+}
+
+// SetRetention меняет сроки хранения; нулевое значение оставляет текущий срок.
+func (s *Scheduler) SetRetention(events, trash time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	WithRetention(events, trash)(s)
+}
+
+// SetArchiver меняет архив, в который попадают события перед удалением.
+func (s *Scheduler) SetArchiver(archiver Archiver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.archiver = archiver
+}
+
+func (s *Scheduler) interval() time.Duration {
+	s.mu.RLock()
+	checkInterval := s.checkInterval
+	s.mu.RUnlock()
+
+	interval, err := time.ParseDuration(checkInterval)
+	if err != nil || interval <= 0 {
+		s.logger.Error("Failed to parse check interval, using default 1m: " + checkInterval)
 		interval = 1 * time.Minute
 	}
+	return interval
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	interval := s.interval()
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	// Очистка старых событий каждые 24 часа
+	// Очистка старых событий и корзины каждые 24 часа
 	cleanupTicker := time.NewTicker(24 * time.Hour)
 	defer cleanupTicker.Stop()
 
@@ -50,6 +148,10 @@ func (s *Scheduler) Start(ctx context.Context) {
 		case <-ctx.Done():
 			s.logger.Info("Scheduler stopped")
 			return
+		case <-s.reconfigured:
+			interval = s.interval()
+			ticker.Reset(interval)
+			s.logger.Info("Scheduler interval changed to: " + interval.String())
 		case <-ticker.C:
 			s.checkAndSendNotifications(ctx)
 		case <-cleanupTicker.C:
@@ -65,6 +167,10 @@ func (s *Scheduler) checkAndSendNotifications(ctx context.Context) {
 		return
 	}
 
+	s.mu.RLock()
+	queueName, exchangeName := s.queueName, s.exchangeName
+	s.mu.RUnlock()
+
 	for _, event := range events {
 		notification := storage.Notification{
 			EventID:   event.ID,
@@ -78,7 +184,7 @@ func (s *Scheduler) checkAndSendNotifications(ctx context.Context) {
 			Body: notification,
 		}
 
-		err := s.queue.Put(s.queueName, s.exchangeName, msg)
+		err := s.queue.Put(queueName, exchangeName, msg)
 		if err != nil {
 			s.logger.Error("Failed to send notification to queue: " + err.Error())
 			continue
@@ -92,8 +198,70 @@ func (s *Scheduler) checkAndSendNotifications(ctx context.Context) {
 	}
 }
 
+// cleanOldEvents архивирует и окончательно удаляет старые события и события из корзины.
+// Если архив записать не удалось, события не удаляются.
 func (s *Scheduler) cleanOldEvents(ctx context.Context) {
-	if err := s.storage.CleanOldEvents(ctx); err != nil {
-		s.logger.Error("Failed to clean old events: " + err.Error())
+	s.mu.RLock()
+	archiver, eventRetention, trashRetention := s.archiver, s.eventRetention, s.trashRetention
+	s.mu.RUnlock()
+
+	if archiver == nil {
+		s.logger.Warn("Archive is not configured, old events are kept")
+		return
+	}
+
+	now := time.Now()
+	events, err := s.storage.ListEventsToPurge(ctx, now.Add(-eventRetention), now.Add(-trashRetention))
+	if err != nil {
+		s.logger.Error("Failed to list events to purge: " + err.Error())
+		return
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	if err := archiver.Archive(events); err != nil {
+		s.logger.Error("Failed to archive events, skipping purge: " + err.Error())
+		return
+	}
+
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	// Записи о вложениях удаляются вместе с событиями, поэтому файлы собираются заранее
+	files := s.attachmentFiles(ctx, ids)
+	if err := s.storage.PurgeEvents(ctx, ids); err != nil {
+		s.logger.Error("Failed to purge events: " + err.Error())
+		return
+	}
+	s.logger.Info(fmt.Sprintf("Archived and purged %d events", len(ids)))
+
+	for _, key := range files {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			s.logger.Error(fmt.Sprintf("Failed to delete attachment content %s: %s", key, err))
+		}
+	}
+}
+
+// attachmentFiles возвращает ключи содержимого файлов, прикреплённых к событиям.
+// Если список получить не удалось, события всё равно удаляются, а файлы остаются.
+func (s *Scheduler) attachmentFiles(ctx context.Context, eventIDs []string) []string {
+	if s.blobs == nil {
+		return nil
+	}
+	var keys []string
+	for _, id := range eventIDs {
+		attachments, err := s.storage.ListAttachments(ctx, id)
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("Failed to list attachments of event %s: %s", id, err))
+			continue
+		}
+		for _, attachment := range attachments {
+			if attachment.Kind == storage.AttachmentFile {
+				keys = append(keys, attachment.ID)
+			}
+		}
 	}
+	return keys
 }
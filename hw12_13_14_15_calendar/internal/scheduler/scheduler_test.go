@@ -2,9 +2,11 @@ package scheduler
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/blob"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/queue"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
@@ -12,8 +14,14 @@ import (
 )
 
 type MockNotificationStorage struct {
-	events []storage.Event
-	err    error
+	events  []storage.Event
+	err     error
+	toPurge []storage.Event
+	purged  []string
+	endedAt time.Time
+	trashAt time.Time
+
+	attachments map[string][]storage.Attachment
 }
 
 func (m *MockNotificationStorage) GetEventsForNotification(ctx context.Context, now time.Time) ([]storage.Event, error) {
@@ -24,10 +32,20 @@ func (m *MockNotificationStorage) MarkEventNotified(ctx context.Context, eventID
 	return nil
 }
 
-func (m *MockNotificationStorage) CleanOldEvents(ctx context.Context) error {
+func (m *MockNotificationStorage) ListEventsToPurge(ctx context.Context, endedBefore, deletedBefore time.Time) ([]storage.Event, error) {
+	m.endedAt, m.trashAt = endedBefore, deletedBefore
+	return m.toPurge, nil
+}
+
+func (m *MockNotificationStorage) PurgeEvents(ctx context.Context, ids []string) error {
+	m.purged = append(m.purged, ids...)
 	return nil
 }
 
+func (m *MockNotificationStorage) ListAttachments(ctx context.Context, eventID string) ([]storage.Attachment, error) {
+	return m.attachments[eventID], nil
+}
+
 func (m *MockNotificationStorage) Close() error {
 	return nil
 }
@@ -229,3 +247,127 @@ func TestScheduler_WithRealisticScenario(t *testing.T) {
 		}
 	}
 }
+
+type putRecord struct {
+	queue    string
+	exchange string
+}
+
+type recordingQueue struct {
+	MockQueue
+	puts chan putRecord
+}
+
+func (q *recordingQueue) Put(queueName string, exchange string, _ queue.MessageQueue[storage.Notification]) error {
+	q.puts <- putRecord{queue: queueName, exchange: exchange}
+	return nil
+}
+
+func TestScheduler_Reconfigure(t *testing.T) {
+	mockStorage := &MockNotificationStorage{
+		events: []storage.Event{{ID: "event-1", Title: "Event", StartTime: time.Now()}},
+	}
+	recQueue := &recordingQueue{puts: make(chan putRecord, 100)}
+
+	scheduler := NewScheduler(&MockLogger{}, mockStorage, recQueue, "old-queue", "old-exchange", "1h")
+	scheduler.SetQueue("new-queue", "new-exchange")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		scheduler.Start(ctx)
+		close(done)
+	}()
+
+	scheduler.SetCheckInterval("10ms")
+
+	select {
+	case put := <-recQueue.puts:
+		assert.Equal(t, "new-queue", put.queue)
+		assert.Equal(t, "new-exchange", put.exchange)
+	case <-time.After(time.Second):
+		t.Fatal("Expected notification after check interval change")
+	}
+
+	cancel()
+	<-done
+}
+
+type mockArchiver struct {
+	archived []storage.Event
+	err      error
+}
+
+func (a *mockArchiver) Archive(events []storage.Event) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.archived = append(a.archived, events...)
+	return nil
+}
+
+func TestScheduler_CleanOldEvents(t *testing.T) {
+	deletedAt := time.Now().Add(-40 * 24 * time.Hour)
+	mockStorage := &MockNotificationStorage{
+		toPurge: []storage.Event{
+			{ID: "old-event", StartTime: time.Now().AddDate(-2, 0, 0)},
+			{ID: "trashed-event", StartTime: time.Now(), DeletedAt: &deletedAt},
+		},
+	}
+	archiver := &mockArchiver{}
+
+	scheduler := NewScheduler(&MockLogger{}, mockStorage, &MockQueue{}, "test-queue", "test-exchange", "1m",
+		WithArchiver(archiver), WithRetention(48*time.Hour, time.Hour))
+	scheduler.cleanOldEvents(context.Background())
+
+	assert.Len(t, archiver.archived, 2)
+	assert.Equal(t, []string{"old-event", "trashed-event"}, mockStorage.purged)
+	assert.WithinDuration(t, time.Now().Add(-48*time.Hour), mockStorage.endedAt, time.Minute)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), mockStorage.trashAt, time.Minute)
+}
+
+func TestScheduler_CleanOldEventsDeletesAttachmentContent(t *testing.T) {
+	ctx := context.Background()
+	blobs := blob.NewS3Store(blob.NewMemoryS3(), "calendar", "")
+	for _, key := range []string{"agenda", "slides", "other"} {
+		assert.NoError(t, blobs.Put(ctx, key, strings.NewReader(key)))
+	}
+	mockStorage := &MockNotificationStorage{
+		toPurge: []storage.Event{{ID: "old-event"}},
+		attachments: map[string][]storage.Attachment{
+			"old-event": {
+				{ID: "agenda", Kind: storage.AttachmentFile},
+				{ID: "slides", Kind: storage.AttachmentFile},
+				{ID: "call", Kind: storage.AttachmentLink, URL: "https://meet.example.com/x"},
+			},
+		},
+	}
+
+	scheduler := NewScheduler(&MockLogger{}, mockStorage, &MockQueue{}, "test-queue", "test-exchange", "1m",
+		WithArchiver(&mockArchiver{}), WithBlobStore(blobs))
+	scheduler.cleanOldEvents(ctx)
+
+	assert.Equal(t, []string{"old-event"}, mockStorage.purged)
+	for key, exists := range map[string]bool{"agenda": false, "slides": false, "other": true} {
+		_, err := blobs.Open(ctx, key)
+		assert.Equal(t, exists, err == nil, key)
+	}
+}
+
+func TestScheduler_CleanOldEventsKeepsEventsWithoutArchive(t *testing.T) {
+	mockStorage := &MockNotificationStorage{
+		toPurge: []storage.Event{{ID: "old-event"}},
+	}
+
+	// Архив не записался - удалять нельзя
+	scheduler := NewScheduler(&MockLogger{}, mockStorage, &MockQueue{}, "test-queue", "test-exchange", "1m",
+		WithArchiver(&mockArchiver{err: assert.AnError}))
+	scheduler.cleanOldEvents(context.Background())
+	assert.Empty(t, mockStorage.purged)
+
+	// Архив не настроен - тоже ничего не удаляем
+	scheduler = NewScheduler(&MockLogger{}, mockStorage, &MockQueue{}, "test-queue", "test-exchange", "1m")
+	scheduler.cleanOldEvents(context.Background())
+	assert.Empty(t, mockStorage.purged)
+}
@@ -1,11 +0,0 @@
-package scheduler
-
-import (
-	"testing"
-)
-
-func TestSQLNotificationStorage_GetEventsForNotification(t *testing.T) {
-	// Этот тест требует реальной базы данных
-	// В реальном проекте нужно использовать testcontainers или mock
-	t.Skip("Skipping test that requires real database")
-}
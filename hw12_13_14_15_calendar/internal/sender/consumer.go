@@ -0,0 +1,75 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/queue"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Consumer читает уведомления из очереди, «отправляет» их (пишет в лог)
+// и умеет переключаться на другую очередь на лету
+type Consumer struct {
+	queue  queue.Queue[storage.Notification]
+	logger app.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewConsumer(queue queue.Queue[storage.Notification], logger app.Logger) *Consumer {
+	return &Consumer{queue: queue, logger: logger}
+}
+
+// Start запускает чтение очереди queueName, останавливая предыдущее чтение, если оно было
+func (c *Consumer) Start(ctx context.Context, queueName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+
+	consumeCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	c.cancel = cancel
+	c.done = done
+
+	go func() {
+		defer close(done)
+		c.consume(consumeCtx, queueName)
+	}()
+	c.logger.Info("Consuming notifications from queue: " + queueName)
+}
+
+func (c *Consumer) consume(ctx context.Context, queueName string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			msgChan, errChan := c.queue.Get(ctx, queueName)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg := <-msgChan:
+					notification := msg.Body
+					c.logger.Info(fmt.Sprintf("Sending notification: EventID=%s, Title=%s, UserID=%s, EventTime=%s",
+						notification.EventID, notification.Title, notification.UserID, notification.EventTime.Format("2006-01-02 15:04:05")))
+				case err := <-errChan:
+					if err != nil {
+						c.logger.Error("Error receiving message: " + err.Error())
+						return
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package internalgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/api"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/validate"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BatchMutateEvents - пакетное создание, изменение и удаление событий
+func (s *CalendarGRPCServer) BatchMutateEvents(ctx context.Context, req *api.BatchMutateEventsRequest) (*api.BatchMutateEventsResponse, error) {
+	s.logger.Info("gRPC BatchMutateEvents called")
+
+	if len(req.Operations) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "operations are required")
+	}
+	if len(req.Operations) > app.MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "too many operations: %d, max %d", len(req.Operations), app.MaxBatchSize)
+	}
+
+	ops := make([]storage.BatchOperation, 0, len(req.Operations))
+	for i, operation := range req.Operations {
+		op, err := batchOperationFromProto(operation)
+		if err != nil {
+			var errs validate.Errors
+			if errors.As(err, &errs) {
+				return nil, invalidArgument(errs.WithPrefix(fmt.Sprintf("operations[%d]", i)))
+			}
+			return nil, status.Errorf(codes.InvalidArgument, "operation %d: %s", i, err)
+		}
+		ops = append(ops, op)
+	}
+
+	results, err := s.app.BatchMutateEvents(ctx, ops, req.Mode == api.BatchMode_ALL_OR_NOTHING)
+	if err != nil {
+		s.logger.Error("Failed to apply batch: " + err.Error())
+		return nil, storageError(err, "failed to apply batch")
+	}
+
+	response := &api.BatchMutateEventsResponse{Results: make([]*api.BatchResult, 0, len(results))}
+	for i, result := range results {
+		item := &api.BatchResult{Index: int32(i), Id: result.ID, Version: result.Version}
+		if result.Err != nil {
+			st := status.Convert(storageError(result.Err, result.Err.Error()))
+			item.Code = int32(st.Code())
+			item.Error = st.Message()
+		} else {
+			response.Applied++
+		}
+		response.Results = append(response.Results, item)
+	}
+	return response, nil
+}
+
+func batchOperationFromProto(operation *api.BatchOperation) (storage.BatchOperation, error) {
+	switch op := operation.GetOperation().(type) {
+	case *api.BatchOperation_Create:
+		if err := op.Create.Validate(); err != nil {
+			return storage.BatchOperation{}, prefixed(err, "create")
+		}
+		return storage.BatchOperation{Type: storage.BatchCreate, Event: storage.Event{
+			ID:           uuid.New().String(),
+			Title:        op.Create.Title,
+			Description:  op.Create.Description,
+			StartTime:    op.Create.StartTime.AsTime(),
+			Duration:     calendar_types.CalendarDuration(op.Create.Duration.AsDuration()),
+			UserID:       op.Create.UserId,
+			CalendarID:   op.Create.CalendarId,
+			NotifyBefore: calendar_types.CalendarDuration(op.Create.NotifyBefore.AsDuration()),
+			Labels:       storage.Labels{Category: op.Create.Category, Color: op.Create.Color, Tags: op.Create.Tags},
+		}}, nil
+	case *api.BatchOperation_Update:
+		if err := op.Update.Validate(); err != nil {
+			return storage.BatchOperation{}, prefixed(err, "update")
+		}
+		return storage.BatchOperation{Type: storage.BatchUpdate, Event: storage.Event{
+			ID:           op.Update.Id,
+			Title:        op.Update.Title,
+			Description:  op.Update.Description,
+			StartTime:    op.Update.StartTime.AsTime(),
+			Duration:     calendar_types.CalendarDuration(op.Update.Duration.AsDuration()),
+			UserID:       op.Update.UserId,
+			NotifyBefore: calendar_types.CalendarDuration(op.Update.NotifyBefore.AsDuration()),
+			Labels:       storage.Labels{Category: op.Update.Category, Color: op.Update.Color, Tags: op.Update.Tags},
+			Version:      op.Update.ExpectedVersion,
+		}}, nil
+	case *api.BatchOperation_Delete:
+		if op.Delete.Id == "" {
+			return storage.BatchOperation{}, fmt.Errorf("id is required")
+		}
+		return storage.BatchOperation{Type: storage.BatchDelete, Event: storage.Event{
+			ID:      op.Delete.Id,
+			Version: op.Delete.ExpectedVersion,
+		}}, nil
+	default:
+		return storage.BatchOperation{}, fmt.Errorf("operation is empty")
+	}
+}
+
+// prefixed добавляет имя операции к полям ошибки валидации
+func prefixed(err error, prefix string) error {
+	var errs validate.Errors
+	if errors.As(err, &errs) {
+		return errs.WithPrefix(prefix)
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package internalgrpc
+
+import (
+	"context"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/api"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateCalendar - создание календаря, владельцем становится вызывающий пользователь
+func (s *CalendarGRPCServer) CreateCalendar(ctx context.Context, req *api.CreateCalendarRequest) (*api.Calendar, error) {
+	s.logger.Info("gRPC CreateCalendar called")
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	kind := storage.CalendarKind(req.Kind)
+	switch kind {
+	case "":
+		kind = storage.CalendarShared
+	case storage.CalendarPersonal, storage.CalendarTeam, storage.CalendarShared:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "kind must be personal, team or shared")
+	}
+
+	calendar, err := s.app.CreateCalendar(ctx, req.Name, kind)
+	if err != nil {
+		s.logger.Error("Failed to create calendar: " + err.Error())
+		return nil, storageError(err, "failed to create calendar")
+	}
+	return mapCalendarToProto(calendar, storage.RoleOwner), nil
+}
+
+// ListCalendars - календари, доступные вызывающему пользователю
+func (s *CalendarGRPCServer) ListCalendars(ctx context.Context, _ *api.ListCalendarsRequest) (*api.ListCalendarsResponse, error) {
+	s.logger.Info("gRPC ListCalendars called")
+
+	calendars, err := s.app.ListCalendars(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list calendars: " + err.Error())
+		return nil, storageError(err, "failed to list calendars")
+	}
+	protoCalendars := make([]*api.Calendar, 0, len(calendars))
+	for _, calendar := range calendars {
+		protoCalendars = append(protoCalendars, mapCalendarToProto(calendar.Calendar, calendar.Role))
+	}
+	return &api.ListCalendarsResponse{Calendars: protoCalendars}, nil
+}
+
+// ShareCalendar - выдача доступа к календарю пользователю или группе
+func (s *CalendarGRPCServer) ShareCalendar(ctx context.Context, req *api.ShareCalendarRequest) (*api.ShareCalendarResponse, error) {
+	s.logger.Info("gRPC ShareCalendar called")
+
+	if req.CalendarId == "" || req.Entry == nil {
+		return nil, status.Error(codes.InvalidArgument, "calendarId and entry are required")
+	}
+	granteeType, err := parseGranteeType(req.Entry.GranteeType)
+	if err != nil {
+		return nil, err
+	}
+	role := storage.Role(req.Entry.Role)
+	if req.Entry.GranteeId == "" || !role.Valid() {
+		return nil, status.Error(codes.InvalidArgument, "granteeId and a valid role are required")
+	}
+
+	err = s.app.ShareCalendar(ctx, storage.ACLEntry{
+		CalendarID:  req.CalendarId,
+		GranteeType: granteeType,
+		GranteeID:   req.Entry.GranteeId,
+		Role:        role,
+	})
+	if err != nil {
+		s.logger.Error("Failed to share calendar: " + err.Error())
+		return nil, storageError(err, "failed to share calendar")
+	}
+	return &api.ShareCalendarResponse{}, nil
+}
+
+// RevokeCalendarAccess - отзыв доступа к календарю
+func (s *CalendarGRPCServer) RevokeCalendarAccess(ctx context.Context, req *api.RevokeCalendarAccessRequest) (*api.RevokeCalendarAccessResponse, error) {
+	s.logger.Info("gRPC RevokeCalendarAccess called")
+
+	if req.CalendarId == "" || req.GranteeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "calendarId and granteeId are required")
+	}
+	granteeType, err := parseGranteeType(req.GranteeType)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.app.RevokeCalendarAccess(ctx, req.CalendarId, granteeType, req.GranteeId); err != nil {
+		s.logger.Error("Failed to revoke calendar access: " + err.Error())
+		return nil, storageError(err, "failed to revoke calendar access")
+	}
+	return &api.RevokeCalendarAccessResponse{}, nil
+}
+
+// ListCalendarAccess - выданные доступы к календарю, видны только владельцу
+func (s *CalendarGRPCServer) ListCalendarAccess(ctx context.Context, req *api.ListCalendarAccessRequest) (*api.ListCalendarAccessResponse, error) {
+	s.logger.Info("gRPC ListCalendarAccess called")
+
+	if req.CalendarId == "" {
+		return nil, status.Error(codes.InvalidArgument, "calendarId is required")
+	}
+
+	entries, err := s.app.ListCalendarAccess(ctx, req.CalendarId)
+	if err != nil {
+		s.logger.Error("Failed to list calendar access: " + err.Error())
+		return nil, storageError(err, "failed to list calendar access")
+	}
+	protoEntries := make([]*api.ACLEntry, 0, len(entries))
+	for _, entry := range entries {
+		protoEntries = append(protoEntries, &api.ACLEntry{
+			GranteeType: string(entry.GranteeType),
+			GranteeId:   entry.GranteeID,
+			Role:        string(entry.Role),
+		})
+	}
+	return &api.ListCalendarAccessResponse{Entries: protoEntries}, nil
+}
+
+func parseGranteeType(granteeType string) (storage.GranteeType, error) {
+	switch storage.GranteeType(granteeType) {
+	case storage.GranteeUser, storage.GranteeGroup:
+		return storage.GranteeType(granteeType), nil
+	default:
+		return "", status.Error(codes.InvalidArgument, "granteeType must be user or group")
+	}
+}
+
+func mapCalendarToProto(calendar storage.Calendar, role storage.Role) *api.Calendar {
+	return &api.Calendar{
+		Id:      calendar.ID,
+		Name:    calendar.Name,
+		Kind:    string(calendar.Kind),
+		OwnerId: calendar.OwnerID,
+		Role:    string(role),
+	}
+}
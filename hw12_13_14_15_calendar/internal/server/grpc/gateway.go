@@ -0,0 +1,225 @@
+package internalgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/api"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/etag"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	internalhttp "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server/http"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// IfMatchHeader - ключ метаданных с ETag, которым клиент подтверждает версию события.
+// Шлюз переносит сюда HTTP-заголовок If-Match; поле expectedVersion запроса важнее.
+const IfMatchHeader = "if-match"
+
+// inProcessBufferSize - буфер соединения шлюза с сервером внутри процесса
+const inProcessBufferSize = 1 << 20
+
+// GatewayHandler возвращает REST-обработчик, сгенерированный grpc-gateway по аннотациям
+// google.api.http из EventService.proto. Шлюз вызывает сервер через соединение внутри процесса
+// (bufconn), поэтому REST-запросы проходят ту же цепочку перехватчиков, что и вызовы gRPC.
+// Соединение закрывается, когда отменён ctx.
+func (s *CalendarGRPCServer) GatewayHandler(ctx context.Context) (http.Handler, error) {
+	listener := bufconn.Listen(inProcessBufferSize)
+	go func() {
+		if err := s.inProcess.Serve(listener); err != nil {
+			s.logger.Error("in-process grpc server failed: " + err.Error())
+		}
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///in-process",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect gateway: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeader),
+		runtime.WithMetadata(requestMetadata),
+		runtime.WithForwardResponseOption(setETag),
+		runtime.WithErrorHandler(gatewayError),
+	)
+	if err := api.RegisterCalendarServiceHandler(ctx, mux, conn); err != nil {
+		return nil, fmt.Errorf("failed to register gateway: %w", err)
+	}
+	return notModified(mux), nil
+}
+
+// incomingHeader передаёт серверу пользователя, его группы, ключ идемпотентности и If-Match
+// под теми же ключами, что и у клиентов gRPC; остальные заголовки - как по умолчанию
+func incomingHeader(header string) (string, bool) {
+	switch key := strings.ToLower(header); key {
+	case UserIDHeader, UserGroupsHeader, IdempotencyKeyHeader, IfMatchHeader:
+		return key, true
+	}
+	return runtime.DefaultHeaderMatcher(header)
+}
+
+// outgoingHeader возвращает клиенту Retry-After ограничителя; ID запроса HTTP-сервер уже вернул сам
+func outgoingHeader(key string) (string, bool) {
+	switch key {
+	case RetryAfterHeader:
+		return "Retry-After", true
+	case RequestIDHeader:
+		return "", false
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+// requestMetadata передаёт серверу ID, который запросу назначил HTTP-сервер,
+// чтобы в логах и журнале изменений был тот же ID, что и в заголовке ответа
+func requestMetadata(ctx context.Context, _ *http.Request) metadata.MD {
+	if requestID := requestctx.RequestID(ctx); requestID != "" {
+		return metadata.Pairs(RequestIDHeader, requestID)
+	}
+	return nil
+}
+
+// setETag возвращает версию события в заголовке ETag
+func setETag(_ context.Context, w http.ResponseWriter, resp proto.Message) error {
+	if event, ok := resp.(*api.EventResponse); ok {
+		w.Header().Set("ETag", etag.Format(event.Version))
+	}
+	return nil
+}
+
+// gatewayError отвечает на ошибку gRPC телом problem+json с HTTP-статусом по коду ошибки,
+// поля из деталей BadRequest попадают в invalid-params.
+// Конфликт версии при If-Match возвращается как 412 Precondition Failed, а не 409.
+// Текст внутренних ошибок клиенту не показывается.
+func gatewayError(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler,
+	w http.ResponseWriter, r *http.Request, err error,
+) {
+	st := status.Convert(err)
+	code := runtime.HTTPStatusFromCode(st.Code())
+	var httpErr *runtime.HTTPStatusError
+	if errors.As(err, &httpErr) {
+		st, code = status.Convert(httpErr.Err), httpErr.HTTPStatus
+	}
+	if st.Code() == codes.Aborted && r.Header.Get("If-Match") != "" {
+		code = http.StatusPreconditionFailed
+	}
+
+	problem := internalhttp.Problem{Status: code, RequestID: requestctx.RequestID(ctx)}
+	if code < http.StatusInternalServerError {
+		problem.Detail = st.Message()
+	}
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				problem.InvalidParams = append(problem.InvalidParams,
+					internalhttp.InvalidParam{Name: violation.Field, Reason: violation.Description})
+			}
+		}
+	}
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		for key, values := range md.HeaderMD {
+			if key == RequestIDHeader && problem.RequestID == "" && len(values) > 0 {
+				problem.RequestID = values[0]
+			}
+			if header, ok := outgoingHeader(key); ok {
+				for _, value := range values {
+					w.Header().Add(header, value)
+				}
+			}
+		}
+	}
+	internalhttp.SendProblem(w, r, problem)
+}
+
+// notModified отвечает на GET с If-None-Match кодом 304 без тела, если ETag ответа совпал с копией клиента
+func notModified(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch := r.Header.Get("If-None-Match")
+		if r.Method != http.MethodGet || ifNoneMatch == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(&notModifiedWriter{ResponseWriter: w, ifNoneMatch: ifNoneMatch}, r)
+	})
+}
+
+type notModifiedWriter struct {
+	http.ResponseWriter
+	ifNoneMatch string
+	wroteHeader bool
+	skipBody    bool
+}
+
+func (w *notModifiedWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code == http.StatusOK {
+		version, err := etag.Parse(w.Header().Get("ETag"))
+		if err == nil && etag.NoneMatch(w.ifNoneMatch, version) {
+			w.skipBody = true
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			code = http.StatusNotModified
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *notModifiedWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.skipBody {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// expectedVersion - версия из поля запроса или, если оно пустое, из метаданных if-match.
+// Если клиент перечислил несколько ETag, берётся текущая версия события id, когда она среди них.
+func (s *CalendarGRPCServer) expectedVersion(ctx context.Context, id string, fromRequest int64) (int64, error) {
+	if fromRequest != 0 {
+		return fromRequest, nil
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, nil
+	}
+	versions, err := etag.IfMatch(strings.Join(md.Get(IfMatchHeader), ","))
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "If-Match must contain event ETags: %v", err)
+	}
+	switch len(versions) {
+	case 0:
+		return 0, nil
+	case 1:
+		return versions[0], nil
+	}
+	event, err := s.app.GetEventByID(ctx, id)
+	if err != nil {
+		return 0, storageError(err, "failed to get event")
+	}
+	return etag.Expected(versions, event.Version), nil
+}
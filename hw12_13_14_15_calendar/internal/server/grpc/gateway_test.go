@@ -0,0 +1,399 @@
+package internalgrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	internalhttp "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server/http"
+	memorystorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type gatewayEvent struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	StartTime    string `json:"startTime"`
+	Duration     string `json:"duration"`
+	UserID       string `json:"userId"`
+	NotifyBefore string `json:"notifyBefore"`
+	CalendarID   string `json:"calendarId"`
+	Version      string `json:"version"`
+}
+
+// setupTestGateway поднимает шлюз с цепочкой перехватчиков по умолчанию и дополнительными extra
+func setupTestGateway(t *testing.T, extra ...grpc.UnaryServerInterceptor) (*httptest.Server, *app.App) {
+	t.Helper()
+	logg := logger.New("debug")
+	calendar := app.New(logg, memorystorage.New(logg))
+	interceptors, err := BuildInterceptors(logg, DefaultInterceptors, time.Minute)
+	require.NoError(t, err)
+	server := NewCalendarGRPCServer(logg, ":0", calendar, WithInterceptors(append(interceptors, extra...)...))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler, err := server.GatewayHandler(ctx)
+	require.NoError(t, err)
+	ts := httptest.NewServer(handler)
+	t.Cleanup(func() {
+		ts.Close()
+		cancel()
+		_ = server.Stop(context.Background())
+	})
+	return ts, calendar
+}
+
+// gatewayDo выполняет запрос с заголовками headers, переданными парами "имя", "значение"
+func gatewayDo(t *testing.T, method, url string, body any, headers ...string) *http.Response {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestGatewayCRUD(t *testing.T) {
+	ts, _ := setupTestGateway(t)
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	user := []string{"X-User-ID", "user123"}
+
+	resp := gatewayDo(t, http.MethodPost, ts.URL+"/v1/events", map[string]any{
+		"title":        "Gateway Event",
+		"userId":       "user123",
+		"startTime":    start.Format(time.RFC3339),
+		"duration":     "3600s",
+		"notifyBefore": "900s",
+	}, user...)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var created gatewayEvent
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "Gateway Event", created.Title)
+	assert.Equal(t, "3600s", created.Duration)
+
+	resp = gatewayDo(t, http.MethodGet, ts.URL+"/v1/events/"+created.ID, nil, user...)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = gatewayDo(t, http.MethodGet, ts.URL+"/v1/events:day?date="+start.Format(time.RFC3339), nil, user...)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var list struct {
+		Events []gatewayEvent `json:"events"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	require.Len(t, list.Events, 1)
+	assert.Equal(t, created.ID, list.Events[0].ID)
+
+	resp = gatewayDo(t, http.MethodDelete, ts.URL+"/v1/events/"+created.ID, nil, user...)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = gatewayDo(t, http.MethodGet, ts.URL+"/v1/events/"+created.ID, nil, user...)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestGatewayRequiresUser(t *testing.T) {
+	ts, calendar := setupTestGateway(t)
+	ctx := requestctx.WithActor(context.Background(), "alice")
+	require.NoError(t, calendar.CreateEvent(ctx, "alice-event", "Private", "", "alice",
+		time.Now().Add(time.Hour), calendar_types.CalendarDuration(time.Hour), 0))
+
+	// Без X-User-ID запрос анонимный: ни чтения, ни изменений, ни поиска
+	requests := []struct {
+		method, path string
+		body         any
+	}{
+		{http.MethodGet, "/v1/events/alice-event", nil},
+		{http.MethodGet, "/v1/events/alice-event/history", nil},
+		{http.MethodDelete, "/v1/events/alice-event", nil},
+		{http.MethodGet, "/v1/events:search?query=private", nil},
+		{http.MethodPost, "/v1/events", map[string]any{
+			"title":     "Anonymous",
+			"userId":    "alice",
+			"startTime": time.Now().Add(time.Hour).Format(time.RFC3339),
+			"duration":  "3600s",
+		}},
+	}
+	for _, r := range requests {
+		resp := gatewayDo(t, r.method, ts.URL+r.path, r.body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "%s %s", r.method, r.path)
+	}
+
+	_, err := calendar.GetEventByID(requestctx.WithActor(context.Background(), "alice"), "alice-event")
+	assert.NoError(t, err, "event must survive anonymous requests")
+}
+
+func TestGatewayValidationError(t *testing.T) {
+	ts, _ := setupTestGateway(t)
+
+	resp, err := http.Post(ts.URL+"/v1/events", "application/json", bytes.NewReader([]byte(`{"userId":"user123"}`)))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	problem := decodeProblem(t, resp)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	var names []string
+	for _, param := range problem.InvalidParams {
+		assert.NotEmpty(t, param.Reason)
+		names = append(names, param.Name)
+	}
+	assert.ElementsMatch(t, []string{"title", "startTime", "duration"}, names)
+}
+
+// decodeProblem проверяет, что ответ - problem+json, и разбирает его тело
+func decodeProblem(t *testing.T, resp *http.Response) internalhttp.Problem {
+	t.Helper()
+	assert.Equal(t, internalhttp.ProblemContentType, resp.Header.Get("Content-Type"))
+	var problem internalhttp.Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	return problem
+}
+
+func TestGatewayProblem(t *testing.T) {
+	ts, calendar := setupTestGateway(t)
+	require.NoError(t, calendar.CreateEvent(requestctx.WithActor(context.Background(), "user123"),
+		"planning", "Planning", "", "user123", time.Now().Add(time.Hour), calendar_types.CalendarDuration(time.Hour), 0))
+
+	resp := gatewayDo(t, http.MethodGet, ts.URL+"/v1/events/missing", nil, "X-User-ID", "user123")
+	defer resp.Body.Close()
+	problem := decodeProblem(t, resp)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "about:blank", problem.Type)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "event not found", problem.Detail)
+	assert.NotEmpty(t, problem.RequestID)
+
+	// Конфликт версии при If-Match - 412, как и у остальных обработчиков
+	resp = gatewayDo(t, http.MethodPut, ts.URL+"/v1/events/planning", map[string]any{
+		"title":     "Moved",
+		"userId":    "user123",
+		"startTime": time.Now().Add(2 * time.Hour).Format(time.RFC3339),
+		"duration":  "3600s",
+	}, "If-Match", `"5"`, "X-User-ID", "user123")
+	defer resp.Body.Close()
+	problem = decodeProblem(t, resp)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	assert.Equal(t, http.StatusPreconditionFailed, problem.Status)
+}
+
+func TestGatewayETags(t *testing.T) {
+	ts, calendar := setupTestGateway(t)
+
+	eventID := "test-event-etag"
+	err := calendar.CreateEvent(
+		requestctx.WithActor(context.Background(), "user123"),
+		eventID, "Original Title", "Original Description", "user123",
+		time.Now().Add(time.Hour),
+		calendar_types.CalendarDuration(time.Hour),
+		calendar_types.CalendarDuration(15*time.Minute),
+	)
+	require.NoError(t, err)
+	url := ts.URL + "/v1/events/" + eventID
+
+	resp := gatewayDo(t, http.MethodGet, url, nil, "X-User-ID", "user123")
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+
+	// Актуальная копия у клиента - тело не нужно
+	resp = gatewayDo(t, http.MethodGet, url, nil, "If-None-Match", etag, "X-User-ID", "user123")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Empty(t, body)
+
+	update := func(ifMatch, title string) *http.Response {
+		resp := gatewayDo(t, http.MethodPut, url, map[string]any{
+			"title":     title,
+			"userId":    "user123",
+			"startTime": time.Now().Add(2 * time.Hour).Format(time.RFC3339),
+			"duration":  "3600s",
+		}, "If-Match", ifMatch, "X-User-ID", "user123")
+		resp.Body.Close()
+		return resp
+	}
+
+	resp = update(etag, "First")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+
+	// Второй клиент всё ещё держит версию 1
+	resp = update(etag, "Second")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp = update("not-an-etag", "Third")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// If-Match может перечислять несколько ETag: хватает совпадения с одним из них
+	resp = update(`"5", "1"`, "Fourth")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	resp = update(`"1", W/"2"`, "Fifth")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
+
+	resp = gatewayDo(t, http.MethodDelete, url, nil, "If-Match", etag, "X-User-ID", "user123")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp = gatewayDo(t, http.MethodDelete, url, nil, "If-Match", `"2", "3"`, "X-User-ID", "user123")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGatewayIdempotencyKey(t *testing.T) {
+	ts, _ := setupTestGateway(t)
+
+	event := map[string]any{
+		"title":     "Retried",
+		"userId":    "user123",
+		"startTime": time.Now().Add(time.Hour).Format(time.RFC3339),
+		"duration":  "3600s",
+	}
+	create := func() gatewayEvent {
+		resp := gatewayDo(t, http.MethodPost, ts.URL+"/v1/events", event, "Idempotency-Key", "retry-1", "X-User-ID", "user123")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var created gatewayEvent
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		return created
+	}
+	assert.Equal(t, create().ID, create().ID)
+}
+
+func TestGatewayEventHistory(t *testing.T) {
+	ts, _ := setupTestGateway(t)
+
+	event := map[string]any{
+		"title":     "Original",
+		"userId":    "alice",
+		"startTime": time.Now().Add(time.Hour).Format(time.RFC3339),
+		"duration":  "3600s",
+	}
+	resp := gatewayDo(t, http.MethodPost, ts.URL+"/v1/events", event, "X-User-ID", "alice")
+	var created gatewayEvent
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	resp.Body.Close()
+
+	event["title"] = "Updated"
+	resp = gatewayDo(t, http.MethodPut, ts.URL+"/v1/events/"+created.ID, event, "X-User-ID", "alice")
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = gatewayDo(t, http.MethodGet, ts.URL+"/v1/events/"+created.ID+"/history", nil, "X-User-ID", "alice")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var history struct {
+		Records []struct {
+			Action    string        `json:"action"`
+			Actor     string        `json:"actor"`
+			RequestID string        `json:"requestId"`
+			Before    *gatewayEvent `json:"before"`
+			After     *gatewayEvent `json:"after"`
+		} `json:"records"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&history))
+	require.Len(t, history.Records, 2)
+	for _, record := range history.Records {
+		assert.Equal(t, "alice", record.Actor)
+		assert.NotEmpty(t, record.RequestID)
+	}
+	require.NotNil(t, history.Records[1].Before)
+	assert.Equal(t, "Original", history.Records[1].Before.Title)
+	assert.Equal(t, "Updated", history.Records[1].After.Title)
+}
+
+func TestGatewayCalendarSharing(t *testing.T) {
+	ts, _ := setupTestGateway(t)
+
+	resp := gatewayDo(t, http.MethodPost, ts.URL+"/v1/calendars", map[string]any{"name": "Team", "kind": "team"},
+		"X-User-ID", "alice")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var team struct {
+		ID      string `json:"id"`
+		OwnerID string `json:"ownerId"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&team))
+	resp.Body.Close()
+	assert.Equal(t, "alice", team.OwnerID)
+
+	resp = gatewayDo(t, http.MethodPost, ts.URL+"/v1/events", map[string]any{
+		"title":      "Salary review",
+		"userId":     "alice",
+		"calendarId": team.ID,
+		"startTime":  time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC).Format(time.RFC3339),
+		"duration":   "3600s",
+	}, "X-User-ID", "alice")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var event gatewayEvent
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&event))
+	resp.Body.Close()
+	assert.Equal(t, team.ID, event.CalendarID)
+
+	resp = gatewayDo(t, http.MethodGet, ts.URL+"/v1/events/"+event.ID, nil, "X-User-ID", "bob")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	grant := map[string]any{"granteeType": "group", "granteeId": "devs", "role": "write"}
+	resp = gatewayDo(t, http.MethodPut, ts.URL+"/v1/calendars/"+team.ID+"/acl", grant, "X-User-ID", "bob")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = gatewayDo(t, http.MethodPut, ts.URL+"/v1/calendars/"+team.ID+"/acl", grant, "X-User-ID", "alice")
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Группы пользователя шлюз передаёт заголовком X-User-Groups
+	resp = gatewayDo(t, http.MethodGet, ts.URL+"/v1/events/"+event.ID, nil, "X-User-ID", "bob", "X-User-Groups", "devs, qa")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGatewayRateLimit(t *testing.T) {
+	policy := ratelimit.Policy{
+		Limiter: ratelimit.NewMemoryLimiter(),
+		Rules:   map[string]ratelimit.Rule{ratelimit.ClassRead: {Limit: 1, Per: time.Minute}},
+	}
+	ts, _ := setupTestGateway(t, RateLimitInterceptor(ratelimit.NewSwitch(policy), logger.New("error")))
+
+	// Шлюз вызывает сервер через перехватчики, поэтому лимит gRPC действует и на REST
+	resp := gatewayDo(t, http.MethodGet, ts.URL+"/v1/events/missing", nil, "X-User-ID", "alice")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = gatewayDo(t, http.MethodGet, ts.URL+"/v1/events/missing", nil, "X-User-ID", "alice")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+
+	resp = gatewayDo(t, http.MethodGet, ts.URL+"/v1/events/missing", nil, "X-User-ID", "bob")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -2,17 +2,25 @@ package internalgrpc
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/api"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/validate"
 	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -23,50 +31,129 @@ type CalendarGRPCServer struct {
 	logger     server.Logger
 	port       string
 	grpcServer *grpc.Server
-	app        server.Application
+	// inProcess обслуживает REST-шлюз через bufconn: та же цепочка перехватчиков, но без TLS
+	inProcess *grpc.Server
+	app       server.Application
 }
 
-func NewCalendarGRPCServer(logger server.Logger, port string, app server.Application) *CalendarGRPCServer {
-	return &CalendarGRPCServer{
-		port:   port,
-		logger: logger,
-		app:    app,
+type options struct {
+	interceptors []grpc.UnaryServerInterceptor
+	reflection   bool
+	tls          *tls.Config
+}
+
+// Option настраивает gRPC сервер при создании
+type Option func(*options)
+
+// WithInterceptors задаёт цепочку перехватчиков в порядке вызова
+func WithInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors, interceptors...)
 	}
 }
 
-func (s *CalendarGRPCServer) Stop(_ context.Context) error {
-	if s.grpcServer != nil {
-		s.grpcServer.GracefulStop()
+// WithReflection включает server reflection, чтобы сервер можно было исследовать через grpcurl
+func WithReflection(enabled bool) Option {
+	return func(o *options) {
+		o.reflection = enabled
 	}
-	return nil
 }
 
-func (s *CalendarGRPCServer) Start(_ context.Context) error {
-	lis, err := net.Listen("tcp", s.port)
-	if err != nil {
-		return fmt.Errorf("failed to listen: %v", err)
+// WithTLS включает TLS; если в конфигурации заданы ClientCAs, клиенты обязаны предъявить сертификат
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+	}
+}
+
+func NewCalendarGRPCServer(logger server.Logger, port string, app server.Application, opts ...Option) *CalendarGRPCServer {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	s.grpcServer = grpc.NewServer()
+	chain := grpc.ChainUnaryInterceptor(o.interceptors...)
+	serverOpts := []grpc.ServerOption{chain}
+	if o.tls != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(o.tls)))
+	}
+	s := &CalendarGRPCServer{
+		port:       port,
+		logger:     logger,
+		app:        app,
+		grpcServer: grpc.NewServer(serverOpts...),
+		inProcess:  grpc.NewServer(chain),
+	}
 	api.RegisterCalendarServiceServer(s.grpcServer, s)
+	api.RegisterCalendarServiceServer(s.inProcess, s)
+	if o.reflection {
+		reflection.Register(s.grpcServer)
+	}
+	return s
+}
 
-	s.logger.Info("gRPC server started on port " + s.port)
-	if err := s.grpcServer.Serve(lis); err != nil {
-		return fmt.Errorf("failed to serve: %v", err)
+// Stop дожидается завершения активных вызовов, а по истечении контекста обрывает их
+func (s *CalendarGRPCServer) Stop(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		s.inProcess.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		s.inProcess.Stop()
+		return fmt.Errorf("grpc server forced to stop: %w", ctx.Err())
+	}
+}
+
+// Start слушает порт и обслуживает запросы, пока не отменён контекст или сервер не упал
+func (s *CalendarGRPCServer) Start(ctx context.Context) error {
+	address := s.port
+	if !strings.Contains(address, ":") {
+		address = ":" + address
+	}
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.grpcServer.Serve(lis)
+	}()
+
+	s.logger.Info("gRPC server started on " + lis.Addr().String())
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-serveErr:
+		if err != nil {
+			return fmt.Errorf("failed to serve: %w", err)
+		}
+		return nil
 	}
-	return nil
 }
 
 // CreateEvent - создание нового события
 func (s *CalendarGRPCServer) CreateEvent(ctx context.Context, req *api.CreateEventRequest) (*api.EventResponse, error) {
 	s.logger.Info("gRPC CreateEvent called")
 
-	if req.Title == "" {
-		return nil, status.Error(codes.InvalidArgument, "title is required")
+	// Проверка дублирует перехватчик валидации: цепочку можно настроить без него
+	if err := req.Validate(); err != nil {
+		return nil, invalidArgument(err)
 	}
 
-	if req.UserId == "" {
-		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	// Поле проверено в Validate, а ключ из метаданных - здесь
+	key := idempotencyKey(ctx, req)
+	if len(key) > api.MaxIdempotencyKeyLength {
+		return nil, status.Errorf(codes.InvalidArgument, "%s must be at most %d characters long",
+			IdempotencyKeyHeader, api.MaxIdempotencyKeyLength)
 	}
 
 	id := uuid.New().String()
@@ -74,61 +161,67 @@ func (s *CalendarGRPCServer) CreateEvent(ctx context.Context, req *api.CreateEve
 	duration := calendar_types.CalendarDuration(req.Duration.AsDuration())
 	notifyBefore := calendar_types.CalendarDuration(req.NotifyBefore.AsDuration())
 
-	err := s.app.CreateEvent(
+	event, err := s.app.CreateEventOnce(
 		ctx,
-		id, req.Title, req.Description, req.UserId,
+		key, req.CalendarId, id, req.Title, req.Description, req.UserId,
 		startTime, duration, notifyBefore,
+		storage.Labels{Category: req.Category, Color: req.Color, Tags: req.Tags},
 	)
 	if err != nil {
 		s.logger.Error("Failed to create event: " + err.Error())
-		return nil, status.Error(codes.Internal, "failed to create event")
-	}
-
-	// Получаем созданное событие для ответа
-	event, err := s.app.GetEventByID(ctx, id)
-	if err != nil {
-		s.logger.Error("Failed to get created event: " + err.Error())
-		return nil, status.Error(codes.Internal, "failed to get created event")
+		return nil, storageError(err, "failed to create event")
 	}
 
 	return mapStorageEventToProtoEvent(event), nil
 }
 
+// idempotencyKey - ключ идемпотентности из поля requestId или, если оно пустое, из метаданных
+func idempotencyKey(ctx context.Context, req *api.CreateEventRequest) string {
+	if req.RequestId != "" {
+		return req.RequestId
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(IdempotencyKeyHeader); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
 // UpdateEvent - обновление существующего события
 func (s *CalendarGRPCServer) UpdateEvent(ctx context.Context, req *api.UpdateEventRequest) (*api.EventResponse, error) {
 	s.logger.Info("gRPC UpdateEvent called")
 
-	if req.Id == "" {
-		return nil, status.Error(codes.InvalidArgument, "id is required")
+	if err := req.Validate(); err != nil {
+		return nil, invalidArgument(err)
 	}
 
-	if req.Title == "" {
-		return nil, status.Error(codes.InvalidArgument, "title is required")
-	}
-
-	if req.UserId == "" {
-		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	version, err := s.expectedVersion(ctx, req.Id, req.ExpectedVersion)
+	if err != nil {
+		return nil, err
 	}
 
 	startTime := req.StartTime.AsTime()
 	duration := calendar_types.CalendarDuration(req.Duration.AsDuration())
 	notifyBefore := calendar_types.CalendarDuration(req.NotifyBefore.AsDuration())
 
-	err := s.app.UpdateEvent(
+	err = s.app.UpdateEvent(
 		ctx,
 		req.Id, req.Title, req.Description, req.UserId,
 		startTime, duration, notifyBefore,
+		storage.Labels{Category: req.Category, Color: req.Color, Tags: req.Tags},
+		version,
 	)
 	if err != nil {
 		s.logger.Error("Failed to update event: " + err.Error())
-		return nil, status.Error(codes.Internal, "failed to update event")
+		return nil, storageError(err, "failed to update event")
 	}
 
 	// Получаем обновлённое событие
 	event, err := s.app.GetEventByID(ctx, req.Id)
 	if err != nil {
 		s.logger.Error("Failed to get updated event: " + err.Error())
-		return nil, status.Error(codes.Internal, "failed to get updated event")
+		return nil, storageError(err, "failed to get updated event")
 	}
 
 	return mapStorageEventToProtoEvent(event), nil
@@ -142,15 +235,115 @@ func (s *CalendarGRPCServer) DeleteEvent(ctx context.Context, req *api.DeleteEve
 		return nil, status.Error(codes.InvalidArgument, "id is required")
 	}
 
-	err := s.app.DeleteEvent(ctx, req.Id)
+	version, err := s.expectedVersion(ctx, req.Id, req.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.app.DeleteEvent(ctx, req.Id, version)
 	if err != nil {
 		s.logger.Error("Failed to delete event: " + err.Error())
-		return nil, status.Error(codes.Internal, "failed to delete event")
+		return nil, storageError(err, "failed to delete event")
 	}
 
 	return &api.DeleteEventResponse{Success: true}, nil
 }
 
+// ListDeletedEvents - получение событий из корзины
+func (s *CalendarGRPCServer) ListDeletedEvents(ctx context.Context, req *api.ListDeletedEventsRequest) (*api.ListEventsResponse, error) {
+	s.logger.Info("gRPC ListDeletedEvents called")
+	events, err := s.app.ListDeletedEvents(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list deleted events: " + err.Error())
+		return nil, storageError(err, "failed to list deleted events")
+	}
+	protoEvents := make([]*api.EventResponse, 0, len(events))
+	for _, event := range events {
+		protoEvents = append(protoEvents, mapStorageEventToProtoEvent(event))
+	}
+	return &api.ListEventsResponse{Events: protoEvents}, nil
+}
+
+// RestoreEvent - восстановление события из корзины
+func (s *CalendarGRPCServer) RestoreEvent(ctx context.Context, req *api.RestoreEventRequest) (*api.EventResponse, error) {
+	s.logger.Info("gRPC RestoreEvent called")
+
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	if err := s.app.RestoreEvent(ctx, req.Id); err != nil {
+		s.logger.Error("Failed to restore event: " + err.Error())
+		return nil, storageError(err, "failed to restore event")
+	}
+
+	event, err := s.app.GetEventByID(ctx, req.Id)
+	if err != nil {
+		s.logger.Error("Failed to get restored event: " + err.Error())
+		return nil, storageError(err, "failed to get restored event")
+	}
+
+	return mapStorageEventToProtoEvent(event), nil
+}
+
+// SearchEvents - полнотекстовый поиск событий
+func (s *CalendarGRPCServer) SearchEvents(ctx context.Context, req *api.SearchEventsRequest) (*api.SearchEventsResponse, error) {
+	s.logger.Info("gRPC SearchEvents called")
+
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	if req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	query := storage.SearchQuery{
+		Text:   req.Query,
+		UserID: req.UserId,
+		Limit:  int(req.Limit),
+	}
+	if req.From != nil {
+		query.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		query.To = req.To.AsTime()
+	}
+
+	results, err := s.app.SearchEvents(ctx, query)
+	if err != nil {
+		s.logger.Error("Failed to search events: " + err.Error())
+		return nil, storageError(err, "failed to search events")
+	}
+	protoResults := make([]*api.SearchResult, 0, len(results))
+	for _, result := range results {
+		protoResults = append(protoResults, &api.SearchResult{
+			Event: mapStorageEventToProtoEvent(result.Event),
+			Rank:  result.Rank,
+		})
+	}
+	return &api.SearchEventsResponse{Results: protoResults}, nil
+}
+
+// GetEventHistory - журнал изменений события
+func (s *CalendarGRPCServer) GetEventHistory(ctx context.Context, req *api.GetEventHistoryRequest) (*api.EventHistoryResponse, error) {
+	s.logger.Info("gRPC GetEventHistory called")
+
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	records, err := s.app.GetEventHistory(ctx, req.Id)
+	if err != nil {
+		s.logger.Error("Failed to get event history: " + err.Error())
+		return nil, storageError(err, "failed to get event history")
+	}
+	protoRecords := make([]*api.AuditRecord, 0, len(records))
+	for _, record := range records {
+		protoRecords = append(protoRecords, mapAuditRecordToProto(record))
+	}
+	return &api.EventHistoryResponse{Records: protoRecords}, nil
+}
+
 // GetEvent - получение события по ID
 func (s *CalendarGRPCServer) GetEvent(ctx context.Context, req *api.GetEventRequest) (*api.EventResponse, error) {
 	s.logger.Info("gRPC GetEvent called")
@@ -162,7 +355,7 @@ func (s *CalendarGRPCServer) GetEvent(ctx context.Context, req *api.GetEventRequ
 	event, err := s.app.GetEventByID(ctx, req.Id)
 	if err != nil {
 		s.logger.Error("Failed to get event: " + err.Error())
-		return nil, status.Error(codes.NotFound, "event not found")
+		return nil, storageError(err, "failed to get event")
 	}
 
 	return mapStorageEventToProtoEvent(event), nil
@@ -174,10 +367,10 @@ func (s *CalendarGRPCServer) ListEventsForDay(ctx context.Context, req *api.List
 	if req.Date == nil {
 		return nil, status.Error(codes.InvalidArgument, "date is required")
 	}
-	events, err := s.app.ListEventsForDay(ctx, req.Date.AsTime())
+	events, err := s.app.ListEventsForDay(ctx, req.Date.AsTime(), req.Tags)
 	if err != nil {
 		s.logger.Error("Failed to list events for day: " + err.Error())
-		return nil, status.Error(codes.Internal, "failed to list events for day")
+		return nil, storageError(err, "failed to list events for day")
 	}
 	protoEvents := make([]*api.EventResponse, 0, len(events))
 	for _, event := range events {
@@ -192,10 +385,10 @@ func (s *CalendarGRPCServer) ListEventsForWeek(ctx context.Context, req *api.Lis
 	if req.Date == nil {
 		return nil, status.Error(codes.InvalidArgument, "date is required")
 	}
-	events, err := s.app.ListEventsForWeek(ctx, req.Date.AsTime())
+	events, err := s.app.ListEventsForWeek(ctx, req.Date.AsTime(), req.Tags)
 	if err != nil {
 		s.logger.Error("Failed to list events for week: " + err.Error())
-		return nil, status.Error(codes.Internal, "failed to list events for week")
+		return nil, storageError(err, "failed to list events for week")
 	}
 	protoEvents := make([]*api.EventResponse, 0, len(events))
 	for _, event := range events {
@@ -210,10 +403,10 @@ func (s *CalendarGRPCServer) ListEventsForMonth(ctx context.Context, req *api.Li
 	if req.Date == nil {
 		return nil, status.Error(codes.InvalidArgument, "date is required")
 	}
-	events, err := s.app.ListEventsForMonth(ctx, req.Date.AsTime())
+	events, err := s.app.ListEventsForMonth(ctx, req.Date.AsTime(), req.Tags)
 	if err != nil {
 		s.logger.Error("Failed to list events for month: " + err.Error())
-		return nil, status.Error(codes.Internal, "failed to list events for month")
+		return nil, storageError(err, "failed to list events for month")
 	}
 	protoEvents := make([]*api.EventResponse, 0, len(events))
 	for _, event := range events {
@@ -223,6 +416,10 @@ func (s *CalendarGRPCServer) ListEventsForMonth(ctx context.Context, req *api.Li
 }
 
 func mapStorageEventToProtoEvent(event storage.Event) *api.EventResponse {
+	var deletedAt *timestamppb.Timestamp
+	if event.DeletedAt != nil {
+		deletedAt = timestamppb.New(*event.DeletedAt)
+	}
 	return &api.EventResponse{
 		Id:           event.ID,
 		Title:        event.Title,
@@ -231,5 +428,104 @@ func mapStorageEventToProtoEvent(event storage.Event) *api.EventResponse {
 		Description:  event.Description,
 		UserId:       event.UserID,
 		NotifyBefore: durationpb.New(time.Duration(event.NotifyBefore)),
+		Version:      event.Version,
+		DeletedAt:    deletedAt,
+		CalendarId:   event.CalendarID,
+		Category:     event.Category,
+		Color:        event.Color,
+		Tags:         event.Tags,
+	}
+}
+
+// GetTagStats - сколькими событиями отмечен каждый тег
+func (s *CalendarGRPCServer) GetTagStats(ctx context.Context, req *api.GetTagStatsRequest) (*api.TagStatsResponse, error) {
+	s.logger.Info("gRPC GetTagStats called")
+
+	var from, to time.Time
+	if req.From != nil {
+		from = req.From.AsTime()
+	}
+	if req.To != nil {
+		to = req.To.AsTime()
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return nil, status.Error(codes.InvalidArgument, "to must be after from")
+	}
+
+	stats, err := s.app.TagStats(ctx, from, to, int(req.Limit))
+	if err != nil {
+		s.logger.Error("Failed to count tags: " + err.Error())
+		return nil, storageError(err, "failed to count tags")
+	}
+	response := &api.TagStatsResponse{Tags: make([]*api.TagStat, 0, len(stats))}
+	for _, stat := range stats {
+		response.Tags = append(response.Tags, &api.TagStat{Tag: stat.Tag, Count: int32(stat.Count)})
+	}
+	return response, nil
+}
+
+func mapAuditRecordToProto(record storage.AuditRecord) *api.AuditRecord {
+	protoRecord := &api.AuditRecord{
+		Id:        record.ID,
+		EventId:   record.EventID,
+		Action:    string(record.Action),
+		Actor:     record.Actor,
+		RequestId: record.RequestID,
+		CreatedAt: timestamppb.New(record.CreatedAt),
+	}
+	if record.Before != nil {
+		protoRecord.Before = mapStorageEventToProtoEvent(*record.Before)
+	}
+	if record.After != nil {
+		protoRecord.After = mapStorageEventToProtoEvent(*record.After)
+	}
+	return protoRecord
+}
+
+// invalidArgument - статус InvalidArgument; ошибки валидации полей передаются в деталях BadRequest
+func invalidArgument(err error) error {
+	st := status.New(codes.InvalidArgument, err.Error())
+	var errs validate.Errors
+	if !errors.As(err, &errs) {
+		return st.Err()
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, fieldErr := range errs {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldErr.Field,
+			Description: fieldErr.Message,
+		})
+	}
+	if detailed, detailsErr := st.WithDetails(badRequest); detailsErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// storageError переводит ошибки хранилища в gRPC-статусы
+func storageError(err error, msg string) error {
+	switch {
+	case errors.Is(err, storage.ErrEventNotFound):
+		return status.Error(codes.NotFound, "event not found")
+	case errors.Is(err, storage.ErrVersionConflict):
+		return status.Error(codes.Aborted, "event was modified concurrently, reload it and retry")
+	case errors.Is(err, storage.ErrEventExists):
+		return status.Error(codes.AlreadyExists, "event already exists")
+	case errors.Is(err, storage.ErrBatchAborted):
+		return status.Error(codes.Aborted, "batch aborted because another operation failed")
+	case errors.Is(err, storage.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, "user is not specified")
+	case errors.Is(err, storage.ErrForbidden):
+		return status.Error(codes.PermissionDenied, "not enough rights for the calendar")
+	case errors.Is(err, storage.ErrCalendarNotFound):
+		return status.Error(codes.NotFound, "calendar not found")
+	case errors.Is(err, storage.ErrGrantNotFound):
+		return status.Error(codes.NotFound, "access grant not found")
+	case errors.Is(err, storage.ErrCalendarExists):
+		return status.Error(codes.AlreadyExists, "calendar already exists")
+	case errors.Is(err, storage.ErrIdempotencyKeyReused):
+		return status.Error(codes.InvalidArgument, "request id was already used for a different request")
+	default:
+		return status.Error(codes.Internal, msg)
 	}
 }
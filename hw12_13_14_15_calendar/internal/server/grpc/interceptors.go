@@ -0,0 +1,167 @@
+package internalgrpc
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Ключи метаданных с идентификатором запроса, пользователем, от имени которого он выполняется,
+// и группами этого пользователя через запятую
+const (
+	RequestIDHeader  = "x-request-id"
+	UserIDHeader     = "x-user-id"
+	UserGroupsHeader = "x-user-groups"
+	// Ключ идемпотентности CreateEvent, если клиент не заполнил поле requestId
+	IdempotencyKeyHeader = "idempotency-key"
+)
+
+// Имена перехватчиков, которые можно перечислить в конфигурации
+const (
+	InterceptorRequestID  = "request-id"
+	InterceptorActor      = "actor"
+	InterceptorLogging    = "logging"
+	InterceptorRecovery   = "recovery"
+	InterceptorDeadline   = "deadline"
+	InterceptorValidation = "validation"
+)
+
+// DefaultInterceptors - порядок цепочки по умолчанию: сначала присваиваем запросу ID,
+// чтобы он попал в логи, а recovery ставим внутрь logging, чтобы паника логировалась как Internal.
+var DefaultInterceptors = []string{
+	InterceptorRequestID,
+	InterceptorActor,
+	InterceptorLogging,
+	InterceptorRecovery,
+	InterceptorDeadline,
+	InterceptorValidation,
+}
+
+// BuildInterceptors собирает цепочку перехватчиков по именам из конфигурации
+func BuildInterceptors(logger server.Logger, names []string, timeout time.Duration) ([]grpc.UnaryServerInterceptor, error) {
+	interceptors := make([]grpc.UnaryServerInterceptor, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case InterceptorRequestID:
+			interceptors = append(interceptors, RequestIDInterceptor())
+		case InterceptorActor:
+			interceptors = append(interceptors, ActorInterceptor())
+		case InterceptorLogging:
+			interceptors = append(interceptors, LoggingInterceptor(logger))
+		case InterceptorRecovery:
+			interceptors = append(interceptors, RecoveryInterceptor(logger))
+		case InterceptorDeadline:
+			interceptors = append(interceptors, DeadlineInterceptor(timeout))
+		case InterceptorValidation:
+			interceptors = append(interceptors, ValidationInterceptor())
+		default:
+			return nil, fmt.Errorf("unknown grpc interceptor: %s", name)
+		}
+	}
+	return interceptors, nil
+}
+
+// RequestIDInterceptor берёт ID запроса из метаданных клиента или генерирует новый,
+// кладёт его в контекст и возвращает клиенту в заголовке ответа
+func RequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		requestID := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(RequestIDHeader); len(values) > 0 {
+				requestID = values[0]
+			}
+		}
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+
+		// Заголовок можно отправить только внутри настоящего gRPC-вызова
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, requestID))
+		return handler(requestctx.WithRequestID(ctx, requestID), req)
+	}
+}
+
+// ActorInterceptor кладёт в контекст пользователя и его группы из метаданных клиента
+func ActorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(UserIDHeader); len(values) > 0 && values[0] != "" {
+				ctx = requestctx.WithActor(ctx, values[0])
+			}
+			if values := md.Get(UserGroupsHeader); len(values) > 0 {
+				if groups := requestctx.ParseGroups(strings.Join(values, ",")); len(groups) > 0 {
+					ctx = requestctx.WithGroups(ctx, groups)
+				}
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// LoggingInterceptor пишет в лог метод, код ответа и длительность каждого вызова
+func LoggingInterceptor(logger server.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		line := fmt.Sprintf("grpc %s %s %dms request_id=%s",
+			info.FullMethod, code, time.Since(start).Milliseconds(), requestctx.RequestID(ctx))
+		switch code {
+		case codes.OK:
+			logger.Info(line)
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			logger.Error(line + ": " + err.Error())
+		default:
+			logger.Warn(line + ": " + err.Error())
+		}
+		return resp, err
+	}
+}
+
+// RecoveryInterceptor превращает панику в обработчике в ответ с кодом Internal
+func RecoveryInterceptor(logger server.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error(fmt.Sprintf("grpc panic in %s: %v\n%s", info.FullMethod, r, debug.Stack()))
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// DeadlineInterceptor ограничивает время обработки запроса, если клиент не передал свой дедлайн
+func DeadlineInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// ValidationInterceptor проверяет запросы, которые умеют валидировать себя
+func ValidationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if validator, ok := req.(interface{ Validate() error }); ok {
+			if err := validator.Validate(); err != nil {
+				return nil, invalidArgument(err)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,206 @@
+package internalgrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/api"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testInfo = &grpc.UnaryServerInfo{FullMethod: "/event.CalendarService/Test"}
+
+func TestRecoveryInterceptor(t *testing.T) {
+	interceptor := RecoveryInterceptor(logger.New("error"))
+
+	_, err := interceptor(context.Background(), nil, testInfo, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestRequestIDInterceptor(t *testing.T) {
+	interceptor := RequestIDInterceptor()
+
+	var gotID string
+	handler := func(ctx context.Context, req any) (any, error) {
+		gotID = requestctx.RequestID(ctx)
+		return nil, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDHeader, "req-42"))
+	_, err := interceptor(ctx, nil, testInfo, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "req-42", gotID)
+
+	_, err = interceptor(context.Background(), nil, testInfo, handler)
+	require.NoError(t, err)
+	assert.NotEmpty(t, gotID)
+	assert.NotEqual(t, "req-42", gotID)
+}
+
+func TestActorInterceptor(t *testing.T) {
+	interceptor := ActorInterceptor()
+
+	var actor string
+	var groups []string
+	handler := func(ctx context.Context, req any) (any, error) {
+		actor = requestctx.Actor(ctx)
+		groups = requestctx.Groups(ctx)
+		return nil, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(UserIDHeader, "alice", UserGroupsHeader, "devs, qa"))
+	_, err := interceptor(ctx, nil, testInfo, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", actor)
+	assert.Equal(t, []string{"devs", "qa"}, groups)
+
+	_, err = interceptor(context.Background(), nil, testInfo, handler)
+	require.NoError(t, err)
+	assert.Empty(t, actor)
+}
+
+func TestDeadlineInterceptor(t *testing.T) {
+	interceptor := DeadlineInterceptor(time.Second)
+
+	var deadline time.Time
+	var hasDeadline bool
+	handler := func(ctx context.Context, req any) (any, error) {
+		deadline, hasDeadline = ctx.Deadline()
+		return nil, nil
+	}
+
+	_, _ = interceptor(context.Background(), nil, testInfo, handler)
+	require.True(t, hasDeadline)
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+
+	// Дедлайн клиента не перезаписывается
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	_, _ = interceptor(ctx, nil, testInfo, handler)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+}
+
+type selfValidating struct {
+	err error
+}
+
+func (s selfValidating) Validate() error {
+	return s.err
+}
+
+func TestValidationInterceptor(t *testing.T) {
+	interceptor := ValidationInterceptor()
+	called := false
+	handler := func(ctx context.Context, req any) (any, error) {
+		called = true
+		return nil, nil
+	}
+
+	_, err := interceptor(context.Background(), selfValidating{err: errors.New("title is required")}, testInfo, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.False(t, called)
+
+	_, err = interceptor(context.Background(), selfValidating{}, testInfo, handler)
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestBuildInterceptors(t *testing.T) {
+	interceptors, err := BuildInterceptors(logger.New("error"), DefaultInterceptors, time.Second)
+	require.NoError(t, err)
+	assert.Len(t, interceptors, len(DefaultInterceptors))
+
+	_, err = BuildInterceptors(logger.New("error"), []string{"unknown"}, time.Second)
+	assert.Error(t, err)
+}
+
+func TestServerLifecycle(t *testing.T) {
+	logg := logger.New("error")
+	interceptors, err := BuildInterceptors(logg, DefaultInterceptors, time.Second)
+	require.NoError(t, err)
+
+	server, _ := setupTestGRPCServer(t)
+	server = NewCalendarGRPCServer(logg, "127.0.0.1:0", server.app, WithInterceptors(interceptors...), WithReflection(true))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan error, 1)
+	go func() {
+		started <- server.Start(ctx)
+	}()
+
+	// Даём серверу начать обслуживание, затем инициируем остановку
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-started)
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), time.Second)
+	defer stopCancel()
+	assert.NoError(t, server.Stop(stopCtx))
+}
+
+func TestServerStartFailsOnInvalidAddress(t *testing.T) {
+	server, _ := setupTestGRPCServer(t)
+	server.port = "127.0.0.1:-1"
+
+	err := server.Start(context.Background())
+	assert.Error(t, err)
+}
+
+func TestRequestIDHeaderReturned(t *testing.T) {
+	logg := logger.New("error")
+	base, _ := setupTestGRPCServer(t)
+	server := NewCalendarGRPCServer(logg, "127.0.0.1:0", base.app, WithInterceptors(RequestIDInterceptor()))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.grpcServer.Serve(lis) }()
+	defer server.grpcServer.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDHeader, "from-client")
+	_, _ = api.NewCalendarServiceClient(conn).GetEvent(ctx, &api.GetEventRequest{Id: "missing"}, grpc.Header(&header))
+	assert.Equal(t, []string{"from-client"}, header.Get(RequestIDHeader))
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	policy := ratelimit.Policy{
+		Limiter: ratelimit.NewMemoryLimiter(),
+		Rules:   map[string]ratelimit.Rule{ratelimit.ClassWrite: {Limit: 1, Per: time.Minute}},
+	}
+	interceptor := RateLimitInterceptor(ratelimit.NewSwitch(policy), logger.New("error"))
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	create := &grpc.UnaryServerInfo{FullMethod: "/event.CalendarService/CreateEvent"}
+	get := &grpc.UnaryServerInfo{FullMethod: "/event.CalendarService/GetEvent"}
+	ctx := requestctx.WithActor(context.Background(), "alice")
+
+	_, err := interceptor(ctx, nil, create, handler)
+	require.NoError(t, err)
+	_, err = interceptor(ctx, nil, create, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Чтение и другие пользователи не затронуты
+	_, err = interceptor(ctx, nil, get, handler)
+	require.NoError(t, err)
+	_, err = interceptor(requestctx.WithActor(context.Background(), "bob"), nil, create, handler)
+	require.NoError(t, err)
+}
@@ -0,0 +1,75 @@
+package internalgrpc
+
+import (
+	"context"
+	"net"
+	"path"
+	"strings"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RetryAfterHeader - ключ метаданных, в котором клиент получает, через сколько секунд повторить запрос
+const RetryAfterHeader = "retry-after"
+
+// RateLimitInterceptor отвечает ResourceExhausted, если клиент исчерпал лимит своего класса запросов.
+// Пользователя он берёт из контекста, поэтому должен стоять в цепочке после ActorInterceptor.
+// Если ограничитель недоступен, запрос пропускается, как и в HTTP. Политику можно заменить на лету.
+func RateLimitInterceptor(policy *ratelimit.Switch, logger server.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		class := methodClass(info.FullMethod)
+		client := ratelimit.Client(requestctx.Actor(ctx), peerIP(ctx))
+		retryAfter, err := policy.Allow(ctx, class, client)
+		if err != nil {
+			logger.Warn("rate limiter failed, request allowed: " + err.Error())
+		}
+		if retryAfter > 0 {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterHeader, ratelimit.RetryAfterSeconds(retryAfter)))
+			return nil, status.Errorf(codes.ResourceExhausted, "too many %s requests, retry later", class)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// methodClass определяет класс запроса по имени метода
+func methodClass(fullMethod string) string {
+	method := path.Base(fullMethod)
+	switch {
+	case strings.HasPrefix(method, "Search"):
+		return ratelimit.ClassSearch
+	case strings.HasPrefix(method, "Batch"):
+		return ratelimit.ClassBatch
+	case strings.HasPrefix(method, "Get"), strings.HasPrefix(method, "List"):
+		return ratelimit.ClassRead
+	default:
+		return ratelimit.ClassWrite
+	}
+}
+
+// peerIP - адрес клиента. Для вызовов REST-шлюза через bufconn это адрес HTTP-клиента,
+// который шлюз передаёт последним элементом x-forwarded-for.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	if p.Addr.Network() == "bufconn" {
+		forwarded := metadata.ValueFromIncomingContext(ctx, "x-forwarded-for")
+		if len(forwarded) == 0 {
+			return ""
+		}
+		hosts := strings.Split(forwarded[len(forwarded)-1], ",")
+		return strings.TrimSpace(hosts[len(hosts)-1])
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
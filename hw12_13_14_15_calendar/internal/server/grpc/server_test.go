@@ -2,6 +2,7 @@ package internalgrpc
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -9,9 +10,15 @@ import (
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
 	memorystorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/memory"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -25,6 +32,11 @@ func setupTestGRPCServer(t *testing.T) (*CalendarGRPCServer, *app.App) {
 	return server, calendar
 }
 
+// internalCtx - контекст внутреннего вызова: тесты вызывают сервер напрямую, без пользователя
+func internalCtx() context.Context {
+	return requestctx.WithSystem(context.Background())
+}
+
 func TestCreateEvent(t *testing.T) {
 	server, _ := setupTestGRPCServer(t)
 
@@ -37,7 +49,7 @@ func TestCreateEvent(t *testing.T) {
 		NotifyBefore: durationpb.New(15 * time.Minute),
 	}
 
-	resp, err := server.CreateEvent(context.Background(), req)
+	resp, err := server.CreateEvent(internalCtx(), req)
 	require.NoError(t, err)
 
 	assert.Equal(t, req.Title, resp.Title)
@@ -45,13 +57,126 @@ func TestCreateEvent(t *testing.T) {
 	assert.Equal(t, req.UserId, resp.UserId)
 }
 
+func TestCreateEventIdempotency(t *testing.T) {
+	server, _ := setupTestGRPCServer(t)
+	ctx := internalCtx()
+
+	req := &api.CreateEventRequest{
+		Title:     "Test Event",
+		UserId:    "user123",
+		StartTime: timestamppb.New(time.Now().Add(time.Hour)),
+		Duration:  durationpb.New(time.Hour),
+		RequestId: "retry-1",
+	}
+	first, err := server.CreateEvent(ctx, req)
+	require.NoError(t, err)
+	retried, err := server.CreateEvent(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, first.Id, retried.Id)
+
+	// Ключ можно передать и метаданными
+	req.RequestId = ""
+	fromMetadata, err := server.CreateEvent(metadata.NewIncomingContext(ctx, metadata.Pairs(IdempotencyKeyHeader, "retry-1")), req)
+	require.NoError(t, err)
+	assert.Equal(t, first.Id, fromMetadata.Id)
+
+	req.RequestId = "retry-1"
+	req.Title = "Other Event"
+	_, err = server.CreateEvent(ctx, req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// lossyStorage теряет ответы на запросы с ключом идемпотентности, как процесс, упавший посреди запроса
+type lossyStorage struct {
+	app.Storage
+	failAdd bool
+}
+
+func (s *lossyStorage) AddEvent(ctx context.Context, event storage.Event) error {
+	if s.failAdd {
+		s.failAdd = false
+		return errors.New("connection reset")
+	}
+	return s.Storage.AddEvent(ctx, event)
+}
+
+func (s *lossyStorage) CompleteIdempotencyKey(context.Context, string, string, storage.Event) error {
+	return errors.New("connection reset")
+}
+
+func (s *lossyStorage) ReleaseIdempotencyKey(context.Context, string, string) error {
+	return errors.New("connection reset")
+}
+
+func TestCreateEventIdempotencyRecovery(t *testing.T) {
+	logg := logger.New("debug")
+	strg := &lossyStorage{Storage: memorystorage.New(logg)}
+	server := NewCalendarGRPCServer(logg, ":0", app.New(logg, strg))
+	ctx := requestctx.WithActor(context.Background(), "user123")
+	req := &api.CreateEventRequest{
+		Title:     "Test Event",
+		UserId:    "user123",
+		StartTime: timestamppb.New(time.Now().Add(time.Hour)),
+		Duration:  durationpb.New(time.Hour),
+		RequestId: "retry-1",
+	}
+
+	// Событие создано, но ответ не сохранился: повтор находит событие по ID из резерва
+	first, err := server.CreateEvent(ctx, req)
+	require.NoError(t, err)
+	retried, err := server.CreateEvent(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, first.Id, retried.Id)
+
+	// Событие не создано, а резерв остался: повтор создаёт событие сам
+	req.RequestId = "retry-2"
+	strg.failAdd = true
+	_, err = server.CreateEvent(ctx, req)
+	require.Error(t, err)
+	created, err := server.CreateEvent(ctx, req)
+	require.NoError(t, err)
+	retried, err = server.CreateEvent(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, created.Id, retried.Id)
+
+	events, err := server.ListEventsForDay(ctx, &api.ListEventsForDayRequest{Date: req.StartTime})
+	require.NoError(t, err)
+	assert.Len(t, events.Events, 2)
+}
+
+func TestCreateEventValidation(t *testing.T) {
+	server, _ := setupTestGRPCServer(t)
+
+	_, err := server.CreateEvent(internalCtx(), &api.CreateEventRequest{
+		Title:        "Test Event",
+		UserId:       "user 123",
+		StartTime:    timestamppb.New(time.Now().Add(time.Hour)),
+		NotifyBefore: durationpb.New(-time.Minute),
+	})
+	st := status.Convert(err)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+
+	require.Len(t, st.Details(), 1)
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	fields := map[string]string{}
+	for _, violation := range badRequest.FieldViolations {
+		fields[violation.Field] = violation.Description
+	}
+	assert.Equal(t, map[string]string{
+		"duration":     "is required",
+		"userId":       "must contain only latin letters, digits and - _ . @",
+		"notifyBefore": "must be at least 0s",
+	}, fields)
+}
+
 func TestGetEvent(t *testing.T) {
 	server, calendar := setupTestGRPCServer(t)
 
 	// Создаем событие
 	eventID := "test-event-123"
 	err := calendar.CreateEvent(
-		context.Background(),
+		internalCtx(),
 		eventID, "Test Event", "Test Description", "user123",
 		time.Now().Add(time.Hour),
 		calendar_types.CalendarDuration(time.Hour),
@@ -61,7 +186,7 @@ func TestGetEvent(t *testing.T) {
 
 	// Получаем событие
 	req := &api.GetEventRequest{Id: eventID}
-	resp, err := server.GetEvent(context.Background(), req)
+	resp, err := server.GetEvent(internalCtx(), req)
 	require.NoError(t, err)
 
 	assert.Equal(t, eventID, resp.Id)
@@ -74,7 +199,7 @@ func TestUpdateEvent(t *testing.T) {
 	// Создаем событие
 	eventID := "test-event-456"
 	err := calendar.CreateEvent(
-		context.Background(),
+		internalCtx(),
 		eventID, "Original Title", "Original Description", "user123",
 		time.Now().Add(time.Hour),
 		calendar_types.CalendarDuration(time.Hour),
@@ -93,7 +218,7 @@ func TestUpdateEvent(t *testing.T) {
 		NotifyBefore: durationpb.New(30 * time.Minute),
 	}
 
-	resp, err := server.UpdateEvent(context.Background(), req)
+	resp, err := server.UpdateEvent(internalCtx(), req)
 	require.NoError(t, err)
 
 	assert.Equal(t, "Updated Title", resp.Title)
@@ -106,7 +231,7 @@ func TestDeleteEvent(t *testing.T) {
 	// Создаем событие
 	eventID := "test-event-789"
 	err := calendar.CreateEvent(
-		context.Background(),
+		internalCtx(),
 		eventID, "Test Event", "Test Description", "user123",
 		time.Now().Add(time.Hour),
 		calendar_types.CalendarDuration(time.Hour),
@@ -116,13 +241,13 @@ func TestDeleteEvent(t *testing.T) {
 
 	// Удаляем событие
 	req := &api.DeleteEventRequest{Id: eventID}
-	resp, err := server.DeleteEvent(context.Background(), req)
+	resp, err := server.DeleteEvent(internalCtx(), req)
 	require.NoError(t, err)
 	assert.True(t, resp.Success)
 
 	// Проверяем что событие удалено
 	getReq := &api.GetEventRequest{Id: eventID}
-	_, err = server.GetEvent(context.Background(), getReq)
+	_, err = server.GetEvent(internalCtx(), getReq)
 	assert.Error(t, err) // Должна быть ошибка, так как событие удалено
 }
 
@@ -132,7 +257,7 @@ func TestListEventsForDay(t *testing.T) {
 	// Создаем событие на сегодня
 	today := time.Now().Truncate(24 * time.Hour)
 	err := calendar.CreateEvent(
-		context.Background(),
+		internalCtx(),
 		"event1", "Today Event", "Description", "user123",
 		today.Add(10*time.Hour),
 		calendar_types.CalendarDuration(time.Hour),
@@ -144,7 +269,7 @@ func TestListEventsForDay(t *testing.T) {
 	req := &api.ListEventsForDayRequest{
 		Date: timestamppb.New(today),
 	}
-	resp, err := server.ListEventsForDay(context.Background(), req)
+	resp, err := server.ListEventsForDay(internalCtx(), req)
 	require.NoError(t, err)
 
 	assert.Len(t, resp.Events, 1)
@@ -157,7 +282,7 @@ func TestListEventsForWeek(t *testing.T) {
 	// Создаем событие на этой неделе
 	today := time.Now().Truncate(24 * time.Hour)
 	err := calendar.CreateEvent(
-		context.Background(),
+		internalCtx(),
 		"event1", "Week Event", "Description", "user123",
 		today.Add(10*time.Hour),
 		calendar_types.CalendarDuration(time.Hour),
@@ -169,7 +294,7 @@ func TestListEventsForWeek(t *testing.T) {
 	req := &api.ListEventsForWeekRequest{
 		Date: timestamppb.New(today),
 	}
-	resp, err := server.ListEventsForWeek(context.Background(), req)
+	resp, err := server.ListEventsForWeek(internalCtx(), req)
 	require.NoError(t, err)
 
 	assert.Len(t, resp.Events, 1)
@@ -182,7 +307,7 @@ func TestListEventsForMonth(t *testing.T) {
 	// Создаем событие в этом месяце
 	today := time.Now().Truncate(24 * time.Hour)
 	err := calendar.CreateEvent(
-		context.Background(),
+		internalCtx(),
 		"event1", "Month Event", "Description", "user123",
 		today.Add(10*time.Hour),
 		calendar_types.CalendarDuration(time.Hour),
@@ -194,7 +319,7 @@ func TestListEventsForMonth(t *testing.T) {
 	req := &api.ListEventsForMonthRequest{
 		Date: timestamppb.New(today),
 	}
-	resp, err := server.ListEventsForMonth(context.Background(), req)
+	resp, err := server.ListEventsForMonth(internalCtx(), req)
 	require.NoError(t, err)
 
 	assert.Len(t, resp.Events, 1)
@@ -206,6 +331,410 @@ func TestInvalidEventID(t *testing.T) {
 
 	// Тестируем несуществующий ID
 	req := &api.GetEventRequest{Id: "non-existent-id"}
-	_, err := server.GetEvent(context.Background(), req)
+	_, err := server.GetEvent(internalCtx(), req)
 	assert.Error(t, err)
 }
+
+func TestUpdateEventVersionConflict(t *testing.T) {
+	server, calendar := setupTestGRPCServer(t)
+
+	eventID := "test-event-version"
+	err := calendar.CreateEvent(
+		internalCtx(),
+		eventID, "Original Title", "", "user123",
+		time.Now().Add(time.Hour),
+		calendar_types.CalendarDuration(time.Hour),
+		calendar_types.CalendarDuration(0),
+	)
+	require.NoError(t, err)
+
+	req := &api.UpdateEventRequest{
+		Id:              eventID,
+		Title:           "First",
+		UserId:          "user123",
+		StartTime:       timestamppb.New(time.Now().Add(time.Hour)),
+		Duration:        durationpb.New(time.Hour),
+		NotifyBefore:    durationpb.New(0),
+		ExpectedVersion: 1,
+	}
+	resp, err := server.UpdateEvent(internalCtx(), req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.Version)
+
+	req.Title = "Second"
+	_, err = server.UpdateEvent(internalCtx(), req)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = server.DeleteEvent(internalCtx(), &api.DeleteEventRequest{Id: eventID, ExpectedVersion: 1})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = server.DeleteEvent(internalCtx(), &api.DeleteEventRequest{Id: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestTrashAndRestore(t *testing.T) {
+	server, calendar := setupTestGRPCServer(t)
+
+	eventID := "test-event-trash"
+	err := calendar.CreateEvent(
+		internalCtx(),
+		eventID, "Trashed", "", "user123",
+		time.Now().Add(time.Hour),
+		calendar_types.CalendarDuration(time.Hour),
+		calendar_types.CalendarDuration(0),
+	)
+	require.NoError(t, err)
+
+	_, err = server.DeleteEvent(internalCtx(), &api.DeleteEventRequest{Id: eventID})
+	require.NoError(t, err)
+
+	trash, err := server.ListDeletedEvents(internalCtx(), &api.ListDeletedEventsRequest{})
+	require.NoError(t, err)
+	require.Len(t, trash.Events, 1)
+	assert.Equal(t, eventID, trash.Events[0].Id)
+	assert.NotNil(t, trash.Events[0].DeletedAt)
+
+	restored, err := server.RestoreEvent(internalCtx(), &api.RestoreEventRequest{Id: eventID})
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, int64(3), restored.Version)
+
+	_, err = server.RestoreEvent(internalCtx(), &api.RestoreEventRequest{Id: eventID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGetEventHistory(t *testing.T) {
+	server, calendar := setupTestGRPCServer(t)
+
+	ctx := requestctx.WithActor(requestctx.WithRequestID(context.Background(), "req-1"), "alice")
+	eventID := "test-event-history"
+	err := calendar.CreateEvent(
+		ctx,
+		eventID, "Original", "", "alice",
+		time.Now().Add(time.Hour),
+		calendar_types.CalendarDuration(time.Hour),
+		calendar_types.CalendarDuration(0),
+	)
+	require.NoError(t, err)
+
+	_, err = server.UpdateEvent(ctx, &api.UpdateEventRequest{
+		Id:           eventID,
+		Title:        "Updated",
+		UserId:       "alice",
+		StartTime:    timestamppb.New(time.Now().Add(time.Hour)),
+		Duration:     durationpb.New(time.Hour),
+		NotifyBefore: durationpb.New(0),
+	})
+	require.NoError(t, err)
+
+	resp, err := server.GetEventHistory(internalCtx(), &api.GetEventHistoryRequest{Id: eventID})
+	require.NoError(t, err)
+	require.Len(t, resp.Records, 2)
+
+	created, updated := resp.Records[0], resp.Records[1]
+	assert.Equal(t, "create", created.Action)
+	assert.Nil(t, created.Before)
+	assert.Equal(t, "Original", created.After.Title)
+	assert.Equal(t, "update", updated.Action)
+	assert.Equal(t, "alice", updated.Actor)
+	assert.Equal(t, "req-1", updated.RequestId)
+	assert.Equal(t, "Original", updated.Before.Title)
+	assert.Equal(t, "Updated", updated.After.Title)
+
+	_, err = server.GetEventHistory(internalCtx(), &api.GetEventHistoryRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestBatchMutateEvents(t *testing.T) {
+	server, _ := setupTestGRPCServer(t)
+
+	create := &api.BatchOperation{Operation: &api.BatchOperation_Create{Create: &api.CreateEventRequest{
+		Title:     "Batch",
+		UserId:    "user123",
+		StartTime: timestamppb.New(time.Now().Add(time.Hour)),
+		Duration:  durationpb.New(time.Hour),
+	}}}
+	missing := &api.BatchOperation{Operation: &api.BatchOperation_Delete{Delete: &api.DeleteEventRequest{Id: "missing"}}}
+
+	resp, err := server.BatchMutateEvents(internalCtx(), &api.BatchMutateEventsRequest{
+		Operations: []*api.BatchOperation{create, missing},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), resp.Applied)
+	assert.Equal(t, int32(codes.Aborted), resp.Results[0].Code)
+	assert.Equal(t, int32(codes.NotFound), resp.Results[1].Code)
+
+	resp, err = server.BatchMutateEvents(internalCtx(), &api.BatchMutateEventsRequest{
+		Mode:       api.BatchMode_BEST_EFFORT,
+		Operations: []*api.BatchOperation{create, missing},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), resp.Applied)
+	assert.Equal(t, int32(codes.OK), resp.Results[0].Code)
+	assert.Equal(t, int64(1), resp.Results[0].Version)
+
+	_, err = server.BatchMutateEvents(internalCtx(), &api.BatchMutateEventsRequest{
+		Operations: []*api.BatchOperation{{}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSearchEvents(t *testing.T) {
+	server, calendar := setupTestGRPCServer(t)
+
+	err := calendar.CreateEvent(
+		internalCtx(),
+		"search-event", "Quarterly planning", "Budget review", "user123",
+		time.Now().Add(time.Hour),
+		calendar_types.CalendarDuration(time.Hour),
+		calendar_types.CalendarDuration(0),
+	)
+	require.NoError(t, err)
+
+	resp, err := server.SearchEvents(internalCtx(), &api.SearchEventsRequest{Query: "budget", UserId: "user123"})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "search-event", resp.Results[0].Event.Id)
+
+	resp, err = server.SearchEvents(internalCtx(), &api.SearchEventsRequest{Query: "budget", UserId: "someone-else"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+
+	_, err = server.SearchEvents(internalCtx(), &api.SearchEventsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCalendarSharing(t *testing.T) {
+	server, _ := setupTestGRPCServer(t)
+
+	alice := requestctx.WithActor(context.Background(), "alice")
+	bob := requestctx.WithActor(context.Background(), "bob")
+
+	team, err := server.CreateCalendar(alice, &api.CreateCalendarRequest{Name: "Team", Kind: "team"})
+	require.NoError(t, err)
+	assert.Equal(t, "owner", team.Role)
+
+	created, err := server.CreateEvent(alice, &api.CreateEventRequest{
+		Title:      "Planning",
+		UserId:     "alice",
+		CalendarId: team.Id,
+		StartTime:  timestamppb.New(time.Now().Add(time.Hour)),
+		Duration:   durationpb.New(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, team.Id, created.CalendarId)
+
+	_, err = server.GetEvent(bob, &api.GetEventRequest{Id: created.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = server.ShareCalendar(bob, &api.ShareCalendarRequest{
+		CalendarId: team.Id,
+		Entry:      &api.ACLEntry{GranteeType: "user", GranteeId: "bob", Role: "read"},
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = server.ShareCalendar(alice, &api.ShareCalendarRequest{
+		CalendarId: team.Id,
+		Entry:      &api.ACLEntry{GranteeType: "user", GranteeId: "bob", Role: "read"},
+	})
+	require.NoError(t, err)
+
+	event, err := server.GetEvent(bob, &api.GetEventRequest{Id: created.Id})
+	require.NoError(t, err)
+	assert.Equal(t, "Planning", event.Title)
+
+	// С ролью read изменять события нельзя
+	_, err = server.DeleteEvent(bob, &api.DeleteEventRequest{Id: created.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	calendars, err := server.ListCalendars(bob, &api.ListCalendarsRequest{})
+	require.NoError(t, err)
+	// Кроме календаря команды, у bob есть личный календарь
+	roles := map[string]string{}
+	for _, calendar := range calendars.Calendars {
+		roles[calendar.Id] = calendar.Role
+	}
+	assert.Equal(t, map[string]string{team.Id: "read", "personal:bob": "owner"}, roles)
+
+	_, err = server.ShareCalendar(alice, &api.ShareCalendarRequest{
+		CalendarId: team.Id,
+		Entry:      &api.ACLEntry{GranteeType: "robot", GranteeId: "bob", Role: "read"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.RevokeCalendarAccess(alice, &api.RevokeCalendarAccessRequest{
+		CalendarId: team.Id, GranteeType: "user", GranteeId: "bob",
+	})
+	require.NoError(t, err)
+
+	_, err = server.CreateEvent(alice, &api.CreateEventRequest{
+		Title:      "Lost",
+		UserId:     "alice",
+		CalendarId: "missing",
+		StartTime:  timestamppb.New(time.Now()),
+		Duration:   durationpb.New(time.Hour),
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestEventLabelsAccess(t *testing.T) {
+	server, _ := setupTestGRPCServer(t)
+	alice := requestctx.WithActor(context.Background(), "alice")
+	bob := requestctx.WithActor(context.Background(), "bob")
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	team, err := server.CreateCalendar(alice, &api.CreateCalendarRequest{Name: "Team", Kind: "team"})
+	require.NoError(t, err)
+	planning, err := server.CreateEvent(alice, &api.CreateEventRequest{
+		Title: "Planning", UserId: "alice", CalendarId: team.Id,
+		StartTime: timestamppb.New(start), Duration: durationpb.New(time.Hour),
+		Category: "Project X", Color: "#1e90ff", Tags: []string{"planning", "project-x"},
+	})
+	require.NoError(t, err)
+	_, err = server.CreateEvent(alice, &api.CreateEventRequest{
+		Title: "Dentist", UserId: "alice",
+		StartTime: timestamppb.New(start.Add(3 * time.Hour)), Duration: durationpb.New(time.Hour),
+		Tags: []string{"private"},
+	})
+	require.NoError(t, err)
+
+	events, err := server.ListEventsForDay(alice, &api.ListEventsForDayRequest{Date: timestamppb.New(start), Tags: []string{"Project-X"}})
+	require.NoError(t, err)
+	require.Len(t, events.Events, 1)
+	assert.Equal(t, planning.Id, events.Events[0].Id)
+
+	// При роли free-busy разметка скрыта вместе с названием и в статистику не попадает
+	_, err = server.ShareCalendar(alice, &api.ShareCalendarRequest{
+		CalendarId: team.Id,
+		Entry:      &api.ACLEntry{GranteeType: "user", GranteeId: "bob", Role: "free-busy"},
+	})
+	require.NoError(t, err)
+	events, err = server.ListEventsForDay(bob, &api.ListEventsForDayRequest{Date: timestamppb.New(start)})
+	require.NoError(t, err)
+	require.Len(t, events.Events, 1)
+	assert.Empty(t, events.Events[0].Category)
+	assert.Empty(t, events.Events[0].Tags)
+	stats, err := server.GetTagStats(bob, &api.GetTagStatsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, stats.Tags)
+
+	_, err = server.ShareCalendar(alice, &api.ShareCalendarRequest{
+		CalendarId: team.Id,
+		Entry:      &api.ACLEntry{GranteeType: "user", GranteeId: "bob", Role: "read"},
+	})
+	require.NoError(t, err)
+	stats, err = server.GetTagStats(bob, &api.GetTagStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"planning", "project-x"}, tagNames(stats))
+
+	stats, err = server.GetTagStats(alice, &api.GetTagStatsRequest{
+		From: timestamppb.New(start.Add(time.Hour)), To: timestamppb.New(start.Add(24 * time.Hour)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"private"}, tagNames(stats))
+
+	_, err = server.GetTagStats(alice, &api.GetTagStatsRequest{From: timestamppb.New(start), To: timestamppb.New(start)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func tagNames(stats *api.TagStatsResponse) []string {
+	names := make([]string, 0, len(stats.Tags))
+	for _, stat := range stats.Tags {
+		names = append(names, stat.Tag)
+	}
+	return names
+}
+
+func TestRequiresUser(t *testing.T) {
+	server, calendar := setupTestGRPCServer(t)
+	err := calendar.CreateEvent(
+		requestctx.WithActor(context.Background(), "alice"),
+		"alice-event", "Private", "", "alice",
+		time.Now().Add(time.Hour),
+		calendar_types.CalendarDuration(time.Hour),
+		calendar_types.CalendarDuration(0),
+	)
+	require.NoError(t, err)
+
+	// Запрос без x-user-id не помечен как внутренний и отклоняется
+	anonymous := context.Background()
+	_, err = server.GetEvent(anonymous, &api.GetEventRequest{Id: "alice-event"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = server.DeleteEvent(anonymous, &api.DeleteEventRequest{Id: "alice-event"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = server.GetEventHistory(anonymous, &api.GetEventHistoryRequest{Id: "alice-event"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = server.SearchEvents(anonymous, &api.SearchEventsRequest{Query: "private"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = server.ListDeletedEvents(anonymous, &api.ListDeletedEventsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = server.CreateEvent(anonymous, &api.CreateEventRequest{
+		Title:     "Anonymous",
+		UserId:    "alice",
+		StartTime: timestamppb.New(time.Now().Add(time.Hour)),
+		Duration:  durationpb.New(time.Hour),
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = server.BatchMutateEvents(anonymous, &api.BatchMutateEventsRequest{
+		Operations: []*api.BatchOperation{{Operation: &api.BatchOperation_Delete{Delete: &api.DeleteEventRequest{Id: "alice-event"}}}},
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = server.GetEvent(internalCtx(), &api.GetEventRequest{Id: "alice-event"})
+	assert.NoError(t, err)
+}
+
+func TestCreateEventInForeignPersonalCalendar(t *testing.T) {
+	server, _ := setupTestGRPCServer(t)
+	bob := requestctx.WithActor(context.Background(), "bob")
+
+	_, err := server.CreateEvent(bob, &api.CreateEventRequest{
+		Title:     "Spam",
+		UserId:    "alice",
+		StartTime: timestamppb.New(time.Now().Add(time.Hour)),
+		Duration:  durationpb.New(time.Hour),
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Отказ не создаёт личный календарь alice: его владелец создаст его сам
+	calendars, err := server.ListCalendars(bob, &api.ListCalendarsRequest{})
+	require.NoError(t, err)
+	for _, calendar := range calendars.Calendars {
+		assert.NotEqual(t, "personal:alice", calendar.Id)
+	}
+	_, err = server.ShareCalendar(bob, &api.ShareCalendarRequest{
+		CalendarId: "personal:alice",
+		Entry:      &api.ACLEntry{GranteeType: "user", GranteeId: "bob", Role: "write"},
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSearchEventsReadableCalendars(t *testing.T) {
+	server, _ := setupTestGRPCServer(t)
+	alice := requestctx.WithActor(context.Background(), "alice")
+	bob := requestctx.WithActor(context.Background(), "bob")
+
+	// Событий alice больше, чем лимит выдачи, и они релевантнее единственного события bob
+	for i := 0; i < 3; i++ {
+		_, err := server.CreateEvent(alice, &api.CreateEventRequest{
+			Title:       "Budget budget",
+			Description: "Budget",
+			UserId:      "alice",
+			StartTime:   timestamppb.New(time.Now().Add(time.Hour)),
+			Duration:    durationpb.New(time.Hour),
+		})
+		require.NoError(t, err)
+	}
+	own, err := server.CreateEvent(bob, &api.CreateEventRequest{
+		Title:     "Budget",
+		UserId:    "bob",
+		StartTime: timestamppb.New(time.Now().Add(time.Hour)),
+		Duration:  durationpb.New(time.Hour),
+	})
+	require.NoError(t, err)
+
+	resp, err := server.SearchEvents(bob, &api.SearchEventsRequest{Query: "budget", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, own.Id, resp.Results[0].Event.Id)
+}
@@ -0,0 +1,211 @@
+package internalhttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/validate"
+	router "github.com/go-chi/chi/v5"
+)
+
+// multipartOverhead - запас к размеру файла на заголовки частей и границы multipart
+const multipartOverhead = 64 << 10
+
+// LinkRequest прикрепляет к событию ссылку, например на видеозвонок
+type LinkRequest struct {
+	Name string `json:"name" validate:"max=255"` // Пусто - подписью служит адрес
+	URL  string `json:"url" validate:"required,url,max=2048"`
+}
+
+type AttachmentResponse struct {
+	ID          string
+	EventID     string
+	Kind        string // file или link
+	Name        string // Имя файла или подпись ссылки
+	ContentType string `json:",omitempty"`
+	Size        int64  `json:",omitempty"`
+	URL         string `json:",omitempty"`
+	CreatedBy   string `json:",omitempty"`
+	CreatedAt   time.Time
+}
+
+func mapAttachmentToResponse(attachment storage.Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:          attachment.ID,
+		EventID:     attachment.EventID,
+		Kind:        string(attachment.Kind),
+		Name:        attachment.Name,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		URL:         attachment.URL,
+		CreatedBy:   attachment.CreatedBy,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
+
+func getAttachments(app server.Application, logger server.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		attachments, err := app.ListAttachments(r.Context(), router.URLParam(r, "id"))
+		if err != nil {
+			logger.Warn(fmt.Sprintf("error getting attachments: %v", err))
+			writeError(w, r, err)
+			return
+		}
+		response := make([]AttachmentResponse, 0, len(attachments))
+		for _, attachment := range attachments {
+			response = append(response, mapAttachmentToResponse(attachment))
+		}
+		if err := sendInResponse(w, response, http.StatusOK); err != nil {
+			logger.Warn("send response error: " + err.Error())
+		}
+	}
+}
+
+// addAttachment прикрепляет к событию файл из части file тела multipart/form-data
+// или ссылку из JSON тела LinkRequest. Файл читается потоком, целиком в память не попадает.
+func addAttachment(app server.Application, logger server.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		eventID := router.URLParam(r, "id")
+
+		var (
+			attachment storage.Attachment
+			err        error
+		)
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "multipart/form-data":
+			attachment, err = addFileAttachment(w, r, app, eventID)
+		case "application/json":
+			attachment, err = addLinkAttachment(r, app, eventID)
+		default:
+			writeProblem(w, r, http.StatusUnsupportedMediaType,
+				"upload a file as multipart/form-data or send a link as application/json")
+			return
+		}
+		if err != nil {
+			logger.Warn(fmt.Sprintf("can't add attachment: %v", err))
+			writeAttachmentError(w, r, err)
+			return
+		}
+
+		if err := sendInResponse(w, mapAttachmentToResponse(attachment), http.StatusCreated); err != nil {
+			logger.Warn("send response error: " + err.Error())
+		}
+	}
+}
+
+// errNoFilePart - в multipart-теле нет части file
+var errNoFilePart = errors.New(`multipart body must contain a "file" part`)
+
+func addFileAttachment(w http.ResponseWriter, r *http.Request, app server.Application, eventID string) (storage.Attachment, error) {
+	limit := app.MaxAttachmentSize() + multipartOverhead
+	if r.ContentLength > limit {
+		return storage.Attachment{}, storage.ErrAttachmentTooLarge
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return storage.Attachment{}, badRequest{err}
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return storage.Attachment{}, badRequest{errNoFilePart}
+		}
+		if err != nil {
+			return storage.Attachment{}, badRequest{err}
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+		defer part.Close()
+		return app.AddFileAttachment(r.Context(), eventID, part.FileName(), part)
+	}
+}
+
+func addLinkAttachment(r *http.Request, app server.Application, eventID string) (storage.Attachment, error) {
+	request, err := fromJson[LinkRequest](r.Body)
+	if err != nil {
+		return storage.Attachment{}, badRequest{fmt.Errorf("malformed JSON body: %w", err)}
+	}
+	if err := validate.Struct(request); err != nil {
+		return storage.Attachment{}, err
+	}
+	return app.AddLinkAttachment(r.Context(), eventID, request.Name, request.URL)
+}
+
+// badRequest - тело запроса не удалось разобрать
+type badRequest struct {
+	err error
+}
+
+func (e badRequest) Error() string { return e.err.Error() }
+
+func (e badRequest) Unwrap() error { return e.err }
+
+// writeAttachmentError дополняет writeError ошибками разбора тела загрузки
+func writeAttachmentError(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		tooLarge *http.MaxBytesError
+		bad      badRequest
+	)
+	switch {
+	case errors.As(err, &tooLarge):
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, storage.ErrAttachmentTooLarge.Error())
+	case errors.As(err, &bad):
+		writeBadRequest(w, r, bad.err)
+	default:
+		writeError(w, r, err)
+	}
+}
+
+// downloadAttachment отдаёт файл потоком, а для ссылки перенаправляет по её адресу.
+// Файл всегда скачивается, а не открывается в браузере: его содержимое прислал пользователь.
+func downloadAttachment(app server.Application, logger server.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		attachment, content, err := app.OpenAttachment(r.Context(), router.URLParam(r, "id"), router.URLParam(r, "attachmentID"))
+		if err != nil {
+			logger.Warn(fmt.Sprintf("can't open attachment: %v", err))
+			writeError(w, r, err)
+			return
+		}
+		if content == nil {
+			http.Redirect(w, r, attachment.URL, http.StatusFound)
+			return
+		}
+		defer content.Close()
+
+		w.Header().Set("Content-Type", attachment.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name})
+		if disposition == "" {
+			disposition = "attachment"
+		}
+		w.Header().Set("Content-Disposition", disposition)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		if _, err := io.Copy(w, content); err != nil {
+			logger.Warn(fmt.Sprintf("error sending attachment %s: %v", attachment.ID, err))
+		}
+	}
+}
+
+func deleteAttachment(app server.Application, logger server.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := app.DeleteAttachment(r.Context(), router.URLParam(r, "id"), router.URLParam(r, "attachmentID"))
+		if err != nil {
+			logger.Warn(fmt.Sprintf("can't delete attachment: %v", err))
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
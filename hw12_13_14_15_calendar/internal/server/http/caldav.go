@@ -0,0 +1,587 @@
+package internalhttp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/etag"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/ical"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	route "github.com/go-chi/chi/v5"
+)
+
+// Подмножество CalDAV (RFC 4791) для Thunderbird и Apple Calendar:
+//
+//	/dav/principals/{user}/                 - принципал пользователя
+//	/dav/calendars/{user}/                  - домашняя коллекция со всеми доступными календарями
+//	/dav/calendars/{user}/{calendar}/       - календарь: PROPFIND, REPORT calendar-query и calendar-multiget
+//	/dav/calendars/{user}/{calendar}/{id}.ics - событие: GET, PUT, DELETE с ETag
+//
+// Пользователя, как и для остального API, определяет прокси перед сервисом (заголовок X-User-ID);
+// {user} в пути должен совпадать с ним. Имя ресурса без .ics - идентификатор события.
+const (
+	caldavPrefix = "/dav"
+
+	nsDAV     = "DAV:"
+	nsCalDAV  = "urn:ietf:params:xml:ns:caldav"
+	nsCalSrv  = "http://calendarserver.org/ns/"
+	icsSuffix = ".ics"
+
+	maxICSBodySize = 1 << 20
+)
+
+func init() {
+	route.RegisterMethod("PROPFIND")
+	route.RegisterMethod("REPORT")
+}
+
+// mountCalDAV регистрирует маршруты CalDAV; каждый путь коллекции доступен со слешем и без
+func mountCalDAV(router route.Router, app server.Application, logger server.Logger) {
+	h := &caldavHandler{app: app, logger: logger}
+
+	router.Get("/.well-known/caldav", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, caldavPrefix+"/", http.StatusMovedPermanently)
+	})
+	router.Route(caldavPrefix, func(router route.Router) {
+		router.Use(h.requireUser)
+		router.Options("/*", h.options)
+		router.MethodFunc("PROPFIND", "/", h.propfindPrincipal)
+		router.MethodFunc("PROPFIND", "/principals/{user}", h.propfindPrincipal)
+		router.MethodFunc("PROPFIND", "/principals/{user}/", h.propfindPrincipal)
+		router.MethodFunc("PROPFIND", "/calendars/{user}", h.propfindHome)
+		router.MethodFunc("PROPFIND", "/calendars/{user}/", h.propfindHome)
+		router.MethodFunc("PROPFIND", "/calendars/{user}/{calendar}", h.propfindCalendar)
+		router.MethodFunc("PROPFIND", "/calendars/{user}/{calendar}/", h.propfindCalendar)
+		router.MethodFunc("REPORT", "/calendars/{user}/{calendar}", h.report)
+		router.MethodFunc("REPORT", "/calendars/{user}/{calendar}/", h.report)
+		router.Get("/calendars/{user}/{calendar}/{resource}", h.getEvent)
+		router.Put("/calendars/{user}/{calendar}/{resource}", h.putEvent)
+		router.Delete("/calendars/{user}/{calendar}/{resource}", h.deleteEvent)
+	})
+}
+
+type caldavHandler struct {
+	app    server.Application
+	logger server.Logger
+}
+
+// requireUser отвечает 401 на запросы без пользователя, чтобы клиент запросил учётные данные
+func (h *caldavHandler) requireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requestctx.Actor(r.Context()) == "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="calendar"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// pathParam возвращает параметр пути без URL-кодирования: клиенты кодируют ":" в ID календарей
+func pathParam(r *http.Request, name string) string {
+	value := route.URLParam(r, name)
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
+
+// ownPath проверяет, что пользователь обращается к своим коллекциям
+func (h *caldavHandler) ownPath(w http.ResponseWriter, r *http.Request) bool {
+	if user := pathParam(r, "user"); user != "" && user != requestctx.Actor(r.Context()) {
+		w.WriteHeader(http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (h *caldavHandler) options(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("DAV", "1, 3, calendar-access")
+	w.Header().Set("Allow", "OPTIONS, GET, PUT, DELETE, PROPFIND, REPORT")
+	w.WriteHeader(http.StatusOK)
+}
+
+func principalHref(user string) string {
+	return caldavPrefix + "/principals/" + user + "/"
+}
+
+func homeHref(user string) string {
+	return caldavPrefix + "/calendars/" + user + "/"
+}
+
+func calendarHref(user, calendarID string) string {
+	return homeHref(user) + calendarID + "/"
+}
+
+func eventHref(user, calendarID, eventID string) string {
+	return calendarHref(user, calendarID) + eventID + icsSuffix
+}
+
+func (h *caldavHandler) propfindPrincipal(w http.ResponseWriter, r *http.Request) {
+	if !h.ownPath(w, r) {
+		return
+	}
+	user := requestctx.Actor(r.Context())
+	href := r.URL.Path
+	if !strings.HasSuffix(href, "/") {
+		href += "/"
+	}
+	writeMultistatus(w, h.logger, []davResponse{{
+		href: href,
+		props: []davProp{
+			{"d:resourcetype", "<d:collection/><d:principal/>"},
+			{"d:displayname", xmlText(user)},
+			{"d:current-user-principal", hrefXML(principalHref(user))},
+			{"d:principal-URL", hrefXML(principalHref(user))},
+			{"c:calendar-home-set", hrefXML(homeHref(user))},
+			{"c:calendar-user-address-set", hrefXML("mailto:" + user)},
+		},
+	}})
+}
+
+func (h *caldavHandler) propfindHome(w http.ResponseWriter, r *http.Request) {
+	if !h.ownPath(w, r) {
+		return
+	}
+	user := requestctx.Actor(r.Context())
+	responses := []davResponse{{
+		href: homeHref(user),
+		props: []davProp{
+			{"d:resourcetype", "<d:collection/>"},
+			{"d:current-user-principal", hrefXML(principalHref(user))},
+		},
+	}}
+	if depth(r) > 0 {
+		calendars, err := h.app.ListCalendars(r.Context())
+		if err != nil {
+			h.logger.Error(fmt.Sprintf("caldav: can't list calendars: %v", err))
+			w.WriteHeader(storageErrorStatus(err))
+			return
+		}
+		for _, calendar := range calendars {
+			response, err := h.calendarResponse(r, calendar)
+			if err != nil {
+				h.logger.Error(fmt.Sprintf("caldav: can't describe calendar %s: %v", calendar.Calendar.ID, err))
+				w.WriteHeader(storageErrorStatus(err))
+				return
+			}
+			responses = append(responses, response)
+		}
+	}
+	writeMultistatus(w, h.logger, responses)
+}
+
+func (h *caldavHandler) propfindCalendar(w http.ResponseWriter, r *http.Request) {
+	if !h.ownPath(w, r) {
+		return
+	}
+	calendar, err := h.app.GetCalendar(r.Context(), pathParam(r, "calendar"))
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("caldav: can't get calendar: %v", err))
+		w.WriteHeader(storageErrorStatus(err))
+		return
+	}
+	response, err := h.calendarResponse(r, calendar)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("caldav: can't describe calendar %s: %v", calendar.Calendar.ID, err))
+		w.WriteHeader(storageErrorStatus(err))
+		return
+	}
+	responses := []davResponse{response}
+
+	if depth(r) > 0 {
+		events, err := h.app.ListCalendarEvents(r.Context(), calendar.Calendar.ID, time.Time{}, time.Time{})
+		if err != nil {
+			h.logger.Error(fmt.Sprintf("caldav: can't list events: %v", err))
+			w.WriteHeader(storageErrorStatus(err))
+			return
+		}
+		user := requestctx.Actor(r.Context())
+		for _, event := range events {
+			responses = append(responses, davResponse{
+				href: eventHref(user, calendar.Calendar.ID, event.ID),
+				props: []davProp{
+					{"d:resourcetype", ""},
+					{"d:getetag", xmlText(etag.Format(event.Version))},
+					{"d:getcontenttype", "text/calendar; charset=utf-8; component=vevent"},
+				},
+			})
+		}
+	}
+	writeMultistatus(w, h.logger, responses)
+}
+
+// calendarResponse описывает календарь; getctag меняется при любом изменении его событий
+func (h *caldavHandler) calendarResponse(r *http.Request, calendar storage.CalendarAccess) (davResponse, error) {
+	events, err := h.app.ListCalendarEvents(r.Context(), calendar.Calendar.ID, time.Time{}, time.Time{})
+	if err != nil {
+		return davResponse{}, err
+	}
+	ctag := fnv.New64a()
+	for _, event := range events {
+		_, _ = fmt.Fprintf(ctag, "%s:%d;", event.ID, event.Version)
+	}
+
+	privileges := "<d:privilege><d:read/></d:privilege>"
+	if calendar.Role.Allows(storage.RoleWrite) {
+		privileges += "<d:privilege><d:write/></d:privilege><d:privilege><d:write-content/></d:privilege>" +
+			"<d:privilege><d:bind/></d:privilege><d:privilege><d:unbind/></d:privilege>"
+	}
+
+	return davResponse{
+		href: calendarHref(requestctx.Actor(r.Context()), calendar.Calendar.ID),
+		props: []davProp{
+			{"d:resourcetype", "<d:collection/><c:calendar/>"},
+			{"d:displayname", xmlText(calendar.Calendar.Name)},
+			{"c:supported-calendar-component-set", `<c:comp name="VEVENT"/>`},
+			{"cs:getctag", xmlText(strconv.FormatUint(ctag.Sum64(), 16))},
+			{"d:current-user-privilege-set", privileges},
+		},
+	}, nil
+}
+
+// reportRequest - тело REPORT calendar-query или calendar-multiget
+type reportRequest struct {
+	XMLName xml.Name
+	Prop    struct {
+		CalendarData *struct{} `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+	} `xml:"DAV: prop"`
+	Hrefs  []string `xml:"DAV: href"`
+	Filter struct {
+		CompFilter compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+type compFilter struct {
+	Name      string `xml:"name,attr"`
+	TimeRange *struct {
+		Start string `xml:"start,attr"`
+		End   string `xml:"end,attr"`
+	} `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	CompFilters []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// timeRange ищет ограничение по времени у VEVENT; нулевые границы не ограничивают выборку
+func (f compFilter) timeRange() (time.Time, time.Time, error) {
+	for _, nested := range f.CompFilters {
+		if nested.Name == "VEVENT" && nested.TimeRange != nil {
+			var from, to time.Time
+			var err error
+			if nested.TimeRange.Start != "" {
+				if from, err = time.Parse("20060102T150405Z", nested.TimeRange.Start); err != nil {
+					return from, to, err
+				}
+			}
+			if nested.TimeRange.End != "" {
+				if to, err = time.Parse("20060102T150405Z", nested.TimeRange.End); err != nil {
+					return from, to, err
+				}
+			}
+			return from, to, nil
+		}
+	}
+	return time.Time{}, time.Time{}, nil
+}
+
+func (h *caldavHandler) report(w http.ResponseWriter, r *http.Request) {
+	if !h.ownPath(w, r) {
+		return
+	}
+	calendarID := pathParam(r, "calendar")
+	user := requestctx.Actor(r.Context())
+
+	var request reportRequest
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxICSBodySize)).Decode(&request); err != nil {
+		h.logger.Warn(fmt.Sprintf("caldav: invalid report body: %v", err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	withData := request.Prop.CalendarData != nil
+
+	var responses []davResponse
+	switch {
+	case request.XMLName.Space == nsCalDAV && request.XMLName.Local == "calendar-query":
+		from, to, err := request.Filter.CompFilter.timeRange()
+		if err != nil {
+			h.logger.Warn(fmt.Sprintf("caldav: invalid time-range: %v", err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events, err := h.app.ListCalendarEvents(r.Context(), calendarID, from, to)
+		if err != nil {
+			h.logger.Warn(fmt.Sprintf("caldav: can't list events: %v", err))
+			w.WriteHeader(storageErrorStatus(err))
+			return
+		}
+		for _, event := range events {
+			response, err := eventResponse(eventHref(user, calendarID, event.ID), event, withData)
+			if err != nil {
+				h.logger.Error(fmt.Sprintf("caldav: can't encode event: %v", err))
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			responses = append(responses, response)
+		}
+
+	case request.XMLName.Space == nsCalDAV && request.XMLName.Local == "calendar-multiget":
+		if _, err := h.app.GetCalendar(r.Context(), calendarID); err != nil {
+			h.logger.Warn(fmt.Sprintf("caldav: can't get calendar: %v", err))
+			w.WriteHeader(storageErrorStatus(err))
+			return
+		}
+		for _, href := range request.Hrefs {
+			href = strings.TrimSpace(href)
+			name, err := url.PathUnescape(path.Base(href))
+			if err != nil {
+				name = path.Base(href)
+			}
+			event, err := h.app.GetEventByID(r.Context(), strings.TrimSuffix(name, icsSuffix))
+			if err == nil && event.CalendarID != calendarID {
+				err = storage.ErrEventNotFound
+			}
+			if err != nil {
+				responses = append(responses, davResponse{href: href, status: storageErrorStatus(err)})
+				continue
+			}
+			response, err := eventResponse(href, event, withData)
+			if err != nil {
+				h.logger.Error(fmt.Sprintf("caldav: can't encode event: %v", err))
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			responses = append(responses, response)
+		}
+
+	default:
+		h.logger.Warn(fmt.Sprintf("caldav: unsupported report %s %s", request.XMLName.Space, request.XMLName.Local))
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, xml.Header+`<d:error xmlns:d="DAV:"><d:supported-report/></d:error>`)
+		return
+	}
+	writeMultistatus(w, h.logger, responses)
+}
+
+func eventResponse(href string, event storage.Event, withData bool) (davResponse, error) {
+	props := []davProp{{"d:getetag", xmlText(etag.Format(event.Version))}}
+	if withData {
+		var buf bytes.Buffer
+		if err := ical.Encode(&buf, []storage.Event{event}); err != nil {
+			return davResponse{}, err
+		}
+		props = append(props, davProp{"c:calendar-data", xmlText(buf.String())})
+	}
+	return davResponse{href: href, props: props}, nil
+}
+
+// calendarEvent находит событие ресурса и проверяет, что оно лежит в календаре из пути
+func (h *caldavHandler) calendarEvent(r *http.Request) (storage.Event, error) {
+	resource := pathParam(r, "resource")
+	if !strings.HasSuffix(resource, icsSuffix) {
+		return storage.Event{}, storage.ErrEventNotFound
+	}
+	event, err := h.app.GetEventByID(r.Context(), strings.TrimSuffix(resource, icsSuffix))
+	if err != nil {
+		return storage.Event{}, err
+	}
+	if event.CalendarID != pathParam(r, "calendar") {
+		return storage.Event{}, storage.ErrEventNotFound
+	}
+	return event, nil
+}
+
+func (h *caldavHandler) getEvent(w http.ResponseWriter, r *http.Request) {
+	if !h.ownPath(w, r) {
+		return
+	}
+	event, err := h.calendarEvent(r)
+	if err != nil {
+		w.WriteHeader(storageErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("ETag", etag.Format(event.Version))
+	if notModified(r, event.Version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if err := ical.Encode(w, []storage.Event{event}); err != nil {
+		h.logger.Warn(fmt.Sprintf("caldav: can't write event: %v", err))
+	}
+}
+
+// putEvent создаёт событие или заменяет его целиком. If-None-Match: * запрещает перезапись,
+// If-Match - изменение чужой версии.
+func (h *caldavHandler) putEvent(w http.ResponseWriter, r *http.Request) {
+	if !h.ownPath(w, r) {
+		return
+	}
+	resource := pathParam(r, "resource")
+	if !strings.HasSuffix(resource, icsSuffix) || len(resource) == len(icsSuffix) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	id := strings.TrimSuffix(resource, icsSuffix)
+	calendarID := pathParam(r, "calendar")
+
+	versions, err := ifMatch(r)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("caldav: invalid If-Match header: %v", err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	events, err := ical.Decode(io.LimitReader(r.Body, maxICSBodySize))
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("caldav: invalid calendar data: %v", err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// Остальные VEVENT с тем же UID - изменённые вхождения повторяющегося события, они не хранятся
+	event := events[0]
+
+	existing, err := h.app.GetEventByID(r.Context(), id)
+	switch {
+	case err == nil:
+		if r.Header.Get("If-None-Match") == "*" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if existing.CalendarID != calendarID {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		err = h.app.UpdateEvent(
+			r.Context(),
+			id, event.Title, event.Description, existing.UserID,
+			event.StartTime, event.Duration, event.NotifyBefore,
+			// Категории в iCalendar нет, поэтому она сохраняется; теги и цвет приходят в CATEGORIES и COLOR
+			storage.Labels{Category: existing.Category, Color: event.Color, Tags: event.Tags},
+			etag.Expected(versions, existing.Version),
+		)
+	case errors.Is(err, storage.ErrEventNotFound):
+		if versions != nil {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		err = h.app.CreateEventInCalendar(
+			r.Context(),
+			calendarID, id, event.Title, event.Description, requestctx.Actor(r.Context()),
+			event.StartTime, event.Duration, event.NotifyBefore, event.Labels,
+		)
+	}
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("caldav: can't save event %s: %v", id, err))
+		w.WriteHeader(storageErrorStatus(err))
+		return
+	}
+
+	saved, err := h.app.GetEventByID(r.Context(), id)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("caldav: can't get saved event %s: %v", id, err))
+		w.WriteHeader(storageErrorStatus(err))
+		return
+	}
+	w.Header().Set("ETag", etag.Format(saved.Version))
+	if existing.ID == "" {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *caldavHandler) deleteEvent(w http.ResponseWriter, r *http.Request) {
+	if !h.ownPath(w, r) {
+		return
+	}
+	versions, err := ifMatch(r)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("caldav: invalid If-Match header: %v", err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	event, err := h.calendarEvent(r)
+	if err == nil {
+		err = h.app.DeleteEvent(r.Context(), event.ID, etag.Expected(versions, event.Version))
+	}
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("caldav: can't delete event: %v", err))
+		w.WriteHeader(storageErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// depth возвращает заголовок Depth; infinity обрабатывается как 1
+func depth(r *http.Request) int {
+	if r.Header.Get("Depth") == "0" {
+		return 0
+	}
+	return 1
+}
+
+// davProp - свойство ресурса; value уже экранировано и вставляется в XML как есть
+type davProp struct {
+	name  string
+	value string
+}
+
+// davResponse - элемент multistatus: свойства ресурса или статус, если ресурс недоступен
+type davResponse struct {
+	href   string
+	props  []davProp
+	status int
+}
+
+func writeMultistatus(w http.ResponseWriter, logger server.Logger, responses []davResponse) {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<d:multistatus xmlns:d="` + nsDAV + `" xmlns:c="` + nsCalDAV + `" xmlns:cs="` + nsCalSrv + `">`)
+	for _, response := range responses {
+		sb.WriteString("<d:response>")
+		sb.WriteString(hrefXML(response.href))
+		if response.status != 0 {
+			sb.WriteString("<d:status>" + statusLine(response.status) + "</d:status>")
+		} else {
+			sb.WriteString("<d:propstat><d:prop>")
+			for _, prop := range response.props {
+				sb.WriteString("<" + prop.name + ">" + prop.value + "</" + prop.name + ">")
+			}
+			sb.WriteString("</d:prop><d:status>" + statusLine(http.StatusOK) + "</d:status></d:propstat>")
+		}
+		sb.WriteString("</d:response>")
+	}
+	sb.WriteString("</d:multistatus>")
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		logger.Warn("caldav: send response error: " + err.Error())
+	}
+}
+
+func statusLine(status int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", status, http.StatusText(status))
+}
+
+func hrefXML(href string) string {
+	return "<d:href>" + xmlText(href) + "</d:href>"
+}
+
+func xmlText(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
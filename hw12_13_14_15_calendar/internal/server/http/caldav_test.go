@@ -0,0 +1,141 @@
+package internalhttp
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const caldavEventID = "8C1F3A52-4D1E-4B8B-9E55-2A1C0B7E9F10"
+
+// caldavRequest повторяет запрос клиента; тело берётся из testdata/caldav
+func caldavRequest(t *testing.T, user, method, url, fixture string, headers map[string]string) (*http.Response, string) {
+	t.Helper()
+	var body io.Reader
+	if fixture != "" {
+		data, err := os.ReadFile(filepath.Join("testdata", "caldav", fixture))
+		require.NoError(t, err)
+		body = strings.NewReader(string(data))
+	}
+	req, err := http.NewRequest(method, url, body)
+	require.NoError(t, err)
+	if user != "" {
+		req.Header.Set(UserIDHeader, user)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp, string(data)
+}
+
+func TestCalDAVDiscovery(t *testing.T) {
+	ts, _ := setupTestServer(t)
+	defer ts.Close()
+
+	resp, _ := caldavRequest(t, "", "PROPFIND", ts.URL+"/dav/", "thunderbird_propfind_principal.xml", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("WWW-Authenticate"))
+
+	resp, body := caldavRequest(t, "alice", "PROPFIND", ts.URL+"/dav/", "thunderbird_propfind_principal.xml",
+		map[string]string{"Depth": "0"})
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	assert.Contains(t, body, "<d:current-user-principal><d:href>/dav/principals/alice/</d:href>")
+	assert.Contains(t, body, "<c:calendar-home-set><d:href>/dav/calendars/alice/</d:href>")
+
+	// В домашней коллекции сразу есть личный календарь
+	resp, body = caldavRequest(t, "alice", "PROPFIND", ts.URL+"/dav/calendars/alice/", "apple_propfind_home.xml",
+		map[string]string{"Depth": "1"})
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	assert.Contains(t, body, "<d:href>/dav/calendars/alice/personal:alice/</d:href>")
+	assert.Contains(t, body, "<c:calendar/>")
+	assert.Contains(t, body, `<c:comp name="VEVENT"/>`)
+	assert.Contains(t, body, "<d:write/>")
+
+	resp, _ = caldavRequest(t, "bob", "PROPFIND", ts.URL+"/dav/calendars/alice/", "apple_propfind_home.xml", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, _ = caldavRequest(t, "alice", http.MethodOptions, ts.URL+"/dav/calendars/alice/", "", nil)
+	assert.Contains(t, resp.Header.Get("DAV"), "calendar-access")
+}
+
+func TestCalDAVEventLifecycle(t *testing.T) {
+	ts, _ := setupTestServer(t)
+	defer ts.Close()
+
+	calendarURL := ts.URL + "/dav/calendars/alice/personal%3Aalice/"
+	eventURL := calendarURL + caldavEventID + ".ics"
+
+	// Создание: Apple Calendar присылает If-None-Match: *
+	resp, _ := caldavRequest(t, "alice", http.MethodPut, eventURL, "apple_put_event.ics",
+		map[string]string{"If-None-Match": "*", "Content-Type": "text/calendar"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, `"1"`, resp.Header.Get("ETag"))
+
+	resp, _ = caldavRequest(t, "alice", http.MethodPut, eventURL, "apple_put_event.ics",
+		map[string]string{"If-None-Match": "*"})
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp, body := caldavRequest(t, "alice", http.MethodGet, eventURL, "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, body, "UID:"+caldavEventID+"\r\n")
+	assert.Contains(t, body, "SUMMARY:Sprint review\r\n")
+	assert.Contains(t, body, "DTSTART:20240603T100000Z\r\n")
+	assert.Contains(t, body, "TRIGGER:-PT15M\r\n")
+
+	resp, body = caldavRequest(t, "alice", "REPORT", calendarURL, "thunderbird_calendar_query.xml",
+		map[string]string{"Depth": "1"})
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	assert.Contains(t, body, caldavEventID+".ics</d:href>")
+	assert.Contains(t, body, "<d:getetag>&#34;1&#34;</d:getetag>")
+	assert.NotContains(t, body, "calendar-data")
+
+	_, body = caldavRequest(t, "alice", "REPORT", calendarURL, "thunderbird_calendar_query_out_of_range.xml", nil)
+	assert.NotContains(t, body, caldavEventID)
+
+	resp, body = caldavRequest(t, "alice", "REPORT", calendarURL, "thunderbird_calendar_multiget.xml", nil)
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	assert.Contains(t, body, "<c:calendar-data>BEGIN:VCALENDAR")
+	assert.Contains(t, body, "missing.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>")
+
+	resp, _ = caldavRequest(t, "alice", "REPORT", calendarURL, "apple_sync_collection.xml", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// Изменение чужой версии отклоняется, текущей - проходит
+	resp, _ = caldavRequest(t, "alice", http.MethodPut, eventURL, "apple_put_event.ics",
+		map[string]string{"If-Match": `"7"`})
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	// Достаточно, чтобы текущая версия была в списке If-Match
+	resp, _ = caldavRequest(t, "alice", http.MethodPut, eventURL, "apple_put_event.ics",
+		map[string]string{"If-Match": `"7", W/"1"`})
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+
+	resp, body = caldavRequest(t, "alice", "PROPFIND", calendarURL, "apple_propfind_home.xml",
+		map[string]string{"Depth": "1"})
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	assert.Contains(t, body, "<d:getetag>&#34;2&#34;</d:getetag>")
+	assert.Contains(t, body, "<cs:getctag>")
+
+	// Чужой пользователь не видит календарь
+	resp, _ = caldavRequest(t, "bob", "REPORT", ts.URL+"/dav/calendars/bob/personal%3Aalice/", "thunderbird_calendar_query.xml", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, _ = caldavRequest(t, "alice", http.MethodDelete, eventURL, "", map[string]string{"If-Match": `"1", "3"`})
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	resp, _ = caldavRequest(t, "alice", http.MethodDelete, eventURL, "", map[string]string{"If-Match": `"1", "2"`})
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = caldavRequest(t, "alice", http.MethodGet, eventURL, "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,17 @@
+package internalhttp
+
+import (
+	"net/http"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/etag"
+)
+
+// ifMatch возвращает версии из If-Match; отсутствие заголовка или * означает "без проверки"
+func ifMatch(r *http.Request) ([]int64, error) {
+	return etag.IfMatch(r.Header.Get("If-Match"))
+}
+
+// notModified проверяет If-None-Match для GET: клиенту не нужно тело, если его копия актуальна
+func notModified(r *http.Request, version int64) bool {
+	return etag.NoneMatch(r.Header.Get("If-None-Match"), version)
+}
@@ -2,11 +2,14 @@ package internalhttp
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/api"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/ratelimit"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -20,34 +23,94 @@ type HttpServer struct {
 	server      *http.Server
 }
 
-func NewServer(logger server.Logger, host string, port int, app server.Application) server.CalculatorServer {
+type options struct {
+	handlers  []mountedHandler
+	rateLimit *ratelimit.Switch
+	tls       *tls.Config
+}
+
+type mountedHandler struct {
+	pattern string
+	handler http.Handler
+}
+
+// Option настраивает HTTP сервер при создании
+type Option func(*options)
+
+// WithHandler подключает дополнительный обработчик, например REST-шлюз к gRPC, по шаблону пути chi
+func WithHandler(pattern string, handler http.Handler) Option {
+	return func(o *options) {
+		o.handlers = append(o.handlers, mountedHandler{pattern: pattern, handler: handler})
+	}
+}
+
+// WithTLS включает HTTPS; сертификаты берутся из конфигурации, собранной tlsconfig
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+	}
+}
+
+func NewServer(logger server.Logger, host string, port int, app server.Application, opts ...Option) server.CalculatorServer {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	router := route.NewRouter()
 
 	router.Use(middleware.RequestID)
-	router.Use(middleware.Recoverer)
+	router.Use(requestContextMiddleware)
+	router.Use(recoverMiddleware(logger))
 	router.Use(middleware.RealIP)
-	router.Use(middleware.Timeout(60 * time.Second))
+	router.Use(timeoutMiddleware(60 * time.Second))
 	router.Use(func(next http.Handler) http.Handler {
 		return loggingMiddleware(logger, next)
 	})
 
-	router.Get("/hello", func(w http.ResponseWriter, r *http.Request) {
-		_, _ = w.Write([]byte("Hello, World!"))
+	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, http.StatusNotFound, "no such resource: "+r.URL.Path)
+	})
+	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, http.StatusMethodNotAllowed, r.Method+" is not supported for "+r.URL.Path)
 	})
-	router.Route("/events", func(router route.Router) {
-		router.Get("/day", getEventsForDay(app, logger))
-		router.Get("/week", getEventsForWeek(app, logger))
-		router.Get("/month", getEventsForMonth(app, logger))
-		router.Get("/", getEvents(app, logger)) // старый универсальный, можно оставить для обратной совместимости
-		router.Post("/", addNewEvent(app, logger))
-		router.Get("/{id}", getEvent(app, logger))
-		router.Put("/{id}", updateEvent(app, logger))
-		router.Delete("/{id}", deleteEvent(app, logger))
+
+	// REST-шлюз /v1 вызывает gRPC сервер, и частоту его запросов ограничивает перехватчик gRPC,
+	// поэтому шлюз подключается вне группы с rateLimitMiddleware
+	for _, mounted := range o.handlers {
+		router.Handle(mounted.pattern, mounted.handler)
+	}
+
+	router.Group(func(router route.Router) {
+		if o.rateLimit != nil {
+			router.Use(rateLimitMiddleware(o.rateLimit, logger))
+		}
+
+		router.Get("/hello", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("Hello, World!"))
+		})
+		// Спецификация REST API, сгенерированная из EventService.proto
+		router.Get("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(api.OpenAPISpec)
+		})
+
+		// Вложений нет в gRPC API: файлы загружаются потоком multipart/form-data,
+		// поэтому они обслуживаются здесь, рядом с путями шлюза
+		router.Route("/v1/events/{id}/attachments", func(router route.Router) {
+			router.Get("/", getAttachments(app, logger))
+			router.Post("/", addAttachment(app, logger))
+			router.Get("/{attachmentID}", downloadAttachment(app, logger))
+			router.Delete("/{attachmentID}", deleteAttachment(app, logger))
+		})
+
+		mountCalDAV(router, app, logger)
 	})
 
 	srv := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", host, port),
-		Handler: router,
+		Addr:      fmt.Sprintf("%s:%d", host, port),
+		Handler:   router,
+		TLSConfig: o.tls,
 	}
 
 	return &HttpServer{
@@ -57,14 +120,35 @@ func NewServer(logger server.Logger, host string, port int, app server.Applicati
 	}
 }
 
+// Start обслуживает запросы, пока не отменён контекст или сервер не упал
 func (s *HttpServer) Start(ctx context.Context) error {
+	serveErr := make(chan error, 1)
 	go func() {
-		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			s.logger.Error("http server error: " + err.Error())
+		if err := s.listenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
 		}
+		close(serveErr)
 	}()
-	<-ctx.Done()
-	return nil
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-serveErr:
+		return err
+	}
+}
+
+// listenAndServe - сертификат задан в TLSConfig, поэтому имена файлов не передаются
+func (s *HttpServer) listenAndServe() error {
+	if s.server.TLSConfig != nil {
+		return s.server.ListenAndServeTLS("", "")
+	}
+	return s.server.ListenAndServe()
+}
+
+// Handler - маршрутизатор сервера со всеми middleware, например чтобы поднять его в httptest
+func (s *HttpServer) Handler() http.Handler {
+	return s.server.Handler
 }
 
 func (s *HttpServer) Stop(ctx context.Context) error {
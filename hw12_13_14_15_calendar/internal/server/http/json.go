@@ -0,0 +1,27 @@
+package internalhttp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+func fromJson[T any](reader io.ReadCloser) (T, error) {
+	decoder := json.NewDecoder(reader)
+	var result T
+	err := decoder.Decode(&result)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+func sendInResponse(writer http.ResponseWriter, data interface{}, status int) error {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(data); err != nil {
+		return err
+	}
+	return nil
+}
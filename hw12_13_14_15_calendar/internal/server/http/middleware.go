@@ -1,12 +1,44 @@
 package internalhttp
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server"
 	"net/http"
+	"runtime/debug"
 	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server"
+	"github.com/go-chi/chi/v5/middleware"
 )
 
+// UserIDHeader - заголовок, которым клиент сообщает, от чьего имени выполняется запрос
+const UserIDHeader = "X-User-ID"
+
+// UserGroupsHeader - группы пользователя через запятую; по ним выдаётся доступ к календарям
+const UserGroupsHeader = "X-User-Groups"
+
+// requestContextMiddleware переносит ID запроса (его назначает middleware.RequestID)
+// и пользователя с его группами из заголовков в requestctx, где их находят журнал изменений и логи.
+// ID запроса возвращается клиенту в заголовке ответа.
+func requestContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if requestID := middleware.GetReqID(ctx); requestID != "" {
+			ctx = requestctx.WithRequestID(ctx, requestID)
+			w.Header().Set(middleware.RequestIDHeader, requestID)
+		}
+		if actor := r.Header.Get(UserIDHeader); actor != "" {
+			ctx = requestctx.WithActor(ctx, actor)
+		}
+		if groups := requestctx.ParseGroups(r.Header.Get(UserGroupsHeader)); len(groups) > 0 {
+			ctx = requestctx.WithGroups(ctx, groups)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func loggingMiddleware(logger server.Logger, next http.Handler) http.Handler { //nolint:unused
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -41,3 +73,42 @@ type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
 }
+
+// recoverMiddleware заменяет middleware.Recoverer: паника в обработчике превращается в 500 с телом problem+json
+func recoverMiddleware(logger server.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				// Так обработчик прерывает ответ намеренно, сервер обработает это сам
+				if rec == http.ErrAbortHandler { //nolint:errorlint
+					panic(rec)
+				}
+				logger.Error(fmt.Sprintf("http panic in %s %s: %v\n%s", r.Method, r.URL.Path, rec, debug.Stack()))
+				writeProblem(w, r, http.StatusInternalServerError, "")
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// timeoutMiddleware заменяет middleware.Timeout: обработчик получает контекст с дедлайном,
+// и если он истёк, а ответ ещё не начат, клиент получает 504 с телом problem+json
+func timeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && ww.Status() == 0 {
+				writeProblem(w, r, http.StatusGatewayTimeout, "request took longer than "+timeout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package internalhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/validate"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ProblemContentType - тип ответа с ошибкой
+const ProblemContentType = "application/problem+json"
+
+// Problem - тело ошибки в формате RFC 7807. Им отвечают все обработчики, кроме CalDAV
+// (у WebDAV свои ответы).
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	RequestID     string         `json:"request_id,omitempty"` // Тот же, что в заголовке X-Request-Id
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+// InvalidParam - поле запроса, не прошедшее проверку
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// writeProblem отвечает ошибкой status с пояснением detail
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	SendProblem(w, r, Problem{Status: status, Detail: detail})
+}
+
+// writeError подбирает статус по ошибке: ошибки валидации - 400 со списком полей,
+// ошибки хранилища - по storageErrorStatus. Текст внутренних ошибок клиенту не показывается.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var errs validate.Errors
+	if errors.As(err, &errs) {
+		problem := Problem{Status: http.StatusBadRequest, Detail: "request has invalid fields"}
+		for _, fieldErr := range errs {
+			problem.InvalidParams = append(problem.InvalidParams, InvalidParam{Name: fieldErr.Field, Reason: fieldErr.Message})
+		}
+		SendProblem(w, r, problem)
+		return
+	}
+
+	status := storageErrorStatus(err)
+	detail := err.Error()
+	if status >= http.StatusInternalServerError {
+		detail = ""
+	}
+	writeProblem(w, r, status, detail)
+}
+
+// writeBadRequest - 400 для запроса, который не удалось разобрать или который не прошёл проверку
+func writeBadRequest(w http.ResponseWriter, r *http.Request, err error) {
+	var errs validate.Errors
+	if errors.As(err, &errs) {
+		writeError(w, r, err)
+		return
+	}
+	writeProblem(w, r, http.StatusBadRequest, err.Error())
+}
+
+// storageErrorStatus подбирает HTTP-статус для ошибки хранилища
+func storageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrEventNotFound),
+		errors.Is(err, storage.ErrCalendarNotFound),
+		errors.Is(err, storage.ErrGrantNotFound),
+		errors.Is(err, storage.ErrAttachmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, storage.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, storage.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, storage.ErrEventExists), errors.Is(err, storage.ErrCalendarExists):
+		return http.StatusConflict
+	case errors.Is(err, storage.ErrBatchAborted):
+		return http.StatusFailedDependency
+	case errors.Is(err, storage.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, storage.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, storage.ErrAttachmentsDisabled):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// SendProblem отвечает ошибкой problem: тип и заголовок подбираются по статусу,
+// ID запроса, если он не задан, берётся из X-Request-Id
+func SendProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	problem.Type = "about:blank"
+	problem.Title = http.StatusText(problem.Status)
+	if problem.RequestID == "" {
+		problem.RequestID = middleware.GetReqID(r.Context())
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,94 @@
+package internalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeProblem(t *testing.T, resp *http.Response) Problem {
+	t.Helper()
+	assert.Equal(t, ProblemContentType, resp.Header.Get("Content-Type"))
+	var problem Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	return problem
+}
+
+func TestProblemForMissingEvent(t *testing.T) {
+	ts, _ := setupTestServer(t)
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v1/events/missing/attachments", nil)
+	req.Header.Set(UserIDHeader, "alice")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	problem := decodeProblem(t, resp)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "about:blank", problem.Type)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "event not found", problem.Detail)
+	assert.NotEmpty(t, problem.RequestID)
+	assert.Equal(t, resp.Header.Get("X-Request-Id"), problem.RequestID)
+}
+
+func TestProblemForInvalidFields(t *testing.T) {
+	ts, _ := setupTestServer(t)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/v1/events/missing/attachments", "application/json",
+		strings.NewReader(`{"url": "javascript:alert(1)"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	problem := decodeProblem(t, resp)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	require.Len(t, problem.InvalidParams, 1)
+	assert.Equal(t, "url", problem.InvalidParams[0].Name)
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	handler := recoverMiddleware(logger.New("error"))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	resp := rec.Result()
+	defer resp.Body.Close()
+	problem := decodeProblem(t, resp)
+	assert.Equal(t, http.StatusInternalServerError, problem.Status)
+	assert.Empty(t, problem.Detail)
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	handler := timeoutMiddleware(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	resp := rec.Result()
+	defer resp.Body.Close()
+	problem := decodeProblem(t, resp)
+	assert.Equal(t, http.StatusGatewayTimeout, problem.Status)
+
+	// Начатый ответ не портится
+	handler = timeoutMiddleware(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		<-r.Context().Done()
+	}))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
@@ -0,0 +1,58 @@
+package internalhttp
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/ratelimit"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server"
+)
+
+// WithRateLimit ограничивает частоту запросов каждого пользователя (или адреса, если пользователь не указан).
+// Политику можно заменить на лету через policy.Store.
+func WithRateLimit(policy *ratelimit.Switch) Option {
+	return func(o *options) {
+		o.rateLimit = policy
+	}
+}
+
+// rateLimitMiddleware отвечает 429 с заголовком Retry-After, если клиент исчерпал лимит своего класса запросов.
+// Если ограничитель недоступен (например, упала база), запрос пропускается: лучше без лимита, чем без календаря.
+func rateLimitMiddleware(policy *ratelimit.Switch, logger server.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			class := requestClass(r)
+			client := ratelimit.Client(requestctx.Actor(r.Context()), remoteIP(r))
+			retryAfter, err := policy.Allow(r.Context(), class, client)
+			if err != nil {
+				logger.Warn("rate limiter failed, request allowed: " + err.Error())
+			}
+			if retryAfter > 0 {
+				w.Header().Set("Retry-After", ratelimit.RetryAfterSeconds(retryAfter))
+				writeProblem(w, r, http.StatusTooManyRequests, "too many "+class+" requests, retry later")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requestClass определяет класс запроса по методу. Пути /v1 шлюза сюда не попадают:
+// их, как и вызовы gRPC, классифицирует RateLimitInterceptor
+func requestClass(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, "PROPFIND", "REPORT":
+		return ratelimit.ClassRead
+	default:
+		return ratelimit.ClassWrite
+	}
+}
+
+// remoteIP - адрес клиента без порта; за прокси его уже подставил middleware.RealIP
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,88 @@
+package internalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/ratelimit"
+	memorystorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	logg := logger.New("error")
+	calendar := app.New(logg, memorystorage.New(logg))
+	policy := ratelimit.Policy{
+		Limiter: ratelimit.NewMemoryLimiter(),
+		Rules:   map[string]ratelimit.Rule{ratelimit.ClassRead: {Limit: 1, Per: time.Minute}},
+	}
+	gateway := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	limits := ratelimit.NewSwitch(policy)
+	srv := NewServer(logg, "localhost", 0, calendar, WithRateLimit(limits), WithHandler("/v1/*", gateway))
+	ts := httptest.NewServer(srv.(*HttpServer).server.Handler)
+	defer ts.Close()
+
+	do := func(user, method, path string) *http.Response {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set(UserIDHeader, user)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := do("alice", http.MethodGet, "/v1/events/planning/attachments")
+	resp.Body.Close()
+	assert.NotEqual(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	resp = do("alice", http.MethodGet, "/v1/events/planning/attachments")
+	problem := decodeProblem(t, resp)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, problem.Status)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+
+	// Лимит считается для каждого пользователя отдельно
+	resp = do("bob", http.MethodGet, "/v1/events/planning/attachments")
+	resp.Body.Close()
+	assert.NotEqual(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	// Запись не ограничена правилом чтения
+	resp = do("alice", http.MethodDelete, "/v1/events/planning/attachments/agenda")
+	resp.Body.Close()
+	assert.NotEqual(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	// Шлюз ограничивает перехватчик gRPC, HTTP-лимит его не трогает
+	for i := 0; i < 3; i++ {
+		resp = do("alice", http.MethodGet, "/v1/events:day")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	// Политика, заменённая при перечитывании конфигурации, действует без перезапуска сервера
+	limits.Store(ratelimit.Policy{})
+	resp = do("alice", http.MethodGet, "/v1/events/planning/attachments")
+	resp.Body.Close()
+	assert.NotEqual(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
+func TestRequestClass(t *testing.T) {
+	tests := map[string]string{
+		"GET /v1/events/1/attachments":        ratelimit.ClassRead,
+		"PROPFIND /caldav/alice/":             ratelimit.ClassRead,
+		"REPORT /caldav/calendars/alice/p/":   ratelimit.ClassRead,
+		"POST /v1/events/1/attachments":       ratelimit.ClassWrite,
+		"PUT /caldav/calendars/alice/p/1.ics": ratelimit.ClassWrite,
+	}
+	for request, class := range tests {
+		method, path, _ := strings.Cut(request, " ")
+		assert.Equal(t, class, requestClass(httptest.NewRequest(method, path, nil)), request)
+	}
+}
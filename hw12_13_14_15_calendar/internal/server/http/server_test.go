@@ -4,15 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/blob"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
 	memorystorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/memory"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -30,255 +33,231 @@ func setupTestServer(t *testing.T) (*httptest.Server, *app.App) {
 	return ts, calendar
 }
 
-func TestCreateEvent(t *testing.T) {
-	ts, _ := setupTestServer(t)
-	defer ts.Close()
+// userTransport подставляет X-User-ID, как шлюз аутентификации перед сервисом
+type userTransport struct {
+	user string
+}
 
-	event := EventRequest{
-		Title:        "Test Event",
-		Description:  "Test Description",
-		UserID:       "user123",
-		StartTime:    time.Now().Add(time.Hour),
-		Duration:     calendar_types.CalendarDuration(time.Hour),
-		NotifyBefore: calendar_types.CalendarDuration(15 * time.Minute),
+func (u userTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Header.Get(UserIDHeader) == "" {
+		r = r.Clone(r.Context())
+		r.Header.Set(UserIDHeader, u.user)
 	}
-
-	body, _ := json.Marshal(event)
-	resp, err := http.Post(ts.URL+"/events/", "application/json", bytes.NewBuffer(body))
-
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-	var response EventResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
-
-	assert.Equal(t, event.Title, response.Title)
-	assert.Equal(t, event.Description, response.Description)
-	assert.Equal(t, event.UserID, response.UserID)
+	return http.DefaultTransport.RoundTrip(r)
 }
 
-func TestGetEvent(t *testing.T) {
-	ts, calendar := setupTestServer(t)
+func TestOpenAPISpec(t *testing.T) {
+	ts, _ := setupTestServer(t)
 	defer ts.Close()
 
-	// Создаем событие
-	eventID := "test-event-123"
-	err := calendar.CreateEvent(
-		context.Background(),
-		eventID, "Test Event", "Test Description", "user123",
-		time.Now().Add(time.Hour),
-		calendar_types.CalendarDuration(time.Hour),
-		calendar_types.CalendarDuration(15*time.Minute),
-	)
-	require.NoError(t, err)
-
-	// Получаем событие
-	resp, err := http.Get(fmt.Sprintf("%s/events/%s", ts.URL, eventID))
+	resp, err := http.Get(ts.URL + "/openapi.json")
 	require.NoError(t, err)
+	defer resp.Body.Close()
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
 
-	var response EventResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
-
-	assert.Equal(t, eventID, response.ID)
-	assert.Equal(t, "Test Event", response.Title)
+	var spec struct {
+		Paths map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&spec))
+	assert.Contains(t, spec.Paths, "/v1/events")
+	assert.Contains(t, spec.Paths, "/v1/events/{id}")
 }
 
-func TestUpdateEvent(t *testing.T) {
-	ts, calendar := setupTestServer(t)
-	defer ts.Close()
+func TestMountedHandler(t *testing.T) {
+	logg := logger.New("debug")
+	calendar := app.New(logg, memorystorage.New(logg))
+	mounted := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	})
 
-	// Создаем событие
-	eventID := "test-event-456"
-	err := calendar.CreateEvent(
-		context.Background(),
-		eventID, "Original Title", "Original Description", "user123",
-		time.Now().Add(time.Hour),
-		calendar_types.CalendarDuration(time.Hour),
-		calendar_types.CalendarDuration(15*time.Minute),
-	)
-	require.NoError(t, err)
+	server := NewServer(logg, "localhost", 0, calendar, WithHandler("/v1/*", mounted))
+	ts := httptest.NewServer(server.(*HttpServer).server.Handler)
+	defer ts.Close()
 
-	// Обновляем событие
-	updateEvent := EventRequest{
-		Title:        "Updated Title",
-		Description:  "Updated Description",
-		UserID:       "user123",
-		StartTime:    time.Now().Add(2 * time.Hour),
-		Duration:     calendar_types.CalendarDuration(2 * time.Hour),
-		NotifyBefore: calendar_types.CalendarDuration(30 * time.Minute),
+	for _, path := range []string{"/v1/events/42", "/v1/events/42/history", "/v1/events:day"} {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, path, string(body))
 	}
 
-	body, _ := json.Marshal(updateEvent)
-	req, _ := http.NewRequest("PUT", fmt.Sprintf("%s/events/%s", ts.URL, eventID), bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-	var response EventResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
+	// Вложения обслуживает сам HTTP-сервер, а не подключённый шлюз
+	resp, err := http.Get(ts.URL + "/v1/events/42/attachments")
 	require.NoError(t, err)
-
-	assert.Equal(t, "Updated Title", response.Title)
-	assert.Equal(t, "Updated Description", response.Description)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	assert.NotEqual(t, "/v1/events/42/attachments", string(body))
 }
 
-func TestDeleteEvent(t *testing.T) {
-	ts, calendar := setupTestServer(t)
+func TestEventAttachments(t *testing.T) {
+	logg := logger.New("debug")
+	blobs := blob.NewMemoryS3()
+	calendar := app.New(logg, memorystorage.New(logg), app.WithAttachments(blob.NewS3Store(blobs, "calendar", ""), 1024))
+	ts := httptest.NewServer(NewServer(logg, "localhost", 0, calendar).(*HttpServer).server.Handler)
 	defer ts.Close()
 
-	// Создаем событие
-	eventID := "test-event-789"
-	err := calendar.CreateEvent(
-		context.Background(),
-		eventID, "Test Event", "Test Description", "user123",
-		time.Now().Add(time.Hour),
-		calendar_types.CalendarDuration(time.Hour),
-		calendar_types.CalendarDuration(15*time.Minute),
-	)
-	require.NoError(t, err)
-
-	// Удаляем событие
-	req, _ := http.NewRequest("DELETE", fmt.Sprintf("%s/events/%s", ts.URL, eventID), nil)
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-	// Проверяем что событие удалено
-	resp, err = http.Get(fmt.Sprintf("%s/events/%s", ts.URL, eventID))
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
-}
+	ctx := requestctx.WithActor(context.Background(), "user123")
+	require.NoError(t, calendar.CreateEvent(ctx, "planning", "Planning", "", "user123",
+		time.Now().Add(time.Hour), calendar_types.CalendarDuration(time.Hour), 0))
+	url := ts.URL + "/v1/events/planning/attachments"
+	client := &http.Client{Transport: userTransport{user: "user123"}}
+
+	upload := func(field, name string, content []byte) (*http.Response, AttachmentResponse) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		_ = writer.WriteField("comment", "goes before the file")
+		part, _ := writer.CreateFormFile(field, name)
+		_, _ = part.Write(content)
+		require.NoError(t, writer.Close())
+
+		resp, err := client.Post(url, writer.FormDataContentType(), &body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var attachment AttachmentResponse
+		if resp.StatusCode == http.StatusCreated {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&attachment))
+		}
+		return resp, attachment
+	}
 
-func TestListEventsForDay(t *testing.T) {
-	ts, calendar := setupTestServer(t)
-	defer ts.Close()
+	pdf := []byte("%PDF-1.4\nagenda")
+	resp, agenda := upload("file", "../docs/agenda.pdf", pdf)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "agenda.pdf", agenda.Name)
+	assert.Equal(t, "application/pdf", agenda.ContentType)
+	assert.Equal(t, int64(len(pdf)), agenda.Size)
+
+	// Тип содержимого определяется по самому файлу, а не по имени
+	resp, page := upload("file", "notes.txt", []byte("<html><script>alert(1)</script></html>"))
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", page.ContentType)
+
+	resp, _ = upload("file", "huge.bin", bytes.Repeat([]byte{1}, 2048))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	resp, _ = upload("document", "agenda.pdf", pdf)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, 2, blobs.Len())
 
-	// Создаем событие на сегодня
-	today := time.Now().Truncate(24 * time.Hour)
-	err := calendar.CreateEvent(
-		context.Background(),
-		"event1", "Today Event", "Description", "user123",
-		today.Add(10*time.Hour),
-		calendar_types.CalendarDuration(time.Hour),
-		calendar_types.CalendarDuration(15*time.Minute),
-	)
+	resp, err := client.Post(url, "application/json", bytes.NewBufferString(`{"url": "https://meet.example.com/abc"}`))
 	require.NoError(t, err)
-
-	// Получаем события за день
-	dateStr := today.Format("2006-01-02")
-	resp, err := http.Get(fmt.Sprintf("%s/events/?day=%s", ts.URL, dateStr))
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var call AttachmentResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&call))
+	resp.Body.Close()
+	assert.Equal(t, "link", call.Kind)
+	assert.Equal(t, "https://meet.example.com/abc", call.Name)
+
+	resp, err = client.Post(url, "application/json", bytes.NewBufferString(`{"url": "javascript:alert(1)"}`))
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	var response []EventResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
+	resp, err = client.Get(url)
 	require.NoError(t, err)
+	var attachments []AttachmentResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&attachments))
+	resp.Body.Close()
+	assert.Equal(t, []AttachmentResponse{agenda, page, call}, attachments)
 
-	assert.Len(t, response, 1)
-	assert.Equal(t, "Today Event", response[0].Title)
-}
-
-func TestListEventsForDayEndpoint(t *testing.T) {
-	ts, calendar := setupTestServer(t)
-	defer ts.Close()
-
-	today := time.Now().Truncate(24 * time.Hour)
-	err := calendar.CreateEvent(
-		context.Background(),
-		"event1", "Today Event", "Description", "user123",
-		today.Add(10*time.Hour),
-		calendar_types.CalendarDuration(time.Hour),
-		calendar_types.CalendarDuration(15*time.Minute),
-	)
+	// Файл скачивается как вложение с типом, определённым при загрузке
+	resp, err = client.Get(url + "/" + page.ID)
 	require.NoError(t, err)
-
-	dateStr := today.Format("2006-01-02")
-	resp, err := http.Get(fmt.Sprintf("%s/events/day?date=%s", ts.URL, dateStr))
+	content, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "<html><script>alert(1)</script></html>", string(content))
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=notes.txt`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+
+	noRedirect := &http.Client{Transport: client.Transport, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err = noRedirect.Get(url + "/" + call.ID)
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Equal(t, "https://meet.example.com/abc", resp.Header.Get("Location"))
 
-	var response []EventResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
+	// Без пользователя и чужому пользователю вложения не видны
+	resp, err = http.Get(url + "/" + agenda.ID)
 	require.NoError(t, err)
-	assert.Len(t, response, 1)
-	assert.Equal(t, "Today Event", response[0].Title)
-}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 
-func TestListEventsForWeekEndpoint(t *testing.T) {
-	ts, calendar := setupTestServer(t)
-	defer ts.Close()
-
-	today := time.Now().Truncate(24 * time.Hour)
-	err := calendar.CreateEvent(
-		context.Background(),
-		"event1", "Week Event", "Description", "user123",
-		today.Add(10*time.Hour),
-		calendar_types.CalendarDuration(time.Hour),
-		calendar_types.CalendarDuration(15*time.Minute),
-	)
+	request, _ := http.NewRequest(http.MethodGet, url+"/"+agenda.ID, nil)
+	request.Header.Set(UserIDHeader, "mallory")
+	resp, err = client.Do(request)
 	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
 
-	dateStr := today.Format("2006-01-02")
-	resp, err := http.Get(fmt.Sprintf("%s/events/week?date=%s", ts.URL, dateStr))
+	request, _ = http.NewRequest(http.MethodDelete, url+"/"+agenda.ID, nil)
+	resp, err = client.Do(request)
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-	var response []EventResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, err = client.Get(url + "/" + agenda.ID)
 	require.NoError(t, err)
-	assert.Len(t, response, 1)
-	assert.Equal(t, "Week Event", response[0].Title)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, 1, blobs.Len())
 }
 
-func TestListEventsForMonthEndpoint(t *testing.T) {
-	ts, calendar := setupTestServer(t)
+func TestAttachmentContentType(t *testing.T) {
+	logg := logger.New("debug")
+	calendar := app.New(logg, memorystorage.New(logg), app.WithAttachments(blob.NewS3Store(blob.NewMemoryS3(), "calendar", ""), 1024))
+	ts := httptest.NewServer(NewServer(logg, "localhost", 0, calendar).(*HttpServer).server.Handler)
 	defer ts.Close()
 
-	today := time.Now().Truncate(24 * time.Hour)
-	err := calendar.CreateEvent(
-		context.Background(),
-		"event1", "Month Event", "Description", "user123",
-		today.Add(10*time.Hour),
-		calendar_types.CalendarDuration(time.Hour),
-		calendar_types.CalendarDuration(15*time.Minute),
-	)
-	require.NoError(t, err)
-
-	dateStr := today.Format("2006-01-02")
-	resp, err := http.Get(fmt.Sprintf("%s/events/month?date=%s", ts.URL, dateStr))
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	ctx := requestctx.WithActor(context.Background(), "user123")
+	require.NoError(t, calendar.CreateEvent(ctx, "planning", "Planning", "", "user123",
+		time.Now().Add(time.Hour), calendar_types.CalendarDuration(time.Hour), 0))
+	client := &http.Client{Transport: userTransport{user: "user123"}}
 
-	var response []EventResponse
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	require.NoError(t, err)
-	assert.Len(t, response, 1)
-	assert.Equal(t, "Month Event", response[0].Title)
+	// Нераспознанное содержимое получает тип по расширению только из безопасного списка
+	tests := map[string]string{
+		"page.html":  "application/octet-stream",
+		"Report.DOC": "application/msword",
+		"data.bin":   "application/octet-stream",
+	}
+	for name, want := range tests {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, _ := writer.CreateFormFile("file", name)
+		_, _ = part.Write([]byte{0xd0, 0xcf, 0x11, 0xe0})
+		require.NoError(t, writer.Close())
+
+		resp, err := client.Post(ts.URL+"/v1/events/planning/attachments", writer.FormDataContentType(), &body)
+		require.NoError(t, err)
+		var attachment AttachmentResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&attachment))
+		resp.Body.Close()
+		assert.Equal(t, want, attachment.ContentType, name)
+	}
 }
 
-func TestInvalidDateFormat(t *testing.T) {
-	ts, _ := setupTestServer(t)
+func TestAttachmentsWithoutBlobStore(t *testing.T) {
+	ts, calendar := setupTestServer(t)
 	defer ts.Close()
+	require.NoError(t, calendar.CreateEvent(requestctx.WithActor(context.Background(), "user123"), "planning", "Planning", "", "user123",
+		time.Now().Add(time.Hour), calendar_types.CalendarDuration(time.Hour), 0))
 
-	// Тестируем неверный формат даты
-	resp, err := http.Get(ts.URL + "/events/?day=invalid-date")
-	require.NoError(t, err)
-	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
-}
+	client := &http.Client{Transport: userTransport{user: "user123"}}
 
-func TestInvalidEventID(t *testing.T) {
-	ts, _ := setupTestServer(t)
-	defer ts.Close()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "agenda.pdf")
+	_, _ = part.Write([]byte("agenda"))
+	require.NoError(t, writer.Close())
+	resp, err := client.Post(ts.URL+"/v1/events/planning/attachments", writer.FormDataContentType(), &body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
 
-	// Тестируем несуществующий ID
-	resp, err := http.Get(ts.URL + "/events/non-existent-id")
+	// Ссылки хранилище содержимого не требуют
+	resp, err = client.Post(ts.URL+"/v1/events/planning/attachments", "application/json",
+		bytes.NewBufferString(`{"name": "Call", "url": "https://meet.example.com/abc"}`))
 	require.NoError(t, err)
-	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 }
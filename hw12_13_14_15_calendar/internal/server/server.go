@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"io"
+
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
 	"time"
@@ -22,18 +24,57 @@ type Application interface {
 		duration, notifyBefore calendar_types.CalendarDuration,
 	) error
 
+	CreateEventInCalendar(
+		ctx context.Context,
+		calendarID, id, title, description, userID string,
+		startTime time.Time,
+		duration, notifyBefore calendar_types.CalendarDuration,
+		labels storage.Labels,
+	) error
+
+	CreateEventOnce(
+		ctx context.Context,
+		key, calendarID, id, title, description, userID string,
+		startTime time.Time,
+		duration, notifyBefore calendar_types.CalendarDuration,
+		labels storage.Labels,
+	) (storage.Event, error)
+
 	UpdateEvent(
 		ctx context.Context,
 		id, title, description, userID string,
 		startTime time.Time,
 		duration, notifyBefore calendar_types.CalendarDuration,
+		labels storage.Labels,
+		expectedVersion int64,
 	) error
 
-	DeleteEvent(ctx context.Context, id string) error
+	DeleteEvent(ctx context.Context, id string, expectedVersion int64) error
+	ListDeletedEvents(ctx context.Context) ([]storage.Event, error)
+	RestoreEvent(ctx context.Context, id string) error
+	SearchEvents(ctx context.Context, query storage.SearchQuery) ([]storage.SearchResult, error)
+	GetEventHistory(ctx context.Context, id string) ([]storage.AuditRecord, error)
+	BatchMutateEvents(ctx context.Context, ops []storage.BatchOperation, atomic bool) ([]storage.BatchResult, error)
 	GetEventByID(ctx context.Context, id string) (storage.Event, error)
-	ListEventsForDay(ctx context.Context, date time.Time) ([]storage.Event, error)
-	ListEventsForWeek(ctx context.Context, date time.Time) ([]storage.Event, error)
-	ListEventsForMonth(ctx context.Context, date time.Time) ([]storage.Event, error)
+	ListEventsForDay(ctx context.Context, date time.Time, tags []string) ([]storage.Event, error)
+	ListEventsForWeek(ctx context.Context, date time.Time, tags []string) ([]storage.Event, error)
+	ListEventsForMonth(ctx context.Context, date time.Time, tags []string) ([]storage.Event, error)
+	TagStats(ctx context.Context, from, to time.Time, limit int) ([]storage.TagStat, error)
+
+	AddFileAttachment(ctx context.Context, eventID, name string, body io.Reader) (storage.Attachment, error)
+	AddLinkAttachment(ctx context.Context, eventID, name, url string) (storage.Attachment, error)
+	ListAttachments(ctx context.Context, eventID string) ([]storage.Attachment, error)
+	OpenAttachment(ctx context.Context, eventID, id string) (storage.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, eventID, id string) error
+	MaxAttachmentSize() int64
+
+	CreateCalendar(ctx context.Context, name string, kind storage.CalendarKind) (storage.Calendar, error)
+	ListCalendars(ctx context.Context) ([]storage.CalendarAccess, error)
+	GetCalendar(ctx context.Context, id string) (storage.CalendarAccess, error)
+	ListCalendarEvents(ctx context.Context, calendarID string, from, to time.Time) ([]storage.Event, error)
+	ShareCalendar(ctx context.Context, entry storage.ACLEntry) error
+	RevokeCalendarAccess(ctx context.Context, calendarID string, granteeType storage.GranteeType, granteeID string) error
+	ListCalendarAccess(ctx context.Context, calendarID string) ([]storage.ACLEntry, error)
 }
 
 type CalculatorServer interface {
@@ -0,0 +1,25 @@
+package storage
+
+import "time"
+
+// AttachmentKind - вид вложения
+type AttachmentKind string
+
+const (
+	AttachmentFile AttachmentKind = "file" // Файл; содержимое лежит в хранилище вложений под ключом ID
+	AttachmentLink AttachmentKind = "link" // Ссылка, например на видеозвонок
+)
+
+// Attachment - файл или ссылка, прикреплённые к событию. Вложения удаляются вместе с событием,
+// когда планировщик окончательно удаляет его из корзины.
+type Attachment struct {
+	ID          string
+	EventID     string
+	Kind        AttachmentKind
+	Name        string    // Имя файла или подпись ссылки
+	ContentType string    // Тип содержимого файла
+	Size        int64     // Размер файла в байтах
+	URL         string    // Адрес ссылки
+	CreatedBy   string    // Кто прикрепил; пусто для внутренних вызовов
+	CreatedAt   time.Time // Когда прикрепили
+}
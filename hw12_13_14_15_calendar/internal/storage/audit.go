@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+)
+
+// AuditAction - вид изменения события
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+)
+
+// AuditRecord - запись журнала изменений события.
+// Before и After - состояние события до и после изменения; nil, если события не было видно.
+type AuditRecord struct {
+	ID        int64       // Порядковый номер записи, назначается хранилищем
+	EventID   string      // Идентификатор изменённого события
+	Action    AuditAction // Что сделали с событием
+	Actor     string      // Кто сделал изменение; пусто, если клиент не представился
+	RequestID string      // Идентификатор запроса, в котором сделано изменение
+	Before    *Event      // Состояние до изменения
+	After     *Event      // Состояние после изменения
+	CreatedAt time.Time   // Когда сделано изменение
+}
+
+// NewAuditRecord описывает изменение события id, сделанное в запросе ctx: пользователя и ID запроса
+// берёт из requestctx. Хранилища пишут запись в той же транзакции, что и само изменение,
+// поэтому журнал не расходится с событиями, даже если запрос прервётся.
+func NewAuditRecord(ctx context.Context, action AuditAction, id string, before, after *Event) AuditRecord {
+	return AuditRecord{
+		EventID:   id,
+		Action:    action,
+		Actor:     requestctx.Actor(ctx),
+		RequestID: requestctx.RequestID(ctx),
+		Before:    before,
+		After:     after,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,67 @@
+// Package backend выбирает реализацию хранилища по storage.type.
+// Им пользуются и календарь, и планировщик, поэтому набор типов у них один.
+package backend
+
+import (
+	"fmt"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/scheduler"
+	filestorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/file"
+	memorystorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/memory"
+	sqlstorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sql"
+	sqlitestorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlite"
+)
+
+// Значения storage.type
+const (
+	TypeMemory   = "memory"
+	TypeDatabase = "database"
+	TypeFile     = "file"
+	TypeSQLite   = "sqlite"
+)
+
+var Types = []string{TypeMemory, TypeDatabase, TypeFile, TypeSQLite}
+
+// Storage - хранилище событий, которое умеет отдавать события планировщику
+type Storage interface {
+	app.Storage
+	scheduler.NotificationStorage
+}
+
+// Settings - всё, что нужно для открытия хранилища; заполняется из конфигурации команды
+type Settings struct {
+	Type        string
+	PostgresDSN string               // для database
+	SQLitePath  string               // для sqlite
+	FilePath    string               // для file
+	FileOptions []filestorage.Option // для file
+}
+
+func Open(settings Settings, logger app.Logger) (Storage, error) {
+	switch settings.Type {
+	case TypeMemory:
+		return memorystorage.New(logger), nil
+	case TypeDatabase:
+		// Миграции выполняются отдельно
+		storage, err := sqlstorage.New(settings.PostgresDSN, logger)
+		if err != nil {
+			return nil, fmt.Errorf("sql storage failed: %w", err)
+		}
+		return storage, nil
+	case TypeFile:
+		storage, err := filestorage.New(settings.FilePath, logger, settings.FileOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("file storage failed: %w", err)
+		}
+		return storage, nil
+	case TypeSQLite:
+		storage, err := sqlitestorage.New(settings.SQLitePath, logger)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite storage failed: %w", err)
+		}
+		return storage, nil
+	default:
+		return nil, fmt.Errorf("unknown storage type: %s", settings.Type)
+	}
+}
@@ -0,0 +1,34 @@
+package storage
+
+// BatchOpType - вид операции в пакетном запросе
+type BatchOpType string
+
+const (
+	BatchCreate BatchOpType = "create"
+	BatchUpdate BatchOpType = "update"
+	BatchDelete BatchOpType = "delete"
+)
+
+// BatchOperation - одна операция пакета. Как и в UpdateEvent, Event.Version - ожидаемая версия
+// (0 - без проверки); для удаления используются только Event.ID и Event.Version.
+type BatchOperation struct {
+	Type  BatchOpType
+	Event Event
+}
+
+// BatchResult - результат одной операции пакета, в том же порядке, что и операции
+type BatchResult struct {
+	ID      string // Идентификатор события
+	Version int64  // Версия события после операции; 0, если операция не применена
+	Err     error  // Ошибка операции; ErrBatchAborted, если пакет откатили из-за другой операции
+}
+
+// AbortBatch помечает успешные операции как откаченные, когда пакет "всё или ничего" не применён
+func AbortBatch(results []BatchResult) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].Err = ErrBatchAborted
+			results[i].Version = 0
+		}
+	}
+}
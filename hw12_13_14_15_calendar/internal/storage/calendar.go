@@ -0,0 +1,84 @@
+package storage
+
+// CalendarKind - назначение календаря
+type CalendarKind string
+
+const (
+	CalendarPersonal CalendarKind = "personal"
+	CalendarTeam     CalendarKind = "team"
+	CalendarShared   CalendarKind = "shared"
+)
+
+// Calendar - календарь, которому принадлежат события. Владелец всегда имеет роль RoleOwner.
+type Calendar struct {
+	ID      string
+	Name    string
+	Kind    CalendarKind
+	OwnerID string
+}
+
+// PersonalCalendarID - идентификатор личного календаря пользователя.
+// В него попадают события, для которых календарь не указан.
+func PersonalCalendarID(userID string) string {
+	return "personal:" + userID
+}
+
+// Role - уровень доступа к календарю; роли упорядочены, старшая включает права младших
+type Role string
+
+const (
+	RoleNone     Role = ""
+	RoleFreeBusy Role = "free-busy" // Видно только занятое время, без названий и описаний
+	RoleRead     Role = "read"
+	RoleWrite    Role = "write"
+	RoleOwner    Role = "owner" // Кроме записи, может менять доступ к календарю
+)
+
+var roleRanks = map[Role]int{RoleNone: 0, RoleFreeBusy: 1, RoleRead: 2, RoleWrite: 3, RoleOwner: 4}
+
+// Valid сообщает, что роль можно выдать
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok && r != RoleNone
+}
+
+// Allows сообщает, что роль даёт права не меньше required
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
+// Max возвращает старшую из двух ролей
+func (r Role) Max(other Role) Role {
+	if roleRanks[other] > roleRanks[r] {
+		return other
+	}
+	return r
+}
+
+// GranteeType - кому выдан доступ: пользователю или группе
+type GranteeType string
+
+const (
+	GranteeUser  GranteeType = "user"
+	GranteeGroup GranteeType = "group"
+)
+
+// ACLEntry - доступ пользователя или группы к календарю
+type ACLEntry struct {
+	CalendarID  string
+	GranteeType GranteeType
+	GranteeID   string
+	Role        Role
+}
+
+// CalendarAccess - календарь и итоговая роль пользователя в нём
+type CalendarAccess struct {
+	Calendar Calendar
+	Role     Role
+}
+
+// Principal - тот, от чьего имени выполняется запрос, и его группы
+type Principal struct {
+	UserID string
+	Groups []string
+}
@@ -0,0 +1,32 @@
+package storage
+
+import "errors"
+
+var (
+	// ErrEventNotFound - события с таким ID нет в хранилище
+	ErrEventNotFound = errors.New("event not found")
+	// ErrVersionConflict - событие изменили после того, как клиент его прочитал
+	ErrVersionConflict = errors.New("event version conflict")
+	// ErrEventExists - событие с таким ID уже есть в хранилище
+	ErrEventExists = errors.New("event already exists")
+	// ErrCalendarNotFound - календаря с таким ID нет в хранилище
+	ErrCalendarNotFound = errors.New("calendar not found")
+	// ErrCalendarExists - календарь с таким ID уже есть в хранилище
+	ErrCalendarExists = errors.New("calendar already exists")
+	// ErrGrantNotFound - у пользователя или группы нет выданного доступа к календарю
+	ErrGrantNotFound = errors.New("access grant not found")
+	// ErrUnauthenticated - в запросе не указан пользователь, а вызов не помечен как внутренний
+	ErrUnauthenticated = errors.New("user is not specified")
+	// ErrForbidden - у пользователя нет нужной роли в календаре
+	ErrForbidden = errors.New("access denied")
+	// ErrBatchAborted - операция пакета не применена, потому что в режиме "всё или ничего" упала другая операция
+	ErrBatchAborted = errors.New("batch aborted")
+	// ErrIdempotencyKeyReused - ключ идемпотентности уже использован для запроса с другим содержимым
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+	// ErrAttachmentNotFound - вложения с таким ID нет у события
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrAttachmentTooLarge - файл вложения больше допустимого размера
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	// ErrAttachmentsDisabled - хранилище содержимого вложений не настроено, файлы прикреплять нельзя
+	ErrAttachmentsDisabled = errors.New("file attachments are not configured")
+)
@@ -5,12 +5,23 @@ import (
 	"time"
 )
 
+// Event хранится и в журнале изменений в виде JSON, поэтому имена полей в JSON заданы явно
 type Event struct {
-	ID           string                          // Уникальный идентификатор (например, UUID)
-	Title        string                          // Название события
-	StartTime    time.Time                       // Время начала события
-	Duration     calendar_types.CalendarDuration // Длительность события
-	Description  string                          // Подробное описание (опционально)
-	UserID       string                          // Идентификатор пользователя
-	NotifyBefore calendar_types.CalendarDuration // За сколько заранее отправить уведомление (опционально)
+	ID           string                          `json:"id"`                   // Уникальный идентификатор (например, UUID)
+	Title        string                          `json:"title"`                // Название события
+	StartTime    time.Time                       `json:"start_time"`           // Время начала события
+	Duration     calendar_types.CalendarDuration `json:"duration"`             // Длительность события
+	Description  string                          `json:"description"`          // Подробное описание (опционально)
+	UserID       string                          `json:"user_id"`              // Идентификатор пользователя
+	CalendarID   string                          `json:"calendar_id"`          // Календарь события; при обновлении пусто - оставить прежний
+	NotifyBefore calendar_types.CalendarDuration `json:"notify_before"`        // За сколько заранее отправить уведомление (опционально)
+	Version      int64                           `json:"version"`              // Версия для оптимистичной блокировки; при обновлении - ожидаемая версия (0 - без проверки)
+	DeletedAt    *time.Time                      `json:"deleted_at,omitempty"` // Когда событие перенесено в корзину; nil - событие активно
+	Labels                                       // Категория, цвет и теги
+}
+
+// ListQuery - отбор событий в списках за день, неделю и месяц
+type ListQuery struct {
+	CalendarIDs []string // Календари; пусто - все календари
+	Tags        []string // Нормализованные теги; событие должно иметь их все. Пусто - без отбора по тегам
 }
@@ -0,0 +1,358 @@
+package filestorage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	memorystorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/memory"
+)
+
+// Имена файлов в каталоге хранилища
+const (
+	walFile      = "wal.log"
+	snapshotFile = "snapshot.json"
+)
+
+// FsyncPolicy - когда записи журнала сбрасываются на диск
+type FsyncPolicy string
+
+const (
+	FsyncAlways   FsyncPolicy = "always"   // после каждой записи: изменение не теряется никогда
+	FsyncInterval FsyncPolicy = "interval" // раз в интервал: при сбое теряется не больше интервала
+	FsyncNever    FsyncPolicy = "never"    // сброс оставлен операционной системе
+)
+
+const (
+	DefaultFsyncInterval = time.Second
+	DefaultSnapshotEvery = 1000
+)
+
+type options struct {
+	fsync         FsyncPolicy
+	fsyncInterval time.Duration
+	snapshotEvery int
+}
+
+type Option func(*options)
+
+// WithFsync задаёт политику сброса журнала на диск; interval нужен только для FsyncInterval
+func WithFsync(policy FsyncPolicy, interval time.Duration) Option {
+	return func(o *options) {
+		o.fsync = policy
+		if interval > 0 {
+			o.fsyncInterval = interval
+		}
+	}
+}
+
+// WithSnapshotEvery задаёт, после скольких записей журнала делается снимок и журнал обрезается
+func WithSnapshotEvery(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.snapshotEvery = n
+		}
+	}
+}
+
+// Storage хранит данные в памяти (memorystorage) и дублирует каждое изменение в журнал на диске.
+// Журнал периодически сворачивается в снимок; при запуске состояние собирается из снимка и журнала.
+type Storage struct {
+	*memorystorage.Storage
+
+	dir     string
+	opts    options
+	logger  app.Logger
+	mu      sync.Mutex
+	wal     *os.File
+	seq     int64 // номер последней записи журнала
+	pending int   // записей со времени последнего снимка
+	dirty   bool  // есть записи, ещё не сброшенные на диск
+
+	snapshotCh chan struct{}
+	done       chan struct{}
+	wg         sync.WaitGroup
+}
+
+// walRecord - одна строка журнала: изменения одной операции хранилища
+type walRecord struct {
+	Seq     int64                  `json:"seq"`
+	Changes []memorystorage.Change `json:"changes"`
+}
+
+type snapshot struct {
+	Seq   int64               `json:"seq"`
+	State memorystorage.State `json:"state"`
+}
+
+// New открывает хранилище в каталоге dir, восстанавливая состояние из снимка и журнала
+func New(dir string, logger app.Logger, opts ...Option) (*Storage, error) {
+	o := options{fsync: FsyncAlways, fsyncInterval: DefaultFsyncInterval, snapshotEvery: DefaultSnapshotEvery}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	switch o.fsync {
+	case FsyncAlways, FsyncInterval, FsyncNever:
+	default:
+		return nil, fmt.Errorf("unknown fsync policy: %q", o.fsync)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create storage dir: %w", err)
+	}
+
+	strg := &Storage{
+		dir:        dir,
+		opts:       o,
+		logger:     logger,
+		snapshotCh: make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	strg.Storage = memorystorage.New(logger, memorystorage.WithJournal(strg))
+
+	if err := strg.recover(); err != nil {
+		return nil, err
+	}
+	wal, err := os.OpenFile(filepath.Join(dir, walFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open wal: %w", err)
+	}
+	strg.wal = wal
+
+	strg.wg.Add(1)
+	go strg.background()
+	return strg, nil
+}
+
+// Record дописывает изменения в журнал; вызывается memorystorage под его блокировкой
+func (strg *Storage) Record(changes []memorystorage.Change) error {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+
+	line, err := encodeRecord(walRecord{Seq: strg.seq + 1, Changes: changes})
+	if err != nil {
+		return err
+	}
+	offset, err := strg.wal.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("wal seek: %w", err)
+	}
+	if _, err := strg.wal.Write(line); err != nil {
+		// Недописанная строка испортила бы журнал для следующих записей
+		_ = strg.wal.Truncate(offset)
+		return fmt.Errorf("wal write: %w", err)
+	}
+	if strg.opts.fsync == FsyncAlways {
+		if err := strg.wal.Sync(); err != nil {
+			_ = strg.wal.Truncate(offset)
+			return fmt.Errorf("wal sync: %w", err)
+		}
+	} else {
+		strg.dirty = true
+	}
+
+	strg.seq++
+	strg.pending++
+	if strg.pending >= strg.opts.snapshotEvery {
+		select {
+		case strg.snapshotCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Close делает итоговый снимок и закрывает журнал
+func (strg *Storage) Close() error {
+	close(strg.done)
+	strg.wg.Wait()
+
+	err := strg.compact()
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+	if syncErr := strg.wal.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := strg.wal.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (strg *Storage) background() {
+	defer strg.wg.Done()
+
+	var tick <-chan time.Time
+	if strg.opts.fsync == FsyncInterval {
+		ticker := time.NewTicker(strg.opts.fsyncInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-strg.done:
+			return
+		case <-tick:
+			strg.mu.Lock()
+			if strg.dirty {
+				if err := strg.wal.Sync(); err != nil {
+					strg.logger.Error("wal sync: " + err.Error())
+				} else {
+					strg.dirty = false
+				}
+			}
+			strg.mu.Unlock()
+		case <-strg.snapshotCh:
+			if err := strg.compact(); err != nil {
+				strg.logger.Error("snapshot: " + err.Error())
+			}
+		}
+	}
+}
+
+// compact записывает снимок и обрезает журнал. Всё делается под блокировкой чтения хранилища,
+// поэтому новые изменения не попадут ни в снимок, ни в журнал, пока он не будет обрезан.
+func (strg *Storage) compact() error {
+	return strg.Storage.Snapshot(func(state memorystorage.State) error {
+		strg.mu.Lock()
+		defer strg.mu.Unlock()
+		if strg.pending == 0 {
+			return nil
+		}
+		if err := strg.writeSnapshot(snapshot{Seq: strg.seq, State: state}); err != nil {
+			return err
+		}
+		// После переименования снимка записи журнала уже в нём; если обрезка не случится,
+		// при восстановлении они будут пропущены по номеру
+		if err := strg.wal.Truncate(0); err != nil {
+			return fmt.Errorf("wal truncate: %w", err)
+		}
+		strg.pending = 0
+		strg.dirty = false
+		return nil
+	})
+}
+
+func (strg *Storage) writeSnapshot(snap snapshot) error {
+	path := filepath.Join(strg.dir, snapshotFile)
+	tmp, err := os.Create(path + ".tmp")
+	if err != nil {
+		return fmt.Errorf("create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := json.NewEncoder(tmp).Encode(snap); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename snapshot: %w", err)
+	}
+	return syncDir(strg.dir)
+}
+
+// recover загружает снимок и повторяет журнал после него. Повреждённая или недописанная
+// запись (обрыв питания посреди записи) считается концом журнала и отрезается.
+func (strg *Storage) recover() error {
+	var snap snapshot
+	data, err := os.ReadFile(filepath.Join(strg.dir, snapshotFile))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return fmt.Errorf("read snapshot: %w", err)
+		}
+		strg.Storage.Restore(snap.State)
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+	strg.seq = snap.Seq
+
+	path := filepath.Join(strg.dir, walFile)
+	wal, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open wal: %w", err)
+	}
+	defer wal.Close()
+
+	var offset int64
+	reader := bufio.NewReader(wal)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			return nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("read wal: %w", err)
+		}
+		record, decodeErr := decodeRecord(line)
+		if err != nil || decodeErr != nil {
+			strg.logger.Warn(fmt.Sprintf("wal is damaged at offset %d, dropping the tail", offset))
+			if err := wal.Truncate(offset); err != nil {
+				return fmt.Errorf("truncate wal: %w", err)
+			}
+			return wal.Sync()
+		}
+		offset += int64(len(line))
+		if record.Seq <= strg.seq {
+			continue
+		}
+		strg.Storage.ApplyChanges(record.Changes)
+		strg.seq = record.Seq
+		strg.pending++
+	}
+}
+
+// Строка журнала: контрольная сумма CRC32 JSON в hex, пробел, JSON, перевод строки
+func encodeRecord(record walRecord) ([]byte, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("encode wal record: %w", err)
+	}
+	line := fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(payload), payload)
+	return []byte(line), nil
+}
+
+func decodeRecord(line []byte) (walRecord, error) {
+	var record walRecord
+	if len(line) < 10 || line[8] != ' ' || line[len(line)-1] != '\n' {
+		return record, errors.New("malformed wal record")
+	}
+	sum, err := strconv.ParseUint(string(line[:8]), 16, 32)
+	if err != nil {
+		return record, fmt.Errorf("malformed wal checksum: %w", err)
+	}
+	payload := line[9 : len(line)-1]
+	if crc32.ChecksumIEEE(payload) != uint32(sum) {
+		return record, errors.New("wal checksum mismatch")
+	}
+	err = json.Unmarshal(payload, &record)
+	return record, err
+}
+
+// syncDir сбрасывает на диск каталог, чтобы переименование файла пережило сбой
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
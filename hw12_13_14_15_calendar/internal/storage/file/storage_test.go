@@ -0,0 +1,194 @@
+package filestorage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+func newEvent(id string) storage.Event {
+	return storage.Event{ID: id, Title: "Event " + id, StartTime: time.Now().UTC(), UserID: "user1"}
+}
+
+func openStorage(t *testing.T, dir string, opts ...Option) app.Storage {
+	t.Helper()
+	strg, err := New(dir, logger.New("debug"), opts...)
+	if err != nil {
+		t.Fatalf("failed to open storage: %v", err)
+	}
+	return strg
+}
+
+func TestRecoverFromWAL(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	strg := openStorage(t, dir)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := strg.AddEvent(ctx, newEvent(id)); err != nil {
+			t.Fatalf("failed to add event: %v", err)
+		}
+	}
+	updated := newEvent("a")
+	updated.Title = "Renamed"
+	if err := strg.UpdateEvent(ctx, updated); err != nil {
+		t.Fatalf("failed to update event: %v", err)
+	}
+	if err := strg.DeleteEvent(ctx, "b", 0); err != nil {
+		t.Fatalf("failed to delete event: %v", err)
+	}
+	if err := strg.CreateCalendar(ctx, storage.Calendar{ID: "team", Name: "Team", OwnerID: "user1"}); err != nil {
+		t.Fatalf("failed to create calendar: %v", err)
+	}
+	// Без Close: имитация падения процесса, снимок не сделан
+	reopened := openStorage(t, dir)
+	defer reopened.Close()
+
+	got, err := reopened.GetEventByID(ctx, "a")
+	if err != nil || got.Title != "Renamed" || got.Version != 2 {
+		t.Errorf("expected renamed event with version 2, got %+v, %v", got, err)
+	}
+	if _, err := reopened.GetEventByID(ctx, "b"); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Errorf("expected deleted event to stay deleted, got %v", err)
+	}
+	if _, err := reopened.GetCalendar(ctx, "team"); err != nil {
+		t.Errorf("expected calendar to be recovered, got %v", err)
+	}
+	if records, _ := reopened.ListAuditRecords(ctx, "a"); len(records) != 2 || records[1].Before == nil {
+		t.Errorf("expected audit records to be recovered with the change, got %+v", records)
+	}
+	results, err := reopened.SearchEvents(ctx, storage.SearchQuery{Text: "renamed", Limit: 10})
+	if err != nil || len(results) != 1 {
+		t.Errorf("expected search index to be rebuilt, got %v, %v", results, err)
+	}
+}
+
+func TestSnapshotAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	strg := openStorage(t, dir, WithSnapshotEvery(2), WithFsync(FsyncInterval, 10*time.Millisecond))
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		if err := strg.AddEvent(ctx, newEvent(id)); err != nil {
+			t.Fatalf("failed to add event: %v", err)
+		}
+	}
+	if err := strg.Close(); err != nil {
+		t.Fatalf("failed to close storage: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, walFile)); err != nil || info.Size() != 0 {
+		t.Errorf("expected wal to be truncated after final snapshot, got %v, %v", info, err)
+	}
+
+	reopened := openStorage(t, dir)
+	if err := reopened.AddEvent(ctx, newEvent("f")); err != nil {
+		t.Fatalf("failed to add event: %v", err)
+	}
+	reopened = openStorage(t, dir)
+	defer reopened.Close()
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		if _, err := reopened.GetEventByID(ctx, id); err != nil {
+			t.Errorf("expected event %s after snapshot and replay, got %v", id, err)
+		}
+	}
+}
+
+func TestTornWALTail(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	strg := openStorage(t, dir)
+	_ = strg.AddEvent(ctx, newEvent("a"))
+	_ = strg.AddEvent(ctx, newEvent("b"))
+
+	// Последняя запись оборвалась посреди строки
+	path := filepath.Join(dir, walFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read wal: %v", err)
+	}
+	if err := os.WriteFile(path, data[:len(data)-5], 0o644); err != nil {
+		t.Fatalf("failed to damage wal: %v", err)
+	}
+
+	reopened := openStorage(t, dir)
+	if _, err := reopened.GetEventByID(ctx, "a"); err != nil {
+		t.Errorf("expected intact record to be replayed, got %v", err)
+	}
+	if _, err := reopened.GetEventByID(ctx, "b"); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Errorf("expected torn record to be dropped, got %v", err)
+	}
+	// Новые записи идут после отрезанного хвоста и переживают перезапуск
+	if err := reopened.AddEvent(ctx, newEvent("c")); err != nil {
+		t.Fatalf("failed to add event: %v", err)
+	}
+	again := openStorage(t, dir)
+	defer again.Close()
+	if _, err := again.GetEventByID(ctx, "c"); err != nil {
+		t.Errorf("expected event written after recovery, got %v", err)
+	}
+}
+
+func TestIdempotencyKeysSurviveRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	record := storage.IdempotencyRecord{
+		UserID: "user1", Key: "retry-1", Fingerprint: "f1", EventID: "a", ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	strg := openStorage(t, dir)
+	if _, reserved, err := strg.ReserveIdempotencyKey(ctx, record); err != nil || !reserved {
+		t.Fatalf("expected key to be reserved, got %v, %v", reserved, err)
+	}
+	if err := strg.CompleteIdempotencyKey(ctx, "user1", "retry-1", newEvent("a")); err != nil {
+		t.Fatalf("failed to complete key: %v", err)
+	}
+	// Без Close ключ восстанавливается из журнала, после Close - из снимка
+	for i := 0; i < 2; i++ {
+		reopened := openStorage(t, dir)
+		existing, reserved, err := reopened.ReserveIdempotencyKey(ctx, record)
+		if err != nil || reserved || existing.Response == nil || existing.Response.ID != "a" {
+			t.Fatalf("expected saved response after restart %d, got %+v, %v, %v", i, existing, reserved, err)
+		}
+		if err := reopened.Close(); err != nil {
+			t.Fatalf("failed to close storage: %v", err)
+		}
+	}
+}
+
+func TestAttachmentsSurviveRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	strg := openStorage(t, dir)
+	if err := strg.AddEvent(ctx, newEvent("a")); err != nil {
+		t.Fatalf("failed to add event: %v", err)
+	}
+	for _, id := range []string{"agenda", "call"} {
+		attachment := storage.Attachment{ID: id, EventID: "a", Kind: storage.AttachmentFile, CreatedAt: time.Now().UTC()}
+		if err := strg.AddAttachment(ctx, attachment); err != nil {
+			t.Fatalf("failed to add attachment: %v", err)
+		}
+	}
+	if err := strg.DeleteAttachment(ctx, "call"); err != nil {
+		t.Fatalf("failed to delete attachment: %v", err)
+	}
+	// Без Close вложения восстанавливаются из журнала, после Close - из снимка
+	for i := 0; i < 2; i++ {
+		reopened := openStorage(t, dir)
+		attachments, err := reopened.ListAttachments(ctx, "a")
+		if err != nil || len(attachments) != 1 || attachments[0].ID != "agenda" {
+			t.Fatalf("expected only agenda after restart %d, got %v, %v", i, attachments, err)
+		}
+		if err := reopened.Close(); err != nil {
+			t.Fatalf("failed to close storage: %v", err)
+		}
+	}
+}
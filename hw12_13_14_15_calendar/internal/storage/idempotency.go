@@ -0,0 +1,20 @@
+package storage
+
+import "time"
+
+// IdempotencyRecord - запрос на создание события, выполненный с ключом идемпотентности.
+// Ключ действует в пределах пользователя: одинаковые ключи разных пользователей не мешают друг другу.
+// Запись хранится до ExpiresAt; после этого ключ можно использовать снова.
+type IdempotencyRecord struct {
+	UserID      string    `json:"user_id"`            // Кто выполнил запрос
+	Key         string    `json:"key"`                // Ключ из заголовка Idempotency-Key или поля request_id
+	Fingerprint string    `json:"fingerprint"`        // Хэш содержимого запроса: тот же ключ с другим запросом - ошибка клиента
+	EventID     string    `json:"event_id"`           // Событие, которое создаёт запрос
+	Response    *Event    `json:"response,omitempty"` // Созданное событие; nil, пока первый запрос не завершился
+	ExpiresAt   time.Time `json:"expires_at"`         // Когда запись перестаёт действовать
+}
+
+// Expired - истёк ли срок записи к моменту now
+func (r IdempotencyRecord) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
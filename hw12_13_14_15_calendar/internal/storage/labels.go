@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Labels - разметка события: категория и цвет для отображения, теги для фильтрации
+type Labels struct {
+	Category string   `json:"category,omitempty"` // Категория, например проект
+	Color    string   `json:"color,omitempty"`    // Цвет в виде #rrggbb
+	Tags     []string `json:"tags,omitempty"`     // Теги в нижнем регистре, без повторов, по алфавиту
+}
+
+// NormalizeLabels приводит разметку к виду, в котором она хранится: теги - как в NormalizeTags,
+// цвет - в нижнем регистре, у категории обрезаются пробелы
+func NormalizeLabels(labels Labels) Labels {
+	return Labels{
+		Category: strings.TrimSpace(labels.Category),
+		Color:    strings.ToLower(strings.TrimSpace(labels.Color)),
+		Tags:     NormalizeTags(labels.Tags),
+	}
+}
+
+// NormalizeTags переводит теги в нижний регистр, убирает пустые и повторы и сортирует;
+// для пустого списка возвращает nil
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var result []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// HasTags сообщает, есть ли у события все теги из tags; пустой tags подходит любому событию
+func (e Event) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, own := range e.Tags {
+			if own == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// TagStatsQuery - по каким событиям считать статистику тегов. Учитываются только активные события.
+type TagStatsQuery struct {
+	CalendarIDs []string  // Календари; пусто - все календари
+	From        time.Time // Начало события не раньше From; нулевое значение - без ограничения
+	To          time.Time // Начало события раньше To; нулевое значение - без ограничения
+	Limit       int       // Сколько самых частых тегов вернуть
+}
+
+// TagStat - сколько событий отмечено тегом
+type TagStat struct {
+	Tag   string
+	Count int
+}
+
+// SortTagStats упорядочивает статистику как SQL-хранилища: сначала частые теги, при равенстве - по алфавиту
+func SortTagStats(stats []TagStat) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Tag < stats[j].Tag
+	})
+}
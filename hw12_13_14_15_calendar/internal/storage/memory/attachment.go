@@ -0,0 +1,77 @@
+package memorystorage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// AddAttachment прикрепляет вложение к активному событию
+func (strg *Storage) AddAttachment(ctx context.Context, a storage.Attachment) error {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+
+	if e, ok := strg.events[a.EventID]; !ok || e.DeletedAt != nil {
+		return storage.ErrEventNotFound
+	}
+	if err := strg.record(Change{Attachment: &a}); err != nil {
+		return err
+	}
+	strg.attachments[a.ID] = a
+	return nil
+}
+
+func (strg *Storage) GetAttachment(ctx context.Context, id string) (storage.Attachment, error) {
+	strg.mu.RLock()
+	defer strg.mu.RUnlock()
+
+	a, ok := strg.attachments[id]
+	if !ok {
+		return storage.Attachment{}, storage.ErrAttachmentNotFound
+	}
+	return a, nil
+}
+
+// ListAttachments возвращает вложения события в порядке добавления
+func (strg *Storage) ListAttachments(ctx context.Context, eventID string) ([]storage.Attachment, error) {
+	strg.mu.RLock()
+	defer strg.mu.RUnlock()
+
+	attachments := []storage.Attachment{}
+	for _, a := range strg.attachments {
+		if a.EventID == eventID {
+			attachments = append(attachments, a)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		if !attachments[i].CreatedAt.Equal(attachments[j].CreatedAt) {
+			return attachments[i].CreatedAt.Before(attachments[j].CreatedAt)
+		}
+		return attachments[i].ID < attachments[j].ID
+	})
+	return attachments, nil
+}
+
+func (strg *Storage) DeleteAttachment(ctx context.Context, id string) error {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+
+	if _, ok := strg.attachments[id]; !ok {
+		return storage.ErrAttachmentNotFound
+	}
+	if err := strg.record(Change{RemovedAttachment: id}); err != nil {
+		return err
+	}
+	delete(strg.attachments, id)
+	return nil
+}
+
+// removeEventAttachments удаляет вложения окончательно удалённого события; блокировку берёт вызывающий код
+func (strg *Storage) removeEventAttachments(eventID string) {
+	for id, a := range strg.attachments {
+		if a.EventID == eventID {
+			delete(strg.attachments, id)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package memorystorage
+
+import (
+	"context"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// newAudit готовит запись журнала изменений; вызывается под блокировкой хранилища.
+// Запись передаётся журналу хранилища вместе с самим изменением, а в strg.audit
+// добавляется, только когда изменение сохранено.
+func (strg *Storage) newAudit(ctx context.Context, action storage.AuditAction, id string, before, after *storage.Event) storage.AuditRecord {
+	record := storage.NewAuditRecord(ctx, action, id, before, after)
+	record.ID = int64(len(strg.audit)) + 1
+	return record
+}
+
+func auditChange(record storage.AuditRecord) Change {
+	return Change{Audit: &record}
+}
+
+func (strg *Storage) ListAuditRecords(ctx context.Context, eventID string) ([]storage.AuditRecord, error) {
+	strg.mu.RLock()
+	defer strg.mu.RUnlock()
+
+	records := []storage.AuditRecord{}
+	for _, record := range strg.audit {
+		if record.EventID == eventID {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,88 @@
+package memorystorage
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// ApplyBatch применяет операции к копии событий и подменяет ею хранилище целиком,
+// поэтому другие запросы видят либо состояние до пакета, либо после. Записи журнала изменений
+// применённых операций сохраняются одной записью журнала хранилища вместе с событиями.
+func (strg *Storage) ApplyBatch(ctx context.Context, ops []storage.BatchOperation, atomic bool) ([]storage.BatchResult, error) {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+
+	events := maps.Clone(strg.events)
+	results := make([]storage.BatchResult, len(ops))
+	audit := make([]storage.AuditRecord, len(ops))
+	failed := false
+	for i, op := range ops {
+		results[i].ID = op.Event.ID
+		before := visibleEvent(events, op.Event.ID)
+
+		var (
+			action storage.AuditAction
+			err    error
+		)
+		switch op.Type {
+		case storage.BatchCreate:
+			action = storage.AuditCreate
+			results[i].Version, err = addEvent(events, op.Event)
+		case storage.BatchUpdate:
+			action = storage.AuditUpdate
+			results[i].Version, err = updateEvent(events, op.Event)
+		case storage.BatchDelete:
+			action = storage.AuditDelete
+			results[i].Version, err = deleteEvent(events, op.Event.ID, op.Event.Version)
+		default:
+			err = fmt.Errorf("unknown batch operation %q", op.Type)
+		}
+		if err != nil {
+			results[i].Err = err
+			failed = true
+			continue
+		}
+		audit[i] = storage.NewAuditRecord(ctx, action, op.Event.ID, before, visibleEvent(events, op.Event.ID))
+	}
+
+	if atomic && failed {
+		storage.AbortBatch(results)
+		return results, nil
+	}
+
+	var (
+		changes []Change
+		records []storage.AuditRecord
+	)
+	for i, op := range ops {
+		if results[i].Err == nil {
+			record := audit[i]
+			record.ID = int64(len(strg.audit)+len(records)) + 1
+			records = append(records, record)
+			changes = append(changes, eventChange(events[op.Event.ID]), auditChange(record))
+		}
+	}
+	if err := strg.record(changes...); err != nil {
+		return nil, err
+	}
+	strg.events = events
+	strg.audit = append(strg.audit, records...)
+	for i, op := range ops {
+		if results[i].Err == nil && op.Type != storage.BatchDelete {
+			strg.index.put(events[op.Event.ID])
+		}
+	}
+	return results, nil
+}
+
+// visibleEvent - копия события вне корзины или nil, если такого нет
+func visibleEvent(events map[string]storage.Event, id string) *storage.Event {
+	event, ok := events[id]
+	if !ok || event.DeletedAt != nil {
+		return nil
+	}
+	return &event
+}
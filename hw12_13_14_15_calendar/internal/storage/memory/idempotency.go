@@ -0,0 +1,83 @@
+package memorystorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// idempotencySweepInterval - как часто из памяти удаляются истёкшие ключи идемпотентности
+const idempotencySweepInterval = time.Hour
+
+type idempotencyKey struct {
+	userID string
+	key    string
+}
+
+func keyOf(record storage.IdempotencyRecord) idempotencyKey {
+	return idempotencyKey{userID: record.UserID, key: record.Key}
+}
+
+func (strg *Storage) ReserveIdempotencyKey(
+	ctx context.Context, record storage.IdempotencyRecord,
+) (storage.IdempotencyRecord, bool, error) {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+
+	now := time.Now()
+	strg.sweepIdempotency(now)
+	if existing, ok := strg.idempotency[keyOf(record)]; ok && !existing.Expired(now) {
+		return existing, false, nil
+	}
+	if err := strg.record(Change{Idempotency: &IdempotencyChange{Record: record}}); err != nil {
+		return storage.IdempotencyRecord{}, false, err
+	}
+	strg.idempotency[keyOf(record)] = record
+	return record, true, nil
+}
+
+func (strg *Storage) CompleteIdempotencyKey(ctx context.Context, userID, key string, response storage.Event) error {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+
+	record, ok := strg.idempotency[idempotencyKey{userID: userID, key: key}]
+	if !ok {
+		return nil
+	}
+	record.Response = &response
+	if err := strg.record(Change{Idempotency: &IdempotencyChange{Record: record}}); err != nil {
+		return err
+	}
+	strg.idempotency[keyOf(record)] = record
+	return nil
+}
+
+func (strg *Storage) ReleaseIdempotencyKey(ctx context.Context, userID, key string) error {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+
+	record, ok := strg.idempotency[idempotencyKey{userID: userID, key: key}]
+	if !ok {
+		return nil
+	}
+	if err := strg.record(Change{Idempotency: &IdempotencyChange{Record: record, Released: true}}); err != nil {
+		return err
+	}
+	delete(strg.idempotency, keyOf(record))
+	return nil
+}
+
+// sweepIdempotency удаляет истёкшие ключи. В журнал это не пишется: истёкшая запись
+// и так не действует, а в снимок не попадает.
+func (strg *Storage) sweepIdempotency(now time.Time) {
+	if now.Sub(strg.idempotencySweep) < idempotencySweepInterval {
+		return
+	}
+	for key, record := range strg.idempotency {
+		if record.Expired(now) {
+			delete(strg.idempotency, key)
+		}
+	}
+	strg.idempotencySweep = now
+}
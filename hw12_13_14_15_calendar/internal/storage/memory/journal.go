@@ -0,0 +1,165 @@
+package memorystorage
+
+import (
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Change - новое состояние одной сущности после изменения; заполнено ровно одно поле
+type Change struct {
+	Event       *storage.Event       `json:"event,omitempty"`
+	Calendar    *storage.Calendar    `json:"calendar,omitempty"`
+	ACL         *ACLState            `json:"acl,omitempty"`
+	Audit       *storage.AuditRecord `json:"audit,omitempty"`
+	Purge       string               `json:"purge,omitempty"` // ID окончательно удалённого события
+	Idempotency *IdempotencyChange   `json:"idempotency,omitempty"`
+	Attachment  *storage.Attachment  `json:"attachment,omitempty"`
+	// ID удалённого вложения; вложения окончательно удалённого события удаляются вместе с ним по Purge
+	RemovedAttachment string `json:"removed_attachment,omitempty"`
+}
+
+// IdempotencyChange - новое состояние ключа идемпотентности или его удаление
+type IdempotencyChange struct {
+	Record   storage.IdempotencyRecord `json:"record"`
+	Released bool                      `json:"released,omitempty"` // Запрос не выполнился, ключ освобождён
+}
+
+// ACLState - все доступы к календарю после изменения
+type ACLState struct {
+	CalendarID string             `json:"calendar_id"`
+	Entries    []storage.ACLEntry `json:"entries"`
+}
+
+// Journal сохраняет изменения до того, как они станут видны другим запросам.
+// Record вызывается под блокировкой хранилища; если он вернул ошибку, изменение отменяется.
+type Journal interface {
+	Record(changes []Change) error
+}
+
+// Option настраивает хранилище при создании
+type Option func(*Storage)
+
+// WithJournal подключает журнал, в который пишутся все изменения
+func WithJournal(journal Journal) Option {
+	return func(s *Storage) {
+		s.journal = journal
+	}
+}
+
+// State - полное содержимое хранилища для снимка
+type State struct {
+	Events      []storage.Event             `json:"events"`
+	Calendars   []storage.Calendar          `json:"calendars"`
+	ACL         []ACLState                  `json:"acl"`
+	Audit       []storage.AuditRecord       `json:"audit"`
+	Idempotency []storage.IdempotencyRecord `json:"idempotency,omitempty"`
+	Attachments []storage.Attachment        `json:"attachments,omitempty"`
+}
+
+// record передаёт изменения журналу, если он подключён
+func (strg *Storage) record(changes ...Change) error {
+	if strg.journal == nil || len(changes) == 0 {
+		return nil
+	}
+	return strg.journal.Record(changes)
+}
+
+// Snapshot копирует состояние и передаёт его в fn, не отпуская блокировку:
+// пока fn работает, изменения ждут, поэтому снимок согласован с журналом.
+func (strg *Storage) Snapshot(fn func(State) error) error {
+	strg.mu.RLock()
+	defer strg.mu.RUnlock()
+
+	state := State{
+		Events:    make([]storage.Event, 0, len(strg.events)),
+		Calendars: make([]storage.Calendar, 0, len(strg.calendars)),
+		ACL:       make([]ACLState, 0, len(strg.acl)),
+		Audit:     append([]storage.AuditRecord{}, strg.audit...),
+	}
+	for _, e := range strg.events {
+		state.Events = append(state.Events, e)
+	}
+	for _, c := range strg.calendars {
+		state.Calendars = append(state.Calendars, c)
+	}
+	for calendarID, entries := range strg.acl {
+		state.ACL = append(state.ACL, ACLState{CalendarID: calendarID, Entries: append([]storage.ACLEntry{}, entries...)})
+	}
+	for _, a := range strg.attachments {
+		state.Attachments = append(state.Attachments, a)
+	}
+	now := time.Now()
+	for _, record := range strg.idempotency {
+		if !record.Expired(now) {
+			state.Idempotency = append(state.Idempotency, record)
+		}
+	}
+	return fn(state)
+}
+
+// Restore заменяет содержимое хранилища снимком; журнал при этом не пишется
+func (strg *Storage) Restore(state State) {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+
+	strg.events = map[string]storage.Event{}
+	strg.calendars = map[string]storage.Calendar{}
+	strg.acl = map[string][]storage.ACLEntry{}
+	strg.idempotency = map[idempotencyKey]storage.IdempotencyRecord{}
+	strg.attachments = map[string]storage.Attachment{}
+	strg.audit = append([]storage.AuditRecord{}, state.Audit...)
+	strg.index = newSearchIndex()
+	for _, e := range state.Events {
+		strg.events[e.ID] = e
+		strg.index.put(e)
+	}
+	for _, c := range state.Calendars {
+		strg.calendars[c.ID] = c
+	}
+	for _, acl := range state.ACL {
+		strg.acl[acl.CalendarID] = acl.Entries
+	}
+	for _, record := range state.Idempotency {
+		strg.idempotency[keyOf(record)] = record
+	}
+	for _, a := range state.Attachments {
+		strg.attachments[a.ID] = a
+	}
+}
+
+// ApplyChanges повторяет изменения из журнала при восстановлении; журнал при этом не пишется
+func (strg *Storage) ApplyChanges(changes []Change) {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+
+	for _, change := range changes {
+		switch {
+		case change.Event != nil:
+			strg.events[change.Event.ID] = *change.Event
+			strg.index.put(*change.Event)
+		case change.Calendar != nil:
+			strg.calendars[change.Calendar.ID] = *change.Calendar
+		case change.ACL != nil:
+			strg.acl[change.ACL.CalendarID] = change.ACL.Entries
+		case change.Audit != nil:
+			strg.audit = append(strg.audit, *change.Audit)
+		case change.Purge != "":
+			delete(strg.events, change.Purge)
+			strg.index.remove(change.Purge)
+			strg.removeEventAttachments(change.Purge)
+		case change.Idempotency != nil && change.Idempotency.Released:
+			delete(strg.idempotency, keyOf(change.Idempotency.Record))
+		case change.Idempotency != nil:
+			strg.idempotency[keyOf(change.Idempotency.Record)] = change.Idempotency.Record
+		case change.Attachment != nil:
+			strg.attachments[change.Attachment.ID] = *change.Attachment
+		case change.RemovedAttachment != "":
+			delete(strg.attachments, change.RemovedAttachment)
+		}
+	}
+}
+
+func eventChange(e storage.Event) Change {
+	return Change{Event: &e}
+}
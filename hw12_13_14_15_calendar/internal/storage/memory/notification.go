@@ -0,0 +1,84 @@
+package memorystorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Методы ниже реализуют scheduler.NotificationStorage
+
+func (strg *Storage) GetEventsForNotification(ctx context.Context, now time.Time) ([]storage.Event, error) {
+	strg.mu.RLock()
+	defer strg.mu.RUnlock()
+
+	// Ищем события, для которых время уведомления попадает в интервал ±1 минута от текущего времени
+	var events []storage.Event
+	for _, e := range strg.events {
+		if e.DeletedAt != nil || e.NotifyBefore <= 0 {
+			continue
+		}
+		notifyAt := e.StartTime.Add(-time.Duration(e.NotifyBefore))
+		if !notifyAt.Before(now.Add(-time.Minute)) && !notifyAt.After(now.Add(time.Minute)) {
+			events = append(events, e)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].StartTime.Before(events[j].StartTime) })
+	return events, nil
+}
+
+func (strg *Storage) MarkEventNotified(ctx context.Context, eventID string) error {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+
+	e, ok := strg.events[eventID]
+	if !ok {
+		strg.logger.Error("No event found to mark as notified: " + eventID)
+		return nil
+	}
+	// Обнуляем NotifyBefore, чтобы событие больше не попадало в выборку
+	e.NotifyBefore = 0
+	if err := strg.record(eventChange(e)); err != nil {
+		return err
+	}
+	strg.events[eventID] = e
+	return nil
+}
+
+func (strg *Storage) ListEventsToPurge(ctx context.Context, endedBefore, deletedBefore time.Time) ([]storage.Event, error) {
+	strg.mu.RLock()
+	defer strg.mu.RUnlock()
+
+	var events []storage.Event
+	for _, e := range strg.events {
+		ended := e.StartTime.Add(time.Duration(e.Duration)).Before(endedBefore)
+		trashed := e.DeletedAt != nil && e.DeletedAt.Before(deletedBefore)
+		if ended || trashed {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (strg *Storage) PurgeEvents(ctx context.Context, ids []string) error {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+
+	changes := make([]Change, 0, len(ids))
+	for _, id := range ids {
+		changes = append(changes, Change{Purge: id})
+	}
+	if err := strg.record(changes...); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		delete(strg.events, id)
+		strg.index.remove(id)
+		strg.removeEventAttachments(id)
+	}
+	strg.logger.Info(fmt.Sprintf("Purged %d events", len(ids)))
+	return nil
+}
@@ -0,0 +1,133 @@
+package memorystorage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// Вес вхождения слова: совпадение в названии важнее, чем в описании
+const (
+	titleWeight       = 2
+	descriptionWeight = 1
+)
+
+// searchIndex - обратный индекс: слово -> события, в которых оно встречается, с весом вхождений.
+// Индекс не потокобезопасен, его защищает мьютекс хранилища.
+type searchIndex struct {
+	postings map[string]map[string]int
+	words    map[string][]string // ID события -> его слова, чтобы убрать событие из индекса
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings: map[string]map[string]int{},
+		words:    map[string][]string{},
+	}
+}
+
+// tokenize разбивает текст на слова в нижнем регистре
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// put индексирует событие, заменяя прежнюю запись о нём
+func (idx *searchIndex) put(e storage.Event) {
+	idx.remove(e.ID)
+
+	weights := map[string]int{}
+	for _, word := range tokenize(e.Title) {
+		weights[word] += titleWeight
+	}
+	for _, word := range tokenize(e.Description) {
+		weights[word] += descriptionWeight
+	}
+
+	words := make([]string, 0, len(weights))
+	for word, weight := range weights {
+		if idx.postings[word] == nil {
+			idx.postings[word] = map[string]int{}
+		}
+		idx.postings[word][e.ID] = weight
+		words = append(words, word)
+	}
+	idx.words[e.ID] = words
+}
+
+func (idx *searchIndex) remove(id string) {
+	for _, word := range idx.words[id] {
+		delete(idx.postings[word], id)
+		if len(idx.postings[word]) == 0 {
+			delete(idx.postings, word)
+		}
+	}
+	delete(idx.words, id)
+}
+
+// match возвращает события, содержащие все слова запроса, с суммарным весом вхождений
+func (idx *searchIndex) match(text string) map[string]float64 {
+	words := tokenize(text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	ranks := map[string]float64{}
+	for id, weight := range idx.postings[words[0]] {
+		ranks[id] = float64(weight)
+	}
+	for _, word := range words[1:] {
+		postings := idx.postings[word]
+		for id := range ranks {
+			weight, ok := postings[id]
+			if !ok {
+				delete(ranks, id)
+				continue
+			}
+			ranks[id] += float64(weight)
+		}
+	}
+	return ranks
+}
+
+func (strg *Storage) SearchEvents(ctx context.Context, query storage.SearchQuery) ([]storage.SearchResult, error) {
+	strg.mu.RLock()
+	defer strg.mu.RUnlock()
+
+	calendars := make(map[string]bool, len(query.CalendarIDs))
+	for _, id := range query.CalendarIDs {
+		calendars[id] = true
+	}
+	results := []storage.SearchResult{}
+	for id, rank := range strg.index.match(query.Text) {
+		e, ok := strg.events[id]
+		if !ok || e.DeletedAt != nil || (len(calendars) > 0 && !calendars[e.CalendarID]) {
+			continue
+		}
+		if query.UserID != "" && e.UserID != query.UserID {
+			continue
+		}
+		if !query.From.IsZero() && e.StartTime.Before(query.From) {
+			continue
+		}
+		if !query.To.IsZero() && !e.StartTime.Before(query.To) {
+			continue
+		}
+		results = append(results, storage.SearchResult{Event: e, Rank: rank})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Event.StartTime.Before(results[j].Event.StartTime)
+	})
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
@@ -2,7 +2,6 @@ package memorystorage
 
 import (
 	"context"
-	"errors"
 	"sync"
 	"time"
 
@@ -12,36 +11,158 @@ import (
 
 type Storage struct {
 	events map[string]storage.Event
-	mu     *sync.RWMutex //nolint:unused
-	logger app.Logger
+	audit  []storage.AuditRecord
+	index  *searchIndex
+
+	calendars map[string]storage.Calendar
+	acl       map[string][]storage.ACLEntry // ID календаря -> выданные доступы
+
+	idempotency      map[idempotencyKey]storage.IdempotencyRecord
+	idempotencySweep time.Time
+
+	attachments map[string]storage.Attachment
+
+	mu      *sync.RWMutex //nolint:unused
+	logger  app.Logger
+	journal Journal
 }
 
 func (strg *Storage) AddEvent(ctx context.Context, e storage.Event) error {
 	strg.mu.Lock()
 	defer strg.mu.Unlock()
-
-	if _, ok := strg.events[e.ID]; ok {
-		return errors.New("event already exists")
+	if _, err := addEvent(strg.events, e); err != nil {
+		return err
 	}
-	strg.events[e.ID] = e
+	after := strg.events[e.ID]
+	record := strg.newAudit(ctx, storage.AuditCreate, e.ID, nil, &after)
+	if err := strg.record(eventChange(after), auditChange(record)); err != nil {
+		delete(strg.events, e.ID)
+		return err
+	}
+	strg.audit = append(strg.audit, record)
+	strg.index.put(e)
 	return nil
 }
 
 func (strg *Storage) UpdateEvent(ctx context.Context, e storage.Event) error {
 	strg.mu.Lock()
 	defer strg.mu.Unlock()
+	previous := strg.events[e.ID]
+	if _, err := updateEvent(strg.events, e); err != nil {
+		return err
+	}
+	after := strg.events[e.ID]
+	record := strg.newAudit(ctx, storage.AuditUpdate, e.ID, &previous, &after)
+	if err := strg.record(eventChange(after), auditChange(record)); err != nil {
+		strg.events[e.ID] = previous
+		return err
+	}
+	strg.audit = append(strg.audit, record)
+	strg.index.put(e)
+	return nil
+}
 
-	if _, ok := strg.events[e.ID]; !ok {
-		return errors.New("event does not exist")
+func (strg *Storage) DeleteEvent(ctx context.Context, id string, expectedVersion int64) error {
+	strg.mu.Lock()
+	defer strg.mu.Unlock()
+	previous := strg.events[id]
+	if _, err := deleteEvent(strg.events, id, expectedVersion); err != nil {
+		return err
+	}
+	// Событие в корзине не видно, поэтому состояния после удаления в журнале нет
+	record := strg.newAudit(ctx, storage.AuditDelete, id, &previous, nil)
+	if err := strg.record(eventChange(strg.events[id]), auditChange(record)); err != nil {
+		strg.events[id] = previous
+		return err
 	}
-	strg.events[e.ID] = e
+	strg.audit = append(strg.audit, record)
 	return nil
 }
 
-func (strg *Storage) DeleteEvent(ctx context.Context, id string) error {
+// Функции ниже меняют переданную карту событий и возвращают новую версию события.
+// Блокировку берёт вызывающий код; пакетные операции применяют их к копии карты.
+
+func addEvent(events map[string]storage.Event, e storage.Event) (int64, error) {
+	if _, ok := events[e.ID]; ok {
+		return 0, storage.ErrEventExists
+	}
+	e.Version = 1
+	e.DeletedAt = nil
+	events[e.ID] = e
+	return e.Version, nil
+}
+
+func updateEvent(events map[string]storage.Event, e storage.Event) (int64, error) {
+	current, ok := events[e.ID]
+	if !ok || current.DeletedAt != nil {
+		return 0, storage.ErrEventNotFound
+	}
+	if e.Version != 0 && e.Version != current.Version {
+		return 0, storage.ErrVersionConflict
+	}
+	if e.CalendarID == "" {
+		e.CalendarID = current.CalendarID
+	}
+	e.Version = current.Version + 1
+	e.DeletedAt = nil
+	events[e.ID] = e
+	return e.Version, nil
+}
+
+func deleteEvent(events map[string]storage.Event, id string, expectedVersion int64) (int64, error) {
+	current, ok := events[id]
+	if !ok || current.DeletedAt != nil {
+		return 0, storage.ErrEventNotFound
+	}
+	if expectedVersion != 0 && expectedVersion != current.Version {
+		return 0, storage.ErrVersionConflict
+	}
+	// Событие остаётся в хранилище до очистки корзины
+	deletedAt := time.Now()
+	current.DeletedAt = &deletedAt
+	current.Version++
+	events[id] = current
+	return current.Version, nil
+}
+
+func (strg *Storage) ListDeletedEvents(ctx context.Context) ([]storage.Event, error) {
+	strg.mu.RLock()
+	defer strg.mu.RUnlock()
+	foundEvents := []storage.Event{}
+	for _, e := range strg.events {
+		if e.DeletedAt != nil {
+			foundEvents = append(foundEvents, e)
+		}
+	}
+	return foundEvents, nil
+}
+
+func (strg *Storage) GetDeletedEvent(ctx context.Context, id string) (storage.Event, error) {
+	strg.mu.RLock()
+	defer strg.mu.RUnlock()
+	event, ok := strg.events[id]
+	if !ok || event.DeletedAt == nil {
+		return storage.Event{}, storage.ErrEventNotFound
+	}
+	return event, nil
+}
+
+func (strg *Storage) RestoreEvent(ctx context.Context, id string) error {
 	strg.mu.Lock()
 	defer strg.mu.Unlock()
-	delete(strg.events, id)
+
+	current, ok := strg.events[id]
+	if !ok || current.DeletedAt == nil {
+		return storage.ErrEventNotFound
+	}
+	current.DeletedAt = nil
+	current.Version++
+	record := strg.newAudit(ctx, storage.AuditRestore, id, nil, &current)
+	if err := strg.record(eventChange(current), auditChange(record)); err != nil {
+		return err
+	}
+	strg.events[id] = current
+	strg.audit = append(strg.audit, record)
 	return nil
 }
 
@@ -49,8 +170,8 @@ func (strg *Storage) GetEventByID(ctx context.Context, id string) (storage.Event
 	strg.mu.RLock()
 	defer strg.mu.RUnlock()
 	event, ok := strg.events[id]
-	if !ok {
-		return storage.Event{}, errors.New("event does not exist")
+	if !ok || event.DeletedAt != nil {
+		return storage.Event{}, storage.ErrEventNotFound
 	}
 	return event, nil
 }
@@ -61,16 +182,8 @@ func sameDay(t1, t2 time.Time) bool {
 	return y1 == y2 && m1 == m2 && d1 == d2
 }
 
-func (strg *Storage) ListEventsForDay(ctx context.Context, date time.Time) ([]storage.Event, error) {
-	strg.mu.RLock()
-	defer strg.mu.RUnlock()
-	foundEvents := []storage.Event{}
-	for _, e := range strg.events {
-		if sameDay(e.StartTime, date) {
-			foundEvents = append(foundEvents, e)
-		}
-	}
-	return foundEvents, nil
+func (strg *Storage) ListEventsForDay(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error) {
+	return strg.listEvents(query, func(start time.Time) bool { return sameDay(start, date) }), nil
 }
 
 func sameWeek(t1, t2 time.Time) bool {
@@ -78,16 +191,8 @@ func sameWeek(t1, t2 time.Time) bool {
 	y2, w2 := t2.ISOWeek()
 	return y1 == y2 && w1 == w2
 }
-func (strg *Storage) ListEventsForWeek(ctx context.Context, date time.Time) ([]storage.Event, error) {
-	strg.mu.RLock()
-	defer strg.mu.RUnlock()
-	foundEvents := []storage.Event{}
-	for _, e := range strg.events {
-		if sameWeek(e.StartTime, date) {
-			foundEvents = append(foundEvents, e)
-		}
-	}
-	return foundEvents, nil
+func (strg *Storage) ListEventsForWeek(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error) {
+	return strg.listEvents(query, func(start time.Time) bool { return sameWeek(start, date) }), nil
 }
 
 func sameMonth(t1, t2 time.Time) bool {
@@ -95,24 +200,50 @@ func sameMonth(t1, t2 time.Time) bool {
 	y2, m2, _ := t2.Date()
 	return y1 == y2 && m1 == m2
 }
-func (strg Storage) ListEventsForMonth(ctx context.Context, date time.Time) ([]storage.Event, error) {
+func (strg *Storage) ListEventsForMonth(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error) {
+	return strg.listEvents(query, func(start time.Time) bool { return sameMonth(start, date) }), nil
+}
+
+// listEvents возвращает активные события из календарей и с тегами query, начало которых подходит под inPeriod
+func (strg *Storage) listEvents(query storage.ListQuery, inPeriod func(time.Time) bool) []storage.Event {
 	strg.mu.RLock()
 	defer strg.mu.RUnlock()
+
+	calendars := make(map[string]bool, len(query.CalendarIDs))
+	for _, id := range query.CalendarIDs {
+		calendars[id] = true
+	}
 	foundEvents := []storage.Event{}
 	for _, e := range strg.events {
-		if sameMonth(e.StartTime, date) {
+		if e.DeletedAt != nil || (len(calendars) > 0 && !calendars[e.CalendarID]) {
+			continue
+		}
+		if inPeriod(e.StartTime) && e.HasTags(query.Tags) {
 			foundEvents = append(foundEvents, e)
 		}
 	}
-	return foundEvents, nil
+	return foundEvents
 }
 
-func New(logger app.Logger) app.Storage {
-	return &Storage{
+func New(logger app.Logger, opts ...Option) *Storage {
+	strg := &Storage{
 		events: map[string]storage.Event{},
+		index:  newSearchIndex(),
+
+		calendars: map[string]storage.Calendar{},
+		acl:       map[string][]storage.ACLEntry{},
+
+		idempotency: map[idempotencyKey]storage.IdempotencyRecord{},
+
+		attachments: map[string]storage.Attachment{},
+
 		mu:     &sync.RWMutex{},
 		logger: logger,
 	}
+	for _, opt := range opts {
+		opt(strg)
+	}
+	return strg
 }
 
 func (storage *Storage) Close() error {
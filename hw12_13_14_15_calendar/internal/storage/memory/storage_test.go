@@ -2,8 +2,11 @@ package memorystorage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
 	"testing"
 	"time"
 
@@ -76,7 +79,7 @@ func TestDeleteEvent(t *testing.T) {
 	}
 	_ = s.AddEvent(ctx, event)
 
-	err := s.DeleteEvent(ctx, event.ID)
+	err := s.DeleteEvent(ctx, event.ID, 0)
 	if err != nil {
 		t.Fatalf("failed to delete event: %v", err)
 	}
@@ -110,12 +113,586 @@ func TestListEventsForDay(t *testing.T) {
 	_ = s.AddEvent(ctx, eventToday)
 	_ = s.AddEvent(ctx, eventTomorrow)
 
-	list, err := s.ListEventsForDay(ctx, now)
+	list, err := s.ListEventsForDay(ctx, now, storage.ListQuery{})
 	if err != nil {
 		t.Fatalf("error listing events: %v", err)
 	}
 
 	if len(list) != 1 {
-		t.Errorf("expected 1 event today, got %d", len(list))
+		t.Fatalf("expected 1 event today, got %d", len(list))
+	}
+
+	// Список ограничивается перечисленными календарями
+	if list, _ := s.ListEventsForDay(ctx, now, storage.ListQuery{CalendarIDs: []string{"team:other"}}); len(list) != 0 {
+		t.Errorf("expected no events outside listed calendars, got %d", len(list))
+	}
+	query := storage.ListQuery{CalendarIDs: []string{"team:other", list[0].CalendarID}}
+	if list, _ := s.ListEventsForDay(ctx, now, query); len(list) != 1 {
+		t.Errorf("expected 1 event in listed calendars, got %d", len(list))
+	}
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	s := New(logger.New("debug"))
+	ctx := context.Background()
+
+	event := storage.Event{
+		ID:        uuid.New().String(),
+		Title:     "Versioned",
+		StartTime: time.Now(),
+		Duration:  calendar_types.CalendarDuration(time.Hour),
+		UserID:    "user5",
+	}
+	if err := s.AddEvent(ctx, event); err != nil {
+		t.Fatalf("failed to add event: %v", err)
+	}
+
+	got, _ := s.GetEventByID(ctx, event.ID)
+	if got.Version != 1 {
+		t.Fatalf("expected version 1 after create, got %d", got.Version)
+	}
+
+	// Первый клиент обновляет событие, зная актуальную версию
+	got.Title = "First"
+	if err := s.UpdateEvent(ctx, got); err != nil {
+		t.Fatalf("failed to update event: %v", err)
+	}
+
+	// Второй клиент прочитал событие до обновления и должен получить конфликт
+	stale := got
+	stale.Title = "Second"
+	if err := s.UpdateEvent(ctx, stale); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Fatalf("expected version conflict, got %v", err)
+	}
+	if err := s.DeleteEvent(ctx, event.ID, 1); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Fatalf("expected version conflict on delete, got %v", err)
+	}
+
+	current, _ := s.GetEventByID(ctx, event.ID)
+	if current.Title != "First" || current.Version != 2 {
+		t.Errorf("expected title 'First' with version 2, got %q with version %d", current.Title, current.Version)
+	}
+
+	// Версия 0 означает обновление без проверки
+	current.Version = 0
+	current.Title = "Unconditional"
+	if err := s.UpdateEvent(ctx, current); err != nil {
+		t.Fatalf("failed to update event without version: %v", err)
+	}
+
+	if err := s.DeleteEvent(ctx, event.ID, 3); err != nil {
+		t.Fatalf("failed to delete event with matching version: %v", err)
+	}
+	if err := s.DeleteEvent(ctx, event.ID, 0); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestTrashAndRestore(t *testing.T) {
+	s := New(logger.New("debug"))
+	ctx := context.Background()
+
+	now := time.Now()
+	event := storage.Event{
+		ID:        uuid.New().String(),
+		Title:     "Trashed",
+		StartTime: now,
+		Duration:  calendar_types.CalendarDuration(time.Hour),
+		UserID:    "user6",
+	}
+	_ = s.AddEvent(ctx, event)
+
+	if err := s.DeleteEvent(ctx, event.ID, 0); err != nil {
+		t.Fatalf("failed to delete event: %v", err)
+	}
+
+	// Удалённое событие не видно в обычных выборках, но лежит в корзине
+	list, _ := s.ListEventsForDay(ctx, now, storage.ListQuery{})
+	if len(list) != 0 {
+		t.Errorf("expected no events for day after delete, got %d", len(list))
+	}
+	trash, err := s.ListDeletedEvents(ctx)
+	if err != nil {
+		t.Fatalf("error listing trash: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != event.ID || trash[0].DeletedAt == nil {
+		t.Fatalf("expected deleted event in trash, got %+v", trash)
+	}
+	if deleted, err := s.GetDeletedEvent(ctx, event.ID); err != nil || deleted.DeletedAt == nil {
+		t.Errorf("expected deleted event by id, got %+v (%v)", deleted, err)
+	}
+	if err := s.DeleteEvent(ctx, event.ID, 0); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Errorf("expected not found on second delete, got %v", err)
+	}
+
+	if err := s.RestoreEvent(ctx, event.ID); err != nil {
+		t.Fatalf("failed to restore event: %v", err)
+	}
+	got, err := s.GetEventByID(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("expected restored event, got %v", err)
+	}
+	if got.DeletedAt != nil {
+		t.Error("expected restored event to have no deletion time")
+	}
+	if got.Version != 3 {
+		t.Errorf("expected version 3 after delete and restore, got %d", got.Version)
+	}
+	if err := s.RestoreEvent(ctx, event.ID); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Errorf("expected not found when restoring active event, got %v", err)
+	}
+	if _, err := s.GetDeletedEvent(ctx, event.ID); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Errorf("expected active event not to be found in trash, got %v", err)
+	}
+}
+
+func TestAuditTrail(t *testing.T) {
+	s := New(logger.New("debug"))
+	ctx := requestctx.WithActor(requestctx.WithRequestID(context.Background(), "req-1"), "alice")
+
+	event := storage.Event{ID: "audited", Title: "Original", StartTime: time.Now(), UserID: "alice"}
+	if err := s.AddEvent(ctx, event); err != nil {
+		t.Fatalf("failed to add event: %v", err)
+	}
+	event.Title = "Updated"
+	if err := s.UpdateEvent(ctx, event); err != nil {
+		t.Fatalf("failed to update event: %v", err)
+	}
+	// Отклонённое изменение в журнал не попадает
+	event.Version = 7
+	if err := s.UpdateEvent(ctx, event); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Fatalf("expected version conflict, got %v", err)
+	}
+	if err := s.DeleteEvent(ctx, event.ID, 0); err != nil {
+		t.Fatalf("failed to delete event: %v", err)
+	}
+	if err := s.RestoreEvent(ctx, event.ID); err != nil {
+		t.Fatalf("failed to restore event: %v", err)
+	}
+
+	records, _ := s.ListAuditRecords(ctx, event.ID)
+	want := []storage.AuditAction{storage.AuditCreate, storage.AuditUpdate, storage.AuditDelete, storage.AuditRestore}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d audit records, got %+v", len(want), records)
+	}
+	for i, record := range records {
+		if record.Action != want[i] || record.Actor != "alice" || record.RequestID != "req-1" || record.ID != int64(i+1) {
+			t.Errorf("unexpected audit record %d: %+v", i, record)
+		}
+	}
+	update := records[1]
+	if update.Before == nil || update.Before.Title != "Original" || update.Before.Version != 1 ||
+		update.After == nil || update.After.Title != "Updated" || update.After.Version != 2 {
+		t.Errorf("unexpected update images: before %+v, after %+v", update.Before, update.After)
+	}
+	if records[0].Before != nil || records[2].After != nil || records[3].Before != nil || records[3].After == nil {
+		t.Errorf("unexpected create, delete or restore images: %+v", records)
+	}
+}
+
+// failingJournal отказывается сохранять изменения
+type failingJournal struct {
+	changes [][]Change
+}
+
+func (j *failingJournal) Record(changes []Change) error {
+	j.changes = append(j.changes, changes)
+	return errors.New("disk is full")
+}
+
+func TestAuditWrittenWithChange(t *testing.T) {
+	journal := &failingJournal{}
+	s := New(logger.New("debug"), WithJournal(journal))
+	ctx := context.Background()
+
+	event := storage.Event{ID: "lost", Title: "Lost", StartTime: time.Now(), UserID: "alice"}
+	if err := s.AddEvent(ctx, event); err == nil {
+		t.Fatal("expected journal error")
+	}
+	// Событие и запись журнала изменений сохраняются одной записью и вместе отменяются
+	if len(journal.changes) != 1 || len(journal.changes[0]) != 2 ||
+		journal.changes[0][0].Event == nil || journal.changes[0][1].Audit == nil {
+		t.Errorf("expected event and audit in one journal record, got %+v", journal.changes)
+	}
+	if _, err := s.GetEventByID(ctx, event.ID); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Errorf("expected event to be rolled back, got %v", err)
+	}
+	if records, _ := s.ListAuditRecords(ctx, event.ID); len(records) != 0 {
+		t.Errorf("expected no audit records, got %+v", records)
+	}
+}
+
+func TestApplyBatch(t *testing.T) {
+	s := New(logger.New("debug"))
+	ctx := context.Background()
+
+	existing := storage.Event{ID: "existing", Title: "Existing", StartTime: time.Now(), UserID: "user7"}
+	_ = s.AddEvent(ctx, existing)
+
+	ops := []storage.BatchOperation{
+		{Type: storage.BatchCreate, Event: storage.Event{ID: "new", Title: "New", StartTime: time.Now(), UserID: "user7"}},
+		{Type: storage.BatchUpdate, Event: storage.Event{ID: "existing", Title: "Changed", UserID: "user7", Version: 1}},
+		{Type: storage.BatchDelete, Event: storage.Event{ID: "missing"}},
+	}
+
+	// Всё или ничего: одна ошибка откатывает весь пакет
+	results, err := s.ApplyBatch(ctx, ops, true)
+	if err != nil {
+		t.Fatalf("unexpected batch error: %v", err)
+	}
+	if !errors.Is(results[0].Err, storage.ErrBatchAborted) || !errors.Is(results[1].Err, storage.ErrBatchAborted) {
+		t.Errorf("expected successful operations to be aborted, got %+v", results)
+	}
+	if !errors.Is(results[2].Err, storage.ErrEventNotFound) {
+		t.Errorf("expected not found for missing event, got %v", results[2].Err)
+	}
+	if _, err := s.GetEventByID(ctx, "new"); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Error("expected aborted batch not to create events")
+	}
+	if records, _ := s.ListAuditRecords(ctx, "existing"); len(records) != 1 {
+		t.Errorf("expected aborted batch not to write audit records, got %+v", records)
+	}
+
+	// Best-effort: успешные операции применяются
+	results, err = s.ApplyBatch(ctx, ops, false)
+	if err != nil {
+		t.Fatalf("unexpected batch error: %v", err)
+	}
+	if results[0].Err != nil || results[0].Version != 1 {
+		t.Errorf("expected create to succeed, got %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].Version != 2 {
+		t.Errorf("expected update to succeed, got %+v", results[1])
+	}
+	if !errors.Is(results[2].Err, storage.ErrEventNotFound) {
+		t.Errorf("expected not found for missing event, got %v", results[2].Err)
+	}
+	got, _ := s.GetEventByID(ctx, "existing")
+	if got.Title != "Changed" {
+		t.Errorf("expected title 'Changed', got %q", got.Title)
+	}
+	records, _ := s.ListAuditRecords(ctx, "existing")
+	if len(records) != 2 || records[1].Action != storage.AuditUpdate || records[1].ID != 3 ||
+		records[1].Before == nil || records[1].Before.Title != "Existing" {
+		t.Errorf("expected update from batch in audit, got %+v", records)
+	}
+	if records, _ := s.ListAuditRecords(ctx, "new"); len(records) != 1 || records[0].Action != storage.AuditCreate {
+		t.Errorf("expected create from batch in audit, got %+v", records)
+	}
+}
+
+func TestSearchEvents(t *testing.T) {
+	s := New(logger.New("debug"))
+	ctx := context.Background()
+
+	day := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+	events := []storage.Event{
+		{ID: "planning", Title: "Sprint planning", Description: "Team meeting", StartTime: day, UserID: "alice", CalendarID: "personal:alice"},
+		{ID: "retro", Title: "Retro", Description: "Sprint retrospective meeting", StartTime: day.Add(time.Hour), UserID: "alice", CalendarID: "personal:alice"},
+		{ID: "lunch", Title: "Обед с командой", StartTime: day.AddDate(0, 0, 1), UserID: "bob", CalendarID: "personal:bob"},
+	}
+	for _, e := range events {
+		_ = s.AddEvent(ctx, e)
+	}
+
+	ids := func(results []storage.SearchResult) []string {
+		found := []string{}
+		for _, result := range results {
+			found = append(found, result.Event.ID)
+		}
+		return found
+	}
+
+	// Совпадение в названии весит больше, чем в описании
+	results, err := s.SearchEvents(ctx, storage.SearchQuery{Text: "sprint"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if got := ids(results); len(got) != 2 || got[0] != "planning" || got[1] != "retro" {
+		t.Errorf("expected [planning retro], got %v", got)
+	}
+
+	// Все слова запроса должны встретиться в событии
+	results, _ = s.SearchEvents(ctx, storage.SearchQuery{Text: "sprint team"})
+	if got := ids(results); len(got) != 1 || got[0] != "planning" {
+		t.Errorf("expected [planning], got %v", got)
+	}
+
+	results, _ = s.SearchEvents(ctx, storage.SearchQuery{Text: "ОБЕД"})
+	if got := ids(results); len(got) != 1 || got[0] != "lunch" {
+		t.Errorf("expected [lunch], got %v", got)
+	}
+
+	results, _ = s.SearchEvents(ctx, storage.SearchQuery{Text: "meeting", From: day.Add(30 * time.Minute), To: day.AddDate(0, 0, 1)})
+	if got := ids(results); len(got) != 1 || got[0] != "retro" {
+		t.Errorf("expected [retro] in date range, got %v", got)
+	}
+
+	results, _ = s.SearchEvents(ctx, storage.SearchQuery{Text: "meeting", UserID: "bob"})
+	if len(results) != 0 {
+		t.Errorf("expected no results for bob, got %v", ids(results))
+	}
+
+	// Календари отбираются до лимита, чтобы чужие события не занимали места в выдаче
+	results, _ = s.SearchEvents(ctx, storage.SearchQuery{Text: "sprint", CalendarIDs: []string{"personal:bob", "personal:alice"}, Limit: 1})
+	if got := ids(results); len(got) != 1 || got[0] != "planning" {
+		t.Errorf("expected [planning] in listed calendars, got %v", got)
+	}
+	results, _ = s.SearchEvents(ctx, storage.SearchQuery{Text: "sprint", CalendarIDs: []string{"personal:bob"}})
+	if len(results) != 0 {
+		t.Errorf("expected no results outside listed calendars, got %v", ids(results))
+	}
+
+	// Индекс следует за изменениями и удалением
+	_ = s.UpdateEvent(ctx, storage.Event{ID: "retro", Title: "Demo", StartTime: day, UserID: "alice"})
+	_ = s.DeleteEvent(ctx, "planning", 0)
+	results, _ = s.SearchEvents(ctx, storage.SearchQuery{Text: "sprint"})
+	if len(results) != 0 {
+		t.Errorf("expected no results after update and delete, got %v", ids(results))
+	}
+}
+
+func TestCalendarAccess(t *testing.T) {
+	s := New(logger.New("debug"))
+	ctx := context.Background()
+
+	team := storage.Calendar{ID: "team", Name: "Team", Kind: storage.CalendarTeam, OwnerID: "alice"}
+	if err := s.CreateCalendar(ctx, team); err != nil {
+		t.Fatalf("failed to create calendar: %v", err)
+	}
+	if err := s.CreateCalendar(ctx, team); !errors.Is(err, storage.ErrCalendarExists) {
+		t.Errorf("expected ErrCalendarExists, got %v", err)
+	}
+	if _, err := s.GetCalendar(ctx, "missing"); !errors.Is(err, storage.ErrCalendarNotFound) {
+		t.Errorf("expected ErrCalendarNotFound, got %v", err)
+	}
+
+	_ = s.SetACLEntry(ctx, storage.ACLEntry{CalendarID: "team", GranteeType: storage.GranteeUser, GranteeID: "bob", Role: storage.RoleFreeBusy})
+	_ = s.SetACLEntry(ctx, storage.ACLEntry{CalendarID: "team", GranteeType: storage.GranteeGroup, GranteeID: "devs", Role: storage.RoleWrite})
+
+	role := func(p storage.Principal) storage.Role {
+		calendars, err := s.ListAccessibleCalendars(ctx, p)
+		if err != nil {
+			t.Fatalf("failed to list calendars: %v", err)
+		}
+		for _, calendar := range calendars {
+			if calendar.Calendar.ID == "team" {
+				return calendar.Role
+			}
+		}
+		return storage.RoleNone
+	}
+
+	if got := role(storage.Principal{UserID: "alice"}); got != storage.RoleOwner {
+		t.Errorf("expected owner for alice, got %q", got)
+	}
+	if got := role(storage.Principal{UserID: "bob"}); got != storage.RoleFreeBusy {
+		t.Errorf("expected free-busy for bob, got %q", got)
+	}
+	// Из прямого доступа и доступа группы берётся старшая роль
+	if got := role(storage.Principal{UserID: "bob", Groups: []string{"devs"}}); got != storage.RoleWrite {
+		t.Errorf("expected write for bob in devs, got %q", got)
+	}
+	if got := role(storage.Principal{UserID: "carol"}); got != storage.RoleNone {
+		t.Errorf("expected no access for carol, got %q", got)
+	}
+
+	// Повторная выдача заменяет роль
+	_ = s.SetACLEntry(ctx, storage.ACLEntry{CalendarID: "team", GranteeType: storage.GranteeUser, GranteeID: "bob", Role: storage.RoleRead})
+	entries, _ := s.ListACL(ctx, "team")
+	if len(entries) != 2 {
+		t.Errorf("expected 2 acl entries, got %v", entries)
+	}
+	if got := role(storage.Principal{UserID: "bob"}); got != storage.RoleRead {
+		t.Errorf("expected read for bob, got %q", got)
+	}
+
+	if err := s.DeleteACLEntry(ctx, "team", storage.GranteeUser, "bob"); err != nil {
+		t.Errorf("failed to revoke access: %v", err)
+	}
+	if err := s.DeleteACLEntry(ctx, "team", storage.GranteeUser, "bob"); !errors.Is(err, storage.ErrGrantNotFound) {
+		t.Errorf("expected ErrGrantNotFound, got %v", err)
+	}
+	if got := role(storage.Principal{UserID: "bob"}); got != storage.RoleNone {
+		t.Errorf("expected no access after revoke, got %q", got)
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	s := New(logger.New("debug"))
+	ctx := context.Background()
+	now := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+
+	soon := storage.Event{ID: "soon", StartTime: now.Add(15 * time.Minute), UserID: "user1",
+		NotifyBefore: calendar_types.CalendarDuration(15 * time.Minute)}
+	later := storage.Event{ID: "later", StartTime: now.Add(time.Hour), UserID: "user1",
+		NotifyBefore: calendar_types.CalendarDuration(15 * time.Minute)}
+	past := storage.Event{ID: "past", StartTime: now.AddDate(-1, 0, 0), Duration: calendar_types.CalendarDuration(time.Hour), UserID: "user1"}
+	for _, e := range []storage.Event{soon, later, past} {
+		_ = s.AddEvent(ctx, e)
+	}
+
+	events, _ := s.GetEventsForNotification(ctx, now)
+	if len(events) != 1 || events[0].ID != "soon" {
+		t.Errorf("expected [soon], got %v", events)
+	}
+	_ = s.MarkEventNotified(ctx, "soon")
+	if events, _ := s.GetEventsForNotification(ctx, now); len(events) != 0 {
+		t.Errorf("expected no events after notification, got %v", events)
+	}
+
+	_ = s.DeleteEvent(ctx, "later", 0)
+	purge, _ := s.ListEventsToPurge(ctx, now.AddDate(0, -1, 0), time.Now().Add(time.Minute))
+	if len(purge) != 2 {
+		t.Errorf("expected past and deleted events to purge, got %v", purge)
+	}
+	if err := s.PurgeEvents(ctx, []string{"past", "later"}); err != nil {
+		t.Fatalf("failed to purge events: %v", err)
+	}
+	if deleted, _ := s.ListDeletedEvents(ctx); len(deleted) != 0 {
+		t.Errorf("expected trash to be empty, got %v", deleted)
+	}
+	if _, err := s.GetEventByID(ctx, "past"); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Errorf("expected purged event to be gone, got %v", err)
+	}
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	strg := New(logger.New("debug"))
+	ctx := context.Background()
+	record := storage.IdempotencyRecord{
+		UserID: "alice", Key: "retry-1", Fingerprint: "f1", EventID: "e1", ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	if _, reserved, err := strg.ReserveIdempotencyKey(ctx, record); err != nil || !reserved {
+		t.Fatalf("expected key to be reserved, got %v, %v", reserved, err)
+	}
+	retry := record
+	retry.EventID = "e2"
+	existing, reserved, err := strg.ReserveIdempotencyKey(ctx, retry)
+	if err != nil || reserved || existing.EventID != "e1" || existing.Response != nil {
+		t.Fatalf("expected pending record for e1, got %+v, %v, %v", existing, reserved, err)
+	}
+
+	if err := strg.CompleteIdempotencyKey(ctx, "alice", "retry-1", storage.Event{ID: "e1", Title: "Standup"}); err != nil {
+		t.Fatalf("failed to complete key: %v", err)
+	}
+	existing, _, _ = strg.ReserveIdempotencyKey(ctx, retry)
+	if existing.Response == nil || existing.Response.Title != "Standup" {
+		t.Errorf("expected saved response, got %+v", existing.Response)
+	}
+
+	// Ключ другого пользователя не пересекается с ключом alice
+	bob := record
+	bob.UserID = "bob"
+	if _, reserved, _ := strg.ReserveIdempotencyKey(ctx, bob); !reserved {
+		t.Error("expected bob to reserve the same key")
+	}
+
+	// Освобождённый и истёкший ключи резервируются заново
+	if err := strg.ReleaseIdempotencyKey(ctx, "bob", "retry-1"); err != nil {
+		t.Fatalf("failed to release key: %v", err)
+	}
+	if _, reserved, _ := strg.ReserveIdempotencyKey(ctx, bob); !reserved {
+		t.Error("expected released key to be reserved again")
+	}
+	expired := record
+	expired.Key = "retry-2"
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	_, _, _ = strg.ReserveIdempotencyKey(ctx, expired)
+	if _, reserved, _ := strg.ReserveIdempotencyKey(ctx, expired); !reserved {
+		t.Error("expected expired key to be reserved again")
+	}
+}
+
+func TestListTagStats(t *testing.T) {
+	s := New(logger.New("debug"))
+	ctx := context.Background()
+	day := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+
+	events := []storage.Event{
+		{ID: "1", StartTime: day, CalendarID: "personal:alice", Labels: storage.Labels{Tags: []string{"project-x", "weekly"}}},
+		{ID: "2", StartTime: day, CalendarID: "personal:alice", Labels: storage.Labels{Tags: []string{"project-x"}}},
+		{ID: "3", StartTime: day, CalendarID: "team:ops", Labels: storage.Labels{Tags: []string{"oncall"}}},
+		{ID: "4", StartTime: day.AddDate(0, 1, 0), CalendarID: "personal:alice", Labels: storage.Labels{Tags: []string{"weekly"}}},
+	}
+	for _, e := range events {
+		_ = s.AddEvent(ctx, e)
+	}
+	_ = s.DeleteEvent(ctx, "2", 0)
+
+	stats, err := s.ListTagStats(ctx, storage.TagStatsQuery{
+		CalendarIDs: []string{"personal:alice"},
+		From:        day,
+		To:          day.AddDate(0, 0, 1),
+		Limit:       10,
+	})
+	if err != nil {
+		t.Fatalf("failed to count tags: %v", err)
+	}
+	want := []storage.TagStat{{Tag: "project-x", Count: 1}, {Tag: "weekly", Count: 1}}
+	if fmt.Sprint(stats) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, stats)
+	}
+
+	stats, _ = s.ListTagStats(ctx, storage.TagStatsQuery{Limit: 1})
+	if want := []storage.TagStat{{Tag: "weekly", Count: 2}}; fmt.Sprint(stats) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, stats)
+	}
+
+	// В списках событие должно иметь все теги запроса
+	list, err := s.ListEventsForMonth(ctx, day, storage.ListQuery{Tags: []string{"project-x", "weekly"}})
+	if err != nil {
+		t.Fatalf("failed to list events: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "1" {
+		t.Errorf("expected event 1 with both tags, got %+v", list)
+	}
+	if list, _ := s.ListEventsForMonth(ctx, day, storage.ListQuery{Tags: []string{"oncall"}, CalendarIDs: []string{"personal:alice"}}); len(list) != 0 {
+		t.Errorf("expected no tagged events outside listed calendars, got %+v", list)
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	s := New(logger.New("debug"))
+	ctx := context.Background()
+	created := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	_ = s.AddEvent(ctx, storage.Event{ID: "1", Title: "Planning", StartTime: created})
+	_ = s.AddEvent(ctx, storage.Event{ID: "2", Title: "Trashed", StartTime: created})
+	_ = s.DeleteEvent(ctx, "2", 0)
+
+	agenda := storage.Attachment{ID: "b", EventID: "1", Kind: storage.AttachmentFile, Name: "agenda.pdf", Size: 3, CreatedAt: created}
+	call := storage.Attachment{ID: "a", EventID: "1", Kind: storage.AttachmentLink, URL: "https://meet.example.com/x", CreatedAt: created.Add(time.Minute)}
+	for _, a := range []storage.Attachment{call, agenda} {
+		if err := s.AddAttachment(ctx, a); err != nil {
+			t.Fatalf("failed to add attachment: %v", err)
+		}
+	}
+	for _, eventID := range []string{"2", "missing"} {
+		err := s.AddAttachment(ctx, storage.Attachment{ID: "c", EventID: eventID})
+		if !errors.Is(err, storage.ErrEventNotFound) {
+			t.Errorf("expected ErrEventNotFound for event %s, got %v", eventID, err)
+		}
+	}
+
+	attachments, err := s.ListAttachments(ctx, "1")
+	if err != nil || fmt.Sprint(attachments) != fmt.Sprint([]storage.Attachment{agenda, call}) {
+		t.Errorf("expected attachments in order of creation, got %v, %v", attachments, err)
+	}
+	if got, err := s.GetAttachment(ctx, "b"); err != nil || got.Name != "agenda.pdf" {
+		t.Errorf("expected agenda, got %+v, %v", got, err)
+	}
+
+	if err := s.DeleteAttachment(ctx, "a"); err != nil {
+		t.Fatalf("failed to delete attachment: %v", err)
+	}
+	if err := s.DeleteAttachment(ctx, "a"); !errors.Is(err, storage.ErrAttachmentNotFound) {
+		t.Errorf("expected ErrAttachmentNotFound, got %v", err)
+	}
+
+	// Вложения удаляются вместе с событием
+	_ = s.PurgeEvents(ctx, []string{"1"})
+	if _, err := s.GetAttachment(ctx, "b"); !errors.Is(err, storage.ErrAttachmentNotFound) {
+		t.Errorf("expected attachment to be purged with event, got %v", err)
 	}
 }
@@ -0,0 +1,39 @@
+package memorystorage
+
+import (
+	"context"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+func (strg *Storage) ListTagStats(ctx context.Context, query storage.TagStatsQuery) ([]storage.TagStat, error) {
+	strg.mu.RLock()
+	defer strg.mu.RUnlock()
+
+	calendars := make(map[string]bool, len(query.CalendarIDs))
+	for _, id := range query.CalendarIDs {
+		calendars[id] = true
+	}
+	counts := map[string]int{}
+	for _, e := range strg.events {
+		if e.DeletedAt != nil || (len(calendars) > 0 && !calendars[e.CalendarID]) {
+			continue
+		}
+		if (!query.From.IsZero() && e.StartTime.Before(query.From)) || (!query.To.IsZero() && !e.StartTime.Before(query.To)) {
+			continue
+		}
+		for _, tag := range e.Tags {
+			counts[tag]++
+		}
+	}
+
+	stats := make([]storage.TagStat, 0, len(counts))
+	for tag, count := range counts {
+		stats = append(stats, storage.TagStat{Tag: tag, Count: count})
+	}
+	storage.SortTagStats(stats)
+	if len(stats) > query.Limit {
+		stats = stats[:query.Limit]
+	}
+	return stats, nil
+}
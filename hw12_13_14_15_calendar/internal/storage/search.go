@@ -0,0 +1,19 @@
+package storage
+
+import "time"
+
+// SearchQuery - параметры полнотекстового поиска событий
+type SearchQuery struct {
+	Text        string    // Слова для поиска; событие должно содержать их все
+	UserID      string    // Искать только события пользователя; пусто - по всем
+	CalendarIDs []string  // Календари; пусто - все календари
+	From        time.Time // Начало события не раньше From; нулевое значение - без ограничения
+	To          time.Time // Начало события раньше To; нулевое значение - без ограничения
+	Limit       int       // Сколько результатов вернуть
+}
+
+// SearchResult - найденное событие и его релевантность; чем больше Rank, тем выше в выдаче
+type SearchResult struct {
+	Event Event
+	Rank  float64
+}
@@ -0,0 +1,67 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlrow"
+)
+
+// AddAttachment прикрепляет вложение к активному событию; событие проверяется тем же запросом
+func (strg *Storage) AddAttachment(ctx context.Context, a storage.Attachment) error {
+	query := `
+		INSERT INTO event_attachments (id, event_id, kind, name, content_type, size, url, created_by, created_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9
+		WHERE EXISTS (SELECT 1 FROM events WHERE id = $2 AND deleted_at IS NULL)
+	`
+	res, err := strg.db.ExecContext(ctx, query,
+		a.ID, a.EventID, string(a.Kind), a.Name, a.ContentType, a.Size, a.URL, a.CreatedBy, a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert attachment: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return storage.ErrEventNotFound
+	}
+	return nil
+}
+
+func (strg *Storage) GetAttachment(ctx context.Context, id string) (storage.Attachment, error) {
+	query := `SELECT ` + sqlrow.AttachmentColumns + ` FROM event_attachments WHERE id = $1`
+	a, err := sqlrow.ScanAttachment(strg.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Attachment{}, storage.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return storage.Attachment{}, fmt.Errorf("failed to get attachment %s: %w", id, err)
+	}
+	return a, nil
+}
+
+// ListAttachments возвращает вложения события в порядке добавления
+func (strg *Storage) ListAttachments(ctx context.Context, eventID string) ([]storage.Attachment, error) {
+	query := `
+		SELECT ` + sqlrow.AttachmentColumns + `
+		FROM event_attachments
+		WHERE event_id = $1
+		ORDER BY created_at, id
+	`
+	rows, err := strg.db.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	return sqlrow.ScanAttachments(rows)
+}
+
+func (strg *Storage) DeleteAttachment(ctx context.Context, id string) error {
+	res, err := strg.db.ExecContext(ctx, `DELETE FROM event_attachments WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment %s: %w", id, err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return storage.ErrAttachmentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package sqlstorage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// ApplyBatch выполняет все операции в одной транзакции. Каждая операция обёрнута в SAVEPOINT,
+// чтобы ошибка одной из них не ломала транзакцию: в режиме best-effort упавшая операция
+// откатывается до своей точки сохранения, а в режиме "всё или ничего" откатывается вся транзакция.
+// Запись журнала изменений пишется в точке сохранения операции и откатывается вместе с ней.
+func (strg *Storage) ApplyBatch(ctx context.Context, ops []storage.BatchOperation, atomic bool) ([]storage.BatchResult, error) {
+	tx, err := strg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin batch: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	results := make([]storage.BatchResult, len(ops))
+	failed := false
+	for i, op := range ops {
+		results[i].ID = op.Event.ID
+
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT batch_op`); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		var opErr error
+		switch op.Type {
+		case storage.BatchCreate:
+			results[i].Version, opErr = audited(ctx, tx, storage.AuditCreate, op.Event.ID, func() (int64, error) {
+				return addEvent(ctx, tx, op.Event)
+			})
+		case storage.BatchUpdate:
+			results[i].Version, opErr = audited(ctx, tx, storage.AuditUpdate, op.Event.ID, func() (int64, error) {
+				return updateEvent(ctx, tx, op.Event)
+			})
+		case storage.BatchDelete:
+			results[i].Version, opErr = audited(ctx, tx, storage.AuditDelete, op.Event.ID, func() (int64, error) {
+				return deleteEvent(ctx, tx, op.Event.ID, op.Event.Version)
+			})
+		default:
+			opErr = fmt.Errorf("unknown batch operation %q", op.Type)
+		}
+
+		if opErr != nil {
+			results[i].Err = opErr
+			failed = true
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT batch_op`); err != nil {
+				return nil, fmt.Errorf("failed to rollback to savepoint: %w", err)
+			}
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT batch_op`); err != nil {
+			return nil, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+	}
+
+	if atomic && failed {
+		storage.AbortBatch(results)
+		return results, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit batch: %w", err)
+	}
+	return results, nil
+}
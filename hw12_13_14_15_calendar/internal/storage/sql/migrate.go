@@ -4,35 +4,175 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/migrations"
 	migration "github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
-func RunMigrations(dsn string, migrationsPath string) error {
+// RunMigrations применяет все встроенные миграции, которых ещё нет в базе
+func RunMigrations(dsn string) error {
+	m, err := NewMigrator(dsn)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	return m.Up()
+}
+
+// Migrator управляет схемой Postgres по встроенным миграциям (пакет migrations)
+type Migrator struct {
+	db      *sql.DB
+	source  source.Driver
+	migrate *migration.Migrate
+}
+
+// MigrationStatus - состояние одной миграции
+type MigrationStatus struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+func NewMigrator(dsn string) (*Migrator, error) {
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
-		return fmt.Errorf("open db: %w", err)
+		return nil, fmt.Errorf("open db: %w", err)
 	}
-	defer db.Close()
 
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
 	if err != nil {
-		return fmt.Errorf("migrate driver: %w", err)
+		db.Close()
+		return nil, fmt.Errorf("migrate driver: %w", err)
+	}
+
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate source: %w", err)
 	}
 
-	m, err := migration.NewWithDatabaseInstance(
-		"file://"+migrationsPath,
-		"postgres", driver)
+	m, err := migration.NewWithInstance("iofs", src, "postgres", driver)
 	if err != nil {
-		return fmt.Errorf("migrate init: %w", err)
+		db.Close()
+		return nil, fmt.Errorf("migrate init: %w", err)
 	}
+	return &Migrator{db: db, source: src, migrate: m}, nil
+}
 
-	err = m.Up()
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.migrate.Close()
+	return errors.Join(srcErr, dbErr, m.db.Close())
+}
+
+// Up применяет все ещё не применённые миграции
+func (m *Migrator) Up() error {
+	err := m.migrate.Up()
 	if err != nil && !errors.Is(err, migration.ErrNoChange) {
 		return fmt.Errorf("migrate up: %w", err)
 	}
+	return nil
+}
 
+// Down откатывает n последних миграций
+func (m *Migrator) Down(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("migrate down: number of steps must be positive, got %d", n)
+	}
+	err := m.migrate.Steps(-n)
+	if err != nil && !errors.Is(err, migration.ErrNoChange) {
+		return fmt.Errorf("migrate down: %w", err)
+	}
+	return nil
+}
+
+// Force записывает версию схемы без выполнения миграций и снимает признак dirty.
+// Нужна, когда миграция упала посередине и базу поправили вручную.
+func (m *Migrator) Force(version int) error {
+	if err := m.migrate.Force(version); err != nil {
+		return fmt.Errorf("migrate force: %w", err)
+	}
 	return nil
 }
+
+// Version возвращает текущую версию схемы; 0 - миграции ещё не применялись.
+// dirty означает, что последняя миграция не завершилась.
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.migrate.Version()
+	if errors.Is(err, migration.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("migrate version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Status перечисляет встроенные миграции и отмечает применённые
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	current, _, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	version, err := m.source.First()
+	for err == nil {
+		r, name, readErr := m.source.ReadUp(version)
+		if readErr != nil {
+			return nil, fmt.Errorf("read migration %d: %w", version, readErr)
+		}
+		r.Close()
+		statuses = append(statuses, MigrationStatus{Version: version, Name: name, Applied: version <= current})
+		version, err = m.source.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+	return statuses, nil
+}
+
+var migrationFile = regexp.MustCompile(`^(\d+)_.+\.(up|down)\.sql$`)
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// CreateMigration создаёт в каталоге dir пустую пару файлов со следующим номером.
+// Работает с исходниками: встроенные миграции попадут в бинарник при следующей сборке.
+func CreateMigration(dir, name string) (up, down string, err error) {
+	if !migrationName.MatchString(name) {
+		return "", "", fmt.Errorf("migration name must consist of lowercase letters, digits and underscores: %q", name)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("read migrations dir: %w", err)
+	}
+	last := 0
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		if n, _ := strconv.Atoi(match[1]); n > last {
+			last = n
+		}
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%03d_%s", last+1, name))
+	up, down = base+".up.sql", base+".down.sql"
+	for _, path := range []string{up, down} {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err != nil {
+			return "", "", fmt.Errorf("create migration: %w", err)
+		}
+		file.Close()
+	}
+	return up, down, nil
+}
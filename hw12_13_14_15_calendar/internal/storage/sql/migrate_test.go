@@ -0,0 +1,36 @@
+package sqlstorage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"001_init.up.sql", "001_init.down.sql", "009_tags.up.sql", "009_tags.down.sql", ".gitkeep"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	up, down, err := CreateMigration(dir, "add_attachments")
+	if err != nil {
+		t.Fatalf("CreateMigration: %v", err)
+	}
+	if want := filepath.Join(dir, "010_add_attachments.up.sql"); up != want {
+		t.Errorf("up = %s, want %s", up, want)
+	}
+	if want := filepath.Join(dir, "010_add_attachments.down.sql"); down != want {
+		t.Errorf("down = %s, want %s", down, want)
+	}
+	for _, path := range []string{up, down} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s not created: %v", path, err)
+		}
+	}
+
+	if _, _, err := CreateMigration(dir, "Bad Name"); err == nil {
+		t.Error("expected error for invalid name")
+	}
+}
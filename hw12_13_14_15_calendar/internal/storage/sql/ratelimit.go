@@ -0,0 +1,86 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/ratelimit"
+)
+
+// rateLimitIdle - через сколько без запросов корзина удаляется из таблицы
+const rateLimitIdle = time.Hour
+
+// RateLimiter хранит корзины ограничителя в Postgres, поэтому лимит общий
+// для всех экземпляров календаря. Каждая проверка - короткая транзакция с блокировкой строки ключа.
+type RateLimiter struct {
+	db *sql.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewRateLimiter(dsn string) (*RateLimiter, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	return &RateLimiter{db: db}, nil
+}
+
+func (l *RateLimiter) Close() error {
+	return l.db.Close()
+}
+
+func (l *RateLimiter) Allow(ctx context.Context, key string, rule ratelimit.Rule) (time.Duration, error) {
+	l.sweep(ctx)
+
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin rate limit tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rate_limits (key, tokens, updated_at) VALUES ($1, $2, now())
+		ON CONFLICT (key) DO NOTHING
+	`, key, rule.Capacity())
+	if err != nil {
+		return 0, fmt.Errorf("create rate limit bucket: %w", err)
+	}
+
+	// Время считает база, чтобы расхождение часов экземпляров не влияло на лимит
+	var tokens, elapsed float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT tokens, EXTRACT(EPOCH FROM now() - updated_at)::DOUBLE PRECISION
+		FROM rate_limits WHERE key = $1 FOR UPDATE
+	`, key).Scan(&tokens, &elapsed)
+	if err != nil {
+		return 0, fmt.Errorf("read rate limit bucket: %w", err)
+	}
+
+	left, retryAfter := rule.Take(tokens, time.Duration(elapsed*float64(time.Second)))
+	_, err = tx.ExecContext(ctx, `UPDATE rate_limits SET tokens = $2, updated_at = now() WHERE key = $1`, key, left)
+	if err != nil {
+		return 0, fmt.Errorf("update rate limit bucket: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit rate limit tx: %w", err)
+	}
+	return retryAfter, nil
+}
+
+// sweep не чаще раза в rateLimitIdle удаляет корзины, к которым давно не обращались
+func (l *RateLimiter) sweep(ctx context.Context) {
+	l.mu.Lock()
+	if time.Since(l.lastSweep) < rateLimitIdle {
+		l.mu.Unlock()
+		return
+	}
+	l.lastSweep = time.Now()
+	l.mu.Unlock()
+
+	_, _ = l.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE updated_at < now() - make_interval(secs => $1)`, rateLimitIdle.Seconds())
+}
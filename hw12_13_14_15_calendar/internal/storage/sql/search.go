@@ -0,0 +1,46 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlrow"
+)
+
+func (strg *Storage) SearchEvents(ctx context.Context, query storage.SearchQuery) ([]storage.SearchResult, error) {
+	// Пустые границы передаются как NULL, пустой список календарей не ограничивает выборку
+	sqlQuery := `
+		SELECT ` + eventColumns + `, ts_rank(search_vector, query) AS rank
+		FROM events, plainto_tsquery('simple', $1) AS query
+		WHERE search_vector @@ query
+		AND deleted_at IS NULL
+		AND ($2 = '' OR user_id = $2)
+		AND ($3 = '' OR calendar_id = ANY(string_to_array($3, ',')))
+		AND ($4::timestamp IS NULL OR start_time >= $4)
+		AND ($5::timestamp IS NULL OR start_time < $5)
+		ORDER BY rank DESC, start_time
+		LIMIT $6
+	`
+	from := sql.NullTime{Time: query.From, Valid: !query.From.IsZero()}
+	to := sql.NullTime{Time: query.To, Valid: !query.To.IsZero()}
+
+	rows, err := strg.db.QueryContext(ctx, sqlQuery, query.Text, query.UserID, strings.Join(query.CalendarIDs, ","), from, to, query.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search events: %w", err)
+	}
+	defer rows.Close()
+
+	results := []storage.SearchResult{}
+	for rows.Next() {
+		var rank float64
+		e, err := sqlrow.ScanEvent(rows, &rank)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, storage.SearchResult{Event: e, Rank: rank})
+	}
+	return results, rows.Err()
+}
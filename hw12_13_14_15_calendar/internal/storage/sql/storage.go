@@ -5,122 +5,269 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
 	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlrow"
 	_ "github.com/jackc/pgx/v5"
 )
 
 type Storage struct {
 	db     *sql.DB
 	logger app.Logger
+
+	sweepMu          sync.Mutex
+	idempotencySweep time.Time // когда последний раз удалялись истёкшие ключи идемпотентности
+}
+
+func (strg *Storage) AddEvent(ctx context.Context, event storage.Event) error {
+	return strg.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := audited(ctx, tx, storage.AuditCreate, event.ID, func() (int64, error) {
+			return addEvent(ctx, tx, event)
+		})
+		return err
+	})
 }
 
-func (storage *Storage) AddEvent(ctx context.Context, event storage.Event) error {
+func (strg *Storage) UpdateEvent(ctx context.Context, event storage.Event) error {
+	return strg.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := audited(ctx, tx, storage.AuditUpdate, event.ID, func() (int64, error) {
+			return updateEvent(ctx, tx, event)
+		})
+		return err
+	})
+}
+
+// inTx выполняет fn в транзакции: событие, его теги и запись журнала изменений пишутся
+// разными запросами, но меняются вместе
+func (strg *Storage) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := strg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (strg *Storage) DeleteEvent(ctx context.Context, id string, expectedVersion int64) error {
+	return strg.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := audited(ctx, tx, storage.AuditDelete, id, func() (int64, error) {
+			return deleteEvent(ctx, tx, id, expectedVersion)
+		})
+		return err
+	})
+}
+
+// querier - общее у *sql.DB и *sql.Tx, чтобы одни и те же запросы работали и в пакетной транзакции
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Функции ниже возвращают версию события после изменения
+
+func addEvent(ctx context.Context, q querier, event storage.Event) (int64, error) {
 	query := `
-		INSERT INTO events (id, title, description, start_time, duration, user_id, notify_before)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO events (id, title, description, start_time, duration, user_id, notify_before, calendar_id, category, color)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING version
 	`
-	_, err := storage.db.ExecContext(ctx, query, event.ID, event.Title, event.Description, event.StartTime, event.Duration, event.UserID, event.NotifyBefore)
-	return err
+	var version int64
+	err := q.QueryRowContext(ctx, query, event.ID, event.Title, event.Description, event.StartTime, sqlrow.Seconds(event.Duration), event.UserID, sqlrow.Seconds(event.NotifyBefore), event.CalendarID, event.Category, event.Color).Scan(&version)
+	if isUniqueViolation(err) {
+		return 0, storage.ErrEventExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return version, replaceTags(ctx, q, event.ID, event.Tags)
 }
 
-func (storage *Storage) UpdateEvent(ctx context.Context, event storage.Event) error {
+func updateEvent(ctx context.Context, q querier, event storage.Event) (int64, error) {
+	// Версия увеличивается при каждом изменении; $8 = 0 означает обновление без проверки версии,
+	// пустой $9 оставляет событие в прежнем календаре. Разметка, как и остальные поля, заменяется целиком.
 	query := `
 		UPDATE events
-		SET title = $2, description = $3, start_time = $4, duration = $5, user_id = $6, notify_before = $7
-		WHERE id = $1
+		SET title = $2, description = $3, start_time = $4, duration = $5, user_id = $6, notify_before = $7,
+			calendar_id = COALESCE(NULLIF($9, ''), calendar_id), category = $10, color = $11,
+			version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL AND ($8 = 0 OR version = $8)
+		RETURNING version
 	`
-	res, err := storage.db.ExecContext(
+	var version int64
+	err := q.QueryRowContext(
 		ctx,
 		query,
 		event.ID,
 		event.Title,
 		event.Description,
 		event.StartTime,
-		event.Duration,
+		sqlrow.Seconds(event.Duration),
 		event.UserID,
-		event.NotifyBefore,
-	)
+		sqlrow.Seconds(event.NotifyBefore),
+		event.Version,
+		event.CalendarID,
+		event.Category,
+		event.Color,
+	).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, missingOrConflict(ctx, q, event.ID)
+	}
 	if err != nil {
+		return 0, err
+	}
+	return version, replaceTags(ctx, q, event.ID, event.Tags)
+}
+
+func deleteEvent(ctx context.Context, q querier, id string, expectedVersion int64) (int64, error) {
+	// Событие только помечается удалённым, окончательно его удаляет планировщик
+	query := `
+		UPDATE events
+		SET deleted_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)
+		RETURNING version
+	`
+	var version int64
+	err := q.QueryRowContext(ctx, query, id, expectedVersion).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, missingOrConflict(ctx, q, id)
+	}
+	return version, err
+}
+
+// uniqueViolation - SQLSTATE нарушения ограничения уникальности
+const uniqueViolation = "23505"
+
+// isUniqueViolation проверяет код ошибки Postgres; метод SQLState есть у ошибок и lib/pq, и pgx
+func isUniqueViolation(err error) bool {
+	var pgErr interface{ SQLState() string }
+	return errors.As(err, &pgErr) && pgErr.SQLState() == uniqueViolation
+}
+
+// missingOrConflict объясняет, почему условный UPDATE не затронул ни одной строки
+func missingOrConflict(ctx context.Context, q querier, id string) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM events WHERE id = $1 AND deleted_at IS NULL)`
+	if err := q.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
 		return err
 	}
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		return sql.ErrNoRows
+	if exists {
+		return storage.ErrVersionConflict
 	}
-	return nil
+	return storage.ErrEventNotFound
 }
 
-func (storage *Storage) DeleteEvent(ctx context.Context, id string) error {
-	query := `DELETE FROM events WHERE id = $1`
-	res, err := storage.db.ExecContext(ctx, query, id)
+func (strg *Storage) ListDeletedEvents(ctx context.Context) ([]storage.Event, error) {
+	query := `SELECT ` + eventColumns + ` FROM events WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`
+	rows, err := strg.db.QueryContext(ctx, query)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	rows, _ := res.RowsAffected()
-	if rows == 0 {
-		return sql.ErrNoRows
+	return sqlrow.ScanEvents(rows)
+}
+
+func (strg *Storage) GetDeletedEvent(ctx context.Context, id string) (storage.Event, error) {
+	query := `SELECT ` + eventColumns + ` FROM events WHERE id = $1 AND deleted_at IS NOT NULL`
+	e, err := sqlrow.ScanEvent(strg.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Event{}, storage.ErrEventNotFound
 	}
-	return nil
+	if err != nil {
+		return storage.Event{}, fmt.Errorf("failed to get deleted event from db by id %s: %w", id, err)
+	}
+	return e, nil
 }
 
-func (strg *Storage) GetEventByID(ctx context.Context, id string) (storage.Event, error) {
+func (strg *Storage) RestoreEvent(ctx context.Context, id string) error {
+	return strg.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := audited(ctx, tx, storage.AuditRestore, id, func() (int64, error) {
+			return restoreEvent(ctx, tx, id)
+		})
+		return err
+	})
+}
+
+func restoreEvent(ctx context.Context, q querier, id string) (int64, error) {
 	query := `
-		SELECT id, title, description, start_time, duration, user_id, notify_before
-		FROM events
-		WHERE id = $1
+		UPDATE events
+		SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING version
 	`
-	var e storage.Event
-	err := strg.db.QueryRowContext(ctx, query, id).Scan(
-		&e.ID, &e.Title, &e.Description, &e.StartTime, &e.Duration, &e.UserID, &e.NotifyBefore,
-	)
+	var version int64
+	err := q.QueryRowContext(ctx, query, id).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, storage.ErrEventNotFound
+	}
+	return version, err
+}
+
+func (strg *Storage) GetEventByID(ctx context.Context, id string) (storage.Event, error) {
+	query := `SELECT ` + eventColumns + ` FROM events WHERE id = $1 AND deleted_at IS NULL`
+	e, err := sqlrow.ScanEvent(strg.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Event{}, storage.ErrEventNotFound
+	}
 	if err != nil {
-		return storage.Event{}, errors.New(fmt.Sprintf("Failed to get event from db by id %s: %s\n", id, err))
+		return storage.Event{}, fmt.Errorf("failed to get event from db by id %s: %w", id, err)
 	}
 	return e, nil
 }
 
-func (storage *Storage) ListEventsForDay(ctx context.Context, date time.Time) ([]storage.Event, error) {
+func (storage *Storage) ListEventsForDay(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error) {
 	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 	end := start.Add(24 * time.Hour)
-	return storage.listEvents(ctx, start, end)
+	return storage.listEvents(ctx, start, end, query)
 }
 
-func (storage *Storage) ListEventsForWeek(ctx context.Context, date time.Time) ([]storage.Event, error) {
+func (storage *Storage) ListEventsForWeek(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error) {
 	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 	end := start.AddDate(0, 0, 7)
-	return storage.listEvents(ctx, start, end)
+	return storage.listEvents(ctx, start, end, query)
 }
 
-func (storage *Storage) ListEventsForMonth(ctx context.Context, date time.Time) ([]storage.Event, error) {
+func (storage *Storage) ListEventsForMonth(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error) {
 	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 	end := start.AddDate(0, 1, 0)
-	return storage.listEvents(ctx, start, end)
+	return storage.listEvents(ctx, start, end, query)
 }
 
-func (strg *Storage) listEvents(ctx context.Context, start time.Time, end time.Time) ([]storage.Event, error) {
-	query := `SELECT * FROM events WHERE start_time >= $1 AND start_time < $2;`
-	rows, err := strg.db.QueryContext(ctx, query, start, end)
+func (strg *Storage) listEvents(ctx context.Context, start time.Time, end time.Time, query storage.ListQuery) ([]storage.Event, error) {
+	// Пустой список календарей не ограничивает выборку. При отборе по тегам событие соединяется
+	// с event_tags и остаётся, если совпали все теги запроса.
+	tagJoin := ""
+	args := []any{start, end, strings.Join(query.CalendarIDs, ",")}
+	if len(query.Tags) > 0 {
+		tagJoin = `
+		JOIN (
+			SELECT event_id FROM event_tags
+			WHERE tag = ANY(string_to_array($4, ','))
+			GROUP BY event_id
+			HAVING COUNT(*) = cardinality(string_to_array($4, ','))
+		) tagged ON tagged.event_id = events.id`
+		args = append(args, strings.Join(query.Tags, ","))
+	}
+	sqlQuery := `
+		SELECT ` + eventColumns + ` FROM events` + tagJoin + `
+		WHERE start_time >= $1 AND start_time < $2 AND deleted_at IS NULL
+		AND ($3 = '' OR calendar_id = ANY(string_to_array($3, ',')))
+	`
+	rows, err := strg.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var events []storage.Event
-	for rows.Next() {
-		var e storage.Event
-		if err := rows.Scan(&e.ID, &e.Title, &e.Description, &e.StartTime, &e.Duration, &e.UserID, &e.NotifyBefore); err != nil {
-			strg.logger.Error(fmt.Sprintf("Failed to scan event from db: %s\n", err))
-			return nil, err
-		}
-		events = append(events, e)
-	}
-	return events, nil
+	return sqlrow.ScanEvents(rows)
 }
 
-func New(dsn string, logger app.Logger) (app.Storage, error) {
+func New(dsn string, logger app.Logger) (*Storage, error) {
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("cannot open db: %w", err)
@@ -0,0 +1,22 @@
+package sqlstorage
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	duplicate := fmt.Errorf("insert event: %w", &pgconn.PgError{Code: "23505"})
+	if !isUniqueViolation(duplicate) {
+		t.Error("expected wrapped 23505 to be a unique violation")
+	}
+	if isUniqueViolation(&pgconn.PgError{Code: "23503"}) {
+		t.Error("expected foreign key violation not to be a unique violation")
+	}
+	if isUniqueViolation(errors.New("connection refused")) || isUniqueViolation(nil) {
+		t.Error("expected errors without SQLSTATE not to be unique violations")
+	}
+}
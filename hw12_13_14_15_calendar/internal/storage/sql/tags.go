@@ -0,0 +1,67 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlrow"
+)
+
+// eventColumns - колонки событий и теги через запятую в том порядке, который ожидает sqlrow.ScanEvent
+const eventColumns = sqlrow.EventColumns + `,
+	COALESCE((SELECT string_agg(tag, ',' ORDER BY tag) FROM event_tags WHERE event_tags.event_id = events.id), '')`
+
+// replaceTags заменяет теги события; теги уже нормализованы и не содержат запятых
+func replaceTags(ctx context.Context, q querier, eventID string, tags []string) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM event_tags WHERE event_id = $1`, eventID); err != nil {
+		return fmt.Errorf("failed to clear tags of event %s: %w", eventID, err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	// Теги передаются одной строкой по той же причине, что и группы в ListAccessibleCalendars
+	query := `INSERT INTO event_tags (event_id, tag) SELECT $1, unnest(string_to_array($2, ','))`
+	if _, err := q.ExecContext(ctx, query, eventID, strings.Join(tags, ",")); err != nil {
+		return fmt.Errorf("failed to save tags of event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+func (strg *Storage) ListTagStats(ctx context.Context, query storage.TagStatsQuery) ([]storage.TagStat, error) {
+	// Пустые границы передаются как NULL, пустой список календарей не ограничивает выборку
+	sqlQuery := `
+		SELECT t.tag, COUNT(*) AS uses
+		FROM event_tags t
+		JOIN events e ON e.id = t.event_id
+		WHERE e.deleted_at IS NULL
+		AND ($1 = '' OR e.calendar_id = ANY(string_to_array($1, ',')))
+		AND ($2::timestamp IS NULL OR e.start_time >= $2)
+		AND ($3::timestamp IS NULL OR e.start_time < $3)
+		GROUP BY t.tag
+		ORDER BY uses DESC, t.tag
+		LIMIT $4
+	`
+	rows, err := strg.db.QueryContext(ctx, sqlQuery,
+		strings.Join(query.CalendarIDs, ","),
+		sql.NullTime{Time: query.From, Valid: !query.From.IsZero()},
+		sql.NullTime{Time: query.To, Valid: !query.To.IsZero()},
+		query.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tags: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []storage.TagStat{}
+	for rows.Next() {
+		var stat storage.TagStat
+		if err := rows.Scan(&stat.Tag, &stat.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag stats: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
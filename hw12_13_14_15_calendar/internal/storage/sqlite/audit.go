@@ -0,0 +1,93 @@
+package sqlitestorage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlrow"
+)
+
+// audited выполняет изменение change события id и в той же транзакции пишет его в журнал.
+// Блокировок строк в SQLite нет, но писатель у базы один: если между чтением состояния
+// и change базу изменил другой процесс, транзакция не сможет записать и завершится ошибкой.
+// Если запись в журнал не удалась, изменение откатывается вместе с транзакцией.
+func audited(ctx context.Context, q querier, action storage.AuditAction, id string, change func() (int64, error)) (int64, error) {
+	before, err := visibleEvent(ctx, q, id)
+	if err != nil {
+		return 0, err
+	}
+	version, err := change()
+	if err != nil {
+		return 0, err
+	}
+	after, err := visibleEvent(ctx, q, id)
+	if err != nil {
+		return 0, err
+	}
+	if err := addAuditRecord(ctx, q, storage.NewAuditRecord(ctx, action, id, before, after)); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// visibleEvent читает событие вне корзины или возвращает nil, если такого нет
+func visibleEvent(ctx context.Context, q querier, id string) (*storage.Event, error) {
+	query := `SELECT ` + eventColumns + ` FROM events WHERE id = ?1 AND deleted_at IS NULL`
+	event, err := sqlrow.ScanEvent(q.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event %s: %w", id, err)
+	}
+	return &event, nil
+}
+
+func addAuditRecord(ctx context.Context, q querier, record storage.AuditRecord) error {
+	before, err := sqlrow.MarshalSnapshot(record.Before)
+	if err != nil {
+		return err
+	}
+	after, err := sqlrow.MarshalSnapshot(record.After)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO event_audit (event_id, action, actor, request_id, before, after, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+	`
+	_, err = q.ExecContext(ctx, query,
+		record.EventID, string(record.Action), record.Actor, record.RequestID, before, after, utc(record.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to insert audit record: %w", err)
+	}
+	return nil
+}
+
+func (strg *Storage) ListAuditRecords(ctx context.Context, eventID string) ([]storage.AuditRecord, error) {
+	query := `
+		SELECT ` + sqlrow.AuditColumns + `
+		FROM event_audit
+		WHERE event_id = ?1
+		ORDER BY id
+	`
+	rows, err := strg.db.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit records: %w", err)
+	}
+	defer rows.Close()
+
+	records := []storage.AuditRecord{}
+	for rows.Next() {
+		record, err := sqlrow.ScanAuditRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit record: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
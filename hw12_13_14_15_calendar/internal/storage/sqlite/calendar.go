@@ -0,0 +1,166 @@
+package sqlitestorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlrow"
+)
+
+func (strg *Storage) CreateCalendar(ctx context.Context, c storage.Calendar) error {
+	query := `
+		INSERT INTO calendars (id, name, kind, owner_id)
+		VALUES (?1, ?2, ?3, ?4)
+		ON CONFLICT (id) DO NOTHING
+	`
+	res, err := strg.db.ExecContext(ctx, query, c.ID, c.Name, string(c.Kind), c.OwnerID)
+	if err != nil {
+		return fmt.Errorf("failed to create calendar: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return storage.ErrCalendarExists
+	}
+	return nil
+}
+
+func (strg *Storage) GetCalendar(ctx context.Context, id string) (storage.Calendar, error) {
+	query := `SELECT ` + sqlrow.CalendarColumns + ` FROM calendars WHERE id = ?1`
+	c, err := sqlrow.ScanCalendar(strg.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Calendar{}, storage.ErrCalendarNotFound
+	}
+	if err != nil {
+		return storage.Calendar{}, fmt.Errorf("failed to get calendar %s: %w", id, err)
+	}
+	return c, nil
+}
+
+// ListAccessibleCalendars выбирает по строке на каждое основание доступа (владение
+// или запись ACL), а итоговую роль в календаре считает как старшую из них.
+func (strg *Storage) ListAccessibleCalendars(ctx context.Context, principal storage.Principal) ([]storage.CalendarAccess, error) {
+	query := `
+		SELECT c.id, c.name, c.kind, c.owner_id, 'owner'
+		FROM calendars c
+		WHERE c.owner_id = ?1
+		UNION ALL
+		SELECT c.id, c.name, c.kind, c.owner_id, a.role
+		FROM calendars c
+		JOIN calendar_acl a ON a.calendar_id = c.id
+		WHERE (a.grantee_type = 'user' AND a.grantee_id = ?1)
+		OR (a.grantee_type = 'group' AND a.grantee_id IN (SELECT value FROM json_each(?2)))
+	`
+	// Группы передаются JSON-массивом и разворачиваются через json_each
+	groups, err := json.Marshal(append([]string{}, principal.Groups...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode groups: %w", err)
+	}
+	rows, err := strg.db.QueryContext(ctx, query, principal.UserID, string(groups))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendars: %w", err)
+	}
+	defer rows.Close()
+
+	access := map[string]storage.CalendarAccess{}
+	for rows.Next() {
+		var role string
+		c, err := sqlrow.ScanCalendar(rows, &role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar: %w", err)
+		}
+		current := access[c.ID]
+		access[c.ID] = storage.CalendarAccess{Calendar: c, Role: current.Role.Max(storage.Role(role))}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.CalendarAccess, 0, len(access))
+	for _, item := range access {
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Calendar.ID < result[j].Calendar.ID })
+	return result, nil
+}
+
+func (strg *Storage) SetACLEntry(ctx context.Context, entry storage.ACLEntry) error {
+	if _, err := strg.GetCalendar(ctx, entry.CalendarID); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO calendar_acl (calendar_id, grantee_type, grantee_id, role)
+		VALUES (?1, ?2, ?3, ?4)
+		ON CONFLICT (calendar_id, grantee_type, grantee_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := strg.db.ExecContext(ctx, query, entry.CalendarID, string(entry.GranteeType), entry.GranteeID, string(entry.Role))
+	if err != nil {
+		return fmt.Errorf("failed to set calendar access: %w", err)
+	}
+	return nil
+}
+
+func (strg *Storage) DeleteACLEntry(ctx context.Context, calendarID string, granteeType storage.GranteeType, granteeID string) error {
+	query := `DELETE FROM calendar_acl WHERE calendar_id = ?1 AND grantee_type = ?2 AND grantee_id = ?3`
+	res, err := strg.db.ExecContext(ctx, query, calendarID, string(granteeType), granteeID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke calendar access: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return storage.ErrGrantNotFound
+	}
+	return nil
+}
+
+func (strg *Storage) ListACL(ctx context.Context, calendarID string) ([]storage.ACLEntry, error) {
+	if _, err := strg.GetCalendar(ctx, calendarID); err != nil {
+		return nil, err
+	}
+	query := `
+		SELECT calendar_id, grantee_type, grantee_id, role
+		FROM calendar_acl
+		WHERE calendar_id = ?1
+		ORDER BY grantee_type, grantee_id
+	`
+	rows, err := strg.db.QueryContext(ctx, query, calendarID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar access: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []storage.ACLEntry{}
+	for rows.Next() {
+		entry, err := sqlrow.ScanACLEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar access: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (strg *Storage) ListCalendarEvents(ctx context.Context, calendarID string, from, to time.Time) ([]storage.Event, error) {
+	// Событие попадает в выборку, если пересекается с интервалом; пустые границы передаются как NULL
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE calendar_id = ?1
+		AND deleted_at IS NULL
+		AND (?2 IS NULL OR unixepoch(start_time) + duration > ?2)
+		AND (?3 IS NULL OR start_time < ?3)
+		ORDER BY start_time
+	`
+	// Конец события вычисляется в секундах Unix, начало сравнивается как строка в UTC
+	rows, err := strg.db.QueryContext(ctx, query, calendarID,
+		sql.NullInt64{Int64: from.Unix(), Valid: !from.IsZero()},
+		sql.NullTime{Time: utc(to), Valid: !to.IsZero()},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar events: %w", err)
+	}
+	return sqlrow.ScanEvents(rows)
+}
@@ -0,0 +1,82 @@
+package sqlitestorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlrow"
+)
+
+// idempotencySweepInterval - как часто из таблицы удаляются истёкшие ключи идемпотентности
+const idempotencySweepInterval = time.Hour
+
+// ReserveIdempotencyKey вставляет запись одним запросом: истёкшая строка перезаписывается,
+// действующая остаётся, и тогда она читается отдельно
+func (strg *Storage) ReserveIdempotencyKey(
+	ctx context.Context, record storage.IdempotencyRecord,
+) (storage.IdempotencyRecord, bool, error) {
+	now := time.Now()
+	strg.sweepIdempotency(ctx, now)
+
+	query := `
+		INSERT INTO idempotency_keys (user_id, key, fingerprint, event_id, expires_at)
+		VALUES (?1, ?2, ?3, ?4, ?5)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, event_id = EXCLUDED.event_id,
+			response = NULL, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= ?6
+	`
+	res, err := strg.db.ExecContext(ctx, query,
+		record.UserID, record.Key, record.Fingerprint, record.EventID, utc(record.ExpiresAt), utc(now))
+	if err != nil {
+		return storage.IdempotencyRecord{}, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows > 0 {
+		return record, true, nil
+	}
+
+	query = `SELECT ` + sqlrow.IdempotencyColumns + ` FROM idempotency_keys WHERE user_id = ?1 AND key = ?2`
+	existing, err := sqlrow.ScanIdempotencyRecord(strg.db.QueryRowContext(ctx, query, record.UserID, record.Key))
+	if err != nil {
+		return storage.IdempotencyRecord{}, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return existing, false, nil
+}
+
+func (strg *Storage) CompleteIdempotencyKey(ctx context.Context, userID, key string, response storage.Event) error {
+	data, err := sqlrow.MarshalSnapshot(&response)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE idempotency_keys SET response = ?3 WHERE user_id = ?1 AND key = ?2`
+	if _, err := strg.db.ExecContext(ctx, query, userID, key, data); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (strg *Storage) ReleaseIdempotencyKey(ctx context.Context, userID, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE user_id = ?1 AND key = ?2`
+	if _, err := strg.db.ExecContext(ctx, query, userID, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// sweepIdempotency не чаще раза в idempotencySweepInterval удаляет истёкшие ключи.
+// Ошибка не мешает запросу: истёкшие строки всё равно не действуют.
+func (strg *Storage) sweepIdempotency(ctx context.Context, now time.Time) {
+	strg.sweepMu.Lock()
+	if now.Sub(strg.idempotencySweep) < idempotencySweepInterval {
+		strg.sweepMu.Unlock()
+		return
+	}
+	strg.idempotencySweep = now
+	strg.sweepMu.Unlock()
+
+	if _, err := strg.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= ?1`, utc(now)); err != nil {
+		strg.logger.Warn("failed to delete expired idempotency keys: " + err.Error())
+	}
+}
@@ -0,0 +1,47 @@
+package sqlitestorage
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+
+	migration "github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Миграции SQLite встроены в бинарник: для запуска достаточно одного исполняемого файла
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+func RunMigrations(dsn string) error {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("open db: %w", err)
+	}
+	defer db.Close()
+
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		return fmt.Errorf("migrate driver: %w", err)
+	}
+
+	source, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return fmt.Errorf("migrate source: %w", err)
+	}
+
+	m, err := migration.NewWithInstance("iofs", source, "sqlite", driver)
+	if err != nil {
+		return fmt.Errorf("migrate init: %w", err)
+	}
+
+	err = m.Up()
+	if err != nil && !errors.Is(err, migration.ErrNoChange) {
+		return fmt.Errorf("migrate up: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+-- Схема SQLite повторяет итоговую схему Postgres из migrations/.
+-- Время хранится строкой в UTC, длительности - целым числом секунд.
+CREATE TABLE IF NOT EXISTS calendars (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    owner_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendars_owner_id ON calendars(owner_id);
+
+CREATE TABLE IF NOT EXISTS calendar_acl (
+    calendar_id TEXT NOT NULL REFERENCES calendars(id) ON DELETE CASCADE,
+    grantee_type TEXT NOT NULL,
+    grantee_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    PRIMARY KEY (calendar_id, grantee_type, grantee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_acl_grantee ON calendar_acl(grantee_type, grantee_id);
+
+CREATE TABLE IF NOT EXISTS events (
+    id TEXT PRIMARY KEY,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    start_time TIMESTAMP NOT NULL,
+    duration INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    notify_before INTEGER NOT NULL DEFAULT 0,
+    calendar_id TEXT NOT NULL REFERENCES calendars(id),
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_user_start_time ON events(user_id, start_time);
+CREATE INDEX IF NOT EXISTS idx_events_start_time ON events(start_time);
+CREATE INDEX IF NOT EXISTS idx_events_calendar_id ON events(calendar_id);
+CREATE INDEX IF NOT EXISTS idx_events_deleted_at ON events(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Журнал изменений не ссылается на events: записи должны пережить окончательное удаление события
+CREATE TABLE IF NOT EXISTS event_audit (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    before TEXT,
+    after TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_audit_event_id ON event_audit(event_id, id);
+
+-- Полнотекстовый поиск: индекс FTS5 над названием и описанием, поддерживается триггерами
+CREATE VIRTUAL TABLE IF NOT EXISTS events_fts USING fts5(
+    title, description, content = 'events', content_rowid = 'rowid', tokenize = 'unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS events_fts_insert AFTER INSERT ON events BEGIN
+    INSERT INTO events_fts (rowid, title, description) VALUES (new.rowid, new.title, new.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS events_fts_delete AFTER DELETE ON events BEGIN
+    INSERT INTO events_fts (events_fts, rowid, title, description) VALUES ('delete', old.rowid, old.title, old.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS events_fts_update AFTER UPDATE OF title, description ON events BEGIN
+    INSERT INTO events_fts (events_fts, rowid, title, description) VALUES ('delete', old.rowid, old.title, old.description);
+    INSERT INTO events_fts (rowid, title, description) VALUES (new.rowid, new.title, new.description);
+END;
@@ -0,0 +1,11 @@
+-- Разметка событий: категория и цвет - колонки событий, теги - отдельная таблица для фильтрации и статистики
+ALTER TABLE events ADD COLUMN category TEXT NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN color TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS event_tags (
+    event_id TEXT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (event_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_tags_tag ON event_tags(tag);
@@ -0,0 +1,14 @@
+-- Вложения событий: файлы и ссылки. Содержимое файлов лежит в хранилище вложений под ключом id.
+CREATE TABLE IF NOT EXISTS event_attachments (
+    id TEXT PRIMARY KEY,
+    event_id TEXT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    name TEXT NOT NULL,
+    content_type TEXT NOT NULL DEFAULT '',
+    size INTEGER NOT NULL DEFAULT 0,
+    url TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_attachments_event_id ON event_attachments(event_id);
@@ -0,0 +1,75 @@
+package sqlitestorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlrow"
+)
+
+// Методы ниже реализуют scheduler.NotificationStorage
+
+func (strg *Storage) GetEventsForNotification(ctx context.Context, now time.Time) ([]storage.Event, error) {
+	// Ищем события, для которых время уведомления попадает в интервал ±1 минута от текущего времени
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE unixepoch(start_time) - notify_before BETWEEN ?1 AND ?2
+		AND notify_before > 0
+		AND deleted_at IS NULL
+		ORDER BY start_time
+	`
+	rows, err := strg.db.QueryContext(ctx, query, now.Add(-time.Minute).Unix(), now.Add(time.Minute).Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	return sqlrow.ScanEvents(rows)
+}
+
+func (strg *Storage) MarkEventNotified(ctx context.Context, eventID string) error {
+	// Устанавливаем notify_before в 0, чтобы событие больше не попадало в выборку
+	res, err := strg.db.ExecContext(ctx, `UPDATE events SET notify_before = 0 WHERE id = ?1`, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to mark event as notified: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		strg.logger.Error("No event found to mark as notified: " + eventID)
+	}
+	return nil
+}
+
+func (strg *Storage) ListEventsToPurge(ctx context.Context, endedBefore, deletedBefore time.Time) ([]storage.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events
+		WHERE unixepoch(start_time) + duration < ?1
+		OR (deleted_at IS NOT NULL AND deleted_at < ?2)
+	`
+	rows, err := strg.db.QueryContext(ctx, query, endedBefore.Unix(), utc(deletedBefore))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events to purge: %w", err)
+	}
+	return sqlrow.ScanEvents(rows)
+}
+
+func (strg *Storage) PurgeEvents(ctx context.Context, ids []string) error {
+	tx, err := strg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin purge: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM events WHERE id = ?1`, id); err != nil {
+			return fmt.Errorf("failed to purge event %s: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit purge: %w", err)
+	}
+	strg.logger.Info(fmt.Sprintf("Purged %d events", len(ids)))
+	return nil
+}
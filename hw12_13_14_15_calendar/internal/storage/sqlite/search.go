@@ -0,0 +1,71 @@
+package sqlitestorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlrow"
+)
+
+func (strg *Storage) SearchEvents(ctx context.Context, query storage.SearchQuery) ([]storage.SearchResult, error) {
+	results := []storage.SearchResult{}
+	match := matchExpression(query.Text)
+	if match == "" {
+		return results, nil
+	}
+
+	// bm25 тем меньше, чем лучше совпадение; название весит больше описания.
+	// Пустые границы передаются как NULL, пустой список календарей не ограничивает выборку.
+	sqlQuery := `
+		SELECT ` + eventColumns + `, m.rank
+		FROM events
+		JOIN (
+			SELECT rowid AS event_rowid, -bm25(events_fts, 2.0, 1.0) AS rank
+			FROM events_fts
+			WHERE events_fts MATCH ?1
+		) m ON events.rowid = m.event_rowid
+		WHERE deleted_at IS NULL
+		AND (?2 = '' OR user_id = ?2)
+		AND (json_array_length(?3) = 0 OR calendar_id IN (SELECT value FROM json_each(?3)))
+		AND (?4 IS NULL OR start_time >= ?4)
+		AND (?5 IS NULL OR start_time < ?5)
+		ORDER BY m.rank DESC, start_time
+		LIMIT ?6
+	`
+	calendars, err := json.Marshal(append([]string{}, query.CalendarIDs...))
+	if err != nil {
+		return nil, err
+	}
+	from := sql.NullTime{Time: utc(query.From), Valid: !query.From.IsZero()}
+	to := sql.NullTime{Time: utc(query.To), Valid: !query.To.IsZero()}
+
+	rows, err := strg.db.QueryContext(ctx, sqlQuery, match, query.UserID, string(calendars), from, to, query.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rank float64
+		e, err := sqlrow.ScanEvent(rows, &rank)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, storage.SearchResult{Event: e, Rank: rank})
+	}
+	return results, rows.Err()
+}
+
+// matchExpression превращает текст запроса в выражение FTS5, где событие должно содержать все слова.
+// Каждое слово берётся в кавычки, чтобы символы вроде * и - не читались как операторы.
+func matchExpression(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,309 @@
+package sqlitestorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlrow"
+	_ "modernc.org/sqlite"
+)
+
+// Storage хранит события в файле SQLite. Реализует и app.Storage, и scheduler.NotificationStorage,
+// поэтому календарь и планировщик могут работать с одним файлом без отдельного сервера БД.
+type Storage struct {
+	db     *sql.DB
+	logger app.Logger
+
+	sweepMu          sync.Mutex
+	idempotencySweep time.Time // когда последний раз удалялись истёкшие ключи идемпотентности
+}
+
+// New открывает (или создаёт) базу в файле path и применяет к ней миграции
+func New(path string, logger app.Logger) (*Storage, error) {
+	dsn := dataSource(path)
+	if err := RunMigrations(dsn); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open db: %w", err)
+	}
+	// SQLite допускает одного писателя; одно соединение избавляет от SQLITE_BUSY внутри процесса
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot ping db: %w", err)
+	}
+	return &Storage{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// dataSource включает журнал WAL (календарь и планировщик читают и пишут один файл одновременно),
+// ожидание блокировки вместо немедленной ошибки и проверку внешних ключей.
+// _time_format=sqlite записывает время в формате, который понимают функции даты SQLite.
+func dataSource(path string) string {
+	return "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_time_format=sqlite"
+}
+
+func (strg *Storage) Close() error {
+	return strg.db.Close()
+}
+
+func (strg *Storage) AddEvent(ctx context.Context, event storage.Event) error {
+	return strg.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := audited(ctx, tx, storage.AuditCreate, event.ID, func() (int64, error) {
+			return addEvent(ctx, tx, event)
+		})
+		return err
+	})
+}
+
+func (strg *Storage) UpdateEvent(ctx context.Context, event storage.Event) error {
+	return strg.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := audited(ctx, tx, storage.AuditUpdate, event.ID, func() (int64, error) {
+			return updateEvent(ctx, tx, event)
+		})
+		return err
+	})
+}
+
+// inTx выполняет fn в транзакции: событие, его теги и запись журнала изменений пишутся
+// разными запросами, но меняются вместе
+func (strg *Storage) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := strg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (strg *Storage) DeleteEvent(ctx context.Context, id string, expectedVersion int64) error {
+	return strg.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := audited(ctx, tx, storage.AuditDelete, id, func() (int64, error) {
+			return deleteEvent(ctx, tx, id, expectedVersion)
+		})
+		return err
+	})
+}
+
+// querier - общее у *sql.DB и *sql.Tx, чтобы одни и те же запросы работали и в пакетной транзакции
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Функции ниже возвращают версию события после изменения
+
+func addEvent(ctx context.Context, q querier, event storage.Event) (int64, error) {
+	query := `
+		INSERT INTO events (id, title, description, start_time, duration, user_id, notify_before, calendar_id, category, color)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+		ON CONFLICT (id) DO NOTHING
+		RETURNING version
+	`
+	var version int64
+	err := q.QueryRowContext(ctx, query, event.ID, event.Title, event.Description, utc(event.StartTime),
+		sqlrow.Seconds(event.Duration), event.UserID, sqlrow.Seconds(event.NotifyBefore), event.CalendarID,
+		event.Category, event.Color).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, storage.ErrEventExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return version, replaceTags(ctx, q, event.ID, event.Tags)
+}
+
+func updateEvent(ctx context.Context, q querier, event storage.Event) (int64, error) {
+	// Версия увеличивается при каждом изменении; ?8 = 0 означает обновление без проверки версии,
+	// пустой ?9 оставляет событие в прежнем календаре. Разметка, как и остальные поля, заменяется целиком.
+	query := `
+		UPDATE events
+		SET title = ?2, description = ?3, start_time = ?4, duration = ?5, user_id = ?6, notify_before = ?7,
+			calendar_id = COALESCE(NULLIF(?9, ''), calendar_id), category = ?11, color = ?12,
+			version = version + 1, updated_at = ?10
+		WHERE id = ?1 AND deleted_at IS NULL AND (?8 = 0 OR version = ?8)
+		RETURNING version
+	`
+	var version int64
+	err := q.QueryRowContext(
+		ctx,
+		query,
+		event.ID,
+		event.Title,
+		event.Description,
+		utc(event.StartTime),
+		sqlrow.Seconds(event.Duration),
+		event.UserID,
+		sqlrow.Seconds(event.NotifyBefore),
+		event.Version,
+		event.CalendarID,
+		utc(time.Now()),
+		event.Category,
+		event.Color,
+	).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, missingOrConflict(ctx, q, event.ID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return version, replaceTags(ctx, q, event.ID, event.Tags)
+}
+
+func deleteEvent(ctx context.Context, q querier, id string, expectedVersion int64) (int64, error) {
+	// Событие только помечается удалённым, окончательно его удаляет планировщик
+	query := `
+		UPDATE events
+		SET deleted_at = ?3, version = version + 1, updated_at = ?3
+		WHERE id = ?1 AND deleted_at IS NULL AND (?2 = 0 OR version = ?2)
+		RETURNING version
+	`
+	var version int64
+	err := q.QueryRowContext(ctx, query, id, expectedVersion, utc(time.Now())).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, missingOrConflict(ctx, q, id)
+	}
+	return version, err
+}
+
+// missingOrConflict объясняет, почему условный UPDATE не затронул ни одной строки
+func missingOrConflict(ctx context.Context, q querier, id string) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM events WHERE id = ?1 AND deleted_at IS NULL)`
+	if err := q.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return storage.ErrVersionConflict
+	}
+	return storage.ErrEventNotFound
+}
+
+func (strg *Storage) ListDeletedEvents(ctx context.Context) ([]storage.Event, error) {
+	query := `SELECT ` + eventColumns + ` FROM events WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`
+	rows, err := strg.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return sqlrow.ScanEvents(rows)
+}
+
+func (strg *Storage) GetDeletedEvent(ctx context.Context, id string) (storage.Event, error) {
+	query := `SELECT ` + eventColumns + ` FROM events WHERE id = ?1 AND deleted_at IS NOT NULL`
+	e, err := sqlrow.ScanEvent(strg.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Event{}, storage.ErrEventNotFound
+	}
+	if err != nil {
+		return storage.Event{}, fmt.Errorf("failed to get deleted event from db by id %s: %w", id, err)
+	}
+	return e, nil
+}
+
+func (strg *Storage) RestoreEvent(ctx context.Context, id string) error {
+	return strg.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := audited(ctx, tx, storage.AuditRestore, id, func() (int64, error) {
+			return restoreEvent(ctx, tx, id)
+		})
+		return err
+	})
+}
+
+func restoreEvent(ctx context.Context, q querier, id string) (int64, error) {
+	query := `
+		UPDATE events
+		SET deleted_at = NULL, version = version + 1, updated_at = ?2
+		WHERE id = ?1 AND deleted_at IS NOT NULL
+		RETURNING version
+	`
+	var version int64
+	err := q.QueryRowContext(ctx, query, id, utc(time.Now())).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, storage.ErrEventNotFound
+	}
+	return version, err
+}
+
+func (strg *Storage) GetEventByID(ctx context.Context, id string) (storage.Event, error) {
+	query := `SELECT ` + eventColumns + ` FROM events WHERE id = ?1 AND deleted_at IS NULL`
+	e, err := sqlrow.ScanEvent(strg.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Event{}, storage.ErrEventNotFound
+	}
+	if err != nil {
+		return storage.Event{}, fmt.Errorf("failed to get event from db by id %s: %w", id, err)
+	}
+	return e, nil
+}
+
+func (strg *Storage) ListEventsForDay(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return strg.listEvents(ctx, start, start.Add(24*time.Hour), query)
+}
+
+func (strg *Storage) ListEventsForWeek(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return strg.listEvents(ctx, start, start.AddDate(0, 0, 7), query)
+}
+
+func (strg *Storage) ListEventsForMonth(ctx context.Context, date time.Time, query storage.ListQuery) ([]storage.Event, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return strg.listEvents(ctx, start, start.AddDate(0, 1, 0), query)
+}
+
+func (strg *Storage) listEvents(ctx context.Context, start time.Time, end time.Time, query storage.ListQuery) ([]storage.Event, error) {
+	// Пустой список календарей не ограничивает выборку. При отборе по тегам событие соединяется
+	// с event_tags и остаётся, если совпали все теги запроса.
+	calendars, err := json.Marshal(append([]string{}, query.CalendarIDs...))
+	if err != nil {
+		return nil, err
+	}
+	tagJoin := ""
+	args := []any{utc(start), utc(end), string(calendars)}
+	if len(query.Tags) > 0 {
+		tags, err := json.Marshal(query.Tags)
+		if err != nil {
+			return nil, err
+		}
+		tagJoin = `
+		JOIN (
+			SELECT event_id FROM event_tags
+			WHERE tag IN (SELECT value FROM json_each(?4))
+			GROUP BY event_id
+			HAVING COUNT(*) = json_array_length(?4)
+		) tagged ON tagged.event_id = events.id`
+		args = append(args, string(tags))
+	}
+	sqlQuery := `
+		SELECT ` + eventColumns + ` FROM events` + tagJoin + `
+		WHERE start_time >= ?1 AND start_time < ?2 AND deleted_at IS NULL
+		AND (json_array_length(?3) = 0 OR calendar_id IN (SELECT value FROM json_each(?3)))
+	`
+	rows, err := strg.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	return sqlrow.ScanEvents(rows)
+}
+
+// utc приводит время к UTC: SQLite сравнивает время как строки, поэтому зона у всех значений должна совпадать
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
@@ -0,0 +1,522 @@
+package sqlitestorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/requestctx"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+	s, err := New(filepath.Join(t.TempDir(), "calendar.db"), logger.New("debug"))
+	if err != nil {
+		t.Fatalf("failed to open storage: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	if err := s.CreateCalendar(context.Background(), storage.Calendar{
+		ID: storage.PersonalCalendarID("alice"), Name: "Personal", Kind: storage.CalendarPersonal, OwnerID: "alice",
+	}); err != nil {
+		t.Fatalf("failed to create calendar: %v", err)
+	}
+	return s
+}
+
+func newEvent(id, title string, start time.Time) storage.Event {
+	return storage.Event{
+		ID:         id,
+		Title:      title,
+		StartTime:  start,
+		Duration:   calendar_types.CalendarDuration(time.Hour),
+		UserID:     "alice",
+		CalendarID: storage.PersonalCalendarID("alice"),
+	}
+}
+
+func TestEventLifecycle(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	// Время в другой зоне сохраняется в UTC и попадает в выборку за день
+	moscow := time.FixedZone("MSK", 3*60*60)
+	event := newEvent("event-1", "Meeting", time.Date(2024, 5, 10, 13, 0, 0, 0, moscow))
+	event.NotifyBefore = calendar_types.CalendarDuration(15 * time.Minute)
+	if err := s.AddEvent(ctx, event); err != nil {
+		t.Fatalf("failed to add event: %v", err)
+	}
+	if err := s.AddEvent(ctx, event); !errors.Is(err, storage.ErrEventExists) {
+		t.Errorf("expected ErrEventExists, got %v", err)
+	}
+
+	got, err := s.GetEventByID(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("failed to get event: %v", err)
+	}
+	if !got.StartTime.Equal(event.StartTime) || got.Duration != event.Duration || got.NotifyBefore != event.NotifyBefore || got.Version != 1 {
+		t.Errorf("unexpected event %+v", got)
+	}
+
+	date := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	day, _ := s.ListEventsForDay(ctx, date, storage.ListQuery{})
+	if len(day) != 1 {
+		t.Errorf("expected 1 event for the day, got %d", len(day))
+	}
+	day, _ = s.ListEventsForDay(ctx, date, storage.ListQuery{CalendarIDs: []string{"team:other"}})
+	if len(day) != 0 {
+		t.Errorf("expected no events outside listed calendars, got %d", len(day))
+	}
+	day, _ = s.ListEventsForDay(ctx, date, storage.ListQuery{CalendarIDs: []string{"team:other", storage.PersonalCalendarID("alice")}})
+	if len(day) != 1 {
+		t.Errorf("expected 1 event in listed calendars, got %d", len(day))
+	}
+
+	event.Title = "Renamed"
+	event.Version = 5
+	if err := s.UpdateEvent(ctx, event); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+	event.Version = 1
+	if err := s.UpdateEvent(ctx, event); err != nil {
+		t.Fatalf("failed to update event: %v", err)
+	}
+
+	if err := s.DeleteEvent(ctx, event.ID, 2); err != nil {
+		t.Fatalf("failed to delete event: %v", err)
+	}
+	if _, err := s.GetEventByID(ctx, event.ID); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Errorf("expected ErrEventNotFound after delete, got %v", err)
+	}
+	deleted, _ := s.ListDeletedEvents(ctx)
+	if len(deleted) != 1 || deleted[0].DeletedAt == nil {
+		t.Errorf("expected event in trash, got %+v", deleted)
+	}
+	if got, err := s.GetDeletedEvent(ctx, event.ID); err != nil || got.DeletedAt == nil {
+		t.Errorf("expected deleted event by id, got %+v (%v)", got, err)
+	}
+	if err := s.RestoreEvent(ctx, event.ID); err != nil {
+		t.Fatalf("failed to restore event: %v", err)
+	}
+	got, _ = s.GetEventByID(ctx, event.ID)
+	if got.Title != "Renamed" || got.Version != 4 || got.DeletedAt != nil {
+		t.Errorf("unexpected restored event %+v", got)
+	}
+	if _, err := s.GetDeletedEvent(ctx, event.ID); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Errorf("expected active event not to be found in trash, got %v", err)
+	}
+}
+
+func TestAuditTrail(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := requestctx.WithActor(requestctx.WithRequestID(context.Background(), "req-1"), "alice")
+
+	event := newEvent("audited", "Original", time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC))
+	event.Tags = []string{"sprint"}
+	if err := s.AddEvent(ctx, event); err != nil {
+		t.Fatalf("failed to add event: %v", err)
+	}
+	event.Title = "Updated"
+	if err := s.UpdateEvent(ctx, event); err != nil {
+		t.Fatalf("failed to update event: %v", err)
+	}
+	// Отклонённое изменение в журнал не попадает
+	if err := s.DeleteEvent(ctx, event.ID, 7); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Fatalf("expected version conflict, got %v", err)
+	}
+	if err := s.DeleteEvent(ctx, event.ID, 2); err != nil {
+		t.Fatalf("failed to delete event: %v", err)
+	}
+	if err := s.RestoreEvent(ctx, event.ID); err != nil {
+		t.Fatalf("failed to restore event: %v", err)
+	}
+
+	records, err := s.ListAuditRecords(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("failed to list audit records: %v", err)
+	}
+	want := []storage.AuditAction{storage.AuditCreate, storage.AuditUpdate, storage.AuditDelete, storage.AuditRestore}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d audit records, got %+v", len(want), records)
+	}
+	for i, record := range records {
+		if record.Action != want[i] || record.Actor != "alice" || record.RequestID != "req-1" {
+			t.Errorf("unexpected audit record %d: %+v", i, record)
+		}
+	}
+	update := records[1]
+	if update.Before == nil || update.Before.Title != "Original" || update.Before.Version != 1 ||
+		update.After == nil || update.After.Title != "Updated" || update.After.Version != 2 ||
+		strings.Join(update.After.Tags, ",") != "sprint" {
+		t.Errorf("unexpected update images: before %+v, after %+v", update.Before, update.After)
+	}
+	if records[0].Before != nil || records[2].After != nil || records[3].Before != nil || records[3].After == nil {
+		t.Errorf("unexpected create, delete or restore images: %+v", records)
+	}
+}
+
+func TestApplyBatch(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	start := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+	_ = s.AddEvent(ctx, newEvent("existing", "Existing", start))
+
+	ops := []storage.BatchOperation{
+		{Type: storage.BatchCreate, Event: newEvent("new", "New", start)},
+		{Type: storage.BatchUpdate, Event: newEvent("missing", "Missing", start)},
+	}
+
+	results, err := s.ApplyBatch(ctx, ops, true)
+	if err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	if !errors.Is(results[0].Err, storage.ErrBatchAborted) || !errors.Is(results[1].Err, storage.ErrEventNotFound) {
+		t.Errorf("unexpected atomic results %+v", results)
+	}
+	if _, err := s.GetEventByID(ctx, "new"); !errors.Is(err, storage.ErrEventNotFound) {
+		t.Errorf("expected atomic batch to be rolled back, got %v", err)
+	}
+	if records, _ := s.ListAuditRecords(ctx, "new"); len(records) != 0 {
+		t.Errorf("expected audit of atomic batch to be rolled back, got %+v", records)
+	}
+
+	results, _ = s.ApplyBatch(ctx, ops, false)
+	if results[0].Err != nil || results[0].Version != 1 {
+		t.Errorf("unexpected best-effort result %+v", results[0])
+	}
+	if _, err := s.GetEventByID(ctx, "new"); err != nil {
+		t.Errorf("expected event from best-effort batch, got %v", err)
+	}
+	if records, _ := s.ListAuditRecords(ctx, "new"); len(records) != 1 || records[0].Action != storage.AuditCreate {
+		t.Errorf("expected create from batch in audit, got %+v", records)
+	}
+	// Операции, откаченные до точки сохранения, в журнал не попадают
+	if records, _ := s.ListAuditRecords(ctx, "missing"); len(records) != 0 {
+		t.Errorf("expected no audit for failed operation, got %+v", records)
+	}
+}
+
+func TestSearchEvents(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	day := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+
+	planning := newEvent("planning", "Sprint planning", day)
+	planning.Description = "Team meeting"
+	retro := newEvent("retro", "Retro", day.Add(time.Hour))
+	retro.Description = "Sprint retrospective meeting"
+	for _, e := range []storage.Event{planning, retro, newEvent("lunch", "Обед с командой", day.AddDate(0, 0, 1))} {
+		_ = s.AddEvent(ctx, e)
+	}
+
+	ids := func(query storage.SearchQuery) []string {
+		query.Limit = 10
+		results, err := s.SearchEvents(ctx, query)
+		if err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+		found := []string{}
+		for _, result := range results {
+			found = append(found, result.Event.ID)
+		}
+		return found
+	}
+
+	// Совпадение в названии весит больше, чем в описании
+	if got := ids(storage.SearchQuery{Text: "sprint"}); len(got) != 2 || got[0] != "planning" {
+		t.Errorf("expected [planning retro], got %v", got)
+	}
+	if got := ids(storage.SearchQuery{Text: "sprint team"}); len(got) != 1 || got[0] != "planning" {
+		t.Errorf("expected [planning], got %v", got)
+	}
+	if got := ids(storage.SearchQuery{Text: "ОБЕД"}); len(got) != 1 || got[0] != "lunch" {
+		t.Errorf("expected [lunch], got %v", got)
+	}
+	if got := ids(storage.SearchQuery{Text: "meeting", From: day.Add(30 * time.Minute)}); len(got) != 1 || got[0] != "retro" {
+		t.Errorf("expected [retro] in date range, got %v", got)
+	}
+	if got := ids(storage.SearchQuery{Text: "sprint", CalendarIDs: []string{"team:other"}}); len(got) != 0 {
+		t.Errorf("expected no results outside listed calendars, got %v", got)
+	}
+	if got := ids(storage.SearchQuery{Text: "sprint", CalendarIDs: []string{"team:other", storage.PersonalCalendarID("alice")}}); len(got) != 2 {
+		t.Errorf("expected [planning retro] in listed calendars, got %v", got)
+	}
+	// Операторы FTS5 в запросе считаются обычным текстом
+	if got := ids(storage.SearchQuery{Text: `"sprint* OR`}); len(got) != 0 {
+		t.Errorf("expected no results for operators, got %v", got)
+	}
+
+	_ = s.UpdateEvent(ctx, storage.Event{ID: "retro", Title: "Demo", StartTime: day, UserID: "alice"})
+	_ = s.DeleteEvent(ctx, "planning", 0)
+	if got := ids(storage.SearchQuery{Text: "sprint"}); len(got) != 0 {
+		t.Errorf("expected no results after update and delete, got %v", got)
+	}
+}
+
+func TestCalendarAccess(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	team := storage.Calendar{ID: "team", Name: "Team", Kind: storage.CalendarTeam, OwnerID: "alice"}
+	if err := s.CreateCalendar(ctx, team); err != nil {
+		t.Fatalf("failed to create calendar: %v", err)
+	}
+	if err := s.CreateCalendar(ctx, team); !errors.Is(err, storage.ErrCalendarExists) {
+		t.Errorf("expected ErrCalendarExists, got %v", err)
+	}
+	_ = s.SetACLEntry(ctx, storage.ACLEntry{CalendarID: "team", GranteeType: storage.GranteeUser, GranteeID: "bob", Role: storage.RoleFreeBusy})
+	_ = s.SetACLEntry(ctx, storage.ACLEntry{CalendarID: "team", GranteeType: storage.GranteeGroup, GranteeID: "devs", Role: storage.RoleWrite})
+
+	calendars, err := s.ListAccessibleCalendars(ctx, storage.Principal{UserID: "bob", Groups: []string{"devs"}})
+	if err != nil {
+		t.Fatalf("failed to list calendars: %v", err)
+	}
+	if len(calendars) != 1 || calendars[0].Role != storage.RoleWrite {
+		t.Errorf("expected write access to team, got %+v", calendars)
+	}
+
+	if err := s.DeleteACLEntry(ctx, "team", storage.GranteeUser, "bob"); err != nil {
+		t.Errorf("failed to revoke access: %v", err)
+	}
+	if err := s.DeleteACLEntry(ctx, "team", storage.GranteeUser, "bob"); !errors.Is(err, storage.ErrGrantNotFound) {
+		t.Errorf("expected ErrGrantNotFound, got %v", err)
+	}
+
+	start := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+	event := newEvent("standup", "Standup", start)
+	event.CalendarID = "team"
+	_ = s.AddEvent(ctx, event)
+	// Событие пересекается с интервалом, хотя началось раньше него
+	events, _ := s.ListCalendarEvents(ctx, "team", start.Add(30*time.Minute), time.Time{})
+	if len(events) != 1 {
+		t.Errorf("expected overlapping event, got %v", events)
+	}
+	events, _ = s.ListCalendarEvents(ctx, "team", start.Add(time.Hour), time.Time{})
+	if len(events) != 0 {
+		t.Errorf("expected no events after the end, got %v", events)
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	now := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+
+	soon := newEvent("soon", "Soon", now.Add(15*time.Minute))
+	soon.NotifyBefore = calendar_types.CalendarDuration(15 * time.Minute)
+	later := newEvent("later", "Later", now.Add(time.Hour))
+	later.NotifyBefore = calendar_types.CalendarDuration(15 * time.Minute)
+	past := newEvent("past", "Past", now.AddDate(-1, 0, 0))
+	for _, e := range []storage.Event{soon, later, past} {
+		_ = s.AddEvent(ctx, e)
+	}
+
+	events, err := s.GetEventsForNotification(ctx, now)
+	if err != nil {
+		t.Fatalf("failed to get events for notification: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "soon" {
+		t.Errorf("expected [soon], got %v", events)
+	}
+	_ = s.MarkEventNotified(ctx, "soon")
+	if events, _ := s.GetEventsForNotification(ctx, now); len(events) != 0 {
+		t.Errorf("expected no events after notification, got %v", events)
+	}
+
+	_ = s.DeleteEvent(ctx, "later", 0)
+	purge, err := s.ListEventsToPurge(ctx, now.AddDate(0, -1, 0), time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("failed to list events to purge: %v", err)
+	}
+	if len(purge) != 2 {
+		t.Errorf("expected past and deleted events to purge, got %v", purge)
+	}
+	if err := s.PurgeEvents(ctx, []string{"past", "later"}); err != nil {
+		t.Fatalf("failed to purge events: %v", err)
+	}
+	if deleted, _ := s.ListDeletedEvents(ctx); len(deleted) != 0 {
+		t.Errorf("expected trash to be empty, got %v", deleted)
+	}
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	record := storage.IdempotencyRecord{
+		UserID: "alice", Key: "retry-1", Fingerprint: "f1", EventID: "e1", ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	if _, reserved, err := s.ReserveIdempotencyKey(ctx, record); err != nil || !reserved {
+		t.Fatalf("expected key to be reserved, got %v, %v", reserved, err)
+	}
+	retry := record
+	retry.EventID = "e2"
+	existing, reserved, err := s.ReserveIdempotencyKey(ctx, retry)
+	if err != nil || reserved || existing.EventID != "e1" || existing.Fingerprint != "f1" || existing.Response != nil {
+		t.Fatalf("expected pending record for e1, got %+v, %v, %v", existing, reserved, err)
+	}
+
+	if err := s.CompleteIdempotencyKey(ctx, "alice", "retry-1", newEvent("e1", "Standup", time.Now())); err != nil {
+		t.Fatalf("failed to complete key: %v", err)
+	}
+	existing, _, err = s.ReserveIdempotencyKey(ctx, retry)
+	if err != nil || existing.Response == nil || existing.Response.Title != "Standup" {
+		t.Errorf("expected saved response, got %+v, %v", existing.Response, err)
+	}
+
+	if err := s.ReleaseIdempotencyKey(ctx, "alice", "retry-1"); err != nil {
+		t.Fatalf("failed to release key: %v", err)
+	}
+	if _, reserved, _ := s.ReserveIdempotencyKey(ctx, retry); !reserved {
+		t.Error("expected released key to be reserved again")
+	}
+
+	expired := record
+	expired.Key = "retry-2"
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	if _, reserved, _ := s.ReserveIdempotencyKey(ctx, expired); !reserved {
+		t.Fatal("expected key to be reserved")
+	}
+	if _, reserved, err := s.ReserveIdempotencyKey(ctx, expired); err != nil || !reserved {
+		t.Errorf("expected expired key to be reserved again, got %v, %v", reserved, err)
+	}
+}
+
+func TestEventLabels(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	day := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+
+	planning := newEvent("planning", "Planning", day)
+	planning.Labels = storage.Labels{Category: "Project X", Color: "#1e90ff", Tags: []string{"project-x", "weekly"}}
+	review := newEvent("review", "Review", day.Add(2*time.Hour))
+	review.Tags = []string{"project-x"}
+	lunch := newEvent("lunch", "Lunch", day.AddDate(0, 1, 0))
+	lunch.Tags = []string{"personal"}
+	for _, e := range []storage.Event{planning, review, lunch} {
+		if err := s.AddEvent(ctx, e); err != nil {
+			t.Fatalf("failed to add event %s: %v", e.ID, err)
+		}
+	}
+
+	got, err := s.GetEventByID(ctx, "planning")
+	if err != nil {
+		t.Fatalf("failed to get event: %v", err)
+	}
+	if got.Category != "Project X" || got.Color != "#1e90ff" || strings.Join(got.Tags, ",") != "project-x,weekly" {
+		t.Errorf("unexpected labels: %+v", got.Labels)
+	}
+
+	// В списках событие должно иметь все теги запроса
+	tagged, err := s.ListEventsForDay(ctx, day, storage.ListQuery{Tags: []string{"project-x"}})
+	if err != nil {
+		t.Fatalf("failed to list events by tags: %v", err)
+	}
+	if len(tagged) != 2 {
+		t.Errorf("expected planning and review, got %+v", tagged)
+	}
+	tagged, _ = s.ListEventsForMonth(ctx, day, storage.ListQuery{Tags: []string{"project-x", "weekly"}})
+	if len(tagged) != 1 || tagged[0].ID != "planning" || strings.Join(tagged[0].Tags, ",") != "project-x,weekly" {
+		t.Errorf("expected only planning with both tags, got %+v", tagged)
+	}
+
+	stats, err := s.ListTagStats(ctx, storage.TagStatsQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to count tags: %v", err)
+	}
+	want := []storage.TagStat{{Tag: "project-x", Count: 2}, {Tag: "personal", Count: 1}, {Tag: "weekly", Count: 1}}
+	if fmt.Sprint(stats) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, stats)
+	}
+
+	// Обновление заменяет теги целиком, удалённые события в статистику не попадают
+	planning.Tags = []string{"weekly"}
+	if err := s.UpdateEvent(ctx, planning); err != nil {
+		t.Fatalf("failed to update event: %v", err)
+	}
+	_ = s.DeleteEvent(ctx, "review", 0)
+	stats, _ = s.ListTagStats(ctx, storage.TagStatsQuery{
+		CalendarIDs: []string{storage.PersonalCalendarID("alice")},
+		From:        day,
+		To:          day.AddDate(0, 0, 1),
+		Limit:       10,
+	})
+	want = []storage.TagStat{{Tag: "weekly", Count: 1}}
+	if fmt.Sprint(stats) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, stats)
+	}
+	if stats, _ := s.ListTagStats(ctx, storage.TagStatsQuery{CalendarIDs: []string{"team:other"}, Limit: 10}); len(stats) != 0 {
+		t.Errorf("expected no tags in another calendar, got %v", stats)
+	}
+
+	// Окончательное удаление события удаляет и его теги
+	if err := s.PurgeEvents(ctx, []string{"review", "planning"}); err != nil {
+		t.Fatalf("failed to purge events: %v", err)
+	}
+	var left int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM event_tags`).Scan(&left); err != nil || left != 1 {
+		t.Errorf("expected only the tag of lunch to remain, got %d (%v)", left, err)
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	start := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	for _, e := range []storage.Event{newEvent("1", "Planning", start), newEvent("2", "Trashed", start)} {
+		if err := s.AddEvent(ctx, e); err != nil {
+			t.Fatalf("failed to add event: %v", err)
+		}
+	}
+	_ = s.DeleteEvent(ctx, "2", 0)
+
+	agenda := storage.Attachment{
+		ID: "b", EventID: "1", Kind: storage.AttachmentFile, Name: "agenda.pdf", ContentType: "application/pdf",
+		Size: 3, CreatedBy: "alice", CreatedAt: start,
+	}
+	call := storage.Attachment{
+		ID: "a", EventID: "1", Kind: storage.AttachmentLink, Name: "Call", URL: "https://meet.example.com/x",
+		CreatedAt: start.Add(time.Minute),
+	}
+	for _, a := range []storage.Attachment{call, agenda} {
+		if err := s.AddAttachment(ctx, a); err != nil {
+			t.Fatalf("failed to add attachment: %v", err)
+		}
+	}
+	for _, eventID := range []string{"2", "missing"} {
+		err := s.AddAttachment(ctx, storage.Attachment{ID: "c", EventID: eventID, Kind: storage.AttachmentLink, CreatedAt: start})
+		if !errors.Is(err, storage.ErrEventNotFound) {
+			t.Errorf("expected ErrEventNotFound for event %s, got %v", eventID, err)
+		}
+	}
+
+	attachments, err := s.ListAttachments(ctx, "1")
+	if err != nil || fmt.Sprint(attachments) != fmt.Sprint([]storage.Attachment{agenda, call}) {
+		t.Errorf("expected attachments in order of creation, got %v, %v", attachments, err)
+	}
+	if got, err := s.GetAttachment(ctx, "b"); err != nil || fmt.Sprint(got) != fmt.Sprint(agenda) {
+		t.Errorf("expected %+v, got %+v, %v", agenda, got, err)
+	}
+
+	if err := s.DeleteAttachment(ctx, "a"); err != nil {
+		t.Fatalf("failed to delete attachment: %v", err)
+	}
+	if err := s.DeleteAttachment(ctx, "a"); !errors.Is(err, storage.ErrAttachmentNotFound) {
+		t.Errorf("expected ErrAttachmentNotFound, got %v", err)
+	}
+
+	// Записи о вложениях удаляются каскадом вместе с событием
+	if err := s.PurgeEvents(ctx, []string{"1"}); err != nil {
+		t.Fatalf("failed to purge event: %v", err)
+	}
+	if _, err := s.GetAttachment(ctx, "b"); !errors.Is(err, storage.ErrAttachmentNotFound) {
+		t.Errorf("expected attachment to be purged with event, got %v", err)
+	}
+}
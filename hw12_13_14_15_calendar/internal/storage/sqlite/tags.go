@@ -0,0 +1,75 @@
+package sqlitestorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/sqlrow"
+)
+
+// eventColumns - колонки событий и теги через запятую в том порядке, который ожидает sqlrow.ScanEvent.
+// group_concat не умеет ORDER BY, поэтому теги сортируются во вложенном подзапросе.
+const eventColumns = sqlrow.EventColumns + `,
+	COALESCE((SELECT group_concat(tag, ',') FROM (SELECT tag FROM event_tags WHERE event_tags.event_id = events.id ORDER BY tag)), '')`
+
+// replaceTags заменяет теги события; теги передаются JSON-массивом и разворачиваются через json_each
+func replaceTags(ctx context.Context, q querier, eventID string, tags []string) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM event_tags WHERE event_id = ?1`, eventID); err != nil {
+		return fmt.Errorf("failed to clear tags of event %s: %w", eventID, err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO event_tags (event_id, tag) SELECT ?1, value FROM json_each(?2)`
+	if _, err := q.ExecContext(ctx, query, eventID, string(data)); err != nil {
+		return fmt.Errorf("failed to save tags of event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+func (strg *Storage) ListTagStats(ctx context.Context, query storage.TagStatsQuery) ([]storage.TagStat, error) {
+	// Пустые границы передаются как NULL, пустой список календарей не ограничивает выборку
+	sqlQuery := `
+		SELECT t.tag, COUNT(*) AS uses
+		FROM event_tags t
+		JOIN events e ON e.id = t.event_id
+		WHERE e.deleted_at IS NULL
+		AND (json_array_length(?1) = 0 OR e.calendar_id IN (SELECT value FROM json_each(?1)))
+		AND (?2 IS NULL OR e.start_time >= ?2)
+		AND (?3 IS NULL OR e.start_time < ?3)
+		GROUP BY t.tag
+		ORDER BY uses DESC, t.tag
+		LIMIT ?4
+	`
+	calendars, err := json.Marshal(append([]string{}, query.CalendarIDs...))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := strg.db.QueryContext(ctx, sqlQuery,
+		string(calendars),
+		sql.NullTime{Time: utc(query.From), Valid: !query.From.IsZero()},
+		sql.NullTime{Time: utc(query.To), Valid: !query.To.IsZero()},
+		query.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tags: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []storage.TagStat{}
+	for rows.Next() {
+		var stat storage.TagStat
+		if err := rows.Scan(&stat.Tag, &stat.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag stats: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
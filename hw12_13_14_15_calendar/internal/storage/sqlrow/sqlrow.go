@@ -0,0 +1,196 @@
+// Package sqlrow - общее для SQL-хранилищ (Postgres и SQLite) преобразование строк таблиц
+// в структуры storage и обратно. Сами запросы остаются в пакетах хранилищ: диалекты различаются.
+package sqlrow
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage"
+)
+
+// EventColumns - колонки событий в порядке, который ожидает ScanEvent. За ними ScanEvent ожидает
+// теги события одной строкой через запятую: подзапрос к event_tags у каждого диалекта свой.
+const EventColumns = `id, title, description, start_time, duration, user_id, notify_before, version, deleted_at, calendar_id, category, color`
+
+// CalendarColumns - колонки календарей в порядке, который ожидает ScanCalendar
+const CalendarColumns = `id, name, kind, owner_id`
+
+// AuditColumns - колонки журнала изменений в порядке, который ожидает ScanAuditRecord
+const AuditColumns = `id, event_id, action, actor, request_id, before, after, created_at`
+
+// IdempotencyColumns - колонки ключей идемпотентности в порядке, который ожидает ScanIdempotencyRecord
+const IdempotencyColumns = `user_id, key, fingerprint, event_id, response, expires_at`
+
+// AttachmentColumns - колонки вложений в порядке, который ожидает ScanAttachment
+const AttachmentColumns = `id, event_id, kind, name, content_type, size, url, created_by, created_at`
+
+// Scanner - общее у *sql.Row и *sql.Rows
+type Scanner interface {
+	Scan(dest ...any) error
+}
+
+// ScanEvent читает колонки EventColumns и теги; extra - куда прочитать колонки, выбранные после них
+func ScanEvent(row Scanner, extra ...any) (storage.Event, error) {
+	var (
+		e                      storage.Event
+		duration, notifyBefore int64
+		tags                   string
+	)
+	dest := append([]any{&e.ID, &e.Title, &e.Description, &e.StartTime, &duration, &e.UserID, &notifyBefore, &e.Version, &e.DeletedAt, &e.CalendarID, &e.Category, &e.Color, &tags}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return e, err
+	}
+	e.Duration = FromSeconds(duration)
+	e.NotifyBefore = FromSeconds(notifyBefore)
+	e.Tags = SplitTags(tags)
+	return e, nil
+}
+
+// ScanEvents читает все строки с колонками EventColumns и закрывает rows
+func ScanEvents(rows *sql.Rows) ([]storage.Event, error) {
+	defer rows.Close()
+
+	var events []storage.Event
+	for rows.Next() {
+		e, err := ScanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// ScanCalendar читает колонки CalendarColumns; extra - куда прочитать колонки, выбранные после них
+func ScanCalendar(row Scanner, extra ...any) (storage.Calendar, error) {
+	var (
+		c    storage.Calendar
+		kind string
+	)
+	if err := row.Scan(append([]any{&c.ID, &c.Name, &kind, &c.OwnerID}, extra...)...); err != nil {
+		return c, err
+	}
+	c.Kind = storage.CalendarKind(kind)
+	return c, nil
+}
+
+// ScanACLEntry читает колонки calendar_id, grantee_type, grantee_id, role
+func ScanACLEntry(row Scanner) (storage.ACLEntry, error) {
+	var (
+		entry             storage.ACLEntry
+		granteeType, role string
+	)
+	if err := row.Scan(&entry.CalendarID, &granteeType, &entry.GranteeID, &role); err != nil {
+		return entry, err
+	}
+	entry.GranteeType = storage.GranteeType(granteeType)
+	entry.Role = storage.Role(role)
+	return entry, nil
+}
+
+// ScanAuditRecord читает колонки AuditColumns; состояния события хранятся в JSON
+func ScanAuditRecord(row Scanner) (storage.AuditRecord, error) {
+	var (
+		record        storage.AuditRecord
+		action        string
+		before, after []byte
+	)
+	err := row.Scan(&record.ID, &record.EventID, &action, &record.Actor, &record.RequestID, &before, &after, &record.CreatedAt)
+	if err != nil {
+		return record, err
+	}
+	record.Action = storage.AuditAction(action)
+	if record.Before, err = UnmarshalSnapshot(before); err != nil {
+		return record, err
+	}
+	record.After, err = UnmarshalSnapshot(after)
+	return record, err
+}
+
+// ScanIdempotencyRecord читает колонки IdempotencyColumns; ответ хранится в JSON, как состояния в журнале
+func ScanIdempotencyRecord(row Scanner) (storage.IdempotencyRecord, error) {
+	var (
+		record   storage.IdempotencyRecord
+		response []byte
+	)
+	err := row.Scan(&record.UserID, &record.Key, &record.Fingerprint, &record.EventID, &response, &record.ExpiresAt)
+	if err != nil {
+		return record, err
+	}
+	record.Response, err = UnmarshalSnapshot(response)
+	return record, err
+}
+
+// ScanAttachment читает колонки AttachmentColumns
+func ScanAttachment(row Scanner) (storage.Attachment, error) {
+	var (
+		a    storage.Attachment
+		kind string
+	)
+	err := row.Scan(&a.ID, &a.EventID, &kind, &a.Name, &a.ContentType, &a.Size, &a.URL, &a.CreatedBy, &a.CreatedAt)
+	if err != nil {
+		return a, err
+	}
+	a.Kind = storage.AttachmentKind(kind)
+	return a, nil
+}
+
+// ScanAttachments читает все строки с колонками AttachmentColumns и закрывает rows
+func ScanAttachments(rows *sql.Rows) ([]storage.Attachment, error) {
+	defer rows.Close()
+
+	attachments := []storage.Attachment{}
+	for rows.Next() {
+		a, err := ScanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+// SplitTags разбирает теги из строки через запятую; пустая строка - событие без тегов
+func SplitTags(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+	return strings.Split(tags, ",")
+}
+
+// Seconds - длительность для целочисленной колонки (duration, notify_before хранятся в секундах)
+func Seconds(d calendar_types.CalendarDuration) int64 {
+	return int64(time.Duration(d) / time.Second)
+}
+
+func FromSeconds(seconds int64) calendar_types.CalendarDuration {
+	return calendar_types.CalendarDuration(time.Duration(seconds) * time.Second)
+}
+
+// MarshalSnapshot превращает состояние события в JSON для колонки журнала; nil даёт NULL
+func MarshalSnapshot(event *storage.Event) (any, error) {
+	if event == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event snapshot: %w", err)
+	}
+	return string(data), nil
+}
+
+func UnmarshalSnapshot(data []byte) (*storage.Event, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var event storage.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event snapshot: %w", err)
+	}
+	return &event, nil
+}
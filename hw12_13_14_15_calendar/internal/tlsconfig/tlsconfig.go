@@ -0,0 +1,188 @@
+// Package tlsconfig собирает *tls.Config для серверов и клиентов из файлов сертификатов.
+//
+// Сертификаты и CA перечитываются без перезапуска: при рукопожатии проверяется время изменения
+// файлов (не чаще раза в CheckInterval), и если файлы обновились, используется новая версия.
+// Если новые файлы не читаются (например, записаны наполовину), остаётся прежняя версия.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+)
+
+// CheckInterval - как часто проверяется, не обновились ли файлы сертификатов
+var CheckInterval = 10 * time.Second
+
+// ServerFiles - файлы TLS сервера. ClientCAFile включает проверку клиентских сертификатов (mTLS):
+// без сертификата, подписанного этим CA, клиент не подключится.
+type ServerFiles struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// ClientFiles - файлы TLS клиента. Без CAFile сервер проверяется по системным CA,
+// CertFile и KeyFile нужны, если сервер требует клиентский сертификат.
+type ClientFiles struct {
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string // Имя в сертификате сервера, если оно отличается от адреса подключения
+}
+
+// NewServerConfig загружает сертификат сервера и, если задан, CA клиентских сертификатов
+func NewServerConfig(files ServerFiles, logger app.Logger) (*tls.Config, error) {
+	if files.CertFile == "" || files.KeyFile == "" {
+		return nil, errors.New("tls: cert and key files are required")
+	}
+	cert, err := newReloadable([]string{files.CertFile, files.KeyFile}, loadKeyPair(files.CertFile, files.KeyFile), logger)
+	if err != nil {
+		return nil, err
+	}
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return cert.get(), nil
+		},
+	}
+	if files.ClientCAFile == "" {
+		return base, nil
+	}
+
+	clientCAs, err := newReloadable([]string{files.ClientCAFile}, loadCertPool(files.ClientCAFile), logger)
+	if err != nil {
+		return nil, err
+	}
+	base.ClientAuth = tls.RequireAndVerifyClientCert
+	base.ClientCAs = clientCAs.get()
+
+	// Пул CA нельзя подменить в общем конфиге, поэтому каждое подключение получает копию с текущим пулом
+	cfg := base.Clone()
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		perConn := base.Clone()
+		perConn.ClientCAs = clientCAs.get()
+		return perConn, nil
+	}
+	return cfg, nil
+}
+
+// NewClientConfig загружает CA сервера и, если задан, клиентский сертификат
+func NewClientConfig(files ClientFiles, logger app.Logger) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: files.ServerName}
+	if files.CAFile != "" {
+		pool, err := loadCertPool(files.CAFile)()
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if files.CertFile != "" || files.KeyFile != "" {
+		cert, err := newReloadable([]string{files.CertFile, files.KeyFile}, loadKeyPair(files.CertFile, files.KeyFile), logger)
+		if err != nil {
+			return nil, err
+		}
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return cert.get(), nil
+		}
+	}
+	return cfg, nil
+}
+
+func loadKeyPair(certFile, keyFile string) func() (*tls.Certificate, error) {
+	return func() (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: load key pair %s: %w", certFile, err)
+		}
+		return &cert, nil
+	}
+}
+
+func loadCertPool(caFile string) func() (*x509.CertPool, error) {
+	return func() (*x509.CertPool, error) {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: read CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("tls: no certificates in %s", caFile)
+		}
+		return pool, nil
+	}
+}
+
+// reloadable хранит загруженное из файлов значение и перезагружает его, когда файлы меняются
+type reloadable[T any] struct {
+	files  []string
+	load   func() (T, error)
+	logger app.Logger
+
+	mu      sync.Mutex
+	value   T
+	modTime time.Time
+	checked time.Time
+}
+
+func newReloadable[T any](files []string, load func() (T, error), logger app.Logger) (*reloadable[T], error) {
+	modTime, err := latestModTime(files)
+	if err != nil {
+		return nil, err
+	}
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return &reloadable[T]{files: files, load: load, logger: logger, value: value, modTime: modTime, checked: time.Now()}, nil
+}
+
+func (r *reloadable[T]) get() T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.checked) < CheckInterval {
+		return r.value
+	}
+	r.checked = now
+
+	modTime, err := latestModTime(r.files)
+	if err != nil {
+		r.logger.Warn("tls: keeping previous version: " + err.Error())
+		return r.value
+	}
+	if modTime.Equal(r.modTime) {
+		return r.value
+	}
+	value, err := r.load()
+	if err != nil {
+		// modTime не сохраняется, чтобы попробовать снова при следующей проверке
+		r.logger.Warn("tls: keeping previous version: " + err.Error())
+		return r.value
+	}
+	r.value, r.modTime = value, modTime
+	r.logger.Info(fmt.Sprintf("tls: reloaded %v", r.files))
+	return r.value
+}
+
+// latestModTime - время последнего изменения среди файлов: сертификат и ключ обновляются парой
+func latestModTime(files []string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("tls: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
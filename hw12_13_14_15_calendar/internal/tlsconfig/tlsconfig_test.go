@@ -0,0 +1,196 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA - самоподписанный CA, которым подписываются сертификаты сервера и клиента в тестах
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string
+}
+
+func newTestCA(t *testing.T, dir string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	file := filepath.Join(dir, "ca.pem")
+	writePEM(t, file, "CERTIFICATE", der)
+	return &testCA{cert: cert, key: key, file: file}
+}
+
+// issue выпускает сертификат с именем name и пишет его и ключ в dir/name.pem и dir/name-key.pem
+func (ca *testCA) issue(t *testing.T, dir, name string, usage x509.ExtKeyUsage) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+// serve принимает TLS-подключения и завершает рукопожатие; сами данные тестам не нужны
+func serve(t *testing.T, config *tls.Config) string {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// dial подключается и возвращает имя в сертификате сервера
+func dial(address string, config *tls.Config) (string, error) {
+	conn, err := tls.Dial("tcp", address, config)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	// В TLS 1.3 отказ сервера в клиентском сертификате приходит после рукопожатия, при первом чтении
+	if _, err := conn.Read(make([]byte, 1)); err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName, nil
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	serverCert, serverKey := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, dir, "client", x509.ExtKeyUsageClientAuth)
+	logg := logger.New("error")
+
+	serverConfig, err := NewServerConfig(ServerFiles{CertFile: serverCert, KeyFile: serverKey, ClientCAFile: ca.file}, logg)
+	require.NoError(t, err)
+	address := serve(t, serverConfig)
+
+	withCert, err := NewClientConfig(ClientFiles{CAFile: ca.file, CertFile: clientCert, KeyFile: clientKey}, logg)
+	require.NoError(t, err)
+	name, err := dial(address, withCert)
+	require.NoError(t, err)
+	assert.Equal(t, "server", name)
+
+	withoutCert, err := NewClientConfig(ClientFiles{CAFile: ca.file}, logg)
+	require.NoError(t, err)
+	_, err = dial(address, withoutCert)
+	assert.Error(t, err)
+
+	// Сервер с сертификатом чужого CA клиент не примет
+	otherDir := t.TempDir()
+	other := newTestCA(t, otherDir)
+	otherCert, otherKey := other.issue(t, otherDir, "impostor", x509.ExtKeyUsageServerAuth)
+	impostorConfig, err := NewServerConfig(ServerFiles{CertFile: otherCert, KeyFile: otherKey}, logg)
+	require.NoError(t, err)
+	_, err = dial(serve(t, impostorConfig), withCert)
+	assert.Error(t, err)
+}
+
+func TestCertificateReload(t *testing.T) {
+	previous := CheckInterval
+	CheckInterval = 0
+	t.Cleanup(func() { CheckInterval = previous })
+
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	certFile, keyFile := ca.issue(t, dir, "v1", x509.ExtKeyUsageServerAuth)
+	logg := logger.New("error")
+
+	serverConfig, err := NewServerConfig(ServerFiles{CertFile: certFile, KeyFile: keyFile}, logg)
+	require.NoError(t, err)
+	address := serve(t, serverConfig)
+	clientConfig, err := NewClientConfig(ClientFiles{CAFile: ca.file}, logg)
+	require.NoError(t, err)
+
+	name, err := dial(address, clientConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", name)
+
+	// Новый сертификат кладётся на место старого, как это делает certbot или cert-manager
+	newCert, newKey := ca.issue(t, dir, "v2", x509.ExtKeyUsageServerAuth)
+	require.NoError(t, os.Rename(newCert, certFile))
+	require.NoError(t, os.Rename(newKey, keyFile))
+	touch(t, time.Minute, certFile, keyFile)
+
+	name, err = dial(address, clientConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", name)
+
+	// Испорченный файл не ломает сервер: остаётся последний исправный сертификат
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	touch(t, 2*time.Minute, certFile)
+	name, err = dial(address, clientConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", name)
+}
+
+// touch сдвигает время изменения вперёд на shift: без этого в пределах секунды оно может совпасть со старым
+func touch(t *testing.T, shift time.Duration, files ...string) {
+	t.Helper()
+	future := time.Now().Add(shift)
+	for _, file := range files {
+		require.NoError(t, os.Chtimes(file, future, future))
+	}
+}
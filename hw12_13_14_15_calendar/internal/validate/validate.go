@@ -0,0 +1,231 @@
+// Package validate проверяет входящие структуры по тегам `validate`.
+//
+// Правила перечисляются через запятую:
+//
+//	required  - значение не нулевое (непустая строка, ненулевое время или длительность)
+//	min=X     - нижняя граница: длина строки в символах, число элементов списка, число,
+//	            длительность (1m) или дата (2006-01-02)
+//	max=X     - верхняя граница в тех же единицах
+//	id        - идентификатор: латиница, цифры и символы - _ . @
+//	color     - цвет в виде #rrggbb
+//	tags      - список тегов: в каждом буквы, цифры и символы - _, не длиннее 32 символов
+//	url       - абсолютный адрес http или https
+//
+// Имя поля в ошибках берётся из тега json, чтобы клиент видел то же имя, что отправлял.
+// Нулевое время и пустая строка в id пропускаются: обязательность задаёт только required.
+package validate
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
+)
+
+// FieldError - нарушение правила в одном поле
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Errors - все нарушения в структуре; отдаётся целиком, чтобы клиент исправил всё за один раз
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Error())
+	}
+	return "invalid request: " + strings.Join(messages, "; ")
+}
+
+var (
+	timeType             = reflect.TypeOf(time.Time{})
+	durationType         = reflect.TypeOf(time.Duration(0))
+	calendarDurationType = reflect.TypeOf(calendar_types.CalendarDuration(0))
+)
+
+var (
+	idPattern    = regexp.MustCompile(`^[A-Za-z0-9_.@-]+$`)
+	colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+	tagPattern   = regexp.MustCompile(`^[\p{L}\p{N}_-]{1,32}$`)
+)
+
+// Struct проверяет поля структуры (или указателя на неё); возвращает Errors или nil.
+// Ошибка в самом теге - ошибка программиста, поэтому она приводит к панике.
+func Struct(v any) error {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: struct expected, got %T", v))
+	}
+
+	var errs Errors
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag, ok := field.Tag.Lookup("validate")
+		if !ok || !field.IsExported() {
+			continue
+		}
+		name := fieldName(field)
+		for _, rule := range strings.Split(tag, ",") {
+			if message := check(value.Field(i), rule); message != "" {
+				errs = append(errs, FieldError{Field: name, Message: message})
+				break
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func fieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+// check возвращает описание нарушения или пустую строку
+func check(value reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	switch name {
+	case "required":
+		if value.IsZero() {
+			return "is required"
+		}
+	case "id":
+		if value.Kind() != reflect.String {
+			panic("validate: id rule applies to strings only")
+		}
+		if s := value.String(); s != "" && !idPattern.MatchString(s) {
+			return "must contain only latin letters, digits and - _ . @"
+		}
+	case "color":
+		if value.Kind() != reflect.String {
+			panic("validate: color rule applies to strings only")
+		}
+		if s := value.String(); s != "" && !colorPattern.MatchString(s) {
+			return "must be a color like #1e90ff"
+		}
+	case "tags":
+		tags, ok := value.Interface().([]string)
+		if !ok {
+			panic("validate: tags rule applies to []string only")
+		}
+		for _, tag := range tags {
+			if !tagPattern.MatchString(tag) {
+				return fmt.Sprintf("tag %q must be 1 to 32 letters, digits, - or _", tag)
+			}
+		}
+	case "url":
+		if value.Kind() != reflect.String {
+			panic("validate: url rule applies to strings only")
+		}
+		if s := value.String(); s != "" && !isWebURL(s) {
+			return "must be an absolute http or https URL"
+		}
+	case "min", "max":
+		return checkBound(value, name, arg)
+	default:
+		panic(fmt.Sprintf("validate: unknown rule %q", rule))
+	}
+	return ""
+}
+
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func checkBound(value reflect.Value, name, arg string) string {
+	isMin := name == "min"
+	switch {
+	case value.Type() == timeType:
+		bound, err := time.Parse(time.DateOnly, arg)
+		if err != nil {
+			panic(fmt.Sprintf("validate: bad date %q: %v", arg, err))
+		}
+		t := value.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		if isMin && t.Before(bound) {
+			return "must not be before " + arg
+		}
+		if !isMin && t.After(bound) {
+			return "must not be after " + arg
+		}
+	case value.Type() == durationType || value.Type() == calendarDurationType:
+		bound, err := time.ParseDuration(arg)
+		if err != nil {
+			panic(fmt.Sprintf("validate: bad duration %q: %v", arg, err))
+		}
+		d := time.Duration(value.Int())
+		if isMin && d < bound {
+			return "must be at least " + arg
+		}
+		if !isMin && d > bound {
+			return "must be at most " + arg
+		}
+	case value.Kind() == reflect.String:
+		bound := parseInt(arg)
+		length := int64(utf8.RuneCountInString(value.String()))
+		if isMin && length < bound {
+			return fmt.Sprintf("must be at least %d characters long", bound)
+		}
+		if !isMin && length > bound {
+			return fmt.Sprintf("must be at most %d characters long", bound)
+		}
+	case value.Kind() == reflect.Slice:
+		bound := parseInt(arg)
+		length := int64(value.Len())
+		if isMin && length < bound {
+			return fmt.Sprintf("must have at least %d items", bound)
+		}
+		if !isMin && length > bound {
+			return fmt.Sprintf("must have at most %d items", bound)
+		}
+	case value.CanInt():
+		bound := parseInt(arg)
+		if isMin && value.Int() < bound {
+			return fmt.Sprintf("must be at least %d", bound)
+		}
+		if !isMin && value.Int() > bound {
+			return fmt.Sprintf("must be at most %d", bound)
+		}
+	default:
+		panic(fmt.Sprintf("validate: %s rule does not apply to %s", name, value.Type()))
+	}
+	return ""
+}
+
+func parseInt(arg string) int64 {
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: bad number %q: %v", arg, err))
+	}
+	return n
+}
+
+// WithPrefix возвращает копию ошибок с префиксом в именах полей: для вложенных структур
+// вроде operations[2].event.title
+func (e Errors) WithPrefix(prefix string) Errors {
+	prefixed := make(Errors, len(e))
+	for i, fieldErr := range e {
+		prefixed[i] = FieldError{Field: prefix + "." + fieldErr.Field, Message: fieldErr.Message}
+	}
+	return prefixed
+}
@@ -0,0 +1,86 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/calendar_types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type request struct {
+	Title    string                          `json:"title" validate:"required,max=5"`
+	Start    time.Time                       `json:"start_time" validate:"required,min=1970-01-01"`
+	Duration calendar_types.CalendarDuration `json:"duration" validate:"required,max=2h"`
+	Notify   time.Duration                   `json:"notify_before" validate:"min=0s,max=1h"`
+	UserID   string                          `json:"user_id" validate:"required,id"`
+	Limit    int                             `validate:"min=1,max=10"`
+	Color    string                          `json:"color" validate:"color"`
+	Tags     []string                        `json:"tags" validate:"max=2,tags"`
+	Link     string                          `json:"url" validate:"url"`
+	Comment  string
+}
+
+func TestStruct(t *testing.T) {
+	valid := request{
+		Title:    "Обед",
+		Start:    time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		Duration: calendar_types.CalendarDuration(time.Hour),
+		Notify:   15 * time.Minute,
+		UserID:   "alice@example.com",
+		Limit:    5,
+		Color:    "#1E90ff",
+		Tags:     []string{"проект-x", "q3_2025"},
+		Link:     "https://meet.example.com/abc?pwd=1",
+	}
+	require.NoError(t, Struct(valid))
+	require.NoError(t, Struct(&valid))
+
+	invalid := request{
+		Title:    "Слишком длинно",
+		Start:    time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+		Duration: 0,
+		Notify:   -time.Minute,
+		UserID:   "alice smith",
+		Limit:    11,
+		Color:    "blue",
+		Tags:     []string{"a,b"},
+		Link:     "javascript:alert(1)",
+	}
+	err := Struct(invalid)
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+
+	fields := map[string]string{}
+	for _, fieldErr := range errs {
+		fields[fieldErr.Field] = fieldErr.Message
+	}
+	assert.Equal(t, map[string]string{
+		"title":         "must be at most 5 characters long",
+		"start_time":    "must not be before 1970-01-01",
+		"duration":      "is required",
+		"notify_before": "must be at least 0s",
+		"user_id":       "must contain only latin letters, digits and - _ . @",
+		"Limit":         "must be at most 10",
+		"color":         "must be a color like #1e90ff",
+		"tags":          `tag "a,b" must be 1 to 32 letters, digits, - or _`,
+		"url":           "must be an absolute http or https URL",
+	}, fields)
+
+	err = Struct(request{
+		Title: "Обед", Start: valid.Start, Duration: valid.Duration, UserID: "alice", Limit: 1,
+		Tags: []string{"a", "b", "c"},
+	})
+	require.True(t, errors.As(err, &errs))
+	assert.Equal(t, Errors{{Field: "tags", Message: "must have at most 2 items"}}, errs)
+}
+
+func TestUnknownRulePanics(t *testing.T) {
+	assert.Panics(t, func() {
+		_ = Struct(struct {
+			Name string `validate:"email"`
+		}{})
+	})
+}
@@ -0,0 +1,13 @@
+-- Журнал изменений не ссылается на events: записи должны пережить окончательное удаление события
+CREATE TABLE IF NOT EXISTS event_audit (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(36) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_audit_event_id ON event_audit(event_id, id);
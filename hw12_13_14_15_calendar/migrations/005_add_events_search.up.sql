@@ -0,0 +1,9 @@
+-- Конфигурация simple не зависит от языка: названия бывают и на русском, и на английском.
+-- Название весит больше описания, это учитывает ts_rank.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_events_search_vector ON events USING GIN (search_vector);
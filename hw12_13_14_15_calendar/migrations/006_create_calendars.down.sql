@@ -0,0 +1,6 @@
+-- События остаются у пользователей: принадлежность календарю не нужна без календарей
+DROP INDEX IF EXISTS idx_events_calendar_id;
+ALTER TABLE events DROP COLUMN IF EXISTS calendar_id;
+
+DROP TABLE IF EXISTS calendar_acl;
+DROP TABLE IF EXISTS calendars;
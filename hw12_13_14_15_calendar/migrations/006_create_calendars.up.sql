@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS calendars (
+    id VARCHAR(128) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    owner_id VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendars_owner_id ON calendars(owner_id);
+
+CREATE TABLE IF NOT EXISTS calendar_acl (
+    calendar_id VARCHAR(128) NOT NULL REFERENCES calendars(id) ON DELETE CASCADE,
+    grantee_type VARCHAR(8) NOT NULL,
+    grantee_id VARCHAR(255) NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    PRIMARY KEY (calendar_id, grantee_type, grantee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_acl_grantee ON calendar_acl(grantee_type, grantee_id);
+
+-- Существующие события переезжают в личные календари своих пользователей
+INSERT INTO calendars (id, name, kind, owner_id)
+SELECT DISTINCT 'personal:' || user_id, 'Personal', 'personal', user_id FROM events
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS calendar_id VARCHAR(128) REFERENCES calendars(id);
+UPDATE events SET calendar_id = 'personal:' || user_id WHERE calendar_id IS NULL;
+ALTER TABLE events ALTER COLUMN calendar_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_events_calendar_id ON events(calendar_id);
@@ -0,0 +1,12 @@
+-- Ответы на запросы создания с ключом идемпотентности; повтор запроса с тем же ключом получает сохранённый ответ
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    fingerprint VARCHAR(64) NOT NULL,
+    event_id VARCHAR(36) NOT NULL,
+    response JSONB,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
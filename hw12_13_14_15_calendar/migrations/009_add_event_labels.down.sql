@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS event_tags;
+
+ALTER TABLE events DROP COLUMN IF EXISTS color;
+ALTER TABLE events DROP COLUMN IF EXISTS category;
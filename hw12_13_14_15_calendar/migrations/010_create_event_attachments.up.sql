@@ -0,0 +1,14 @@
+-- Вложения событий: файлы и ссылки. Содержимое файлов лежит в хранилище вложений под ключом id.
+CREATE TABLE IF NOT EXISTS event_attachments (
+    id VARCHAR(36) PRIMARY KEY,
+    event_id VARCHAR(36) NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    kind VARCHAR(8) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    url TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_attachments_event_id ON event_attachments(event_id);
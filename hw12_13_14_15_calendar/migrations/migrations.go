@@ -0,0 +1,10 @@
+// Package migrations встраивает миграции Postgres в бинарник, чтобы календарю
+// не нужен был каталог migrations рядом с исполняемым файлом.
+package migrations
+
+import "embed"
+
+// FS - файлы миграций в формате golang-migrate: NNN_name.up.sql и NNN_name.down.sql
+//
+//go:embed *.sql
+var FS embed.FS
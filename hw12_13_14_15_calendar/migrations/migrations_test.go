@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// Каждой миграции нужен откат, иначе migrate down остановится на ней
+func TestMigrationsArePaired(t *testing.T) {
+	names, err := fs.Glob(FS, "*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]bool{}
+	for _, name := range names {
+		files[name] = true
+	}
+	if len(files) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	for name := range files {
+		if base, ok := strings.CutSuffix(name, ".up.sql"); ok {
+			if !files[base+".down.sql"] {
+				t.Errorf("%s has no down migration", name)
+			}
+			continue
+		}
+		if base, ok := strings.CutSuffix(name, ".down.sql"); ok {
+			if !files[base+".up.sql"] {
+				t.Errorf("%s has no up migration", name)
+			}
+			continue
+		}
+		t.Errorf("%s is neither up nor down migration", name)
+	}
+}
@@ -0,0 +1,173 @@
+// Package client - Go-клиент сервиса календаря. Один интерфейс Client работает поверх gRPC
+// (NewGRPC) и поверх REST API /v1 (NewHTTP); повторы, таймауты и передача пользователя
+// и токена настраиваются опциями одинаково для обоих транспортов.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Event - событие календаря в том виде, в каком его возвращает сервер
+type Event struct {
+	ID           string
+	CalendarID   string
+	Title        string
+	Description  string
+	UserID       string
+	StartTime    time.Time
+	Duration     time.Duration
+	NotifyBefore time.Duration
+	Version      int64
+	DeletedAt    *time.Time // Заполнено только у событий из корзины
+	Category     string
+	Color        string   // #rrggbb
+	Tags         []string // В нижнем регистре, по алфавиту
+}
+
+// NewEvent - параметры создания события
+type NewEvent struct {
+	CalendarID   string // Пустой - календарь по умолчанию
+	Title        string
+	Description  string
+	UserID       string
+	StartTime    time.Time
+	Duration     time.Duration
+	NotifyBefore time.Duration
+	Category     string
+	Color        string // #rrggbb
+	Tags         []string
+	// RequestID - ключ идемпотентности. Если он пуст, а повторы включены,
+	// клиент генерирует его сам, чтобы повтор не создал второе событие.
+	RequestID string
+}
+
+// EventUpdate - новые значения полей события
+type EventUpdate struct {
+	ID           string
+	Title        string
+	Description  string
+	UserID       string
+	StartTime    time.Time
+	Duration     time.Duration
+	NotifyBefore time.Duration
+	// Разметка заменяется целиком: пустые значения стирают прежние
+	Category string
+	Color    string
+	Tags     []string
+	// ExpectedVersion - версия, которую видел клиент; 0 - без проверки
+	ExpectedVersion int64
+}
+
+// SearchQuery - параметры полнотекстового поиска
+type SearchQuery struct {
+	Text   string
+	UserID string
+	From   time.Time // Нулевое время - без ограничения
+	To     time.Time
+	Limit  int // 0 - лимит сервера
+}
+
+// SearchResult - найденное событие и его релевантность
+type SearchResult struct {
+	Event Event
+	Rank  float64
+}
+
+// TagStatsQuery - по событиям с началом в [From, To) посчитать теги
+type TagStatsQuery struct {
+	From  time.Time // Нулевое время - без ограничения
+	To    time.Time
+	Limit int // 0 - лимит сервера
+}
+
+// TagStat - сколькими событиями отмечен тег
+type TagStat struct {
+	Tag   string
+	Count int
+}
+
+// Period - интервал выборки событий
+type Period int
+
+const (
+	Day Period = iota
+	Week
+	Month
+)
+
+func (p Period) String() string {
+	switch p {
+	case Day:
+		return "day"
+	case Week:
+		return "week"
+	case Month:
+		return "month"
+	default:
+		return fmt.Sprintf("Period(%d)", int(p))
+	}
+}
+
+// ParsePeriod разбирает имя периода: day, week или month
+func ParsePeriod(name string) (Period, error) {
+	for _, p := range []Period{Day, Week, Month} {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown period %q, expected day, week or month", name)
+}
+
+// Client - операции над событиями. Реализации безопасны для использования из нескольких горутин.
+type Client interface {
+	CreateEvent(ctx context.Context, event NewEvent) (Event, error)
+	GetEvent(ctx context.Context, id string) (Event, error)
+	UpdateEvent(ctx context.Context, update EventUpdate) (Event, error)
+	// DeleteEvent переносит событие в корзину; expectedVersion 0 - без проверки версии
+	DeleteEvent(ctx context.Context, id string, expectedVersion int64) error
+	// ListEvents возвращает события дня, недели или месяца, в который попадает date;
+	// если переданы tags - только события, отмеченные всеми этими тегами
+	ListEvents(ctx context.Context, period Period, date time.Time, tags ...string) ([]Event, error)
+	SearchEvents(ctx context.Context, query SearchQuery) ([]SearchResult, error)
+	// TagStats возвращает теги видимых пользователю событий, начиная с самых частых
+	TagStats(ctx context.Context, query TagStatsQuery) ([]TagStat, error)
+	// Watch сообщает fn об изменениях событий периода, пока не отменён контекст или fn не вернёт ошибку.
+	// Потоковых вызовов у API нет, поэтому изменения находятся опросом ListEvents (см. WithWatchInterval).
+	Watch(ctx context.Context, period Period, date time.Time, fn func(Change) error) error
+	Close() error
+}
+
+// Error - ошибка, которую вернул сервер. Код приводится к кодам gRPC для обоих транспортов,
+// поэтому проверять ошибки можно одинаково.
+type Error struct {
+	Code    codes.Code
+	Message string
+	// RetryAfter - через сколько сервер разрешил повторить запрос, если он об этом сообщил
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("calendar: %s: %s", e.Code, e.Message)
+}
+
+// Code возвращает код ошибки сервера; для прочих ошибок - Unknown, для nil - OK
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return codes.Unknown
+}
+
+// IsNotFound сообщает, что события нет или оно удалено
+func IsNotFound(err error) bool {
+	return Code(err) == codes.NotFound
+}
@@ -0,0 +1,424 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/api"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/app"
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/logger"
+	internalgrpc "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server/grpc"
+	internalhttp "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/server/http"
+	memorystorage "github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// testServer - календарь в памяти, доступный и по gRPC, и по REST /v1
+type testServer struct {
+	grpcAddr string
+	httpURL  string
+	// requests и headers видят все HTTP-запросы; failures первых запросов отвечают 503
+	requests atomic.Int32
+	failures atomic.Int32
+	mu       sync.Mutex
+	headers  http.Header
+	metadata metadata.MD
+}
+
+func setupTestServer(t *testing.T) *testServer {
+	t.Helper()
+	logg := logger.New("error")
+	calendar := app.New(logg, memorystorage.New(logg))
+	ts := &testServer{}
+
+	// Шлюз вызывает сервис через собственную цепочку перехватчиков, поэтому ActorInterceptor нужен и в ней
+	service := internalgrpc.NewCalendarGRPCServer(logg, ":0", calendar,
+		internalgrpc.WithInterceptors(internalgrpc.ActorInterceptor()))
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			ts.mu.Lock()
+			ts.metadata = md
+			ts.mu.Unlock()
+			return handler(ctx, req)
+		},
+		internalgrpc.ActorInterceptor(),
+	))
+	api.RegisterCalendarServiceServer(grpcServer, service)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+	ts.grpcAddr = lis.Addr().String()
+
+	gateway, err := service.GatewayHandler(context.Background())
+	require.NoError(t, err)
+	httpServer := internalhttp.NewServer(logg, "localhost", 0, calendar, internalhttp.WithHandler("/v1/*", gateway))
+	handler := httpServer.(*internalhttp.HttpServer).Handler()
+	httpTest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.requests.Add(1)
+		ts.mu.Lock()
+		ts.headers = r.Header.Clone()
+		ts.mu.Unlock()
+		if ts.failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(httpTest.Close)
+	ts.httpURL = httpTest.URL
+	return ts
+}
+
+// transports создаёт клиент каждого транспорта с одними и теми же опциями
+func (ts *testServer) transports(t *testing.T, opts ...Option) map[string]Client {
+	t.Helper()
+	grpcClient, err := NewGRPC(ts.grpcAddr, opts...)
+	require.NoError(t, err)
+	httpClient, err := NewHTTP(ts.httpURL, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = grpcClient.Close()
+		_ = httpClient.Close()
+	})
+	return map[string]Client{"grpc": grpcClient, "http": httpClient}
+}
+
+func TestClientCRUD(t *testing.T) {
+	ts := setupTestServer(t)
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for name, c := range ts.transports(t, WithUser("alice")) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			created, err := c.CreateEvent(ctx, NewEvent{
+				Title:        "Planning " + name,
+				Description:  "quarterly planning",
+				UserID:       "alice",
+				StartTime:    start,
+				Duration:     time.Hour,
+				NotifyBefore: 15 * time.Minute,
+			})
+			require.NoError(t, err)
+			assert.NotEmpty(t, created.ID)
+			assert.True(t, created.StartTime.Equal(start))
+			assert.Equal(t, time.Hour, created.Duration)
+
+			got, err := c.GetEvent(ctx, created.ID)
+			require.NoError(t, err)
+			assert.Equal(t, created, got)
+
+			updated, err := c.UpdateEvent(ctx, EventUpdate{
+				ID:              created.ID,
+				Title:           "Planning moved",
+				UserID:          "alice",
+				StartTime:       start.Add(time.Hour),
+				Duration:        30 * time.Minute,
+				ExpectedVersion: created.Version,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "Planning moved", updated.Title)
+			assert.Greater(t, updated.Version, created.Version)
+
+			_, err = c.UpdateEvent(ctx, EventUpdate{
+				ID: created.ID, Title: "stale", UserID: "alice", StartTime: start,
+				Duration: time.Hour, ExpectedVersion: created.Version,
+			})
+			assert.Equal(t, codes.Aborted, Code(err))
+
+			for _, period := range []Period{Day, Week, Month} {
+				events, err := c.ListEvents(ctx, period, start)
+				require.NoError(t, err)
+				assert.Contains(t, ids(events), created.ID, period.String())
+			}
+
+			results, err := c.SearchEvents(ctx, SearchQuery{Text: "planning", Limit: 10})
+			require.NoError(t, err)
+			require.NotEmpty(t, results)
+
+			require.NoError(t, c.DeleteEvent(ctx, created.ID, updated.Version))
+			_, err = c.GetEvent(ctx, created.ID)
+			assert.True(t, IsNotFound(err), "got %v", err)
+		})
+	}
+}
+
+func TestClientLabels(t *testing.T) {
+	ts := setupTestServer(t)
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, name := range []string{"grpc", "http"} {
+		t.Run(name, func(t *testing.T) {
+			// У каждого транспорта свой пользователь, чтобы статистика не смешивалась
+			user := "carol-" + name
+			c := ts.transports(t, WithUser(user))[name]
+			ctx := context.Background()
+
+			planning, err := c.CreateEvent(ctx, NewEvent{
+				Title: "Planning", UserID: user, StartTime: start, Duration: time.Hour,
+				Category: "Project X", Color: "#1E90FF", Tags: []string{"Weekly", "project-x", "weekly"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "Project X", planning.Category)
+			assert.Equal(t, "#1e90ff", planning.Color)
+			assert.Equal(t, []string{"project-x", "weekly"}, planning.Tags)
+
+			review, err := c.CreateEvent(ctx, NewEvent{
+				Title: "Review", UserID: user, StartTime: start.Add(2 * time.Hour), Duration: time.Hour,
+				Tags: []string{"project-x"},
+			})
+			require.NoError(t, err)
+
+			events, err := c.ListEvents(ctx, Day, start, "weekly")
+			require.NoError(t, err)
+			assert.Equal(t, []string{planning.ID}, ids(events))
+			events, err = c.ListEvents(ctx, Week, start, "project-x")
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{planning.ID, review.ID}, ids(events))
+
+			stats, err := c.TagStats(ctx, TagStatsQuery{})
+			require.NoError(t, err)
+			assert.Equal(t, []TagStat{{Tag: "project-x", Count: 2}, {Tag: "weekly", Count: 1}}, stats)
+
+			// Обновление заменяет разметку целиком
+			updated, err := c.UpdateEvent(ctx, EventUpdate{
+				ID: planning.ID, Title: "Planning", UserID: user, StartTime: start, Duration: time.Hour,
+				ExpectedVersion: planning.Version,
+			})
+			require.NoError(t, err)
+			assert.Empty(t, updated.Category)
+			assert.Empty(t, updated.Tags)
+			stats, err = c.TagStats(ctx, TagStatsQuery{From: start, To: start.Add(24 * time.Hour), Limit: 1})
+			require.NoError(t, err)
+			assert.Equal(t, []TagStat{{Tag: "project-x", Count: 1}}, stats)
+
+			_, err = c.CreateEvent(ctx, NewEvent{
+				Title: "Bad", UserID: user, StartTime: start, Duration: time.Hour, Color: "blue", Tags: []string{"a,b"},
+			})
+			assert.Equal(t, codes.InvalidArgument, Code(err))
+		})
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := context.Background()
+	alice, err := NewHTTP(ts.httpURL, WithUser("alice"))
+	require.NoError(t, err)
+	event, err := alice.CreateEvent(ctx, NewEvent{Title: "Private", UserID: "alice", StartTime: time.Now(), Duration: time.Hour})
+	require.NoError(t, err)
+
+	for name, c := range ts.transports(t, WithUser("bob", "team", "ops"), WithToken("secret")) {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.GetEvent(ctx, event.ID)
+			assert.Equal(t, codes.PermissionDenied, Code(err))
+		})
+	}
+
+	ts.mu.Lock()
+	assert.Equal(t, "bob", ts.headers.Get("X-User-ID"))
+	assert.Equal(t, "team,ops", ts.headers.Get("X-User-Groups"))
+	assert.Equal(t, "Bearer secret", ts.headers.Get("Authorization"))
+	assert.Equal(t, []string{"bob"}, ts.metadata.Get("x-user-id"))
+	assert.Equal(t, []string{"Bearer secret"}, ts.metadata.Get("authorization"))
+	ts.mu.Unlock()
+
+	// Запрос без пользователя отклоняется, а не считается внутренним
+	for name, c := range ts.transports(t) {
+		t.Run(name+"/anonymous", func(t *testing.T) {
+			_, err := c.GetEvent(ctx, event.ID)
+			assert.Equal(t, codes.Unauthenticated, Code(err))
+		})
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := context.Background()
+	event := NewEvent{Title: "Retried", UserID: "alice", StartTime: time.Now(), Duration: time.Hour}
+
+	c, err := NewHTTP(ts.httpURL, WithUser("alice"), WithRetries(3, time.Millisecond))
+	require.NoError(t, err)
+	ts.failures.Store(2)
+	created, err := c.CreateEvent(ctx, event)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, ts.requests.Load())
+
+	// Без повторов ошибка сервера возвращается сразу
+	c, err = NewHTTP(ts.httpURL, WithUser("alice"))
+	require.NoError(t, err)
+	ts.requests.Store(0)
+	ts.failures.Store(1)
+	_, err = c.GetEvent(ctx, created.ID)
+	assert.Equal(t, codes.Unavailable, Code(err))
+	assert.EqualValues(t, 1, ts.requests.Load())
+
+	// Неидемпотентный запрос не повторяется
+	opts := newOptions([]Option{WithRetries(3, time.Millisecond)})
+	calls := 0
+	err = opts.call(ctx, false, func(context.Context) error {
+		calls++
+		return &Error{Code: codes.Unavailable}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	// Ошибки клиента не повторяются
+	c, err = NewHTTP(ts.httpURL, WithUser("alice"), WithRetries(3, time.Millisecond))
+	require.NoError(t, err)
+	ts.requests.Store(0)
+	_, err = c.GetEvent(ctx, "missing")
+	assert.True(t, IsNotFound(err))
+	assert.EqualValues(t, 1, ts.requests.Load())
+}
+
+func TestClientTimeout(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+
+	c, err := NewHTTP(slow.URL, WithTimeout(20*time.Millisecond))
+	require.NoError(t, err)
+	_, err = c.GetEvent(context.Background(), "id")
+	assert.Equal(t, codes.DeadlineExceeded, Code(err))
+}
+
+func TestHTTPError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "application/problem+json")
+	rec.Header().Set("Retry-After", "2")
+	rec.WriteHeader(http.StatusTooManyRequests)
+	_, _ = rec.WriteString(`{"type":"about:blank","status":429,"detail":"too many write requests, retry later"}`)
+
+	err := httpError(rec.Result())
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, codes.ResourceExhausted, e.Code)
+	assert.Equal(t, "too many write requests, retry later", e.Message)
+	assert.Equal(t, 2*time.Second, e.RetryAfter)
+}
+
+func TestWatch(t *testing.T) {
+	ts := setupTestServer(t)
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for name, c := range ts.transports(t, WithUser("alice"), WithWatchInterval(10*time.Millisecond)) {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			existing, err := c.CreateEvent(ctx, NewEvent{Title: "Existing", UserID: "alice", StartTime: start, Duration: time.Hour})
+			require.NoError(t, err)
+
+			changes := make(chan Change)
+			done := make(chan error, 1)
+			go func() {
+				done <- c.Watch(ctx, Day, start, func(change Change) error {
+					if change.Event.ID != existing.ID {
+						return nil
+					}
+					changes <- change
+					return nil
+				})
+			}()
+
+			change := <-changes
+			assert.Equal(t, Added, change.Type)
+
+			_, err = c.UpdateEvent(ctx, EventUpdate{ID: existing.ID, Title: "Renamed", UserID: "alice", StartTime: start, Duration: time.Hour})
+			require.NoError(t, err)
+			change = <-changes
+			assert.Equal(t, Updated, change.Type)
+			assert.Equal(t, "Renamed", change.Event.Title)
+
+			require.NoError(t, c.DeleteEvent(ctx, existing.ID, 0))
+			change = <-changes
+			assert.Equal(t, Removed, change.Type)
+
+			cancel()
+			assert.ErrorIs(t, <-done, context.Canceled)
+		})
+	}
+}
+
+func TestEachPage(t *testing.T) {
+	ts := setupTestServer(t)
+	c, err := NewHTTP(ts.httpURL, WithUser("alice"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Понедельник 3 марта - воскресенье 30 марта 2025
+	from := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	var want []string
+	for _, start := range []time.Time{
+		from.Add(-time.Hour), // вне интервала
+		from.AddDate(0, 0, 22),
+		from.Add(9 * time.Hour),
+		from.AddDate(0, 0, 8),
+		to.Add(-time.Minute),
+		to, // вне интервала
+	} {
+		event, err := c.CreateEvent(ctx, NewEvent{Title: "Paged", UserID: "alice", StartTime: start, Duration: time.Hour})
+		require.NoError(t, err)
+		if !start.Before(from) && start.Before(to) {
+			want = append(want, event.ID)
+		}
+	}
+
+	var pages [][]Event
+	require.NoError(t, EachPage(ctx, c, Week, from, to, func(page []Event) error {
+		pages = append(pages, page)
+		return nil
+	}))
+	require.Len(t, pages, 3, "пустая неделя пропускается")
+	var got []Event
+	for _, page := range pages {
+		got = append(got, page...)
+	}
+	assert.ElementsMatch(t, want, ids(got))
+	for i := 1; i < len(got); i++ {
+		assert.False(t, got[i].StartTime.Before(got[i-1].StartTime), "события упорядочены по началу")
+	}
+
+	all, err := ListRange(ctx, c, from, to)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, want, ids(all))
+
+	stop := errors.New("stop")
+	err = EachPage(ctx, c, Day, from, to, func([]Event) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestPeriodStart(t *testing.T) {
+	sunday := time.Date(2025, 3, 9, 18, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC), periodStart(Day, sunday))
+	assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), periodStart(Week, sunday))
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), periodStart(Month, sunday))
+
+	period, err := ParsePeriod("week")
+	require.NoError(t, err)
+	assert.Equal(t, Week, period)
+	_, err = ParsePeriod("year")
+	assert.Error(t, err)
+}
+
+func ids(events []Event) []string {
+	result := make([]string, 0, len(events))
+	for _, event := range events {
+		result = append(result, event.ID)
+	}
+	return result
+}
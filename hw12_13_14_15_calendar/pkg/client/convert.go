@@ -0,0 +1,113 @@
+package client
+
+import (
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/api"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Оба транспорта обмениваются сообщениями из api: gRPC - напрямую, HTTP - в их JSON-представлении
+
+func createRequest(event NewEvent) *api.CreateEventRequest {
+	return &api.CreateEventRequest{
+		Title:        event.Title,
+		StartTime:    timestamppb.New(event.StartTime),
+		Duration:     durationpb.New(event.Duration),
+		Description:  event.Description,
+		UserId:       event.UserID,
+		NotifyBefore: durationpb.New(event.NotifyBefore),
+		CalendarId:   event.CalendarID,
+		RequestId:    event.RequestID,
+		Category:     event.Category,
+		Color:        event.Color,
+		Tags:         event.Tags,
+	}
+}
+
+func updateRequest(update EventUpdate) *api.UpdateEventRequest {
+	return &api.UpdateEventRequest{
+		Id:              update.ID,
+		Title:           update.Title,
+		StartTime:       timestamppb.New(update.StartTime),
+		Duration:        durationpb.New(update.Duration),
+		Description:     update.Description,
+		UserId:          update.UserID,
+		NotifyBefore:    durationpb.New(update.NotifyBefore),
+		ExpectedVersion: update.ExpectedVersion,
+		Category:        update.Category,
+		Color:           update.Color,
+		Tags:            update.Tags,
+	}
+}
+
+func searchRequest(query SearchQuery) *api.SearchEventsRequest {
+	req := &api.SearchEventsRequest{
+		Query:  query.Text,
+		UserId: query.UserID,
+		Limit:  int32(query.Limit),
+	}
+	if !query.From.IsZero() {
+		req.From = timestamppb.New(query.From)
+	}
+	if !query.To.IsZero() {
+		req.To = timestamppb.New(query.To)
+	}
+	return req
+}
+
+func tagStatsRequest(query TagStatsQuery) *api.GetTagStatsRequest {
+	req := &api.GetTagStatsRequest{Limit: int32(query.Limit)}
+	if !query.From.IsZero() {
+		req.From = timestamppb.New(query.From)
+	}
+	if !query.To.IsZero() {
+		req.To = timestamppb.New(query.To)
+	}
+	return req
+}
+
+func tagStatsFromProto(stats []*api.TagStat) []TagStat {
+	result := make([]TagStat, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, TagStat{Tag: stat.GetTag(), Count: int(stat.GetCount())})
+	}
+	return result
+}
+
+func eventFromProto(event *api.EventResponse) Event {
+	result := Event{
+		ID:           event.GetId(),
+		CalendarID:   event.GetCalendarId(),
+		Title:        event.GetTitle(),
+		Description:  event.GetDescription(),
+		UserID:       event.GetUserId(),
+		StartTime:    event.GetStartTime().AsTime(),
+		Duration:     event.GetDuration().AsDuration(),
+		NotifyBefore: event.GetNotifyBefore().AsDuration(),
+		Version:      event.GetVersion(),
+		Category:     event.GetCategory(),
+		Color:        event.GetColor(),
+		Tags:         event.GetTags(),
+	}
+	if event.GetDeletedAt() != nil {
+		deletedAt := event.GetDeletedAt().AsTime()
+		result.DeletedAt = &deletedAt
+	}
+	return result
+}
+
+func eventsFromProto(events []*api.EventResponse) []Event {
+	result := make([]Event, 0, len(events))
+	for _, event := range events {
+		result = append(result, eventFromProto(event))
+	}
+	return result
+}
+
+func searchResultsFromProto(results []*api.SearchResult) []SearchResult {
+	converted := make([]SearchResult, 0, len(results))
+	for _, result := range results {
+		converted = append(converted, SearchResult{Event: eventFromProto(result.GetEvent()), Rank: result.GetRank()})
+	}
+	return converted
+}
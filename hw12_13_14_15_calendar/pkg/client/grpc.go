@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type grpcClient struct {
+	options
+	conn    *grpc.ClientConn
+	service api.CalendarServiceClient
+}
+
+// NewGRPC создаёт клиент gRPC API по адресу target, например "localhost:6523".
+// Соединение устанавливается при первом запросе.
+func NewGRPC(target string, opts ...Option) (Client, error) {
+	c := &grpcClient{options: newOptions(opts)}
+
+	creds := insecure.NewCredentials()
+	if c.tls != nil {
+		creds = credentials.NewTLS(c.tls)
+	}
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(c.intercept),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grpc client: %w", err)
+	}
+	c.conn = conn
+	c.service = api.NewCalendarServiceClient(conn)
+	return c, nil
+}
+
+// intercept добавляет в метаданные пользователя и токен и приводит ошибку к *Error
+func (c *grpcClient) intercept(
+	ctx context.Context, method string, req, reply any,
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+) error {
+	headers, err := c.credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	for _, header := range headers {
+		ctx = metadata.AppendToOutgoingContext(ctx, header[0], header[1])
+	}
+
+	var md metadata.MD
+	err = invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&md))...)
+	return grpcError(err, md)
+}
+
+func grpcError(err error, md metadata.MD) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	e := &Error{Code: st.Code(), Message: st.Message()}
+	if values := md.Get("retry-after"); len(values) > 0 {
+		if seconds, err := strconv.Atoi(values[0]); err == nil {
+			e.RetryAfter = time.Duration(seconds) * time.Second
+		}
+	}
+	return e
+}
+
+func (c *grpcClient) CreateEvent(ctx context.Context, event NewEvent) (Event, error) {
+	event, idempotent := c.withRequestID(event)
+	var resp *api.EventResponse
+	err := c.call(ctx, idempotent, func(ctx context.Context) (err error) {
+		resp, err = c.service.CreateEvent(ctx, createRequest(event))
+		return err
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	return eventFromProto(resp), nil
+}
+
+func (c *grpcClient) GetEvent(ctx context.Context, id string) (Event, error) {
+	var resp *api.EventResponse
+	err := c.call(ctx, true, func(ctx context.Context) (err error) {
+		resp, err = c.service.GetEvent(ctx, &api.GetEventRequest{Id: id})
+		return err
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	return eventFromProto(resp), nil
+}
+
+func (c *grpcClient) UpdateEvent(ctx context.Context, update EventUpdate) (Event, error) {
+	var resp *api.EventResponse
+	err := c.call(ctx, true, func(ctx context.Context) (err error) {
+		resp, err = c.service.UpdateEvent(ctx, updateRequest(update))
+		return err
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	return eventFromProto(resp), nil
+}
+
+func (c *grpcClient) DeleteEvent(ctx context.Context, id string, expectedVersion int64) error {
+	return c.call(ctx, true, func(ctx context.Context) error {
+		_, err := c.service.DeleteEvent(ctx, &api.DeleteEventRequest{Id: id, ExpectedVersion: expectedVersion})
+		return err
+	})
+}
+
+func (c *grpcClient) ListEvents(ctx context.Context, period Period, date time.Time, tags ...string) ([]Event, error) {
+	var resp *api.ListEventsResponse
+	err := c.call(ctx, true, func(ctx context.Context) (err error) {
+		ts := timestamppb.New(date)
+		switch period {
+		case Day:
+			resp, err = c.service.ListEventsForDay(ctx, &api.ListEventsForDayRequest{Date: ts, Tags: tags})
+		case Week:
+			resp, err = c.service.ListEventsForWeek(ctx, &api.ListEventsForWeekRequest{Date: ts, Tags: tags})
+		case Month:
+			resp, err = c.service.ListEventsForMonth(ctx, &api.ListEventsForMonthRequest{Date: ts, Tags: tags})
+		default:
+			err = &Error{Code: codes.InvalidArgument, Message: "unknown period " + period.String()}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return eventsFromProto(resp.GetEvents()), nil
+}
+
+func (c *grpcClient) SearchEvents(ctx context.Context, query SearchQuery) ([]SearchResult, error) {
+	var resp *api.SearchEventsResponse
+	err := c.call(ctx, true, func(ctx context.Context) (err error) {
+		resp, err = c.service.SearchEvents(ctx, searchRequest(query))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return searchResultsFromProto(resp.GetResults()), nil
+}
+
+func (c *grpcClient) TagStats(ctx context.Context, query TagStatsQuery) ([]TagStat, error) {
+	var resp *api.TagStatsResponse
+	err := c.call(ctx, true, func(ctx context.Context) (err error) {
+		resp, err = c.service.GetTagStats(ctx, tagStatsRequest(query))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tagStatsFromProto(resp.GetTags()), nil
+}
+
+func (c *grpcClient) Watch(ctx context.Context, period Period, date time.Time, fn func(Change) error) error {
+	return watch(ctx, c, c.watchInterval, period, date, fn)
+}
+
+func (c *grpcClient) Close() error {
+	if err := c.conn.Close(); err != nil {
+		return fmt.Errorf("failed to close grpc connection: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxErrorBody - сколько байт тела ошибки читается, чтобы достать из него сообщение
+const maxErrorBody = 64 << 10
+
+type httpClient struct {
+	options
+	baseURL string
+	http    *http.Client
+}
+
+// NewHTTP создаёт клиент REST API /v1 по адресу сервера, например "http://localhost:8888"
+func NewHTTP(baseURL string, opts ...Option) (Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base url %q: scheme must be http or https", baseURL)
+	}
+
+	c := &httpClient{options: newOptions(opts), baseURL: strings.TrimRight(baseURL, "/")}
+	c.http = c.httpClient
+	if c.http == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = c.tls
+		c.http = &http.Client{Transport: transport}
+	}
+	return c, nil
+}
+
+func (c *httpClient) CreateEvent(ctx context.Context, event NewEvent) (Event, error) {
+	event, idempotent := c.withRequestID(event)
+	var resp api.EventResponse
+	err := c.call(ctx, idempotent, func(ctx context.Context) error {
+		return c.do(ctx, http.MethodPost, "/v1/events", nil, createRequest(event), &resp)
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	return eventFromProto(&resp), nil
+}
+
+func (c *httpClient) GetEvent(ctx context.Context, id string) (Event, error) {
+	var resp api.EventResponse
+	err := c.call(ctx, true, func(ctx context.Context) error {
+		return c.do(ctx, http.MethodGet, "/v1/events/"+url.PathEscape(id), nil, nil, &resp)
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	return eventFromProto(&resp), nil
+}
+
+func (c *httpClient) UpdateEvent(ctx context.Context, update EventUpdate) (Event, error) {
+	var resp api.EventResponse
+	err := c.call(ctx, true, func(ctx context.Context) error {
+		return c.do(ctx, http.MethodPut, "/v1/events/"+url.PathEscape(update.ID), nil, updateRequest(update), &resp)
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	return eventFromProto(&resp), nil
+}
+
+func (c *httpClient) DeleteEvent(ctx context.Context, id string, expectedVersion int64) error {
+	query := url.Values{}
+	if expectedVersion != 0 {
+		query.Set("expectedVersion", strconv.FormatInt(expectedVersion, 10))
+	}
+	return c.call(ctx, true, func(ctx context.Context) error {
+		return c.do(ctx, http.MethodDelete, "/v1/events/"+url.PathEscape(id), query, nil, &api.DeleteEventResponse{})
+	})
+}
+
+func (c *httpClient) ListEvents(ctx context.Context, period Period, date time.Time, tags ...string) ([]Event, error) {
+	switch period {
+	case Day, Week, Month:
+	default:
+		return nil, &Error{Code: codes.InvalidArgument, Message: "unknown period " + period.String()}
+	}
+
+	query := url.Values{"date": {date.UTC().Format(time.RFC3339Nano)}}
+	if len(tags) > 0 {
+		query["tags"] = tags
+	}
+	var resp api.ListEventsResponse
+	err := c.call(ctx, true, func(ctx context.Context) error {
+		return c.do(ctx, http.MethodGet, "/v1/events:"+period.String(), query, nil, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return eventsFromProto(resp.GetEvents()), nil
+}
+
+func (c *httpClient) SearchEvents(ctx context.Context, search SearchQuery) ([]SearchResult, error) {
+	query := url.Values{"query": {search.Text}}
+	if search.UserID != "" {
+		query.Set("userId", search.UserID)
+	}
+	if !search.From.IsZero() {
+		query.Set("from", search.From.UTC().Format(time.RFC3339Nano))
+	}
+	if !search.To.IsZero() {
+		query.Set("to", search.To.UTC().Format(time.RFC3339Nano))
+	}
+	if search.Limit != 0 {
+		query.Set("limit", strconv.Itoa(search.Limit))
+	}
+
+	var resp api.SearchEventsResponse
+	err := c.call(ctx, true, func(ctx context.Context) error {
+		return c.do(ctx, http.MethodGet, "/v1/events:search", query, nil, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return searchResultsFromProto(resp.GetResults()), nil
+}
+
+func (c *httpClient) TagStats(ctx context.Context, stats TagStatsQuery) ([]TagStat, error) {
+	query := url.Values{}
+	if !stats.From.IsZero() {
+		query.Set("from", stats.From.UTC().Format(time.RFC3339Nano))
+	}
+	if !stats.To.IsZero() {
+		query.Set("to", stats.To.UTC().Format(time.RFC3339Nano))
+	}
+	if stats.Limit != 0 {
+		query.Set("limit", strconv.Itoa(stats.Limit))
+	}
+
+	var resp api.TagStatsResponse
+	err := c.call(ctx, true, func(ctx context.Context) error {
+		return c.do(ctx, http.MethodGet, "/v1/tags:stats", query, nil, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tagStatsFromProto(resp.GetTags()), nil
+}
+
+func (c *httpClient) Watch(ctx context.Context, period Period, date time.Time, fn func(Change) error) error {
+	return watch(ctx, c, c.watchInterval, period, date, fn)
+}
+
+func (c *httpClient) Close() error {
+	c.http.CloseIdleConnections()
+	return nil
+}
+
+// do отправляет body в JSON-представлении protobuf и разбирает ответ в resp
+func (c *httpClient) do(ctx context.Context, method, path string, query url.Values, body, resp proto.Message) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := protojson.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	headers, err := c.credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	for _, header := range headers {
+		req.Header.Set(header[0], header[1])
+	}
+
+	httpResp, err := c.http.Do(req)
+	if err != nil {
+		return transportError(ctx, err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode >= http.StatusBadRequest {
+		return httpError(httpResp)
+	}
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return transportError(ctx, err)
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// transportError - запрос не дошёл до сервера или ответ оборвался. Если истёк таймаут попытки,
+// а не отменён вызов целиком, ошибка считается временной, как DeadlineExceeded в gRPC.
+func transportError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &Error{Code: codes.DeadlineExceeded, Message: err.Error()}
+	case ctx.Err() != nil:
+		return err
+	default:
+		return &Error{Code: codes.Unavailable, Message: err.Error()}
+	}
+}
+
+// httpError разбирает ошибку /v1 (google.rpc.Status от шлюза) или problem+json
+// от middleware сервера, которые отвечают раньше шлюза
+func httpError(resp *http.Response) error {
+	var body struct {
+		Code    codes.Code `json:"code"`
+		Message string     `json:"message"`
+		Detail  string     `json:"detail"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	_ = json.Unmarshal(data, &body)
+
+	e := &Error{Code: body.Code, Message: body.Message}
+	if e.Code == codes.OK {
+		e.Code = codeFromHTTPStatus(resp.StatusCode)
+	}
+	if e.Message == "" {
+		e.Message = body.Detail
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		e.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return e
+}
+
+func codeFromHTTPStatus(status int) codes.Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Unknown
+	}
+}
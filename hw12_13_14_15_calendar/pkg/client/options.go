@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Значения по умолчанию
+const (
+	DefaultTimeout       = 10 * time.Second
+	DefaultBackoff       = 200 * time.Millisecond
+	DefaultWatchInterval = 5 * time.Second
+)
+
+// TokenSource возвращает токен доступа перед каждым запросом, поэтому может обновлять его
+type TokenSource func(ctx context.Context) (string, error)
+
+type options struct {
+	timeout       time.Duration
+	retries       int
+	backoff       time.Duration
+	userID        string
+	groups        []string
+	token         TokenSource
+	tls           *tls.Config
+	httpClient    *http.Client
+	watchInterval time.Duration
+}
+
+// Option настраивает клиент при создании
+type Option func(*options)
+
+func newOptions(opts []Option) options {
+	o := options{
+		timeout:       DefaultTimeout,
+		backoff:       DefaultBackoff,
+		watchInterval: DefaultWatchInterval,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTimeout ограничивает длительность одной попытки запроса; 0 - без ограничения
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetries включает повторы при недоступности сервера, истёкшем таймауте попытки
+// и превышении лимита запросов. Пауза между попытками начинается с backoff и удваивается,
+// но не бывает меньше Retry-After, которым ответил сервер.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.retries = retries
+		o.backoff = backoff
+	}
+}
+
+// WithUser передаёт пользователя, от имени которого выполняются запросы, и его группы
+func WithUser(userID string, groups ...string) Option {
+	return func(o *options) {
+		o.userID = userID
+		o.groups = groups
+	}
+}
+
+// WithToken передаёт токен в заголовке Authorization: Bearer.
+// Сам сервис токены не проверяет - это делает прокси перед ним.
+func WithToken(token string) Option {
+	return WithTokenSource(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// WithTokenSource - как WithToken, но токен запрашивается перед каждым запросом
+func WithTokenSource(source TokenSource) Option {
+	return func(o *options) {
+		o.token = source
+	}
+}
+
+// WithTLS включает TLS; для mTLS в конфигурации задаётся сертификат клиента
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+	}
+}
+
+// WithHTTPClient задаёт HTTP-клиент для NewHTTP; его Transport используется как есть, без WithTLS
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithWatchInterval задаёт период опроса сервера в Watch
+func WithWatchInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.watchInterval = interval
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// EachPage передаёт fn события интервала [from, to) постранично: одна страница - один день,
+// неделя (с понедельника) или месяц по UTC, события внутри страницы упорядочены по началу.
+// Курсоров у API нет, поэтому страницы строятся по календарю; пустые страницы пропускаются.
+func EachPage(ctx context.Context, c Client, period Period, from, to time.Time, fn func(page []Event) error) error {
+	seen := map[string]struct{}{}
+	for cursor := periodStart(period, from.UTC()); cursor.Before(to); cursor = nextPeriod(period, cursor) {
+		events, err := c.ListEvents(ctx, period, cursor)
+		if err != nil {
+			return err
+		}
+
+		page := make([]Event, 0, len(events))
+		for _, event := range events {
+			if _, ok := seen[event.ID]; ok || event.StartTime.Before(from) || !event.StartTime.Before(to) {
+				continue
+			}
+			seen[event.ID] = struct{}{}
+			page = append(page, event)
+		}
+		if len(page) == 0 {
+			continue
+		}
+		sort.SliceStable(page, func(i, j int) bool {
+			return page[i].StartTime.Before(page[j].StartTime)
+		})
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListRange возвращает все события интервала [from, to), запрашивая их помесячно
+func ListRange(ctx context.Context, c Client, from, to time.Time) ([]Event, error) {
+	var events []Event
+	err := EachPage(ctx, c, Month, from, to, func(page []Event) error {
+		events = append(events, page...)
+		return nil
+	})
+	return events, err
+}
+
+func periodStart(period Period, t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch period {
+	case Week:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case Month:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+func nextPeriod(period Period, start time.Time) time.Time {
+	switch period {
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+// call выполняет запрос с таймаутом на каждую попытку и, если включены повторы, повторяет его
+// при временных ошибках. Неидемпотентные запросы не повторяются.
+func (o *options) call(ctx context.Context, idempotent bool, fn func(ctx context.Context) error) error {
+	backoff := o.backoff
+	for attempt := 0; ; attempt++ {
+		err := o.attempt(ctx, fn)
+		if err == nil || !idempotent || attempt >= o.retries || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+
+		wait := backoff
+		var e *Error
+		if errors.As(err, &e) && e.RetryAfter > wait {
+			wait = e.RetryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func (o *options) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+// retryable - ошибки, после которых тот же запрос может пройти:
+// сервер недоступен, не успел ответить или исчерпан лимит. Aborted не повторяется:
+// этим кодом сервер сообщает и о конфликте версий, который повтор не разрешит.
+func retryable(err error) bool {
+	switch Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// withRequestID генерирует ключ идемпотентности создания, если повторы включены, а ключа нет.
+// Вместе с ключом возвращается признак, что запрос можно повторять.
+func (o *options) withRequestID(event NewEvent) (NewEvent, bool) {
+	if event.RequestID == "" && o.retries > 0 {
+		event.RequestID = uuid.New().String()
+	}
+	return event, event.RequestID != ""
+}
+
+// credentials - пары заголовок/значение с пользователем и токеном для очередного запроса
+func (o *options) credentials(ctx context.Context) ([][2]string, error) {
+	var headers [][2]string
+	if o.userID != "" {
+		headers = append(headers, [2]string{"x-user-id", o.userID})
+	}
+	if len(o.groups) > 0 {
+		headers = append(headers, [2]string{"x-user-groups", strings.Join(o.groups, ",")})
+	}
+	if o.token != nil {
+		token, err := o.token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			headers = append(headers, [2]string{"authorization", "Bearer " + token})
+		}
+	}
+	return headers, nil
+}
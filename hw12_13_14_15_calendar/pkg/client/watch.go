@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// ChangeType - что произошло с событием
+type ChangeType int
+
+const (
+	// Added - событие появилось в периоде; первым опросом Watch так сообщает обо всех текущих событиях
+	Added ChangeType = iota
+	// Updated - изменилась версия события
+	Updated
+	// Removed - событие удалено или перенесено за пределы периода
+	Removed
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "added"
+	case Updated:
+		return "updated"
+	case Removed:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// Change - изменение, найденное Watch. У Removed событие - последнее, каким его видел клиент.
+type Change struct {
+	Type  ChangeType
+	Event Event
+}
+
+// watch опрашивает список событий периода и сравнивает его с предыдущим по версиям.
+// Временные ошибки пропускают очередной опрос, остальные завершают наблюдение.
+func watch(ctx context.Context, c Client, interval time.Duration, period Period, date time.Time, fn func(Change) error) error {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	known := map[string]Event{}
+	for {
+		events, err := c.ListEvents(ctx, period, date)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil && !retryable(err):
+			return err
+		case err == nil:
+			if err := diff(known, events, fn); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// diff сообщает fn о различиях между known и events и приводит known к events
+func diff(known map[string]Event, events []Event, fn func(Change) error) error {
+	seen := make(map[string]struct{}, len(events))
+	for _, event := range events {
+		seen[event.ID] = struct{}{}
+		previous, ok := known[event.ID]
+		known[event.ID] = event
+		switch {
+		case !ok:
+			if err := fn(Change{Type: Added, Event: event}); err != nil {
+				return err
+			}
+		case previous.Version != event.Version:
+			if err := fn(Change{Type: Updated, Event: event}); err != nil {
+				return err
+			}
+		}
+	}
+	for id, event := range known {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		delete(known, id)
+		if err := fn(Change{Type: Removed, Event: event}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Faoxis/golang_hw/hw12_13_14_15_calendar/pkg/client"
+)
+
+const testUser = "test_user"
+
+var (
+	apiURL   string
+	grpcAddr string
+)
+
+func TestMain(m *testing.M) {
+	setup()
+	code := m.Run()
+	os.Exit(code)
+}
+
+func main() {
+	// testing.Main не вызывает TestMain, поэтому окружение готовится здесь
+	setup()
+
+	// Запускаем тесты
+	testing.Main(func(pat, str string) (bool, error) { return true, nil },
+		[]testing.InternalTest{
+			{"TestIntegration", TestIntegration},
+		},
+		nil,
+		nil)
+}
+
+func setup() {
+	apiURL = os.Getenv("API_URL")
+	if apiURL == "" {
+		apiURL = "http://localhost:8888"
+	}
+	// gRPC проверяется, только если задан адрес
+	grpcAddr = os.Getenv("GRPC_ADDR")
+
+	// Ждем, пока API станет доступен
+	waitForAPI()
+}
+
+// newClients - клиенты всех проверяемых транспортов, по имени транспорта
+func newClients() (map[string]client.Client, error) {
+	opts := []client.Option{
+		client.WithUser(testUser),
+		client.WithRetries(3, 500*time.Millisecond),
+		client.WithWatchInterval(time.Second),
+	}
+	httpClient, err := client.NewHTTP(apiURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	clients := map[string]client.Client{"http": httpClient}
+	if grpcAddr != "" {
+		grpcClient, err := client.NewGRPC(grpcAddr, opts...)
+		if err != nil {
+			return nil, err
+		}
+		clients["grpc"] = grpcClient
+	}
+	return clients, nil
+}
+
+func waitForAPI() {
+	c, err := client.NewHTTP(apiURL, client.WithUser(testUser), client.WithTimeout(2*time.Second))
+	if err != nil {
+		panic(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 30; i++ {
+		if _, err := c.ListEvents(context.Background(), client.Day, time.Now()); err == nil {
+			fmt.Println("API is ready")
+			return
+		}
+		time.Sleep(2 * time.Second)
+	}
+	panic("API is not available after 60 seconds")
+}
+
+func TestIntegration(t *testing.T) {
+	clients, err := newClients()
+	if err != nil {
+		t.Fatalf("Failed to create clients: %v", err)
+	}
+	for name, c := range clients {
+		t.Run(name, func(t *testing.T) {
+			defer c.Close()
+			t.Run("EventCRUD", func(t *testing.T) { testEventCRUD(t, c) })
+			t.Run("EventListing", func(t *testing.T) { testEventListing(t, c) })
+			t.Run("Watch", func(t *testing.T) { testWatch(t, c) })
+			t.Run("Notifications", func(t *testing.T) { testNotifications(t, c) })
+		})
+	}
+}
+
+func testEventCRUD(t *testing.T, c client.Client) {
+	t.Log("Testing Event CRUD operations...")
+	ctx := context.Background()
+
+	// Создаем событие
+	event := client.NewEvent{
+		Title:        "Test Event",
+		Description:  "Test Description",
+		StartTime:    time.Now().Add(time.Hour),
+		Duration:     time.Hour,
+		UserID:       testUser,
+		NotifyBefore: 5 * time.Minute,
+	}
+	createdEvent, err := c.CreateEvent(ctx, event)
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if createdEvent.Title != event.Title {
+		t.Errorf("Expected title %s, got %s", event.Title, createdEvent.Title)
+	}
+
+	// Читаем событие
+	retrievedEvent, err := c.GetEvent(ctx, createdEvent.ID)
+	if err != nil {
+		t.Fatalf("Failed to get event: %v", err)
+	}
+	if retrievedEvent.ID != createdEvent.ID {
+		t.Errorf("Expected ID %s, got %s", createdEvent.ID, retrievedEvent.ID)
+	}
+
+	// Обновляем событие
+	updatedEvent, err := c.UpdateEvent(ctx, client.EventUpdate{
+		ID:              createdEvent.ID,
+		Title:           "Updated Test Event",
+		Description:     "Updated Description",
+		StartTime:       time.Now().Add(2 * time.Hour),
+		Duration:        2 * time.Hour,
+		UserID:          testUser,
+		NotifyBefore:    10 * time.Minute,
+		ExpectedVersion: retrievedEvent.Version,
+	})
+	if err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if updatedEvent.Title != "Updated Test Event" {
+		t.Errorf("Expected updated title, got %s", updatedEvent.Title)
+	}
+
+	// Удаляем событие
+	if err := c.DeleteEvent(ctx, createdEvent.ID, updatedEvent.Version); err != nil {
+		t.Fatalf("Failed to delete event: %v", err)
+	}
+
+	// Проверяем, что событие удалено
+	if _, err := c.GetEvent(ctx, createdEvent.ID); !client.IsNotFound(err) {
+		t.Fatalf("Expected not found for deleted event, got %v", err)
+	}
+}
+
+func testEventListing(t *testing.T, c client.Client) {
+	t.Log("Testing Event listing operations...")
+	ctx := context.Background()
+
+	// Создаем несколько событий для тестирования
+	now := time.Now()
+	events := []client.NewEvent{
+		{Title: "Today Event 1", Description: "Event today", StartTime: now.Add(time.Hour), Duration: time.Hour},
+		{Title: "Today Event 2", Description: "Another event today", StartTime: now.Add(2 * time.Hour), Duration: 30 * time.Minute},
+		{Title: "Tomorrow Event", Description: "Event tomorrow", StartTime: now.AddDate(0, 0, 1).Add(time.Hour), Duration: time.Hour},
+	}
+
+	createdEvents := make([]client.Event, 0, len(events))
+	for _, event := range events {
+		event.UserID = testUser
+		event.NotifyBefore = 5 * time.Minute
+		createdEvent, err := c.CreateEvent(ctx, event)
+		if err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+		createdEvents = append(createdEvents, createdEvent)
+	}
+	// Очищаем созданные события
+	defer func() {
+		for _, event := range createdEvents {
+			if err := c.DeleteEvent(ctx, event.ID, 0); err != nil {
+				t.Logf("Failed to delete test event %s: %v", event.ID, err)
+			}
+		}
+	}()
+
+	// Каждое событие видно в дне, неделе и месяце, на которые оно приходится
+	for _, event := range createdEvents {
+		for _, period := range []client.Period{client.Day, client.Week, client.Month} {
+			listed, err := c.ListEvents(ctx, period, event.StartTime)
+			if err != nil {
+				t.Fatalf("Failed to get events for %s: %v", period, err)
+			}
+			if !containsEvent(listed, event.ID) {
+				t.Errorf("Event %q is missing from its %s", event.Title, period)
+			}
+		}
+	}
+
+	// Постраничный обход интервала находит все события
+	listed, err := client.ListRange(ctx, c, now, now.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("Failed to list events range: %v", err)
+	}
+	for _, event := range createdEvents {
+		if !containsEvent(listed, event.ID) {
+			t.Errorf("Event %q is missing from range", event.Title)
+		}
+	}
+}
+
+func testWatch(t *testing.T, c client.Client) {
+	t.Log("Testing Watch...")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	start := time.Now().AddDate(0, 0, 3)
+	added := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Watch(ctx, client.Day, start, func(change client.Change) error {
+			if change.Type == client.Added && change.Event.Title == "Watched Event" {
+				close(added)
+				return context.Canceled
+			}
+			return nil
+		})
+	}()
+
+	// Неважно, успеет ли Watch сделать первый опрос: о событиях начального снимка он тоже сообщает как о новых
+	created, err := c.CreateEvent(ctx, client.NewEvent{
+		Title: "Watched Event", StartTime: start, Duration: time.Hour, UserID: testUser,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	defer c.DeleteEvent(context.Background(), created.ID, 0)
+
+	select {
+	case <-added:
+	case err := <-done:
+		t.Fatalf("Watch stopped before the event was seen: %v", err)
+	}
+}
+
+func testNotifications(t *testing.T, c client.Client) {
+	t.Log("Testing Notifications...")
+	ctx := context.Background()
+
+	// Создаем событие с уведомлением через 1 минуту
+	createdEvent, err := c.CreateEvent(ctx, client.NewEvent{
+		Title:        "Notification Test Event",
+		Description:  "Event for notification testing",
+		StartTime:    time.Now().Add(2 * time.Minute),
+		Duration:     time.Hour,
+		UserID:       testUser,
+		NotifyBefore: time.Minute, // Уведомление за 1 минуту
+	})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	// Ждем немного, чтобы scheduler мог обработать событие
+	time.Sleep(5 * time.Second)
+
+	// Проверяем, что событие все еще существует (не было удалено)
+	if _, err := c.GetEvent(ctx, createdEvent.ID); err != nil {
+		t.Fatalf("Failed to get event: %v", err)
+	}
+
+	// Очищаем тестовое событие
+	if err := c.DeleteEvent(ctx, createdEvent.ID, 0); err != nil {
+		t.Logf("Failed to delete test event: %v", err)
+	}
+
+	t.Log("Notification test completed - scheduler and sender are working")
+}
+
+func containsEvent(events []client.Event, id string) bool {
+	for _, event := range events {
+		if event.ID == id {
+			return true
+		}
+	}
+	return false
+}